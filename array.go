@@ -0,0 +1,279 @@
+package sqld
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isArrayType reports whether fields of type t hold Postgres arrays such as
+// text[], int[] or uuid[]: slices of strings, numbers, booleans, UUIDs or
+// decimals, or pointers to them.
+func isArrayType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	elem := t.Elem()
+	if canonicalValue(elem) != nil {
+		return true
+	}
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	switch elem.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Uint8:
+		return false // Binary, see isBinaryType
+	}
+	return isIntegerKind(elem.Kind())
+}
+
+// arrayElemType returns the type of the elements of the array type t.
+func arrayElemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Elem()
+}
+
+// arrayValue returns val, the value of an array field of type t, as a
+// []interface{} of its elements: strings, int64, float64 and booleans, and
+// the strings of UUIDs and decimals, see canonicalValue. database/sql
+// drivers return arrays as literals such as {a,"b c",NULL}, which are parsed;
+// pgx returns them decoded. Text that isn't an array literal, such as a JSON
+// array, is returned unchanged.
+func arrayValue(t reflect.Type, val interface{}) (interface{}, error) {
+	elem := arrayElemType(t)
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case rawValue:
+		return arrayValue(t, v.value)
+	case []byte:
+		return arrayValue(t, string(v))
+	case string:
+		if !strings.HasPrefix(v, "{") {
+			return val, nil
+		}
+		texts, err := parseArrayLiteral(v)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, len(texts))
+		for i, text := range texts {
+			if text == nil {
+				continue
+			}
+			if values[i], err = arrayElemValue(elem, *text); err != nil {
+				return nil, fmt.Errorf("element %d: %w", i+1, err)
+			}
+		}
+		return values, nil
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice {
+		return val, nil
+	}
+	convert := canonicalValue(elem)
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		value := rv.Index(i).Interface()
+		if convert != nil {
+			var err error
+			if value, err = convert(value); err != nil {
+				return nil, fmt.Errorf("element %d: %w", i+1, err)
+			}
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// arrayElemValue returns the element text of an array literal as a value of
+// elements of type elem.
+func arrayElemValue(elem reflect.Type, text string) (interface{}, error) {
+	if convert := canonicalValue(elem); convert != nil {
+		return convert(text)
+	}
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	switch {
+	case elem.Kind() == reflect.Bool:
+		return text == "t" || text == "true", nil
+	case elem.Kind() == reflect.Float32 || elem.Kind() == reflect.Float64:
+		return strconv.ParseFloat(text, 64)
+	case isIntegerKind(elem.Kind()):
+		return strconv.ParseInt(text, 10, 64)
+	}
+	return text, nil
+}
+
+// parseArrayLiteral returns the elements of a one-dimensional Postgres array
+// literal, nil for NULL.
+func parseArrayLiteral(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("invalid array %q", s)
+	}
+	body := s[1 : len(s)-1]
+	elems := []*string{}
+	if body == "" {
+		return elems, nil
+	}
+	for i := 0; ; i++ {
+		var elem strings.Builder
+		quoted := i < len(body) && body[i] == '"'
+		if quoted {
+			for i++; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				elem.WriteByte(body[i])
+			}
+			if i == len(body) {
+				return nil, fmt.Errorf("invalid array %q", s)
+			}
+			i++ // Closing quote
+		} else {
+			for ; i < len(body) && body[i] != ','; i++ {
+				if body[i] == '{' {
+					return nil, fmt.Errorf("multidimensional arrays are not supported: %q", s)
+				}
+				elem.WriteByte(body[i])
+			}
+		}
+
+		text := elem.String()
+		if !quoted && strings.EqualFold(text, "NULL") {
+			elems = append(elems, nil)
+		} else {
+			elems = append(elems, &text)
+		}
+		if i == len(body) {
+			return elems, nil
+		}
+		if body[i] != ',' {
+			return nil, fmt.Errorf("invalid array %q", s)
+		}
+	}
+}
+
+// arrayLiteral returns value, a list given for an array of type t, as the
+// Postgres array literal it is bound as, so that every driver accepts it.
+// Elements must be of the kind of the elements of t, numbers decoded from
+// JSON being accepted for numeric elements.
+func arrayLiteral(t reflect.Type, value interface{}) (string, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return "", fmt.Errorf("expected a list, got %T", value)
+	}
+	elem := arrayElemType(t)
+	elems := make([]string, v.Len())
+	for i := range elems {
+		item := v.Index(i)
+		if item.Kind() == reflect.Interface || item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				elems[i] = "NULL"
+				continue
+			}
+			item = item.Elem()
+		}
+		text, err := arrayElemText(elem, item.Interface())
+		if err != nil {
+			return "", fmt.Errorf("element %d: %w", i+1, err)
+		}
+		elems[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// arrayElemText returns value, an element of a list given for an array of
+// elements of type elem, as the text of an array literal.
+func arrayElemText(elem reflect.Type, value interface{}) (string, error) {
+	if convert := canonicalValue(elem); convert != nil {
+		converted, err := convert(value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprint(converted), nil
+	}
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case elem.Kind() == reflect.String && v.Kind() == reflect.String:
+		return v.String(), nil
+	case elem.Kind() == reflect.Bool && v.Kind() == reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case isNumericType(elem):
+		if n, ok := value.(json.Number); ok {
+			if _, err := strconv.ParseFloat(string(n), 64); err == nil {
+				return string(n), nil
+			}
+		}
+		switch {
+		case v.CanInt():
+			return strconv.FormatInt(v.Int(), 10), nil
+		case v.CanUint():
+			return strconv.FormatUint(v.Uint(), 10), nil
+		case v.CanFloat() && (!isIntegerKind(elem.Kind()) || v.Float() == math.Trunc(v.Float())):
+			return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+		}
+	}
+	return "", fmt.Errorf("expected %s, got %T", elem, value)
+}
+
+// rawValue scans the columns of ExecuteRaw results that database/sql drivers
+// can't scan into their fields, such as arrays, hstores and composite types,
+// keeping the value of the driver for arrayValue, hstoreValue and
+// compositeValue.
+type rawValue struct {
+	value interface{}
+}
+
+// Scan implements sql.Scanner.
+func (s *rawValue) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		src = string(b) // The driver may reuse the bytes
+	}
+	s.value = src
+	return nil
+}
+
+// rawScanType returns the struct type ExecuteRaw scans rows of type t into:
+// t with its array, hstore and composite fields scanned as rawValue. It
+// returns t itself when t has no such fields or embeds structs.
+func (r *Registry) rawScanType(t reflect.Type) reflect.Type {
+	if t.Kind() != reflect.Struct {
+		return t
+	}
+	var fields []reflect.StructField
+	raw := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			return t
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if _, composite := r.compositeAttributes(field.Type); composite || isArrayType(field.Type) || isHstoreType(field.Type) {
+			field.Type = reflect.TypeOf(rawValue{})
+			raw = true
+		}
+		fields = append(fields, reflect.StructField{Name: field.Name, Type: field.Type, Tag: field.Tag})
+	}
+	if !raw {
+		return t
+	}
+	return reflect.StructOf(fields)
+}
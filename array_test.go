@@ -0,0 +1,101 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ArrayArticle struct {
+	ID       int64       `json:"id" db:"id"`
+	Tags     []string    `json:"tags" db:"tags"`
+	Scores   []int64     `json:"scores" db:"scores"`
+	Editors  []uuid.UUID `json:"editors" db:"editors"`
+	Keywords []string    `json:"keywords" db:"keywords"`
+}
+
+func (ArrayArticle) TableName() string { return "articles" }
+
+type ArrayArticleParams struct {
+	Tags []string `db:"tags" json:"tags"`
+	IDs  []int64  `db:"ids" json:"ids"`
+}
+
+func TestParseArrayLiteral(t *testing.T) {
+	text := func(s string) *string { return &s }
+	tests := []struct {
+		literal string
+		want    []*string
+		wantErr string
+	}{
+		{literal: "{}", want: []*string{}},
+		{literal: "{a,b}", want: []*string{text("a"), text("b")}},
+		{literal: `{"b c","say \"hi\"",NULL,"NULL",""}`, want: []*string{text("b c"), text(`say "hi"`), nil, text("NULL"), text("")}},
+		{literal: "{{1,2},{3,4}}", wantErr: "multidimensional arrays are not supported"},
+		{literal: `{"a}`, wantErr: "invalid array"},
+		{literal: "a,b", wantErr: "invalid array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			got, err := parseArrayLiteral(tt.literal)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExecute_Arrays(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(ArrayArticle{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// lib/pq returns literals as bytes, pgx stdlib as strings
+	mock.ExpectQuery(`SELECT id, tags, scores, editors, keywords FROM articles`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "scores", "editors", "keywords"}).
+			AddRow(int64(1), []byte(`{go,"sql db",NULL}`), "{3,-1}", "{"+testUUID+"}", nil))
+	resp, err := Execute[ArrayArticle](ctx, db, QueryRequest{Select: []string{"id", "tags", "scores", "editors", "keywords"}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{
+		"id":       int64(1),
+		"tags":     []interface{}{"go", "sql db", nil},
+		"scores":   []interface{}{int64(3), int64(-1)},
+		"editors":  []interface{}{testUUID},
+		"keywords": nil,
+	}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteRaw_Arrays(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, tags FROM articles WHERE tags && \$1 AND id = ANY\(\$2\)`).
+		WithArgs(`{"go","say \"hi\""}`, `{"1","2"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags"}).AddRow(int64(1), []byte(`{go,sql}`)))
+	results, err := ExecuteRaw[ArrayArticleParams, ArrayArticle](context.Background(), db,
+		"SELECT id, tags FROM articles WHERE tags && {{tags}} AND id = ANY({{ids}})",
+		map[string]interface{}{"tags": []interface{}{"go", `say "hi"`}, "ids": []interface{}{1.0, 2.0}})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"go", "sql"}, results[0]["tags"])
+
+	_, err = ExecuteRaw[ArrayArticleParams, ArrayArticle](context.Background(), db,
+		"SELECT id FROM articles WHERE id = ANY({{ids}})", map[string]interface{}{"ids": []interface{}{1.5}})
+	assert.ErrorContains(t, err, "parameter ids: element 1: expected int64, got float64")
+	_, err = ExecuteRaw[ArrayArticleParams, ArrayArticle](context.Background(), db,
+		"SELECT id FROM articles WHERE tags && {{tags}}", map[string]interface{}{"tags": "go"})
+	assert.ErrorContains(t, err, "parameter tags: expected a list, got string")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,208 @@
+package sqld
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+)
+
+// arrowBatchRows is the number of rows of the record batches of WriteArrow.
+const arrowBatchRows = 1024
+
+// arrowTimestamp is the type of datetime columns of WriteArrow.
+var arrowTimestamp = &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+
+// WriteArrow runs req like ExecuteStream and writes its rows to w in the
+// Arrow IPC streaming format, in record batches of 1024 rows written and
+// flushed as they are read, so that data lakes and analytics tools can load
+// them without an ETL job. The schema follows the registered fields: integers
+// are int64, numbers float64, booleans bool and datetimes UTC timestamps in
+// microseconds. The other fields are utf8 holding the JSON value, such as
+// decimal strings, or the JSON text of arrays and objects. It returns the
+// number of rows written.
+func WriteArrow[T Model](ctx context.Context, db interface{}, req QueryRequest, w io.Writer, opts ...ExecuteOption) (written int, err error) {
+	stream, err := ExecuteStream[T](ctx, db, req, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+
+	w, closeOutput := exportWriter(newExecuteConfig(opts), w)
+	defer func() {
+		if closeErr := closeOutput(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to write rows: %w", closeErr)
+		}
+	}()
+
+	columns := stream.Columns()
+	schema := arrowSchema(stream.metadata, columns)
+	mem := memory.NewGoAllocator()
+	buf := bufio.NewWriter(w)
+	writer := ipc.NewWriter(buf, ipc.WithSchema(schema), ipc.WithAllocator(mem))
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+
+	batched := 0
+	writeBatch := func() error {
+		record := builder.NewRecord()
+		defer record.Release()
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write rows: %w", err)
+		}
+		batched = 0
+		return flushWriter(buf, w)
+	}
+
+	for stream.Next() {
+		row := stream.Row()
+		for i, column := range columns {
+			if err := appendArrowValue(builder.Field(i), row[column]); err != nil {
+				return written, fmt.Errorf("failed to write field %s: %w", column, err)
+			}
+		}
+		written++
+		if batched++; batched == arrowBatchRows {
+			if err := writeBatch(); err != nil {
+				return written, err
+			}
+		}
+	}
+	if batched > 0 {
+		if err := writeBatch(); err != nil {
+			return written, err
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return written, err // The rows read are written, without the end of the stream
+	}
+	if err := writer.Close(); err != nil {
+		return written, fmt.Errorf("failed to write rows: %w", err)
+	}
+	return written, flushWriter(buf, w)
+}
+
+// arrowSchema returns the schema of the Arrow columns of the selected fields
+// of metadata, see WriteArrow.
+func arrowSchema(metadata ModelMetadata, selected []string) *arrow.Schema {
+	fields := make([]arrow.Field, len(selected))
+	for i, name := range selected {
+		fields[i] = arrow.Field{Name: name, Type: arrow.BinaryTypes.String, Nullable: true}
+		field, ok := metadata.Fields[name]
+		if !ok {
+			continue
+		}
+		fields[i].Nullable = isNullableType(field.Type)
+		switch FieldType(field.Type) {
+		case "integer":
+			fields[i].Type = arrow.PrimitiveTypes.Int64
+		case "number":
+			fields[i].Type = arrow.PrimitiveTypes.Float64
+		case "boolean":
+			fields[i].Type = arrow.FixedWidthTypes.Boolean
+		case "datetime":
+			fields[i].Type = arrowTimestamp
+		}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// appendArrowValue appends value, a value of a row, to the column built by b.
+// Datetimes are accepted in every format of TimeOutput.
+func appendArrowValue(b array.Builder, value interface{}) error {
+	if value == nil {
+		b.AppendNull()
+		return nil
+	}
+	switch b := b.(type) {
+	case *array.Int64Builder:
+		n, err := arrowInteger(value)
+		if err != nil {
+			return err
+		}
+		b.Append(n)
+	case *array.Float64Builder:
+		n, err := arrowNumber(value)
+		if err != nil {
+			return err
+		}
+		b.Append(n)
+	case *array.BooleanBuilder:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("expected a boolean, got %T", value)
+		}
+		b.Append(v)
+	case *array.TimestampBuilder:
+		var t time.Time
+		switch v := value.(type) {
+		case time.Time:
+			t = v
+		case string:
+			parsed, ok := parseTimeText(v)
+			if !ok {
+				return fmt.Errorf("%q is not a time", v)
+			}
+			t = parsed
+		default:
+			millis, err := arrowNumber(value) // TimeFormatEpochMillis
+			if err != nil {
+				return fmt.Errorf("expected a time, got %T", value)
+			}
+			t = time.UnixMilli(int64(millis))
+		}
+		b.Append(arrow.Timestamp(t.UnixMicro()))
+	case *array.StringBuilder:
+		text, err := exportText(value)
+		if err != nil {
+			return err
+		}
+		b.Append(text)
+	default:
+		return fmt.Errorf("unsupported column builder %T", b)
+	}
+	return nil
+}
+
+// arrowInteger returns value, an integer of a row, as an int64.
+func arrowInteger(value interface{}) (int64, error) {
+	if n, ok := value.(json.Number); ok {
+		return n.Int64()
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt():
+		return v.Int(), nil
+	case v.CanUint() && v.Uint() <= math.MaxInt64:
+		return int64(v.Uint()), nil
+	case v.CanFloat() && v.Float() == math.Trunc(v.Float()):
+		return int64(v.Float()), nil
+	}
+	return 0, fmt.Errorf("expected an integer, got %v", value)
+}
+
+// arrowNumber returns value, a number of a row, as a float64.
+func arrowNumber(value interface{}) (float64, error) {
+	if n, ok := value.(json.Number); ok {
+		return n.Float64()
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), nil
+	case v.CanUint():
+		return float64(v.Uint()), nil
+	case v.CanFloat():
+		return v.Float(), nil
+	}
+	return 0, fmt.Errorf("expected a number, got %T", value)
+}
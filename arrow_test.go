@@ -0,0 +1,67 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteArrow(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(TypedOrder{}))
+	registry.SetTimeOutput(TimeOutput{Format: TimeFormatRFC3339})
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	placed := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "quantity", "total", "placed_at", "coupon"})
+	for i := 1; i <= arrowBatchRows+1; i++ {
+		coupon := interface{}(nil)
+		if i == 1 {
+			coupon = "SPRING"
+		}
+		rows.AddRow("6ba7b810-9dad-11d1-80b4-00c04fd430c8", i, "12.50", placed, coupon)
+	}
+	mock.ExpectQuery(`SELECT id, quantity, total, placed_at, coupon FROM orders`).WillReturnRows(rows)
+	var out bytes.Buffer
+	n, err := WriteArrow[TypedOrder](ctx, db, QueryRequest{Select: []string{"id", "quantity", "total", "placed_at", "coupon"}}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, arrowBatchRows+1, n)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	reader, err := ipc.NewReader(&out)
+	require.NoError(t, err)
+	defer reader.Release()
+	schema := reader.Schema()
+	assert.Equal(t, arrow.BinaryTypes.String, schema.Field(0).Type)
+	assert.Equal(t, arrow.PrimitiveTypes.Int64, schema.Field(1).Type)
+	assert.Equal(t, arrow.BinaryTypes.String, schema.Field(2).Type)
+	assert.Equal(t, arrowTimestamp, schema.Field(3).Type)
+	assert.False(t, schema.Field(3).Nullable)
+	assert.True(t, schema.Field(4).Nullable)
+
+	require.True(t, reader.Next())
+	record := reader.Record()
+	require.EqualValues(t, arrowBatchRows, record.NumRows())
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", record.Column(0).(*array.String).Value(0))
+	assert.Equal(t, int64(1), record.Column(1).(*array.Int64).Value(0))
+	assert.Equal(t, "12.50", record.Column(2).(*array.String).Value(0))
+	assert.Equal(t, arrow.Timestamp(placed.UnixMicro()), record.Column(3).(*array.Timestamp).Value(0))
+	assert.Equal(t, "SPRING", record.Column(4).(*array.String).Value(0))
+	assert.True(t, record.Column(4).IsNull(1))
+	require.True(t, reader.Next())
+	assert.EqualValues(t, 1, reader.Record().NumRows())
+	assert.False(t, reader.Next())
+	require.NoError(t, reader.Err())
+}
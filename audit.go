@@ -0,0 +1,137 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Audit describes the audit columns of a model, which Insert, InsertMany,
+// Update and Patch fill automatically. Every field is optional; callers can't
+// set the fields listed here themselves.
+type Audit struct {
+	CreatedAtField string // JSON name of the field set to the current time on insert
+	UpdatedAtField string // JSON name of the field set to the current time on insert and update
+	CreatedByField string // JSON name of the field set to the actor on insert
+	UpdatedByField string // JSON name of the field set to the actor on insert and update
+}
+
+// fields returns the JSON names of the audit fields, skipping unset ones.
+func (a Audit) fields() []string {
+	var fields []string
+	for _, name := range []string{a.CreatedAtField, a.UpdatedAtField, a.CreatedByField, a.UpdatedByField} {
+		if name != "" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// ActorExtractor returns the actor of a request, such as the id of the
+// authenticated user, from its context. It returns false when there is none.
+type ActorExtractor func(ctx context.Context) (interface{}, bool)
+
+// RegisterAudit declares the audit columns of model T in the default
+// registry.
+func RegisterAudit[T Model](a Audit) error {
+	var model T
+	return defaultRegistry.RegisterAudit(model, a)
+}
+
+// RegisterAudit declares the audit columns of a registered model.
+func (r *Registry) RegisterAudit(model Model, a Audit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(model)
+	metadata, ok := r.models[t]
+	if !ok {
+		return &ModelNotRegisteredError{Model: t.Name()}
+	}
+	fields := a.fields()
+	if len(fields) == 0 {
+		return fmt.Errorf("audit must declare at least one field")
+	}
+	for _, name := range fields {
+		if _, ok := metadata.Fields[name]; !ok {
+			return &UnknownFieldError{Field: name, In: "audit"}
+		}
+	}
+	r.audits[t] = a
+	return nil
+}
+
+// SetActorExtractor sets the actor extractor of the default registry.
+// Without one, the created-by and updated-by audit fields are left unset.
+func SetActorExtractor(extractor ActorExtractor) {
+	defaultRegistry.SetActorExtractor(extractor)
+}
+
+// SetActorExtractor sets the actor extractor of the registry.
+func (r *Registry) SetActorExtractor(extractor ActorExtractor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actor = extractor
+}
+
+// actorFromContext returns the actor of ctx, if any.
+func (r *Registry) actorFromContext(ctx context.Context) (interface{}, bool) {
+	r.mu.RLock()
+	extractor := r.actor
+	r.mu.RUnlock()
+	if extractor == nil {
+		return nil, false
+	}
+	return extractor(ctx)
+}
+
+// auditValues returns the values of the audit fields of metadata written by
+// an insert (or an update when insert is false), keyed by JSON name.
+func (r *Registry) auditValues(ctx context.Context, metadata ModelMetadata, insert bool) map[string]interface{} {
+	a := metadata.Audit
+	if a == nil {
+		return nil
+	}
+	values := make(map[string]interface{})
+	now := currentTime(metadata.dialect())
+	if insert && a.CreatedAtField != "" {
+		values[a.CreatedAtField] = now
+	}
+	if a.UpdatedAtField != "" {
+		values[a.UpdatedAtField] = now
+	}
+	if actor, ok := r.actorFromContext(ctx); ok {
+		if insert && a.CreatedByField != "" {
+			values[a.CreatedByField] = actor
+		}
+		if a.UpdatedByField != "" {
+			values[a.UpdatedByField] = actor
+		}
+	}
+	return values
+}
+
+// isAuditField reports whether name is an audit field of metadata.
+func isAuditField(metadata ModelMetadata, name string) bool {
+	if metadata.Audit == nil {
+		return false
+	}
+	for _, field := range metadata.Audit.fields() {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withValues returns a copy of values with extra added.
+func withValues(values, extra map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(values)+len(extra))
+	for k, v := range values {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,117 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type AuditedNote struct {
+	ID        int64     `json:"id" db:"id" sqld:"readonly"`
+	Body      string    `json:"body" db:"body" sqld:"required"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	CreatedBy string    `json:"created_by" db:"created_by" sqld:"required"`
+	UpdatedBy string    `json:"updated_by" db:"updated_by"`
+}
+
+func (AuditedNote) TableName() string {
+	return "notes"
+}
+
+// actorKey is the context key of the actor in tests.
+type actorKey struct{}
+
+func setupAudit(t *testing.T) {
+	t.Helper()
+	require.NoError(t, Register(AuditedNote{}))
+	require.NoError(t, RegisterAudit[AuditedNote](Audit{
+		CreatedAtField: "created_at",
+		UpdatedAtField: "updated_at",
+		CreatedByField: "created_by",
+		UpdatedByField: "updated_by",
+	}))
+	SetActorExtractor(func(ctx context.Context) (interface{}, bool) {
+		actor, ok := ctx.Value(actorKey{}).(string)
+		return actor, ok
+	})
+	t.Cleanup(func() { SetActorExtractor(nil) })
+}
+
+func TestRegisterAudit_Invalid(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(AuditedNote{}))
+
+	assert.Error(t, registry.RegisterAudit(AuditedNote{}, Audit{}))
+	assert.Error(t, registry.RegisterAudit(AuditedNote{}, Audit{CreatedAtField: "inserted_at"}))
+	assert.Error(t, registry.RegisterAudit(SalesReport{}, Audit{CreatedAtField: "created_at"}))
+}
+
+func TestInsert_Audit(t *testing.T) {
+	setupAudit(t)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO notes \(body,created_at,created_by,updated_at,updated_by\) VALUES \(\$1,CURRENT_TIMESTAMP,\$2,CURRENT_TIMESTAMP,\$3\) RETURNING`).
+		WithArgs("hello", "alice", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	ctx := context.WithValue(context.Background(), actorKey{}, "alice")
+	_, err = Insert[AuditedNote](ctx, db, InsertRequest{Values: map[string]interface{}{"body": "hello"}})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = Insert[AuditedNote](ctx, db, InsertRequest{Values: map[string]interface{}{"body": "hello", "created_by": "mallory"}})
+	assert.ErrorContains(t, err, "set automatically")
+}
+
+func TestInsertMany_Audit(t *testing.T) {
+	setupAudit(t)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Without an actor, only the timestamps are set
+	mock.ExpectExec(`INSERT INTO notes \(body,created_at,updated_at\) VALUES \(\$1,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP\),\(\$2,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP\)`).
+		WithArgs("a", "b").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	_, err = InsertMany[AuditedNote](context.Background(), db, InsertManyRequest{
+		Rows: []map[string]interface{}{{"body": "a"}, {"body": "b"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_Audit(t *testing.T) {
+	setupAudit(t)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`UPDATE notes SET body = \$1, updated_at = CURRENT_TIMESTAMP, updated_by = \$2 WHERE id = \$3 RETURNING`).
+		WithArgs("edited", "bob", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	ctx := context.WithValue(context.Background(), actorKey{}, "bob")
+	_, err = Update[AuditedNote](ctx, db, UpdateRequest{
+		Set:   map[string]interface{}{"body": "edited"},
+		Where: map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = Update[AuditedNote](ctx, db, UpdateRequest{
+		Set:   map[string]interface{}{"updated_at": time.Now()},
+		Where: map[string]interface{}{"id": 1},
+	})
+	assert.ErrorContains(t, err, "set automatically")
+}
@@ -0,0 +1,91 @@
+package sqld
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// BinaryEncoding is how the values of binary fields are encoded in results,
+// see BinaryOutput.
+type BinaryEncoding int
+
+const (
+	// BinaryBase64 emits binary values as standard base64 strings, the
+	// encoding encoding/json uses for []byte.
+	BinaryBase64 BinaryEncoding = iota
+	// BinaryHex emits binary values as lowercase hex strings.
+	BinaryHex
+)
+
+// BinaryOutput configures how the values of binary fields, such as bytea and
+// blob columns held in []byte fields, are emitted in results.
+type BinaryOutput struct {
+	Encoding BinaryEncoding
+	MaxSize  int // Largest value in bytes, 0 for no limit; larger values fail the query
+}
+
+// SetBinaryOutput sets how the default registry emits the values of binary
+// fields in Execute and ExecuteRaw results. Without it they are base64
+// strings of any size.
+func SetBinaryOutput(output BinaryOutput) {
+	defaultRegistry.SetBinaryOutput(output)
+}
+
+// SetBinaryOutput sets how the registry emits the values of binary fields.
+func (r *Registry) SetBinaryOutput(output BinaryOutput) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.binary = output
+}
+
+// isBinaryType reports whether fields of type t hold binary values: []byte
+// and other byte slices, or pointers to them, that don't encode themselves
+// like json.RawMessage does.
+func isBinaryType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 &&
+		t != reflect.TypeOf(json.RawMessage{}) &&
+		!reflect.PointerTo(t).Implements(sqlScannerType) &&
+		!t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem())
+}
+
+// binaryValue returns val, the value of a binary field, encoded as set with
+// SetBinaryOutput. Text is encoded as its bytes; nil stays nil.
+func (r *Registry) binaryValue(val interface{}) (interface{}, error) {
+	var b []byte
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		b = v
+	case *[]byte:
+		if v == nil {
+			return nil, nil
+		}
+		b = *v
+	case string:
+		b = []byte(v)
+	default:
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Uint8 {
+			return val, nil
+		}
+		b = rv.Bytes()
+	}
+
+	r.mu.RLock()
+	output := r.binary
+	r.mu.RUnlock()
+	if output.MaxSize > 0 && len(b) > output.MaxSize {
+		return nil, fmt.Errorf("value of %d bytes exceeds the limit of %d", len(b), output.MaxSize)
+	}
+	if output.Encoding == BinaryHex {
+		return hex.EncodeToString(b), nil
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
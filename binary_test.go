@@ -0,0 +1,72 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type BinaryAttachment struct {
+	ID        int64   `json:"id" db:"id"`
+	Content   []byte  `json:"content" db:"content"`
+	Thumbnail *[]byte `json:"thumbnail" db:"thumbnail"`
+}
+
+func (BinaryAttachment) TableName() string { return "attachments" }
+
+func TestSetBinaryOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  *BinaryOutput
+		want    QueryResult
+		wantErr string
+	}{
+		{name: "base64 by default", want: QueryResult{"id": int64(1), "content": "3q2+7w==", "thumbnail": nil}},
+		{name: "hex", output: &BinaryOutput{Encoding: BinaryHex}, want: QueryResult{"id": int64(1), "content": "deadbeef", "thumbnail": nil}},
+		{name: "within limit", output: &BinaryOutput{MaxSize: 4}, want: QueryResult{"id": int64(1), "content": "3q2+7w==", "thumbnail": nil}},
+		{name: "over limit", output: &BinaryOutput{MaxSize: 3}, wantErr: "failed to scan field content: value of 4 bytes exceeds the limit of 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			require.NoError(t, registry.Register(BinaryAttachment{}))
+			if tt.output != nil {
+				registry.SetBinaryOutput(*tt.output)
+			}
+			ctx := WithRegistry(context.Background(), registry)
+
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT id, content, thumbnail FROM attachments`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "content", "thumbnail"}).AddRow(int64(1), []byte{0xde, 0xad, 0xbe, 0xef}, nil))
+			resp, err := Execute[BinaryAttachment](ctx, db, QueryRequest{Select: []string{"id", "content", "thumbnail"}})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []QueryResult{tt.want}, resp.Data)
+		})
+	}
+}
+
+func TestExecuteRaw_Binary(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetBinaryOutput(BinaryOutput{Encoding: BinaryHex})
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, content FROM attachments`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}).AddRow(int64(1), []byte{0x01, 0xff}))
+	results, err := ExecuteRaw[struct{}, BinaryAttachment](ctx, db, "SELECT id, content FROM attachments", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "01ff", results[0]["content"])
+}
@@ -24,73 +24,229 @@ func buildQuery[T Model](req QueryRequest) (squirrel.SelectBuilder, error) {
 	if err != nil {
 		return squirrel.SelectBuilder{}, fmt.Errorf("failed to get model metadata: %w", err)
 	}
+	req = withDefaultSelect(metadata, req)
+	metadata, err = metadata.owner().resolveRequest(metadata, req)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	return buildSelect(metadata, req)
+}
+
+// buildSelect builds the SELECT statement for req against the given model metadata.
+func buildSelect(metadata ModelMetadata, req QueryRequest) (squirrel.SelectBuilder, error) {
+	scopes, err := resolveCTEs(metadata, req.With)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	source, err := sourceMetadata(metadata, scopes, req.From)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	query, err := selectBody(source, req)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	return applyCTEs(query, metadata, scopes, req.With)
+}
 
+// selectBody builds the SELECT statement for req without its WITH clause.
+func selectBody(metadata ModelMetadata, req QueryRequest) (squirrel.SelectBuilder, error) {
 	// Validate select fields
 	if len(req.Select) == 0 {
 		return squirrel.SelectBuilder{}, fmt.Errorf("select fields cannot be empty")
 	}
 
-	// Use Postgres placeholder format ($1, $2, etc)
-	builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	// Use the placeholder format of the dialect ($1, $2, etc for Postgres)
+	dialect := metadata.dialect()
+	builder := squirrel.StatementBuilder.PlaceholderFormat(dialect.Placeholder())
+
+	// Trees are read from a CTE aliased with the table name
+	table := metadata
+	if req.Tree != nil {
+		metadata = metadata.aliased()
+	}
+
+	// Columns are qualified as soon as related tables are joined
+	relations := referencedRelations(metadata, req)
+	qualify := len(relations) > 0
 
-	// Convert JSON field names to actual field names for SELECT
+	// Convert JSON field names to actual field names for SELECT.
+	// Fields of related models are aliased with the requested name so that
+	// they can't collide with identically named columns of the model.
 	selectFields := make([]string, len(req.Select))
 	for i, jsonName := range req.Select {
-		field, ok := metadata.Fields[jsonName]
+		ref, ok := metadata.lookupField(jsonName)
 		if !ok {
-			return squirrel.SelectBuilder{}, fmt.Errorf("invalid field in select: %s", jsonName)
+			return squirrel.SelectBuilder{}, unknownField(metadata, jsonName, "select")
+		}
+		selectFields[i] = ref.column(metadata, qualify)
+		if ref.Relation != "" {
+			selectFields[i] = fmt.Sprintf(`%s AS %s`, selectFields[i], dialect.QuoteIdent(jsonName))
 		}
-		selectFields[i] = field.Name
 	}
 
 	// Build query with converted field names
 	query := builder.Select(selectFields...).
-		From(model.TableName())
+		From(fromClause(metadata, req.From))
+	query, err := applyTree(query, table, req.Tree)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	query, err = applyRelationJoins(query, metadata, relations, req.IncludeDeleted)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
 
 	// Convert JSON field names to actual field names for WHERE
-	if len(req.Where) > 0 {
-		eq := make(squirrel.Eq)
-		for jsonName, value := range req.Where {
-			field, ok := metadata.Fields[jsonName]
-			if !ok {
-				return squirrel.SelectBuilder{}, fmt.Errorf("invalid field in where clause: %s", jsonName)
-			}
-			eq[field.Name] = value
-		}
-		query = query.Where(eq)
+	query, err = applyWhere(query, metadata, req.Where, qualify)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
 	}
+	query = applySoftDelete(query, metadata, req.IncludeDeleted, qualify)
 
 	// Handle ORDER BY clauses
 	if len(req.OrderBy) > 0 {
 		for _, orderBy := range req.OrderBy {
-			field, ok := metadata.Fields[orderBy.Field]
+			ref, ok := metadata.lookupField(orderBy.Field)
 			if !ok {
-				return squirrel.SelectBuilder{}, fmt.Errorf("invalid field in order by clause: %s", orderBy.Field)
+				return squirrel.SelectBuilder{}, unknownField(metadata, orderBy.Field, "order by clause")
 			}
+			column := ref.column(metadata, qualify)
 			if orderBy.Desc {
-				query = query.OrderBy(field.Name + " DESC")
+				query = query.OrderBy(column + " DESC")
 			} else {
-				query = query.OrderBy(field.Name + " ASC")
+				query = query.OrderBy(column + " ASC")
 			}
 		}
 	}
 
+	if req.LimitBy != nil {
+		clause, err := req.LimitBy.clause(dialect, metadata, qualify)
+		if err != nil {
+			return squirrel.SelectBuilder{}, err
+		}
+		query = query.Suffix(clause)
+	}
+
 	// Handle LIMIT and OFFSET
+	var limit, offset *uint64
 	if req.Limit != nil {
 		if *req.Limit < 0 {
 			return squirrel.SelectBuilder{}, fmt.Errorf("limit must be non-negative")
 		}
-		query = query.Limit(uint64(*req.Limit))
+		l := uint64(*req.Limit)
+		limit = &l
 	}
 
 	if req.Offset != nil {
 		if *req.Offset < 0 {
 			return squirrel.SelectBuilder{}, fmt.Errorf("offset must be non-negative")
 		}
-		query = query.Offset(uint64(*req.Offset))
+		o := uint64(*req.Offset)
+		offset = &o
+	}
+	if clause := dialect.LimitOffset(limit, offset); clause != "" {
+		query = query.Suffix(clause)
+	}
+
+	if req.Lock != nil {
+		if err := checkDialectWritable(dialect, "row locks"); err != nil {
+			return squirrel.SelectBuilder{}, err
+		}
+		clause, err := req.Lock.clause(metadata, req, qualify)
+		if err != nil {
+			return squirrel.SelectBuilder{}, err
+		}
+		query = query.Suffix(clause)
 	}
 
 	// TODO: Add support for GROUP BY
 
 	return query, nil
 }
+
+// buildCount builds a COUNT(*) query over the same tables and conditions as
+// buildSelect, used to compute pagination totals.
+func buildCount(metadata ModelMetadata, req QueryRequest) (squirrel.SelectBuilder, error) {
+	return buildAggregate(metadata, req, func(ModelMetadata, bool) ([]string, error) {
+		return []string{"COUNT(*)"}, nil
+	})
+}
+
+// buildAggregate builds a query over the same tables and conditions as
+// buildSelect, without ordering or pagination, selecting the aggregate
+// columns returned by columns for the metadata read from.
+func buildAggregate(metadata ModelMetadata, req QueryRequest, columns func(source ModelMetadata, qualify bool) ([]string, error)) (squirrel.SelectBuilder, error) {
+	// Use the placeholder format of the dialect ($1, $2, etc for Postgres)
+	builder := squirrel.StatementBuilder.PlaceholderFormat(metadata.dialect().Placeholder())
+
+	scopes, err := resolveCTEs(metadata, req.With)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	source, err := sourceMetadata(metadata, scopes, req.From)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+
+	// Trees are read from a CTE aliased with the table name
+	table := source
+	if req.Tree != nil {
+		source = source.aliased()
+	}
+
+	relations := referencedRelations(source, req)
+	selected, err := columns(source, len(relations) > 0)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	query := builder.Select(selected...).From(fromClause(source, req.From))
+	query, err = applyTree(query, table, req.Tree)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	query, err = applyRelationJoins(query, source, relations, req.IncludeDeleted)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	query, err = applyWhere(query, source, req.Where, len(relations) > 0)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	query = applySoftDelete(query, source, req.IncludeDeleted, len(relations) > 0)
+	return applyCTEs(query, metadata, scopes, req.With)
+}
+
+// applyWhere converts the JSON field names of where into columns and adds
+// them to the query as equality conditions.
+func applyWhere(query squirrel.SelectBuilder, metadata ModelMetadata, where map[string]interface{}, qualify bool) (squirrel.SelectBuilder, error) {
+	if len(where) == 0 {
+		return query, nil
+	}
+	eq, err := whereEq(metadata, where, qualify)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	return query.Where(eq), nil
+}
+
+// whereEq converts the JSON field names of where into columns. Slice values
+// become IN conditions. Values of UUID and decimal fields are checked and
+// bound as strings, see canonicalValue.
+func whereEq(metadata ModelMetadata, where map[string]interface{}, qualify bool) (squirrel.Eq, error) {
+	eq := make(squirrel.Eq)
+	for jsonName, value := range where {
+		ref, ok := metadata.lookupField(jsonName)
+		if !ok {
+			return nil, unknownField(metadata, jsonName, "where clause")
+		}
+		if convert := canonicalValue(ref.Field.Type); convert != nil {
+			var err error
+			if value, err = conditionValue(value, convert); err != nil {
+				return nil, &TypeMismatchError{Field: jsonName, Value: value, Err: err}
+			}
+		}
+		eq[ref.column(metadata, qualify)] = value
+	}
+	return eq, nil
+}
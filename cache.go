@@ -0,0 +1,162 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// resultCacheKey is the context key under which the result cache is stored.
+type resultCacheKey struct{}
+
+// refreshKey marks contexts whose calls must bypass cached results.
+type refreshKey struct{}
+
+// ResultCache keeps the results of Execute and ExecuteRaw calls across
+// requests for a fixed time. Unlike WithMemo, which lives for one request,
+// it is meant to be shared by the whole process and used for heavy queries
+// whose results may be slightly stale, such as dashboards. It is usually
+// primed with Warm or StartWarming, so the first users after a deploy don't
+// pay for the cold queries.
+type ResultCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]resultCacheEntry
+}
+
+// resultCacheEntry is one cached result.
+type resultCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// WarmQuery is a named query executed by ResultCache.Warm.
+type WarmQuery struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// NewResultCache returns a cache keeping results for ttl.
+func NewResultCache(ttl time.Duration) *ResultCache {
+	return &ResultCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]resultCacheEntry),
+	}
+}
+
+// WithResultCache returns a context under which Execute and ExecuteRaw calls
+// are served from c when an identical call was cached less than the cache
+// TTL ago. Calls are identical when they use the same model (or
+// parameter/result types), database handle and request. Failed calls are
+// not cached.
+func WithResultCache(ctx context.Context, c *ResultCache) context.Context {
+	return context.WithValue(ctx, resultCacheKey{}, c)
+}
+
+// resultCacheFromContext returns the result cache of ctx, if any.
+func resultCacheFromContext(ctx context.Context) *ResultCache {
+	c, _ := ctx.Value(resultCacheKey{}).(*ResultCache)
+	return c
+}
+
+// do returns the cached result for key, calling fn and caching its result
+// when there is none, it has expired or ctx asks for a refresh.
+func (c *ResultCache) do(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error) {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	if !refresh {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+		if ok && c.now().Before(entry.expires) {
+			return entry.value, nil
+		}
+	}
+
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[key] = resultCacheEntry{value: value, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// Warm executes queries and stores their results in c, replacing any cached
+// result. Expired entries are dropped first. It runs every query even when
+// some fail, and returns the failures joined.
+func (c *ResultCache) Warm(ctx context.Context, queries ...WarmQuery) error {
+	c.prune()
+
+	ctx = context.WithValue(WithResultCache(ctx, c), refreshKey{}, true)
+	var errs []error
+	for _, q := range queries {
+		if err := q.Run(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to warm %s: %w", q.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StartWarming warms queries right away and then every interval until ctx is
+// done. Failures are logged at error level with the logger of the registry
+// of ctx, see SetSlogLogger. Use an interval slightly below the cache TTL to
+// keep the results from ever expiring.
+func (c *ResultCache) StartWarming(ctx context.Context, interval time.Duration, queries ...WarmQuery) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := c.Warm(ctx, queries...); err != nil {
+				registryFromContext(ctx).slogLogger().ErrorContext(ctx, "sqld cache warming failed", slog.Any("error", err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// prune drops expired entries.
+func (c *ResultCache) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// WarmExecute returns a WarmQuery running Execute[T] with req against db.
+// Handlers get the warmed result when they run the same request against the
+// same db under WithResultCache.
+func WarmExecute[T Model](name string, db interface{}, req QueryRequest) WarmQuery {
+	return WarmQuery{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			_, err := Execute[T](ctx, db, req)
+			return err
+		},
+	}
+}
+
+// WarmExecuteRaw returns a WarmQuery running ExecuteRaw[P, R] with query and
+// params against db.
+func WarmExecuteRaw[P, R any](name string, db interface{}, query string, params map[string]interface{}) WarmQuery {
+	return WarmQuery{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			_, err := ExecuteRaw[P, R](ctx, db, query, params)
+			return err
+		},
+	}
+}
@@ -0,0 +1,200 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCache_WarmAndServe(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cache := NewResultCache(time.Minute)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	req := QueryRequest{Select: []string{"id", "name"}}
+	expectQuery := func(name string) {
+		mock.ExpectQuery("SELECT id, name FROM test_models").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), name))
+	}
+
+	// Warming runs the query
+	expectQuery("warm")
+	require.NoError(t, cache.Warm(context.Background(), WarmExecute[BuilderTestModel]("dashboard", db, req)))
+
+	// Requests under the cache are served without querying
+	ctx := WithResultCache(context.Background(), cache)
+	resp, err := Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Equal(t, "warm", resp.Data[0]["name"])
+	resp.Data[0]["name"] = "modified"
+
+	// Warming again refreshes the cached result even though it is fresh
+	expectQuery("rewarmed")
+	require.NoError(t, cache.Warm(context.Background(), WarmExecute[BuilderTestModel]("dashboard", db, req)))
+	resp, err = Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Equal(t, "rewarmed", resp.Data[0]["name"])
+
+	// Expired results are fetched again
+	now = now.Add(2 * time.Minute)
+	expectQuery("expired")
+	resp, err = Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Equal(t, "expired", resp.Data[0]["name"])
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResultCache_ChecksRequests(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(LookupTestModel{}))
+	load := func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error) {
+		return []interface{}{"loaded"}, nil
+	}
+	require.NoError(t, registry.RegisterLookup(LookupTestModel{}, "owner", Lookup{Load: load, Flag: "owner"}))
+	require.NoError(t, registry.RegisterLookup(LookupTestModel{}, "stats", Lookup{Load: load, Flag: "stats", Optional: true}))
+	enabled := map[string]bool{"owner": true, "stats": true}
+	registry.SetFlagProvider(FlagProviderFunc(func(ctx context.Context, flag string) bool { return enabled[flag] }))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cache := NewResultCache(time.Minute)
+	ctx := WithResultCache(WithRegistry(context.Background(), registry), cache)
+	owned := QueryRequest{Select: []string{"id"}, Include: []string{"owner"}}
+	withStats := QueryRequest{Select: []string{"id"}, Include: []string{"stats"}}
+	expectQuery := func() {
+		mock.ExpectQuery("SELECT id FROM lookup_models").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	}
+	expectQuery()
+	_, err = Execute[LookupTestModel](ctx, db, owned)
+	require.NoError(t, err)
+	expectQuery()
+	_, err = Execute[LookupTestModel](ctx, db, withStats)
+	require.NoError(t, err)
+
+	// Cached results of disabled features aren't served
+	enabled["owner"] = false
+	_, err = Execute[LookupTestModel](ctx, db, owned)
+	assert.ErrorContains(t, err, "include owner is disabled")
+
+	// nor those of requests over the complexity limits
+	enabled["owner"] = true
+	registry.SetComplexityLimits(ComplexityLimits{MaxPageSize: 10})
+	_, err = Execute[LookupTestModel](ctx, db, owned)
+	var complexityErr *ComplexityError
+	assert.ErrorAs(t, err, &complexityErr)
+	registry.SetComplexityLimits(ComplexityLimits{})
+
+	// Disabling an optional include changes the result, which is cached apart
+	enabled["stats"] = false
+	expectQuery()
+	resp, err := Execute[LookupTestModel](ctx, db, withStats)
+	require.NoError(t, err)
+	assert.Nil(t, resp.Data[0]["stats"])
+	assert.Equal(t, []string{"lookup stats is disabled"}, resp.Warnings)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResultCache_WarmErrors(t *testing.T) {
+	cache := NewResultCache(time.Minute)
+	var ran []string
+	err := cache.Warm(context.Background(),
+		WarmQuery{Name: "broken", Run: func(ctx context.Context) error {
+			ran = append(ran, "broken")
+			return errors.New("boom")
+		}},
+		WarmQuery{Name: "fine", Run: func(ctx context.Context) error {
+			ran = append(ran, "fine")
+			assert.Equal(t, cache, resultCacheFromContext(ctx))
+			return nil
+		}},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to warm broken: boom")
+	assert.Equal(t, []string{"broken", "fine"}, ran)
+}
+
+func TestResultCache_FailuresAreNotCached(t *testing.T) {
+	cache := NewResultCache(time.Minute)
+	ctx := context.Background()
+
+	calls := 0
+	fn := func() (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("transient")
+		}
+		return calls, nil
+	}
+	_, err := cache.do(ctx, "key", fn)
+	assert.Error(t, err)
+	value, err := cache.do(ctx, "key", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+	value, err = cache.do(ctx, "key", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+}
+
+func TestResultCache_StartWarming(t *testing.T) {
+	cache := NewResultCache(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := make(chan struct{}, 10)
+	cache.StartWarming(ctx, time.Millisecond, WarmQuery{Name: "tick", Run: func(ctx context.Context) error {
+		runs <- struct{}{}
+		return nil
+	}})
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("warming did not run")
+		}
+	}
+}
+
+// lineWriter sends every write to a channel.
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestResultCache_StartWarmingLogsFailures(t *testing.T) {
+	out := make(lineWriter, 10)
+	registry := NewRegistry()
+	registry.SetSlogLogger(slog.New(slog.NewTextHandler(out, nil)))
+	ctx, cancel := context.WithCancel(WithRegistry(context.Background(), registry))
+	defer cancel()
+
+	cache := NewResultCache(time.Minute)
+	cache.StartWarming(ctx, time.Hour, WarmQuery{Name: "dashboard", Run: func(ctx context.Context) error {
+		return errors.New("boom")
+	}})
+	select {
+	case line := <-out:
+		assert.Contains(t, line, "level=ERROR")
+		assert.Contains(t, line, "failed to warm dashboard: boom")
+	case <-time.After(time.Second):
+		t.Fatal("warming failure was not logged")
+	}
+}
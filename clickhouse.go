@@ -0,0 +1,112 @@
+package sqld
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// ClickHouse is the dialect of ClickHouse, used through its database/sql
+// driver for event and time-series data: ? parameters, `quoted` identifiers,
+// LIMIT/OFFSET and LIMIT BY. It is read-only: mutations, transactions and row
+// locks are rejected before any SQL is sent.
+var ClickHouse Dialect = clickhouseDialect{}
+
+// readOnlyDialect is implemented by dialects of databases only queried
+// through sqld.
+type readOnlyDialect interface {
+	// ReadOnly reports whether mutations, transactions and row locks are
+	// rejected.
+	ReadOnly() bool
+}
+
+// limitByDialect is implemented by dialects supporting LimitBy.
+type limitByDialect interface {
+	// LimitBy returns the clause keeping the first limit rows of each
+	// combination of the columns.
+	LimitBy(limit uint64, columns []string) string
+}
+
+type clickhouseDialect struct{}
+
+func (clickhouseDialect) Name() string { return "clickhouse" }
+
+func (clickhouseDialect) Placeholder() squirrel.PlaceholderFormat { return squirrel.Question }
+
+func (clickhouseDialect) BindVar(int) string { return "?" }
+
+func (clickhouseDialect) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (clickhouseDialect) LimitOffset(limit, offset *uint64) string {
+	return limitOffset(limit, offset)
+}
+
+func (clickhouseDialect) SupportsReturning() bool { return false }
+
+func (clickhouseDialect) Explain(query string) string { return "EXPLAIN " + query }
+
+func (clickhouseDialect) ReadOnly() bool { return true }
+
+func (clickhouseDialect) LimitBy(limit uint64, columns []string) string {
+	return fmt.Sprintf("LIMIT %d BY %s", limit, strings.Join(columns, ", "))
+}
+
+// LimitByClause keeps the first Limit rows of each distinct combination of
+// Fields, in the order of OrderBy, e.g. the 3 latest events of each user:
+//
+//	{"limit": 3, "fields": ["user_id"]}
+//
+// It is applied before Limit and Offset. Only the ClickHouse dialect supports it.
+type LimitByClause struct {
+	Limit  int      `json:"limit"`
+	Fields []string `json:"fields"`
+}
+
+// validate checks the clause against the metadata of what the query reads.
+func (l *LimitByClause) validate(metadata ModelMetadata) error {
+	if l.Limit < 0 {
+		return fmt.Errorf("limit by must be non-negative")
+	}
+	if len(l.Fields) == 0 {
+		return fmt.Errorf("limit by requires fields")
+	}
+	for _, field := range l.Fields {
+		ref, ok := metadata.lookupField(field)
+		if !ok {
+			return &UnknownFieldError{Field: field, In: "limit by"}
+		}
+		if err := ref.Field.checkFilterable(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clause returns the LIMIT BY clause of the dialect for a query on metadata.
+func (l *LimitByClause) clause(dialect Dialect, metadata ModelMetadata, qualify bool) (string, error) {
+	if err := l.validate(metadata); err != nil {
+		return "", err
+	}
+	d, ok := dialect.(limitByDialect)
+	if !ok {
+		return "", fmt.Errorf("limit by is not supported by the %s dialect", dialect.Name())
+	}
+	columns := make([]string, len(l.Fields))
+	for i, field := range l.Fields {
+		ref, _ := metadata.lookupField(field)
+		columns[i] = ref.column(metadata, qualify)
+	}
+	return d.LimitBy(uint64(l.Limit), columns), nil
+}
+
+// checkDialectWritable returns an error when dialect is read-only. what
+// names the rejected operations.
+func checkDialectWritable(dialect Dialect, what string) error {
+	if ro, ok := dialect.(readOnlyDialect); ok && ro.ReadOnly() {
+		return fmt.Errorf("%s are not supported by the read-only %s dialect", what, dialect.Name())
+	}
+	return nil
+}
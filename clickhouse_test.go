@@ -0,0 +1,87 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_ClickHouseLimitBy(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+	useDialect(t, ClickHouse)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT name, created_at FROM test_models WHERE age = \? ORDER BY created_at DESC LIMIT 2 BY name LIMIT 10$`).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "created_at"}))
+
+	limit := 10
+	_, err = Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select:  []string{"name", "created_at"},
+		Where:   map[string]interface{}{"age": 30},
+		OrderBy: []OrderByClause{{Field: "created_at", Desc: true}},
+		LimitBy: &LimitByClause{Limit: 2, Fields: []string{"name"}},
+		Limit:   &limit,
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLimitBy_Validation(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+	ctx := context.Background()
+
+	_, err := Execute[BuilderTestModel](ctx, nil, QueryRequest{
+		Select:  []string{"name"},
+		LimitBy: &LimitByClause{Limit: 1, Fields: []string{"name"}},
+	})
+	assert.ErrorContains(t, err, "limit by is not supported by the postgres dialect")
+
+	useDialect(t, ClickHouse)
+	_, err = Execute[BuilderTestModel](ctx, nil, QueryRequest{
+		Select:  []string{"name"},
+		LimitBy: &LimitByClause{Limit: 1, Fields: []string{"salary"}},
+	})
+	assert.ErrorContains(t, err, "invalid field in limit by: salary")
+	_, err = Execute[BuilderTestModel](ctx, nil, QueryRequest{
+		Select:  []string{"name"},
+		LimitBy: &LimitByClause{Limit: 1},
+	})
+	assert.ErrorContains(t, err, "limit by requires fields")
+}
+
+func TestClickHouse_ReadOnly(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	require.NoError(t, Register(BuilderTestModel{}))
+	useDialect(t, ClickHouse)
+
+	// No statement may reach the database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err = Insert[MutationAccount](ctx, db, InsertRequest{Values: map[string]interface{}{"owner": "alice"}})
+	assert.ErrorContains(t, err, "mutations are not supported by the read-only clickhouse dialect")
+	_, err = Delete[MutationAccount](ctx, db, DeleteRequest{Where: map[string]interface{}{"id": 1}})
+	assert.ErrorContains(t, err, "mutations are not supported")
+
+	err = InTx(ctx, db, func(Runner) error { return nil })
+	assert.ErrorContains(t, err, "transactions are not supported")
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}}, WithReadOnlyTx(sql.LevelDefault))
+	assert.ErrorContains(t, err, "transactions are not supported")
+
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{
+		Select: []string{"id"},
+		Lock:   &LockRequest{Strength: LockUpdate},
+	})
+	assert.ErrorContains(t, err, "row locks are not supported")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,144 @@
+// Command sqld provides code generation helpers for the sqld package.
+//
+// Usage:
+//
+//	sqld enums -dsn postgres://... -pkg refdata -out refdata/enums_gen.go
+//
+// The enums subcommand reads a reference table (common_reference_master by
+// default) and emits Go constants plus a RegisterEnums function so that filter
+// validation stays in sync with the database. It is go:generate friendly:
+//
+//	//go:generate sqld enums -dsn $DATABASE_URL -pkg refdata -out enums_gen.go
+//
+// The gen subcommand reads the tables of a schema and emits model structs with
+// db and json tags, TableName methods and a RegisterModels function:
+//
+//	//go:generate sqld gen -dsn $DATABASE_URL -tables employees,departments -pkg models -out models_gen.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/remiges-sachin/sqld"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "enums":
+		err = runEnums(os.Args[2:])
+	case "gen":
+		err = runGen(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sqld %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: sqld <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  enums   generate Go enums from a reference table")
+	fmt.Fprintln(os.Stderr, "  gen     generate Go models from the tables of a schema")
+}
+
+func runEnums(args []string) error {
+	fs := flag.NewFlagSet("enums", flag.ExitOnError)
+	dsn := fs.String("dsn", os.Getenv("DATABASE_URL"), "Postgres connection string")
+	table := fs.String("table", "common_reference_master", "reference table")
+	group := fs.String("group", "entity", "column naming the enum group")
+	key := fs.String("key", "entity_key", "column naming the enum member")
+	value := fs.String("value", "id", "column holding the stored value")
+	pkg := fs.String("pkg", "refdata", "package name of the generated file")
+	out := fs.String("out", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	if *dsn == "" {
+		return fmt.Errorf("-dsn or DATABASE_URL is required")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, *dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	groups, err := sqld.LoadEnumGroups(ctx, conn, sqld.EnumTable{
+		Table:       *table,
+		GroupColumn: *group,
+		KeyColumn:   *key,
+		ValueColumn: *value,
+	})
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return sqld.GenerateEnums(w, *pkg, groups)
+}
+
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	dsn := fs.String("dsn", os.Getenv("DATABASE_URL"), "Postgres connection string")
+	schema := fs.String("schema", "public", "schema holding the tables")
+	tables := fs.String("tables", "", "comma-separated tables (default every table of the schema)")
+	pkg := fs.String("pkg", "models", "package name of the generated file")
+	out := fs.String("out", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	if *dsn == "" {
+		return fmt.Errorf("-dsn or DATABASE_URL is required")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, *dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	var names []string
+	if *tables != "" {
+		names = strings.Split(*tables, ",")
+	}
+	schemas, err := sqld.LoadTableSchemas(ctx, conn, *schema, names...)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return sqld.GenerateModels(w, *pkg, schemas)
+}
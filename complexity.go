@@ -0,0 +1,157 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTooComplex is returned, by a *ComplexityError, for requests exceeding
+// the ComplexityLimits of their model.
+var ErrTooComplex = errors.New("request too complex")
+
+// Complexity measures how much work a request asks of the database.
+type Complexity struct {
+	Predicates int `json:"predicates"` // Conditions of Where, including those of lateral joins and CTEs
+	Joins      int `json:"joins"`      // Related tables joined, CTEs, nested relations and includes
+	Aggregates int `json:"aggregates"` // Summary functions, and the pivot
+	PageSize   int `json:"page_size"`  // Rows returned at most, 0 when unlimited
+	// Score weighs the others: Predicates + 5×Joins + 2×Aggregates + PageSize/10
+	Score int `json:"score"`
+}
+
+// ComplexityLimits cap the Complexity of requests. Zero leaves a cap unset;
+// a MaxPageSize rejects requests without a limit.
+type ComplexityLimits struct {
+	MaxScore      int
+	MaxPredicates int
+	MaxJoins      int
+	MaxAggregates int
+	MaxPageSize   int
+}
+
+// ComplexityError is returned for a request whose complexity exceeds its
+// limits.
+type ComplexityError struct {
+	Complexity Complexity
+	Limits     ComplexityLimits
+}
+
+func (e *ComplexityError) Error() string {
+	c, l := e.Complexity, e.Limits
+	switch {
+	case l.MaxPredicates > 0 && c.Predicates > l.MaxPredicates:
+		return fmt.Sprintf("request too complex: %d predicates exceed %d", c.Predicates, l.MaxPredicates)
+	case l.MaxJoins > 0 && c.Joins > l.MaxJoins:
+		return fmt.Sprintf("request too complex: %d joins exceed %d", c.Joins, l.MaxJoins)
+	case l.MaxAggregates > 0 && c.Aggregates > l.MaxAggregates:
+		return fmt.Sprintf("request too complex: %d aggregates exceed %d", c.Aggregates, l.MaxAggregates)
+	case l.MaxPageSize > 0 && c.PageSize == 0:
+		return fmt.Sprintf("request too complex: no limit, at most %d rows are allowed", l.MaxPageSize)
+	case l.MaxPageSize > 0 && c.PageSize > l.MaxPageSize:
+		return fmt.Sprintf("request too complex: page size %d exceeds %d", c.PageSize, l.MaxPageSize)
+	}
+	return fmt.Sprintf("request too complex: score %d exceeds %d", c.Score, l.MaxScore)
+}
+
+func (e *ComplexityError) Is(target error) bool {
+	return target == ErrTooComplex
+}
+
+// exceeded reports whether c exceeds any of limits.
+func (l ComplexityLimits) exceeded(c Complexity) bool {
+	over := func(value, max int) bool { return max > 0 && value > max }
+	return over(c.Score, l.MaxScore) ||
+		over(c.Predicates, l.MaxPredicates) ||
+		over(c.Joins, l.MaxJoins) ||
+		over(c.Aggregates, l.MaxAggregates) ||
+		(l.MaxPageSize > 0 && (c.PageSize == 0 || c.PageSize > l.MaxPageSize))
+}
+
+// SetComplexityLimits sets the limits of the requests run with the default
+// registry against models registered without WithComplexityLimits. Requests
+// exceeding them are rejected with a *ComplexityError before reaching the
+// database. The zero ComplexityLimits disables the check.
+func SetComplexityLimits(limits ComplexityLimits) {
+	defaultRegistry.SetComplexityLimits(limits)
+}
+
+// SetComplexityLimits sets the limits of the requests run with the registry
+// against models registered without WithComplexityLimits.
+func (r *Registry) SetComplexityLimits(limits ComplexityLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.complexity = limits
+}
+
+// WithComplexityLimits sets the limits of the requests run against the
+// model, replacing those set with SetComplexityLimits.
+func WithComplexityLimits(limits ComplexityLimits) RegisterOption {
+	return func(c *registerConfig) { c.complexity = &limits }
+}
+
+// MeasureComplexity returns the Complexity of req against model T, e.g. to
+// log or bill requests by cost. It validates req like Execute, but doesn't
+// check it against the limits.
+func MeasureComplexity[T Model](ctx context.Context, req QueryRequest, opts ...ExecuteOption) (Complexity, error) {
+	var model T
+	r := registryFromContext(ctx)
+	metadata, req, err := r.validateQuery(ctx, model, r.callerVariant(ctx, model, newExecuteConfig(opts).version), req)
+	if err != nil {
+		return Complexity{}, err
+	}
+	return requestComplexity(metadata, req), nil
+}
+
+// checkComplexity rejects req, resolved against metadata, when it exceeds
+// the complexity limits of the model or of its registry.
+func (r *Registry) checkComplexity(metadata ModelMetadata, req QueryRequest) error {
+	limits := metadata.ComplexityLimits
+	if limits == nil {
+		r.mu.RLock()
+		global := r.complexity
+		r.mu.RUnlock()
+		limits = &global
+	}
+	if *limits == (ComplexityLimits{}) {
+		return nil
+	}
+	c := requestComplexity(metadata, req)
+	if limits.exceeded(c) {
+		return &ComplexityError{Complexity: c, Limits: *limits}
+	}
+	return nil
+}
+
+// requestComplexity returns the Complexity of req, resolved against metadata.
+func requestComplexity(metadata ModelMetadata, req QueryRequest) Complexity {
+	c := queryComplexity(metadata, req)
+	if limited := withPagination(req); limited.Limit != nil {
+		c.PageSize = *limited.Limit
+	}
+	c.Score = c.Predicates + 5*c.Joins + 2*c.Aggregates + c.PageSize/10
+	return c
+}
+
+// queryComplexity counts the predicates, joins and aggregates of req and of
+// its CTEs.
+func queryComplexity(metadata ModelMetadata, req QueryRequest) Complexity {
+	c := Complexity{
+		Predicates: len(req.Where),
+		Joins:      len(referencedRelations(metadata, req)) + len(req.Joins) + len(req.Lateral) + len(req.With) + len(req.Nested) + len(req.Include),
+		Aggregates: len(req.Summary),
+	}
+	if req.Pivot != nil {
+		c.Aggregates++
+	}
+	for _, lateral := range req.Lateral {
+		c.Predicates += len(lateral.Where)
+	}
+	for _, cte := range req.With {
+		inner := queryComplexity(metadata, cte.Query)
+		c.Predicates += inner.Predicates
+		c.Joins += inner.Joins
+		c.Aggregates += inner.Aggregates
+	}
+	return c
+}
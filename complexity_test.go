@@ -0,0 +1,67 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureComplexity(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	limit := 50
+	c, err := MeasureComplexity[BuilderTestModel](ctx, QueryRequest{
+		Select:  []string{"id", "name"},
+		Where:   map[string]interface{}{"name": "a", "age": 30},
+		Summary: []SummaryField{{Field: "age", Func: "avg"}, {Field: "age", Func: "max"}},
+		Limit:   &limit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Complexity{Predicates: 2, Aggregates: 2, PageSize: 50, Score: 2 + 4 + 5}, c)
+
+	c, err = MeasureComplexity[BuilderTestModel](ctx, QueryRequest{
+		Select:     []string{"id"},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 20},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 20, c.PageSize)
+
+	_, err = MeasureComplexity[BuilderTestModel](ctx, QueryRequest{Select: []string{"nope"}})
+	assert.ErrorIs(t, err, ErrUnknownField)
+}
+
+func TestComplexityLimits(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	require.NoError(t, registry.Register(TypedOrder{}, WithComplexityLimits(ComplexityLimits{MaxPredicates: 1})))
+	ctx := WithRegistry(context.Background(), registry)
+	registry.SetComplexityLimits(ComplexityLimits{MaxScore: 10, MaxPageSize: 100})
+
+	limit := 50
+	_, err := BuildOnly[BuilderTestModel](ctx, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"name": "a"}, Limit: &limit})
+	require.NoError(t, err)
+
+	// Requests without a limit exceed any page size
+	_, err = BuildOnly[BuilderTestModel](ctx, QueryRequest{Select: []string{"id"}})
+	require.ErrorIs(t, err, ErrTooComplex)
+	assert.EqualError(t, err, "request too complex: no limit, at most 100 rows are allowed")
+
+	limit = 100
+	_, err = BuildOnly[BuilderTestModel](ctx, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"name": "a"}, Limit: &limit})
+	require.ErrorIs(t, err, ErrTooComplex)
+	var complexityErr *ComplexityError
+	require.True(t, errors.As(err, &complexityErr))
+	assert.Equal(t, 11, complexityErr.Complexity.Score)
+	assert.EqualError(t, err, "request too complex: score 11 exceeds 10")
+
+	// The limits of the model replace those of the registry
+	_, err = BuildOnly[TypedOrder](ctx, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"quantity": 2}})
+	require.NoError(t, err)
+	_, err = BuildOnly[TypedOrder](ctx, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"quantity": 2, "note": "gift"}})
+	assert.EqualError(t, err, "request too complex: 2 predicates exceed 1")
+}
@@ -0,0 +1,143 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RegisterComposite registers struct type T as a Postgres composite type in
+// the default registry, so that fields of type T, or pointers to it, return
+// the record as a nested object keyed by the json names of the fields of T.
+// The fields of T are the attributes of the type, in order:
+//
+//	type Address struct {
+//	    Street string `json:"street"`
+//	    City   string `json:"city"`
+//	}
+//
+//	sqld.RegisterComposite[Address]()
+func RegisterComposite[T any]() error {
+	return defaultRegistry.RegisterComposite(reflect.TypeFor[T]())
+}
+
+// RegisterComposite registers struct type t as a Postgres composite type,
+// see the package-level RegisterComposite.
+func (r *Registry) RegisterComposite(t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("composite type %s is not a struct", t)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.composites[t] = modelFields(t)
+	return nil
+}
+
+// compositeAttributes returns the attributes of t, or of the type t points
+// to, if it is registered as a composite type.
+func (r *Registry) compositeAttributes(t reflect.Type) ([]taggedField, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	attributes, ok := r.composites[t]
+	return attributes, ok
+}
+
+// compositeValue returns val, the value of a field of type t, as a nested
+// map if t is a registered composite type: database/sql and pgx return
+// records as text such as (1,"Main St",), which is parsed, converting each
+// attribute with textValue. Other values are returned unchanged.
+func (r *Registry) compositeValue(t reflect.Type, val interface{}) (interface{}, error) {
+	attributes, ok := r.compositeAttributes(t)
+	if !ok {
+		return val, nil
+	}
+	switch v := val.(type) {
+	case rawValue:
+		return r.compositeValue(t, v.value)
+	case []byte:
+		return r.compositeValue(t, string(v))
+	case string:
+		texts, err := parseRecordLiteral(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(texts) != len(attributes) {
+			return nil, fmt.Errorf("record %q has %d attributes, %s has %d", v, len(texts), t, len(attributes))
+		}
+		values := make(map[string]interface{}, len(attributes))
+		for i, attribute := range attributes {
+			if texts[i] == nil {
+				values[attribute.jsonName] = nil
+				continue
+			}
+			if values[attribute.jsonName], err = r.textValue(attribute.Type, *texts[i]); err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", attribute.jsonName, err)
+			}
+		}
+		return values, nil
+	}
+	return val, nil
+}
+
+// textValue returns text, a value of type t nested in a record, as it is
+// emitted in results.
+func (r *Registry) textValue(t reflect.Type, text string) (interface{}, error) {
+	switch {
+	case isHstoreType(t):
+		return hstoreValue(text)
+	case isArrayType(t):
+		return arrayValue(t, text)
+	case FieldType(t) == "datetime":
+		if parsed, ok := parseTimeText(text); ok {
+			return r.timeValue(parsed), nil
+		}
+		return text, nil
+	}
+	if _, ok := r.compositeAttributes(t); ok {
+		return r.compositeValue(t, text)
+	}
+	return arrayElemValue(t, text)
+}
+
+// parseRecordLiteral returns the attributes of a Postgres record literal,
+// nil for NULL.
+func parseRecordLiteral(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf("invalid record %q", s)
+	}
+	body := s[1 : len(s)-1]
+	var attributes []*string
+	for i := 0; ; i++ {
+		var b strings.Builder
+		quoted, wasQuoted := false, false
+		for ; i < len(body) && (quoted || body[i] != ','); i++ {
+			switch {
+			case body[i] == '\\' && i+1 < len(body):
+				i++
+				b.WriteByte(body[i])
+			case body[i] == '"' && quoted && i+1 < len(body) && body[i+1] == '"':
+				i++
+				b.WriteByte('"')
+			case body[i] == '"':
+				quoted = !quoted
+				wasQuoted = true // "" is an empty string rather than NULL
+			default:
+				b.WriteByte(body[i])
+			}
+		}
+		if quoted {
+			return nil, fmt.Errorf("invalid record %q", s)
+		}
+		if text := b.String(); text == "" && !wasQuoted {
+			attributes = append(attributes, nil)
+		} else {
+			attributes = append(attributes, &text)
+		}
+		if i == len(body) {
+			return attributes, nil
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package sqld
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CompositeAddress struct {
+	Street string   `json:"street"`
+	City   string   `json:"city"`
+	Zip    *string  `json:"zip"`
+	Lines  []string `json:"lines"`
+}
+
+type CompositeGeo struct {
+	Address CompositeAddress `json:"address"`
+	Rank    int              `json:"rank"`
+}
+
+type CompositeShop struct {
+	ID         int64             `json:"id" db:"id"`
+	Address    CompositeAddress  `json:"address" db:"address"`
+	Geo        *CompositeGeo     `json:"geo" db:"geo"`
+	Attributes map[string]string `json:"attributes" db:"attributes"`
+}
+
+func (CompositeShop) TableName() string { return "shops" }
+
+func TestParseRecordLiteral(t *testing.T) {
+	text := func(s string) *string { return &s }
+	tests := []struct {
+		literal string
+		want    []*string
+		wantErr string
+	}{
+		{literal: "(1,Main)", want: []*string{text("1"), text("Main")}},
+		{literal: `("Main St",,"")`, want: []*string{text("Main St"), nil, text("")}},
+		{literal: `("say ""hi""","a\\b")`, want: []*string{text(`say "hi"`), text(`a\b`)}},
+		{literal: `("(x,y)",2)`, want: []*string{text("(x,y)"), text("2")}},
+		{literal: `("open)`, wantErr: "invalid record"},
+		{literal: "1,2", wantErr: "invalid record"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			got, err := parseRecordLiteral(tt.literal)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseHstore(t *testing.T) {
+	got, err := parseHstore(`"a"=>"1", "b c"=>NULL, "q"=>"say \"hi\""`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "1", "b c": nil, "q": `say "hi"`}, got)
+
+	got, err = parseHstore("")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = parseHstore(`"a"=>`)
+	assert.ErrorContains(t, err, "invalid hstore")
+}
+
+func TestExecute_CompositeAndHstore(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.RegisterComposite(reflect.TypeFor[CompositeAddress]()))
+	require.NoError(t, registry.RegisterComposite(reflect.TypeFor[CompositeGeo]()))
+	require.NoError(t, registry.Register(CompositeShop{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, address, geo, attributes FROM shops`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "address", "geo", "attributes"}).
+			AddRow(int64(1), []byte(`("1 Main St",Pune,,"{a,""b c""}")`), `("(Elm,Goa,403001,{})",2)`, `"open"=>"yes", "note"=>NULL`).
+			AddRow(int64(2), nil, nil, nil))
+	resp, err := Execute[CompositeShop](ctx, db, QueryRequest{Select: []string{"id", "address", "geo", "attributes"}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{
+		{
+			"id":         int64(1),
+			"address":    map[string]interface{}{"street": "1 Main St", "city": "Pune", "zip": nil, "lines": []interface{}{"a", "b c"}},
+			"geo":        map[string]interface{}{"address": map[string]interface{}{"street": "Elm", "city": "Goa", "zip": "403001", "lines": []interface{}{}}, "rank": int64(2)},
+			"attributes": map[string]interface{}{"open": "yes", "note": nil},
+		},
+		{"id": int64(2), "address": nil, "geo": nil, "attributes": nil},
+	}, resp.Data)
+
+	mock.ExpectQuery(`SELECT address FROM shops`).
+		WillReturnRows(sqlmock.NewRows([]string{"address"}).AddRow(`(Elm,Goa)`))
+	_, err = Execute[CompositeShop](ctx, db, QueryRequest{Select: []string{"address"}})
+	assert.ErrorContains(t, err, "failed to scan field address: record \"(Elm,Goa)\" has 2 attributes, sqld.CompositeAddress has 4")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteRaw_CompositeAndHstore(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.RegisterComposite(reflect.TypeFor[CompositeAddress]()))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, address, attributes FROM shops`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "address", "attributes"}).
+			AddRow(int64(1), `(Elm,Goa,,)`, []byte(`"open"=>"yes"`)))
+	results, err := ExecuteRaw[struct{}, CompositeShop](ctx, db, "SELECT id, address, attributes FROM shops", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"street": "Elm", "city": "Goa", "zip": nil, "lines": nil}, results[0]["address"])
+	assert.Equal(t, map[string]interface{}{"open": "yes"}, results[0]["attributes"])
+}
+
+func TestRegisterComposite_NotStruct(t *testing.T) {
+	assert.EqualError(t, NewRegistry().RegisterComposite(reflect.TypeFor[string]()), "composite type string is not a struct")
+}
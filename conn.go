@@ -0,0 +1,142 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ManagedConnOptions configures reconnect behaviour of a ManagedConn.
+type ManagedConnOptions struct {
+	// MaxRetries is the number of reconnect attempts after the first one fails.
+	// Defaults to 3.
+	MaxRetries int
+
+	// InitialBackoff is the wait before the first retry. It doubles after
+	// every failed attempt up to MaxBackoff. Defaults to 100ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between retries. Defaults to 5s.
+	MaxBackoff time.Duration
+
+	// HealthCheckInterval makes Conn ping the connection when it hasn't been
+	// checked for longer than the interval, replacing it if the ping fails.
+	// Zero disables pings; broken connections are then detected through
+	// pgx.Conn.IsClosed, which pgx sets after fatal errors.
+	HealthCheckInterval time.Duration
+}
+
+// ManagedConn owns a single *pgx.Conn and lazily re-establishes it when it
+// has been closed or broken, retrying with exponential backoff. It can be
+// passed to Execute and ExecuteRaw anywhere a *pgx.Conn is accepted, so
+// services using a single connection don't need their own supervision code.
+//
+// Like *pgx.Conn, a ManagedConn must not be used by several goroutines at
+// once. Services with concurrent requests should use a pgxpool.Pool instead,
+// which already handles reconnects and health checks per connection.
+type ManagedConn struct {
+	config *pgx.ConnConfig
+	opts   ManagedConnOptions
+
+	// connect is pgx.ConnectConfig, replaceable in tests
+	connect func(ctx context.Context, config *pgx.ConnConfig) (*pgx.Conn, error)
+
+	mu          sync.Mutex
+	conn        *pgx.Conn
+	lastChecked time.Time
+}
+
+// NewManagedConn returns a ManagedConn for config. No connection is opened
+// until the first call to Conn.
+func NewManagedConn(config *pgx.ConnConfig, opts ManagedConnOptions) *ManagedConn {
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = 100 * time.Millisecond
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 5 * time.Second
+	}
+	return &ManagedConn{
+		config:  config,
+		opts:    opts,
+		connect: pgx.ConnectConfig,
+	}
+}
+
+// Conn returns a healthy connection, reconnecting if necessary.
+func (m *ManagedConn) Conn(ctx context.Context) (*pgx.Conn, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conn != nil && !m.conn.IsClosed() {
+		if m.opts.HealthCheckInterval <= 0 || time.Since(m.lastChecked) < m.opts.HealthCheckInterval {
+			return m.conn, nil
+		}
+		if err := m.conn.Ping(ctx); err == nil {
+			m.lastChecked = time.Now()
+			return m.conn, nil
+		}
+		m.conn.Close(ctx)
+	}
+	m.conn = nil
+
+	backoff := m.opts.InitialBackoff
+	var lastErr error
+	for attempt := 0; attempt <= m.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("failed to reconnect: %w", ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > m.opts.MaxBackoff {
+				backoff = m.opts.MaxBackoff
+			}
+		}
+
+		conn, err := m.connect(ctx, m.config)
+		if err == nil {
+			m.conn = conn
+			m.lastChecked = time.Now()
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("failed to connect after %d attempts: %w", m.opts.MaxRetries+1, lastErr)
+}
+
+// Close closes the current connection, if any. A later call to Conn opens a
+// new one.
+func (m *ManagedConn) Close(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.conn == nil {
+		return nil
+	}
+	err := m.conn.Close(ctx)
+	m.conn = nil
+	return err
+}
+
+// resolveDB returns the database handle to run queries on, routing a
+// ShardRouter on the shard key of ctx and obtaining a live connection from a
+// ManagedConn.
+func resolveDB(ctx context.Context, db interface{}) (interface{}, error) {
+	if r, ok := db.(*ShardRouter); ok {
+		routed, err := r.route(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		db = routed
+	}
+	if m, ok := db.(*ManagedConn); ok {
+		return m.Conn(ctx)
+	}
+	return db, nil
+}
@@ -0,0 +1,190 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Constraint describes the column constraints of a field checked by Insert
+// and Update before the statement is sent, see WithConstraint.
+type Constraint struct {
+	NotNull    bool // The column rejects NULL
+	HasDefault bool // The column has a default, so a not-null field may be omitted on insert
+	MaxLength  int  // Maximum number of characters of the column, 0 for no limit
+}
+
+// FieldError is a value rejected for a field of a write.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the fields of an Insert or Update whose values break
+// the constraints of their columns, so that API clients can report them next
+// to the fields.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		problems[i] = f.Field + " " + f.Message
+	}
+	return "invalid values: " + strings.Join(problems, "; ")
+}
+
+// validateConstraints checks values, written by an insert when insert is set
+// and by an update otherwise, against the constraints of the fields of
+// metadata. It returns a *ValidationError listing every rejected field.
+func validateConstraints(metadata ModelMetadata, values map[string]interface{}, insert bool) error {
+	var errs []FieldError
+	for _, name := range sortedKeys(metadata.Fields) {
+		field := metadata.Fields[name]
+		value, ok := values[name]
+		if ok && value != nil {
+			// Pointers and wrappers such as sql.NullString hold the value
+			v := reflect.ValueOf(value)
+			if v.Kind() == reflect.Ptr && !v.IsNil() {
+				v = v.Elem()
+			}
+			value = resultValue(v)
+		}
+		if !ok {
+			if insert && field.NotNull && !field.HasDefault && !field.ReadOnly && !isAuditField(metadata, name) {
+				errs = append(errs, FieldError{Field: name, Message: "is required"})
+			}
+			continue
+		}
+		if value == nil {
+			if field.NotNull {
+				errs = append(errs, FieldError{Field: name, Message: "cannot be null"})
+			}
+			continue
+		}
+		if s, ok := value.(string); ok && field.MaxLength > 0 && utf8.RuneCountInString(s) > field.MaxLength {
+			errs = append(errs, FieldError{Field: name, Message: fmt.Sprintf("must be at most %d characters", field.MaxLength)})
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// constraintsQueryDialect is implemented by dialects without an
+// information_schema.columns view, like columnsQueryDialect.
+type constraintsQueryDialect interface {
+	// ConstraintsQuery returns the query listing the column_name,
+	// is_nullable, column_default and character_maximum_length of the
+	// columns of a table, bound like the query of ColumnsQuery.
+	ConstraintsQuery(qualified bool) string
+}
+
+// catalogConstraint is the constraints of a column reported by the database
+// catalog.
+type catalogConstraint struct {
+	ColumnName    string         `db:"column_name"`
+	IsNullable    string         `db:"is_nullable"`
+	ColumnDefault sql.NullString `db:"column_default"`
+	MaxLength     sql.NullInt64  `db:"character_maximum_length"`
+}
+
+// LoadConstraints reads the not-null, default and maximum length constraints
+// of the columns of every model of the registry from the catalog of db, e.g.
+// at startup, so that Insert and Update reject bad values with a
+// *ValidationError before the database does. Constraints declared with
+// WithConstraint are kept.
+//
+// The registry is the one of ctx, see WithRegistry.
+func LoadConstraints(ctx context.Context, db interface{}) error {
+	r := registryFromContext(ctx)
+	r.mu.RLock()
+	tables := make(map[reflect.Type]ModelMetadata, len(r.models))
+	types := make([]reflect.Type, 0, len(r.models))
+	for t, metadata := range r.models {
+		tables[t] = metadata
+		types = append(types, t)
+	}
+	r.mu.RUnlock()
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+
+	dialect := r.Dialect()
+	loaded := make(map[reflect.Type]map[string]catalogConstraint, len(types))
+	for _, t := range types {
+		metadata := tables[t]
+		parts := metadata.tableIdent
+		if parts == nil {
+			parts = catalogTableParts(dialect, metadata.TableName)
+		}
+		columns, err := queryCatalogConstraints(ctx, db, dialect, parts)
+		if err != nil {
+			return fmt.Errorf("failed to read the constraints of %s: %w", metadata.TableName, err)
+		}
+		loaded[t] = make(map[string]catalogConstraint, len(columns))
+		for _, column := range columns {
+			loaded[t][strings.ToLower(column.ColumnName)] = column
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for t, columns := range loaded {
+		if metadata, ok := r.models[t]; ok {
+			r.models[t] = withCatalogConstraints(metadata, columns)
+		}
+		for variant, metadata := range r.variants[t] {
+			r.variants[t][variant] = withCatalogConstraints(metadata, columns)
+		}
+	}
+	return nil
+}
+
+// withCatalogConstraints returns metadata with the constraints of columns,
+// keyed by lower case column name, added to its fields. The fields are
+// copied, as calls in progress may be reading them.
+func withCatalogConstraints(metadata ModelMetadata, columns map[string]catalogConstraint) ModelMetadata {
+	fields := make(map[string]Field, len(metadata.Fields))
+	for name, field := range metadata.Fields {
+		if column, ok := columns[strings.ToLower(field.Name)]; ok {
+			field.NotNull = field.NotNull || !catalogColumn{IsNullable: column.IsNullable}.nullable()
+			field.HasDefault = field.HasDefault || column.ColumnDefault.Valid
+			if field.MaxLength == 0 && column.MaxLength.Valid {
+				field.MaxLength = int(column.MaxLength.Int64)
+			}
+		}
+		fields[name] = field
+	}
+	metadata.Fields = fields
+	return metadata
+}
+
+// queryCatalogConstraints reads the constraints of the columns of the table
+// named by parts, the table optionally preceded by its schema.
+func queryCatalogConstraints(ctx context.Context, db interface{}, dialect Dialect, parts []string) ([]catalogConstraint, error) {
+	qualified := len(parts) > 1
+	var query string
+	if d, ok := dialect.(constraintsQueryDialect); ok {
+		query = d.ConstraintsQuery(qualified)
+	} else if qualified {
+		query = fmt.Sprintf("SELECT column_name, is_nullable, column_default, character_maximum_length FROM information_schema.columns WHERE table_schema = %s AND table_name = %s",
+			dialect.BindVar(1), dialect.BindVar(2))
+	} else {
+		query = fmt.Sprintf("SELECT column_name, is_nullable, column_default, character_maximum_length FROM information_schema.columns WHERE table_name = %s", dialect.BindVar(1))
+	}
+	args := make([]interface{}, len(parts))
+	for i, part := range parts {
+		args[i] = part
+	}
+
+	var columns []catalogConstraint
+	if err := selectAll(ctx, db, &columns, query, args...); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
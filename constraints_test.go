@@ -0,0 +1,77 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithConstraint(t *testing.T) {
+	registry := NewRegistry()
+	assert.ErrorContains(t, registry.Register(MutationAccount{}, WithConstraint("color", Constraint{NotNull: true})),
+		"invalid field in constraint: color")
+	require.NoError(t, registry.Register(MutationAccount{},
+		WithConstraint("owner", Constraint{NotNull: true, MaxLength: 5}),
+		WithConstraint("note", Constraint{NotNull: true}),
+		WithConstraint("balance", Constraint{NotNull: true, HasDefault: true}),
+	))
+	ctx := WithRegistry(context.Background(), registry)
+
+	_, err := Insert[MutationAccount](ctx, nil, InsertRequest{Values: map[string]interface{}{"owner": "alexandra", "note": nil}})
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "%v", err)
+	assert.Equal(t, []FieldError{
+		{Field: "note", Message: "cannot be null"},
+		{Field: "owner", Message: "must be at most 5 characters"},
+	}, verr.Fields)
+	assert.EqualError(t, err, "failed to validate insert: invalid values: note cannot be null; owner must be at most 5 characters")
+
+	_, err = Insert[MutationAccount](ctx, nil, InsertRequest{Values: map[string]interface{}{"owner": "alice"}})
+	assert.ErrorContains(t, err, "invalid values: note is required")
+
+	_, err = Update[MutationAccount](ctx, nil, UpdateRequest{
+		Set:   map[string]interface{}{"note": nil},
+		Where: map[string]interface{}{"id": 1},
+	})
+	assert.EqualError(t, err, "failed to validate update: invalid values: note cannot be null")
+}
+
+func TestLoadConstraints(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(MutationAccount{}))
+	require.NoError(t, registry.Register(MutationAccount{}, ForRole("admin"), WithConstraint("owner", Constraint{MaxLength: 3})))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT column_name, is_nullable, column_default, character_maximum_length FROM information_schema.columns WHERE table_name = \$1`).
+		WithArgs("mutation_accounts").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "is_nullable", "column_default", "character_maximum_length"}).
+			AddRow("id", "NO", "nextval('mutation_accounts_id_seq')", nil).
+			AddRow("owner_name", "NO", nil, int64(20)).
+			AddRow("balance", "NO", "0", nil).
+			AddRow("note", "YES", nil, int64(200)))
+	require.NoError(t, LoadConstraints(ctx, db))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	metadata, err := registry.GetModelMetadata(MutationAccount{})
+	require.NoError(t, err)
+	assert.Equal(t, Constraint{NotNull: true, HasDefault: true}, fieldConstraint(metadata.Fields["id"]))
+	assert.Equal(t, Constraint{NotNull: true, MaxLength: 20}, fieldConstraint(metadata.Fields["owner"]))
+	assert.Equal(t, Constraint{MaxLength: 200}, fieldConstraint(metadata.Fields["note"]))
+
+	admin, err := registry.variantMetadata(MutationAccount{}, modelVariant{role: "admin"})
+	require.NoError(t, err)
+	assert.Equal(t, Constraint{NotNull: true, MaxLength: 3}, fieldConstraint(admin.Fields["owner"]))
+}
+
+// fieldConstraint returns the constraints of field.
+func fieldConstraint(field Field) Constraint {
+	return Constraint{NotNull: field.NotNull, HasDefault: field.HasDefault, MaxLength: field.MaxLength}
+}
@@ -0,0 +1,144 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CopySource yields the rows loaded by CopyFrom, one per call. It returns
+// false once there are no more rows.
+type CopySource[T Model] func() (row T, ok bool, err error)
+
+// CopyFromSlice returns a CopySource yielding the rows of rows.
+func CopyFromSlice[T Model](rows []T) CopySource[T] {
+	i := 0
+	return func() (T, bool, error) {
+		if i >= len(rows) {
+			var zero T
+			return zero, false, nil
+		}
+		i++
+		return rows[i-1], true, nil
+	}
+}
+
+// CopyFromChannel returns a CopySource yielding the rows received from ch
+// until it is closed.
+func CopyFromChannel[T Model](ch <-chan T) CopySource[T] {
+	return func() (T, bool, error) {
+		row, ok := <-ch
+		return row, ok, nil
+	}
+}
+
+// CopyFrom loads the rows of src into the table of model T with the COPY
+// protocol, which is much faster than INSERT for large imports. fields lists
+// the JSON names of the fields to load; when empty, every field not tagged
+// sqld:"readonly" is loaded. Rows are streamed, so src may yield more rows
+// than fit in memory. It returns the number of rows copied.
+//
+// COPY is only available on pgx connections.
+func CopyFrom[T Model](ctx context.Context, db interface{}, src CopySource[T], fields ...string) (int64, error) {
+	var model T
+	metadata, err := registryFromContext(ctx).GetModelMetadata(model)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := checkWritable(metadata); err != nil {
+		return 0, err
+	}
+	if len(fields) == 0 {
+		for _, name := range sortedKeys(metadata.Fields) {
+			if !metadata.Fields[name].ReadOnly {
+				fields = append(fields, name)
+			}
+		}
+	}
+	rows, err := newCopySourceRows(metadata, src, fields)
+	if err != nil {
+		return 0, err
+	}
+
+	db, err = resolveDB(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	conn, ok := db.(interface {
+		CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+	})
+	if !ok {
+		return 0, fmt.Errorf("copy requires a pgx connection, got %T", db)
+	}
+	table := pgx.Identifier{metadata.TableName}
+	if metadata.tableIdent != nil {
+		table = pgx.Identifier(metadata.tableIdent)
+	}
+	start := time.Now()
+	n, err := conn.CopyFrom(ctx, table, rows.columns, rows)
+	logQuery(ctx, "COPY "+table.Sanitize()+" FROM STDIN", nil, start, dbError(err))
+	if err != nil {
+		return n, fmt.Errorf("failed to copy rows: %w", dbError(err))
+	}
+	return n, nil
+}
+
+// copySourceRows adapts a CopySource to pgx.CopyFromSource.
+type copySourceRows[T Model] struct {
+	src     CopySource[T]
+	columns []string
+	indexes [][]int // struct field index of each column
+	row     T
+	err     error
+}
+
+// newCopySourceRows maps fields to the columns of metadata and to the struct
+// fields of T.
+func newCopySourceRows[T Model](metadata ModelMetadata, src CopySource[T], fields []string) (*copySourceRows[T], error) {
+	rows := &copySourceRows[T]{src: src}
+	for _, name := range fields {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return nil, &UnknownFieldError{Field: name, In: "copy"}
+		}
+		if field.ReadOnly {
+			return nil, fmt.Errorf("field %s is read-only", name)
+		}
+		rows.columns = append(rows.columns, field.Name)
+		rows.indexes = append(rows.indexes, field.index)
+	}
+	return rows, nil
+}
+
+func (r *copySourceRows[T]) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	row, ok, err := r.src()
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.row = row
+	return ok
+}
+
+func (r *copySourceRows[T]) Values() ([]interface{}, error) {
+	v := reflect.ValueOf(r.row)
+	values := make([]interface{}, len(r.indexes))
+	for i, index := range r.indexes {
+		field, err := v.FieldByIndexErr(index)
+		if err != nil {
+			continue // Promoted through a nil embedded pointer, copied as NULL
+		}
+		values[i] = field.Interface()
+	}
+	return values, nil
+}
+
+func (r *copySourceRows[T]) Err() error {
+	return r.err
+}
@@ -0,0 +1,96 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainCopySource reads every row of rows.
+func drainCopySource(t *testing.T, rows pgx.CopyFromSource) [][]interface{} {
+	t.Helper()
+	var values [][]interface{}
+	for rows.Next() {
+		v, err := rows.Values()
+		require.NoError(t, err)
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestCopySourceRows(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	metadata, err := getModelMetadata(MutationAccount{})
+	require.NoError(t, err)
+
+	note := "vip"
+	src := CopyFromSlice([]MutationAccount{
+		{ID: 1, Owner: "alice", Balance: 10, Note: &note},
+		{ID: 2, Owner: "bob", Balance: 20},
+	})
+	rows, err := newCopySourceRows(metadata, src, []string{"owner", "balance", "note"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"owner_name", "balance", "note"}, rows.columns)
+	assert.Equal(t, [][]interface{}{
+		{"alice", 10.0, &note},
+		{"bob", 20.0, (*string)(nil)},
+	}, drainCopySource(t, rows))
+	assert.NoError(t, rows.Err())
+}
+
+func TestCopySourceRows_Channel(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	metadata, err := getModelMetadata(MutationAccount{})
+	require.NoError(t, err)
+
+	ch := make(chan MutationAccount, 2)
+	ch <- MutationAccount{Owner: "alice"}
+	ch <- MutationAccount{Owner: "bob"}
+	close(ch)
+
+	rows, err := newCopySourceRows(metadata, CopyFromChannel(ch), []string{"owner"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{"alice"}, {"bob"}}, drainCopySource(t, rows))
+}
+
+func TestCopySourceRows_Error(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	metadata, err := getModelMetadata(MutationAccount{})
+	require.NoError(t, err)
+
+	calls := 0
+	src := CopySource[MutationAccount](func() (MutationAccount, bool, error) {
+		calls++
+		if calls > 1 {
+			return MutationAccount{}, false, errors.New("read failed")
+		}
+		return MutationAccount{Owner: "alice"}, true, nil
+	})
+	rows, err := newCopySourceRows(metadata, src, []string{"owner"})
+	require.NoError(t, err)
+	assert.Len(t, drainCopySource(t, rows), 1)
+	assert.EqualError(t, rows.Err(), "read failed")
+	assert.False(t, rows.Next())
+
+	_, err = newCopySourceRows(metadata, src, []string{"id"})
+	assert.ErrorContains(t, err, "read-only")
+	_, err = newCopySourceRows(metadata, src, []string{"color"})
+	assert.ErrorContains(t, err, "invalid field")
+}
+
+func TestCopyFrom_RequiresPgx(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = CopyFrom[MutationAccount](context.Background(), db, CopyFromSlice([]MutationAccount{{Owner: "alice"}}))
+	assert.ErrorContains(t, err, "requires a pgx connection")
+}
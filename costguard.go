@@ -0,0 +1,130 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrQueryTooExpensive is returned, by a *QueryCostError, for queries the
+// planner expects to exceed the CostLimits of the call.
+var ErrQueryTooExpensive = errors.New("query too expensive")
+
+// CostLimits bound the estimates of the query planner for the queries of
+// Execute and ExecuteStream, checked with EXPLAIN before they run. Zero
+// leaves a bound unset.
+type CostLimits struct {
+	MaxCost float64 // Total cost of the plan, in the units of the planner
+	MaxRows float64 // Number of rows the plan returns
+}
+
+// QueryCostError is returned for a query whose estimates exceed its
+// CostLimits.
+type QueryCostError struct {
+	Cost   float64 // Estimated total cost
+	Rows   float64 // Estimated number of rows
+	Limits CostLimits
+}
+
+func (e *QueryCostError) Error() string {
+	if e.Limits.MaxCost > 0 && e.Cost > e.Limits.MaxCost {
+		return fmt.Sprintf("query too expensive: estimated cost %.2f exceeds %.2f", e.Cost, e.Limits.MaxCost)
+	}
+	return fmt.Sprintf("query too expensive: estimated %.0f rows exceed %.0f", e.Rows, e.Limits.MaxRows)
+}
+
+func (e *QueryCostError) Is(target error) bool {
+	return target == ErrQueryTooExpensive
+}
+
+// costDialect is implemented by dialects whose planner estimates can be read,
+// used by CostLimits.
+type costDialect interface {
+	// ExplainCost returns the statement returning the plan of query, without
+	// running it, as a single value.
+	ExplainCost(query string) string
+
+	// ParseCost returns the estimated total cost and rows of the plan
+	// returned by the statement of ExplainCost.
+	ParseCost(plan []byte) (cost, rows float64, err error)
+}
+
+func (postgresDialect) ExplainCost(query string) string {
+	return "EXPLAIN (FORMAT JSON) " + query
+}
+
+func (postgresDialect) ParseCost(plan []byte) (cost, rows float64, err error) {
+	var plans []struct {
+		Plan struct {
+			TotalCost float64 `json:"Total Cost"`
+			PlanRows  float64 `json:"Plan Rows"`
+		}
+	}
+	if err := json.Unmarshal(plan, &plans); err != nil {
+		return 0, 0, err
+	}
+	if len(plans) == 0 {
+		return 0, 0, fmt.Errorf("empty plan")
+	}
+	return plans[0].Plan.TotalCost, plans[0].Plan.PlanRows, nil
+}
+
+type costLimitsKey struct{}
+
+// SetCostLimits sets the limits of the planner estimates of the queries run
+// with the default registry. Each query is then preceded by an EXPLAIN,
+// which costs a round trip, and rejected with a *QueryCostError when its
+// estimates exceed them, protecting the database from the most expensive
+// combinations of filters clients can send. Only the Postgres dialect can
+// estimate costs; with other dialects, queries fail. The zero CostLimits
+// disables the check.
+func SetCostLimits(limits CostLimits) {
+	defaultRegistry.SetCostLimits(limits)
+}
+
+// SetCostLimits sets the limits of the planner estimates of the queries run
+// with the registry.
+func (r *Registry) SetCostLimits(limits CostLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.costLimits = limits
+}
+
+// WithCostLimits checks the queries of the call against limits, overriding
+// those set with SetCostLimits. The zero CostLimits disables the check.
+func WithCostLimits(limits CostLimits) ExecuteOption {
+	return func(c *executeConfig) { c.costLimits = &limits }
+}
+
+// checkCost rejects query, to be run on db with args, when the planner
+// estimates exceed the cost limits of the call or of the registry of ctx.
+func checkCost(ctx context.Context, db interface{}, query string, args []interface{}) error {
+	r := registryFromContext(ctx)
+	limits, ok := ctx.Value(costLimitsKey{}).(CostLimits)
+	if !ok {
+		r.mu.RLock()
+		limits = r.costLimits
+		r.mu.RUnlock()
+	}
+	if limits == (CostLimits{}) {
+		return nil
+	}
+	dialect, ok := r.Dialect().(costDialect)
+	if !ok {
+		return fmt.Errorf("dialect %s can't estimate query costs", r.Dialect().Name())
+	}
+
+	var plan []byte
+	if err := getOne(ctx, db, &plan, dialect.ExplainCost(query), args...); err != nil {
+		return fmt.Errorf("failed to estimate query cost: %w", err)
+	}
+	cost, rows, err := dialect.ParseCost(plan)
+	if err != nil {
+		return fmt.Errorf("failed to estimate query cost: %w", err)
+	}
+	if (limits.MaxCost > 0 && cost > limits.MaxCost) || (limits.MaxRows > 0 && rows > limits.MaxRows) {
+		return &QueryCostError{Cost: cost, Rows: rows, Limits: limits}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostLimits(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+	registry.SetCostLimits(CostLimits{MaxCost: 1000, MaxRows: 500})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"name": "a"}}
+	explain := func(cost, rows string) {
+		mock.ExpectQuery(`EXPLAIN \(FORMAT JSON\) SELECT id FROM test_models WHERE name = \$1`).
+			WithArgs("a").
+			WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).
+				AddRow(`[{"Plan": {"Node Type": "Seq Scan", "Total Cost": ` + cost + `, "Plan Rows": ` + rows + `}}]`))
+	}
+
+	explain("25.88", "6")
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE name = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	resp, err := Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Len(t, resp.Data, 1)
+
+	explain("1834.50", "6")
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	require.ErrorIs(t, err, ErrQueryTooExpensive)
+	var costErr *QueryCostError
+	require.True(t, errors.As(err, &costErr))
+	assert.Equal(t, 1834.5, costErr.Cost)
+	assert.EqualError(t, err, "query too expensive: estimated cost 1834.50 exceeds 1000.00")
+
+	explain("25.88", "90000")
+	_, err = ExecuteStream[BuilderTestModel](ctx, db, req)
+	require.ErrorIs(t, err, ErrQueryTooExpensive)
+	assert.EqualError(t, err, "query too expensive: estimated 90000 rows exceed 500")
+
+	// The limits of the call override those of the registry
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE name = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = Execute[BuilderTestModel](ctx, db, req, WithCostLimits(CostLimits{}))
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	registry.SetDialect(MySQL)
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	assert.EqualError(t, err, "dialect mysql can't estimate query costs")
+}
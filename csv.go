@@ -0,0 +1,137 @@
+package sqld
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// CSVOptions configures the output of ExecuteCSV. The zero value writes
+// comma-separated values with a header row, quoting fields only when needed,
+// as RFC 4180 describes.
+type CSVOptions struct {
+	Delimiter rune // Field delimiter, ',' when zero
+	QuoteAll  bool // Quote every field, not only those that need it
+	NoHeader  bool // Leave out the header row
+	UseCRLF   bool // End lines with \r\n instead of \n
+}
+
+// ExecuteCSV runs req like ExecuteStream and writes its rows to w as CSV as
+// they are read, after a header row naming the selected fields. Nulls are
+// empty fields, times are RFC 3339 and arrays, objects and JSON are encoded
+// as JSON. Writers such as http.ResponseWriter are flushed every 100 rows
+// and at the end. It returns the number of rows written, without the header.
+func ExecuteCSV[T Model](ctx context.Context, db interface{}, req QueryRequest, w io.Writer, csvOpts CSVOptions, opts ...ExecuteOption) (written int, err error) {
+	delimiter := csvOpts.Delimiter
+	if delimiter == 0 {
+		delimiter = ','
+	}
+	if delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+		return 0, fmt.Errorf("invalid CSV delimiter %q", delimiter)
+	}
+	stream, err := ExecuteStream[T](ctx, db, req, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+
+	w, closeOutput := exportWriter(newExecuteConfig(opts), w)
+	defer func() {
+		if closeErr := closeOutput(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to write rows: %w", closeErr)
+		}
+	}()
+
+	buf := bufio.NewWriter(w)
+	cw := csvWriter{w: buf, delimiter: delimiter, quoteAll: csvOpts.QuoteAll, crlf: csvOpts.UseCRLF}
+	columns := stream.Columns()
+	if !csvOpts.NoHeader {
+		cw.writeRecord(columns)
+	}
+
+	record := make([]string, len(columns))
+	for stream.Next() {
+		row := stream.Row()
+		for i, column := range columns {
+			field, err := exportText(row[column])
+			if err != nil {
+				return written, fmt.Errorf("failed to write field %s: %w", column, err)
+			}
+			record[i] = field
+		}
+		cw.writeRecord(record)
+		written++
+		if written%exportFlushRows == 0 {
+			if err := flushWriter(buf, w); err != nil {
+				return written, err
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		flushWriter(buf, w) // What was read is still written
+		return written, err
+	}
+	return written, flushWriter(buf, w)
+}
+
+// exportText returns the text of value, a value of a row, in a CSV field or
+// an Arrow utf8 column.
+func exportText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.RawMessage:
+		return string(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	}
+	return fmt.Sprint(value), nil
+}
+
+// csvWriter writes CSV records, errors being reported by the flush of the
+// underlying writer.
+type csvWriter struct {
+	w         *bufio.Writer
+	delimiter rune
+	quoteAll  bool
+	crlf      bool
+}
+
+func (c csvWriter) writeRecord(record []string) {
+	for i, field := range record {
+		if i > 0 {
+			c.w.WriteRune(c.delimiter)
+		}
+		if !c.quoteAll && !c.needsQuotes(field) {
+			c.w.WriteString(field)
+			continue
+		}
+		c.w.WriteByte('"')
+		c.w.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		c.w.WriteByte('"')
+	}
+	if c.crlf {
+		c.w.WriteString("\r\n")
+	} else {
+		c.w.WriteByte('\n')
+	}
+}
+
+// needsQuotes reports whether field must be quoted: when it holds the
+// delimiter, a quote or a line break, or starts with a space.
+func (c csvWriter) needsQuotes(field string) bool {
+	return strings.ContainsRune(field, c.delimiter) || strings.ContainsAny(field, "\"\r\n") ||
+		strings.HasPrefix(field, " ")
+}
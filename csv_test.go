@@ -0,0 +1,49 @@
+package sqld
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteCSV(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id", "name"}}
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(1, "plain").AddRow(2, `say "hi", then go`).AddRow(3, nil))
+	var out strings.Builder
+	n, err := ExecuteCSV[BuilderTestModel](ctx, db, req, &out, CSVOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "id,name\n1,plain\n2,\"say \"\"hi\"\", then go\"\n3,\n", out.String())
+
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a;b"))
+	out.Reset()
+	_, err = ExecuteCSV[BuilderTestModel](ctx, db, req, &out, CSVOptions{Delimiter: ';', QuoteAll: true, UseCRLF: true})
+	require.NoError(t, err)
+	assert.Equal(t, "\"id\";\"name\"\r\n\"1\";\"a;b\"\r\n", out.String())
+
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	out.Reset()
+	_, err = ExecuteCSV[BuilderTestModel](ctx, db, req, &out, CSVOptions{Delimiter: '\t', NoHeader: true})
+	require.NoError(t, err)
+	assert.Equal(t, "1\ta\n", out.String())
+
+	_, err = ExecuteCSV[BuilderTestModel](ctx, db, req, &out, CSVOptions{Delimiter: '"'})
+	assert.EqualError(t, err, `invalid CSV delimiter '"'`)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
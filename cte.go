@@ -0,0 +1,130 @@
+package sqld
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// CTE is a named query of a WITH clause. Later CTEs and the main query read
+// from it by naming it in QueryRequest.From, which lets staged filtering
+// pipelines be composed without string concatenation.
+type CTE struct {
+	// Name is the name the CTE is referenced by.
+	Name string `json:"name"`
+
+	// Query is the query of the CTE, on the same model as the main query.
+	// Its From may name an earlier CTE. Only the fields of the model itself
+	// can be selected, and these are the only fields available to queries
+	// reading from the CTE.
+	Query QueryRequest `json:"query"`
+}
+
+// resolveCTEs validates the CTEs of with and returns the metadata of each,
+// keyed by name. The metadata of a CTE only holds the fields it selects.
+func resolveCTEs(metadata ModelMetadata, with []CTE) (map[string]ModelMetadata, error) {
+	if len(with) == 0 {
+		return nil, nil
+	}
+
+	scopes := make(map[string]ModelMetadata, len(with))
+	for _, cte := range with {
+		if !identRegex.MatchString(cte.Name) || strings.Contains(cte.Name, ".") {
+			return nil, fmt.Errorf("invalid CTE name: %q", cte.Name)
+		}
+		clashes := cte.Name == metadata.TableName || metadata.dialect().QuoteIdent(cte.Name) == metadata.aliased().TableName
+		if _, exists := scopes[cte.Name]; exists || clashes {
+			return nil, fmt.Errorf("CTE %s clashes with another CTE or the table", cte.Name)
+		}
+
+		q := cte.Query
+		if len(q.With) > 0 {
+			return nil, fmt.Errorf("CTE %s: nested WITH is not supported", cte.Name)
+		}
+		if q.Pagination != nil || q.Pivot != nil || len(q.Include) > 0 || len(q.Nested) > 0 {
+			return nil, fmt.Errorf("CTE %s: pagination, pivot, include and nested are not supported", cte.Name)
+		}
+
+		source, err := sourceMetadata(metadata, scopes, q.From)
+		if err != nil {
+			return nil, fmt.Errorf("CTE %s: %w", cte.Name, err)
+		}
+		if err := validateRequest(q, source); err != nil {
+			return nil, fmt.Errorf("CTE %s: %w", cte.Name, err)
+		}
+
+		scope := source.aliased()
+		scope.Fields = make(map[string]Field, len(q.Select))
+		scope.Hierarchy = nil
+		// Soft-deleted rows are already filtered out by the CTE itself
+		scope.SoftDelete = nil
+		for _, name := range q.Select {
+			ref, _ := source.lookupField(name)
+			if ref.Relation != "" {
+				return nil, fmt.Errorf("CTE %s: related field %s can't be selected", cte.Name, name)
+			}
+			scope.Fields[name] = ref.Field
+		}
+		scopes[cte.Name] = scope
+	}
+	return scopes, nil
+}
+
+// sourceMetadata returns the metadata of what a query reads from: the model
+// itself, or the CTE named by from.
+func sourceMetadata(metadata ModelMetadata, scopes map[string]ModelMetadata, from string) (ModelMetadata, error) {
+	if from == "" {
+		return metadata, nil
+	}
+	scope, ok := scopes[from]
+	if !ok {
+		return ModelMetadata{}, fmt.Errorf("invalid from: %s is not a preceding CTE", from)
+	}
+	return scope, nil
+}
+
+// fromClause returns the FROM expression of a query. CTEs are aliased with
+// the table name so that qualified columns and joins apply unchanged; the
+// metadata of a CTE is already aliased, see aliased.
+func fromClause(metadata ModelMetadata, from string) string {
+	if from == "" {
+		return metadata.TableName
+	}
+	return tableAlias(metadata.dialect(), from, metadata.TableName)
+}
+
+// aliased returns metadata for reading the rows of the model from a CTE
+// aliased with its table name. A table qualified with a schema can't be an
+// alias, so the alias and the columns qualified with it use the table
+// identifier alone.
+func (m ModelMetadata) aliased() ModelMetadata {
+	if len(m.tableIdent) > 1 {
+		m.TableName = m.dialect().QuoteIdent(m.tableIdent[len(m.tableIdent)-1])
+	}
+	return m
+}
+
+// applyCTEs prefixes query with the WITH clause of the CTEs of with.
+func applyCTEs(query squirrel.SelectBuilder, metadata ModelMetadata, scopes map[string]ModelMetadata, with []CTE) (squirrel.SelectBuilder, error) {
+	if len(with) == 0 {
+		return query, nil
+	}
+
+	parts := make([]string, len(with))
+	subqueries := make([]interface{}, len(with))
+	for i, cte := range with {
+		source, err := sourceMetadata(metadata, scopes, cte.Query.From)
+		if err != nil {
+			return squirrel.SelectBuilder{}, err
+		}
+		sub, err := selectBody(source, cte.Query)
+		if err != nil {
+			return squirrel.SelectBuilder{}, fmt.Errorf("CTE %s: %w", cte.Name, err)
+		}
+		// Placeholders are numbered once the whole statement is built
+		parts[i] = cte.Name + " AS (?)"
+		subqueries[i] = sub.PlaceholderFormat(squirrel.Question)
+	}
+	return query.PrefixExpr(squirrel.Expr("WITH "+strings.Join(parts, ", "), subqueries...)), nil
+}
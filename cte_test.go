@@ -0,0 +1,180 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSelect_CTE(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	req := QueryRequest{
+		With: []CTE{
+			{Name: "engineering", Query: QueryRequest{
+				Select: []string{"id", "name", "department_id"},
+				Where:  map[string]interface{}{"department_id": 1},
+			}},
+			{Name: "named", Query: QueryRequest{
+				Select:  []string{"id", "name"},
+				From:    "engineering",
+				Where:   map[string]interface{}{"name": "alice"},
+				OrderBy: []OrderByClause{{Field: "id"}},
+			}},
+		},
+		Select: []string{"id", "name"},
+		From:   "named",
+		Where:  map[string]interface{}{"id": 7},
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, employees))
+
+	query, err := buildSelect(employees, req)
+	require.NoError(t, err)
+	sql, args, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "WITH engineering AS (SELECT id, name, department_id FROM employees WHERE department_id = $1), "+
+		"named AS (SELECT id, name FROM engineering AS employees WHERE name = $2 ORDER BY id ASC) "+
+		"SELECT id, name FROM named AS employees WHERE id = $3", sql)
+	assert.Equal(t, []interface{}{1, "alice", 7}, args)
+
+	count, err := buildCount(employees, req)
+	require.NoError(t, err)
+	sql, _, err = count.ToSql()
+	require.NoError(t, err)
+	assert.Contains(t, sql, "SELECT COUNT(*) FROM named AS employees WHERE id = $3")
+}
+
+func TestBuildSelect_CTEWithRelation(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	req := QueryRequest{
+		With: []CTE{{Name: "staff", Query: QueryRequest{
+			Select: []string{"name", "department_id"},
+			Where:  map[string]interface{}{"department.name": "eng"},
+		}}},
+		Select: []string{"name", "department.name"},
+		From:   "staff",
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, employees))
+
+	query, err := buildSelect(employees, req)
+	require.NoError(t, err)
+	sql, _, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "WITH staff AS (SELECT employees.name, employees.department_id FROM employees "+
+		"LEFT JOIN departments AS department ON department.id = employees.department_id WHERE department.name = $1) "+
+		`SELECT employees.name, department.name AS "department.name" FROM staff AS employees `+
+		"LEFT JOIN departments AS department ON department.id = employees.department_id", sql)
+}
+
+func TestBuildSelect_CTEWithTable(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(RelEmployee{}, WithTable("hr.employees")))
+	require.NoError(t, registry.Register(RelDepartment{}))
+	require.NoError(t, registry.RegisterRelation(RelEmployee{}, RelDepartment{}, "department", Relation{
+		Kind:         BelongsTo,
+		LocalField:   "department_id",
+		ForeignField: "id",
+	}))
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	req := QueryRequest{
+		With:   []CTE{{Name: "staff", Query: QueryRequest{Select: []string{"name", "department_id"}}}},
+		Select: []string{"name", "department.name"},
+		From:   "staff",
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, employees))
+
+	// The CTE is aliased with the table alone, since "hr"."employees"
+	// isn't a valid alias
+	query, err := buildSelect(employees, req)
+	require.NoError(t, err)
+	sql, _, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `WITH staff AS (SELECT name, department_id FROM "hr"."employees") `+
+		`SELECT "employees".name, department.name AS "department.name" FROM staff AS "employees" `+
+		`LEFT JOIN departments AS department ON department.id = "employees".department_id`, sql)
+
+	req.With[0].Name, req.From = "employees", "employees"
+	assert.Error(t, BasicValidator{}.ValidateQuery(req, employees), "CTE clashes with the alias of the table")
+}
+
+func TestValidateQuery_CTEErrors(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	cte := func(name string, q QueryRequest) []CTE { return []CTE{{Name: name, Query: q}} }
+	tests := []struct {
+		name string
+		req  QueryRequest
+	}{
+		{"unknown from", QueryRequest{Select: []string{"id"}, From: "missing"}},
+		{"field not selected by CTE", QueryRequest{
+			With: cte("c", QueryRequest{Select: []string{"id"}}), Select: []string{"name"}, From: "c",
+		}},
+		{"invalid CTE name", QueryRequest{
+			With: cte("bad name", QueryRequest{Select: []string{"id"}}), Select: []string{"id"},
+		}},
+		{"CTE named like the table", QueryRequest{
+			With: cte("employees", QueryRequest{Select: []string{"id"}}), Select: []string{"id"},
+		}},
+		{"CTE reading from a later CTE", QueryRequest{
+			With: []CTE{
+				{Name: "a", Query: QueryRequest{Select: []string{"id"}, From: "b"}},
+				{Name: "b", Query: QueryRequest{Select: []string{"id"}}},
+			},
+			Select: []string{"id"},
+		}},
+		{"nested WITH", QueryRequest{
+			With:   cte("c", QueryRequest{Select: []string{"id"}, With: cte("d", QueryRequest{Select: []string{"id"}})}),
+			Select: []string{"id"},
+		}},
+		{"related field in CTE", QueryRequest{
+			With: cte("c", QueryRequest{Select: []string{"department.name"}}), Select: []string{"id"},
+		}},
+		{"pagination in CTE", QueryRequest{
+			With: cte("c", QueryRequest{Select: []string{"id"}, Pagination: &PaginationRequest{Page: 1}}), Select: []string{"id"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, BasicValidator{}.ValidateQuery(tt.req, employees))
+		})
+	}
+}
+
+func TestExecute_CTE(t *testing.T) {
+	require.NoError(t, Register(RelEmployee{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`WITH eng AS \(SELECT id, name FROM employees WHERE department_id = \$1\) SELECT name FROM eng AS employees`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("alice"))
+
+	resp, err := Execute[RelEmployee](context.Background(), db, QueryRequest{
+		With: []CTE{{Name: "eng", Query: QueryRequest{
+			Select: []string{"id", "name"},
+			Where:  map[string]interface{}{"department_id": 1},
+		}}},
+		Select: []string{"name"},
+		From:   "eng",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"name": "alice"}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,90 @@
+package sqld
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Kinds of database errors API layers commonly answer differently, e.g. with
+// a 409 or a 422, matched with errors.Is. The errors carrying them are
+// *DatabaseError.
+var (
+	ErrUniqueViolation      = errors.New("unique violation")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrCheckViolation       = errors.New("check violation")
+	ErrNotNullViolation     = errors.New("not null violation")
+	ErrSerializationFailure = errors.New("serialization failure")
+)
+
+// sqlStateErrors maps SQLSTATE codes to the kinds of DatabaseError.
+var sqlStateErrors = map[string]error{
+	"23505": ErrUniqueViolation,
+	"23503": ErrForeignKeyViolation,
+	"23514": ErrCheckViolation,
+	"23502": ErrNotNullViolation,
+	"40001": ErrSerializationFailure,
+}
+
+// DatabaseError is a driver error with a SQLSTATE code sqld maps to one of
+// the kinds above, such as ErrUniqueViolation. Its message is that of the
+// driver error, which it wraps, so that errors.As still finds e.g. the
+// *pgconn.PgError.
+type DatabaseError struct {
+	Kind       error  // ErrUniqueViolation, ErrForeignKeyViolation, ...
+	Code       string // SQLSTATE code, e.g. "23505"
+	Constraint string // Name of the violated constraint, if reported
+	Table      string // Table of the constraint, if reported
+	Column     string // Column of the constraint, if reported
+	Detail     string // Detail of the driver, e.g. the duplicate key
+	Err        error  // Error of the driver
+}
+
+func (e *DatabaseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DatabaseError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *DatabaseError) Unwrap() error {
+	return e.Err
+}
+
+// sqlStater is implemented by the errors of drivers reporting SQLSTATE codes,
+// such as pgx and lib/pq.
+type sqlStater interface {
+	SQLState() string
+}
+
+// dbError returns err as a *DatabaseError when its SQLSTATE code is mapped,
+// and err otherwise. The constraint, table, column and detail come from
+// *pgconn.PgError, or from the string fields of that name of other driver
+// errors, such as *pq.Error.
+func dbError(err error) error {
+	var mapped *DatabaseError
+	var state sqlStater
+	if err == nil || errors.As(err, &mapped) || !errors.As(err, &state) {
+		return err
+	}
+	kind, ok := sqlStateErrors[state.SQLState()]
+	if !ok {
+		return err
+	}
+	e := &DatabaseError{Kind: kind, Code: state.SQLState(), Err: err}
+	if pgErr, ok := state.(*pgconn.PgError); ok {
+		e.Constraint, e.Table, e.Column, e.Detail = pgErr.ConstraintName, pgErr.TableName, pgErr.ColumnName, pgErr.Detail
+		return e
+	}
+	v := reflect.Indirect(reflect.ValueOf(state))
+	if v.Kind() == reflect.Struct {
+		for name, dest := range map[string]*string{"Constraint": &e.Constraint, "Table": &e.Table, "Column": &e.Column, "Detail": &e.Detail} {
+			if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+				*dest = f.String()
+			}
+		}
+	}
+	return e
+}
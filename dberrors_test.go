@@ -0,0 +1,62 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePqError mimics *pq.Error, which reports its SQLSTATE and constraint in
+// fields of its own.
+type fakePqError struct {
+	Code       string
+	Constraint string
+	Table      string
+}
+
+func (e *fakePqError) Error() string    { return "pq: " + e.Code }
+func (e *fakePqError) SQLState() string { return e.Code }
+
+func TestDatabaseErrors(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(MutationAccount{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO mutation_accounts`).WillReturnError(&pgconn.PgError{
+		Code: "23505", Message: "duplicate key value violates unique constraint", ConstraintName: "accounts_owner_key",
+		TableName: "mutation_accounts", Detail: "Key (owner_name)=(alice) already exists.",
+	})
+	_, err = Insert[MutationAccount](ctx, db, InsertRequest{Values: map[string]interface{}{"owner": "alice"}, Returning: []string{"id"}})
+	assert.ErrorIs(t, err, ErrUniqueViolation)
+	var dbErr *DatabaseError
+	require.ErrorAs(t, err, &dbErr)
+	assert.Equal(t, "23505", dbErr.Code)
+	assert.Equal(t, "accounts_owner_key", dbErr.Constraint)
+	assert.Equal(t, "mutation_accounts", dbErr.Table)
+	assert.Equal(t, "Key (owner_name)=(alice) already exists.", dbErr.Detail)
+	var pgErr *pgconn.PgError
+	assert.ErrorAs(t, err, &pgErr)
+	assert.Contains(t, err.Error(), "duplicate key value violates unique constraint")
+
+	mock.ExpectQuery(`UPDATE mutation_accounts`).WillReturnError(&fakePqError{Code: "23503", Constraint: "accounts_team_fkey", Table: "mutation_accounts"})
+	_, err = Update[MutationAccount](ctx, db, UpdateRequest{Set: map[string]interface{}{"balance": 1}, Where: map[string]interface{}{"id": 1}})
+	assert.ErrorIs(t, err, ErrForeignKeyViolation)
+	require.ErrorAs(t, err, &dbErr)
+	assert.Equal(t, "accounts_team_fkey", dbErr.Constraint)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// Other codes and errors are left as they are
+	assert.Equal(t, error(&fakePqError{Code: "42601"}), dbError(&fakePqError{Code: "42601"}))
+	plain := errors.New("connection refused")
+	assert.Equal(t, plain, dbError(plain))
+	assert.ErrorIs(t, dbError(&pgconn.PgError{Code: "40001"}), ErrSerializationFailure)
+}
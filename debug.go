@@ -0,0 +1,89 @@
+package sqld
+
+import (
+	"context"
+	"log/slog"
+)
+
+type debugKey struct{}
+
+// SetSlogLogger sets the logger of the debug output and background failures,
+// such as those of ResultCache.StartWarming, of the default registry, nil for
+// slog.Default.
+func SetSlogLogger(logger *slog.Logger) {
+	defaultRegistry.SetSlogLogger(logger)
+}
+
+// SetSlogLogger sets the logger of the debug output and background failures
+// of the registry.
+func (r *Registry) SetSlogLogger(logger *slog.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.slog = logger
+}
+
+// SetDebug sets whether every statement run with the default registry is
+// logged at debug level, with its SQL and values bound, as WithDebug does
+// for a single call. The values are logged as is, so it is meant for
+// development rather than production.
+func SetDebug(debug bool) {
+	defaultRegistry.SetDebug(debug)
+}
+
+// SetDebug sets whether every statement run with the registry is logged at
+// debug level.
+func (r *Registry) SetDebug(debug bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.debug = debug
+}
+
+// debugLogger returns the logger of the debug output of the call, nil when
+// neither the call nor the registry asks for it.
+func (r *Registry) debugLogger(ctx context.Context) *slog.Logger {
+	r.mu.RLock()
+	debug := r.debug
+	r.mu.RUnlock()
+	if on, _ := ctx.Value(debugKey{}).(bool); !on && !debug {
+		return nil
+	}
+	return r.slogLogger()
+}
+
+// slogLogger returns the logger set with SetSlogLogger, or slog.Default.
+func (r *Registry) slogLogger() *slog.Logger {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.slog == nil {
+		return slog.Default()
+	}
+	return r.slog
+}
+
+// SlogQueryLogger returns a QueryLogger writing every statement to logger,
+// at info level or at error level when it failed. The values bound are only
+// logged when SetLogQueryArgs includes them.
+func SlogQueryLogger(logger *slog.Logger) QueryLogger {
+	return QueryLoggerFunc(func(ctx context.Context, e QueryEvent) {
+		attrs := queryAttrs(e)
+		if e.Err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "sqld query failed", append(attrs, slog.Any("error", e.Err))...)
+			return
+		}
+		logger.LogAttrs(ctx, slog.LevelInfo, "sqld query", attrs...)
+	})
+}
+
+// queryAttrs returns the attributes logged for e.
+func queryAttrs(e QueryEvent) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("sql", e.SQL),
+		slog.String("fingerprint", e.Fingerprint),
+		slog.Int("args", e.ArgCount),
+		slog.Duration("duration", e.Duration),
+	}
+	if e.Args != nil {
+		attrs[2] = slog.Any("args", e.Args)
+	}
+	return attrs
+}
@@ -0,0 +1,75 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDebug(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	var out bytes.Buffer
+	registry.SetSlogLogger(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"email": "a@example.com"}}
+	expect := func() {
+		mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1`).
+			WithArgs("a@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	}
+
+	expect()
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Empty(t, out.String(), "nothing is logged without debug")
+
+	expect()
+	_, err = Execute[BuilderTestModel](ctx, db, req, WithDebug())
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "level=DEBUG")
+	assert.Contains(t, out.String(), `sql="SELECT id FROM test_models WHERE email = $1"`)
+	assert.Contains(t, out.String(), "args=[a@example.com]")
+
+	out.Reset()
+	registry.SetDebug(true)
+	expect()
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "level=DEBUG")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSlogQueryLogger(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	var out bytes.Buffer
+	registry.SetQueryLogger(SlogQueryLogger(slog.New(slog.NewTextHandler(&out, nil))))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1`).
+		WithArgs("a@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"email": "a@example.com"}})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "level=INFO")
+	assert.Contains(t, out.String(), "args=1")
+	assert.NotContains(t, out.String(), "a@example.com")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,65 @@
+package sqld
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// isDecimalType reports whether fields of type t hold arbitrary-precision
+// decimals such as amounts of money: decimal.Decimal and pgtype.Numeric,
+// their pointers and nullable wrappers such as decimal.NullDecimal.
+func isDecimalType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if underlying, ok := nullValueType(t); ok {
+		t = underlying
+	}
+	return t == reflect.TypeOf(decimal.Decimal{}) || t == reflect.TypeOf(pgtype.Numeric{})
+}
+
+// decimalValue returns value, a decimal given as a string, a JSON number, a
+// Go number or a decimal value, as a decimal string. Strings keep their
+// digits, e.g. the scale of 12.50. Nil stays nil.
+func decimalValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if _, err := decimal.NewFromString(v); err != nil {
+			return nil, fmt.Errorf("%q is not a decimal", v)
+		}
+		return v, nil
+	case []byte:
+		return decimalValue(string(v))
+	case json.Number:
+		return decimalValue(string(v))
+	case float64:
+		return decimal.NewFromFloat(v).String(), nil
+	case float32:
+		return decimal.NewFromFloat32(v).String(), nil
+	case decimal.Decimal:
+		return v.String(), nil
+	case decimal.NullDecimal:
+		if !v.Valid {
+			return nil, nil
+		}
+		return v.Decimal.String(), nil
+	case pgtype.Numeric:
+		value, _ := pgtypeValue(v)
+		return value, nil
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.CanInt():
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case rv.CanUint():
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return nil, fmt.Errorf("expected a decimal, got %T", value)
+}
@@ -0,0 +1,78 @@
+package sqld
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DecimalPayment struct {
+	ID       int64               `json:"id" db:"id"`
+	Amount   decimal.Decimal     `json:"amount" db:"amount"`
+	Fee      decimal.NullDecimal `json:"fee" db:"fee"`
+	Discount pgtype.Numeric      `json:"discount" db:"discount"`
+}
+
+func (DecimalPayment) TableName() string { return "payments" }
+
+type DecimalPaymentParams struct {
+	Amount decimal.Decimal `db:"amount" json:"amount"`
+}
+
+func TestExecute_Decimal(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(DecimalPayment{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT SUM\(amount\) AS "amount.sum", COUNT\(amount\) AS "amount.count" FROM payments WHERE amount IN \(\$1,\$2\)`).
+		WithArgs("0.1", "1234.50").
+		WillReturnRows(sqlmock.NewRows([]string{"amount.sum", "amount.count"}).AddRow([]byte("1234.60"), int64(2)))
+	mock.ExpectQuery(`SELECT id, amount, fee, discount FROM payments WHERE amount IN \(\$1,\$2\)`).
+		WithArgs("0.1", "1234.50").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "fee", "discount"}).
+			AddRow(int64(1), []byte("1234.50"), nil, "0.10"))
+	resp, err := Execute[DecimalPayment](ctx, db, QueryRequest{
+		Select:  []string{"id", "amount", "fee", "discount"},
+		Where:   map[string]interface{}{"amount": []interface{}{0.1, "1234.50"}},
+		Summary: []SummaryField{{Field: "amount", Func: SummarySum}, {Field: "amount", Func: SummaryCount}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "amount": "1234.50", "fee": nil, "discount": "0.10"}}, resp.Data)
+	assert.Equal(t, map[string]map[string]interface{}{"amount": {"sum": "1234.60", "count": int64(2)}}, resp.Summary)
+
+	_, err = Execute[DecimalPayment](ctx, db, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"amount": "12,50"}})
+	assert.ErrorContains(t, err, `invalid value for field amount: "12,50" is not a decimal`)
+	_, err = Insert[DecimalPayment](ctx, db, InsertRequest{Values: map[string]interface{}{"amount": true}})
+	assert.ErrorContains(t, err, "invalid value for field amount: expected a decimal, got bool")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteRaw_DecimalParam(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id FROM payments WHERE amount > \$1`).
+		WithArgs("99.99").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	_, err = ExecuteRaw[DecimalPaymentParams, DecimalPayment](context.Background(), db,
+		"SELECT id FROM payments WHERE amount > {{amount}}", map[string]interface{}{"amount": 99.99})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFieldType_Decimal(t *testing.T) {
+	assert.Equal(t, "string", FieldType(reflect.TypeFor[decimal.Decimal]()))
+	assert.Equal(t, "string", FieldType(reflect.TypeFor[decimal.NullDecimal]()))
+	assert.Equal(t, "string", FieldType(reflect.TypeFor[*pgtype.Numeric]()))
+}
@@ -0,0 +1,150 @@
+package sqld
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// ModelDescription describes a registered model for API clients, e.g. to
+// render the field pickers of a query-builder UI without hardcoding the
+// schema. It is returned by DescribeModel and encodes to JSON.
+type ModelDescription struct {
+	Name          string                `json:"name"`
+	Table         string                `json:"table"`
+	ReadOnly      bool                  `json:"read_only,omitempty"`
+	Fields        []FieldDescription    `json:"fields"`
+	Relations     []RelationDescription `json:"relations,omitempty"`
+	DefaultSelect []string              `json:"default_select,omitempty"`
+}
+
+// FieldDescription describes a field of a model.
+type FieldDescription struct {
+	Name       string   `json:"name"` // Go field name
+	Key        string   `json:"key"`  // JSON key used in requests and results
+	Type       string   `json:"type"` // See FieldType
+	Nullable   bool     `json:"nullable,omitempty"`
+	Required   bool     `json:"required,omitempty"`
+	ReadOnly   bool     `json:"read_only,omitempty"`
+	Enum       string   `json:"enum,omitempty"`
+	Selectable bool     `json:"selectable"`
+	Filterable bool     `json:"filterable"` // Usable in where, order_by and limit_by
+	Operators  []string `json:"operators,omitempty"`
+}
+
+// RelationDescription describes a relation of a model. Fields lists the keys
+// of the target model, selected as "<relation>.<key>".
+type RelationDescription struct {
+	Name   string       `json:"name"`
+	Kind   RelationKind `json:"kind"`
+	Target string       `json:"target"`
+	Fields []string     `json:"fields"`
+}
+
+// Operators of where conditions, as listed in FieldDescription.Operators.
+const (
+	OperatorEq     = "eq"      // {"field": value}
+	OperatorIn     = "in"      // {"field": [value, ...]}
+	OperatorIsNull = "is_null" // {"field": null}, for nullable fields
+)
+
+// DescribeModel describes model T as registered with the default registry.
+func DescribeModel[T Model]() (ModelDescription, error) {
+	var model T
+	return defaultRegistry.DescribeModel(model)
+}
+
+// DescribeModel describes model as registered with the registry.
+func (r *Registry) DescribeModel(model Model) (ModelDescription, error) {
+	metadata, err := r.GetModelMetadata(model)
+	if err != nil {
+		return ModelDescription{}, err
+	}
+	t := reflect.TypeOf(model)
+	desc := ModelDescription{
+		Name:          t.Name(),
+		Table:         metadata.TableName,
+		ReadOnly:      metadata.ReadOnly,
+		Fields:        make([]FieldDescription, 0, len(metadata.Fields)),
+		DefaultSelect: metadata.DefaultSelect,
+	}
+	for _, key := range sortedKeys(metadata.Fields) {
+		desc.Fields = append(desc.Fields, describeField(t, key, metadata.Fields[key]))
+	}
+	for _, name := range sortedKeys(metadata.Relations) {
+		rel := metadata.Relations[name]
+		desc.Relations = append(desc.Relations, RelationDescription{
+			Name:   name,
+			Kind:   rel.Kind,
+			Target: rel.target.TableName,
+			Fields: sortedKeys(rel.target.Fields),
+		})
+	}
+	return desc, nil
+}
+
+// describeField describes the field of model type t with the JSON key key.
+func describeField(t reflect.Type, key string, field Field) FieldDescription {
+	desc := FieldDescription{
+		Key:        key,
+		Type:       FieldType(field.Type),
+		Nullable:   isNullableType(field.Type),
+		Required:   field.Required,
+		ReadOnly:   field.ReadOnly,
+		Enum:       field.Enum,
+		Selectable: !field.FilterOnly,
+		Filterable: !field.SelectOnly,
+	}
+	if field.index != nil {
+		desc.Name = t.FieldByIndex(field.index).Name
+	}
+	if desc.Filterable {
+		desc.Operators = []string{OperatorEq, OperatorIn}
+		if desc.Nullable {
+			desc.Operators = append(desc.Operators, OperatorIsNull)
+		}
+	}
+	return desc
+}
+
+// FieldType returns the kind of values of a field of Go type t in JSON
+// requests and results: "string", "integer", "number", "boolean",
+// "datetime", "array", "object" or "any". Pointers and wrappers such as
+// sql.NullInt64 have the type of their value.
+func FieldType(t reflect.Type) string {
+	if isUUIDType(t) || isDecimalType(t) {
+		return "string" // See canonicalValue
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if underlying, ok := nullValueType(t); ok {
+		t = underlying
+	}
+	if typ, ok := pgtypeFieldTypes[t]; ok {
+		return typ
+	}
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return "datetime"
+	case t == reflect.TypeOf(json.RawMessage{}),
+		reflect.PointerTo(t).Implements(sqlScannerType),
+		reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()):
+		return "any" // Encoded as the type decides
+	case isIntegerKind(t.Kind()):
+		return "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.String:
+		return "string"
+	case t.Kind() == reflect.Bool:
+		return "boolean"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return "string" // Base64 in JSON
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "array"
+	case t.Kind() == reflect.Map || t.Kind() == reflect.Struct:
+		return "object"
+	}
+	return "any"
+}
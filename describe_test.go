@@ -0,0 +1,66 @@
+package sqld
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeModel(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	require.NoError(t, registry.Register(SensitiveUser{},
+		WithoutFields("password_hash"),
+		WithFilterOnlyFields("ssn"),
+		WithSelectOnlyFields("bio"),
+		WithDefaultSelect("id", "email"),
+	))
+
+	desc, err := registry.DescribeModel(SensitiveUser{})
+	require.NoError(t, err)
+	assert.Equal(t, "SensitiveUser", desc.Name)
+	assert.Equal(t, "sensitive_users", desc.Table)
+	assert.Equal(t, []string{"id", "email"}, desc.DefaultSelect)
+	assert.Equal(t, []FieldDescription{
+		{Name: "Bio", Key: "bio", Type: "string", Selectable: true},
+		{Name: "Email", Key: "email", Type: "string", Selectable: true, Filterable: true, Operators: []string{"eq", "in"}},
+		{Name: "ID", Key: "id", Type: "integer", Selectable: true, Filterable: true, Operators: []string{"eq", "in"}},
+		{Name: "SSN", Key: "ssn", Type: "string", Filterable: true, Operators: []string{"eq", "in"}},
+	}, desc.Fields)
+
+	desc, err = registry.DescribeModel(RelEmployee{})
+	require.NoError(t, err)
+	assert.Equal(t, []RelationDescription{
+		{Name: "department", Kind: BelongsTo, Target: "departments", Fields: []string{"id", "name"}},
+		{Name: "projects", Kind: ManyToMany, Target: "projects", Fields: []string{"id", "title"}},
+	}, desc.Relations)
+
+	encoded, err := json.Marshal(desc)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"relations":[{"name":"department","kind":"belongs_to","target":"departments"`)
+
+	_, err = registry.DescribeModel(JobCategory{})
+	assert.ErrorContains(t, err, "model JobCategory not registered")
+}
+
+func TestFieldType(t *testing.T) {
+	tests := map[string]interface{}{
+		"integer":  sql.NullInt64{},
+		"number":   float32(0),
+		"string":   new(string),
+		"boolean":  false,
+		"datetime": time.Time{},
+		"array":    []string{},
+		"object":   map[string]interface{}{},
+		"any":      CustomID{},
+	}
+	for want, v := range tests {
+		assert.Equal(t, want, FieldType(reflect.TypeOf(v)), "%T", v)
+	}
+	assert.Equal(t, "any", FieldType(reflect.TypeOf((*interface{})(nil)).Elem()))
+}
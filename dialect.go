@@ -0,0 +1,182 @@
+package sqld
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Dialect describes the SQL flavour of a database: how bind parameters,
+// identifiers and row limits are written. The dialect of the default registry
+// is set with SetDialect and defaults to Postgres.
+type Dialect interface {
+	// Name identifies the dialect, e.g. "postgres".
+	Name() string
+
+	// Placeholder returns the format of bind parameters in built queries.
+	Placeholder() squirrel.PlaceholderFormat
+
+	// BindVar returns the bind parameter at position i, starting at 1, used
+	// for the named parameters of ExecuteRaw.
+	BindVar(i int) string
+
+	// QuoteIdent quotes an identifier, e.g. a column alias.
+	QuoteIdent(name string) string
+
+	// LimitOffset returns the clause restricting a query to limit rows after
+	// skipping offset rows, where nil means unset. It is empty when both are nil.
+	LimitOffset(limit, offset *uint64) string
+
+	// SupportsReturning reports whether INSERT, UPDATE and DELETE accept a
+	// RETURNING clause.
+	SupportsReturning() bool
+}
+
+// statementTimeoutDialect is implemented by dialects that can bound the
+// statements of a transaction, used by WithTimeout.
+type statementTimeoutDialect interface {
+	// StatementTimeout returns the statement limiting the duration of the
+	// following statements of the current transaction to d.
+	StatementTimeout(d time.Duration) string
+}
+
+// explainDialect is implemented by dialects that can describe the plan of a
+// statement, used by the slow query hook.
+type explainDialect interface {
+	// Explain returns the statement returning the plan of query, without
+	// running it.
+	Explain(query string) string
+}
+
+// currentTimeDialect is implemented by dialects without the standard
+// CURRENT_TIMESTAMP, used for audit and soft delete timestamps.
+type currentTimeDialect interface {
+	// CurrentTime returns the expression of the current timestamp.
+	CurrentTime() string
+}
+
+// tableAliasDialect is implemented by dialects rejecting AS before table
+// aliases, used for joins, trees, CTEs and unions.
+type tableAliasDialect interface {
+	// TableAlias returns table, a table name or parenthesized query, aliased
+	// as alias.
+	TableAlias(table, alias string) string
+}
+
+// currentTime returns the expression of the current timestamp in d.
+func currentTime(d Dialect) squirrel.Sqlizer {
+	if c, ok := d.(currentTimeDialect); ok {
+		return squirrel.Expr(c.CurrentTime())
+	}
+	return squirrel.Expr("CURRENT_TIMESTAMP")
+}
+
+// tableAlias returns table aliased as alias in d.
+func tableAlias(d Dialect, table, alias string) string {
+	if a, ok := d.(tableAliasDialect); ok {
+		return a.TableAlias(table, alias)
+	}
+	return table + " AS " + alias
+}
+
+// Supported dialects.
+var (
+	// Postgres is the dialect of PostgreSQL: $1 parameters, "quoted"
+	// identifiers and LIMIT/OFFSET.
+	Postgres Dialect = postgresDialect{}
+
+	// MySQL is the dialect of MySQL and MariaDB: ? parameters, `quoted`
+	// identifiers and LIMIT/OFFSET. Mutations can't return rows.
+	MySQL Dialect = mysqlDialect{}
+)
+
+// SetDialect sets the dialect of the default registry.
+func SetDialect(d Dialect) {
+	defaultRegistry.SetDialect(d)
+}
+
+// SetDialect sets the dialect of the registry.
+func (r *Registry) SetDialect(d Dialect) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dialect = d
+}
+
+// Dialect returns the dialect of the registry, Postgres unless set otherwise.
+func (r *Registry) Dialect() Dialect {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.dialect == nil {
+		return Postgres
+	}
+	return r.dialect
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Placeholder() squirrel.PlaceholderFormat { return squirrel.Dollar }
+
+func (postgresDialect) BindVar(i int) string { return fmt.Sprintf("$%d", i) }
+
+func (postgresDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) LimitOffset(limit, offset *uint64) string {
+	return limitOffset(limit, offset)
+}
+
+func (postgresDialect) SupportsReturning() bool { return true }
+
+func (postgresDialect) Explain(query string) string { return "EXPLAIN " + query }
+
+func (postgresDialect) StatementTimeout(d time.Duration) string {
+	// statement_timeout is in milliseconds, and 0 disables it
+	ms := d.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return fmt.Sprintf("SET LOCAL statement_timeout = %d", ms)
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Placeholder() squirrel.PlaceholderFormat { return squirrel.Question }
+
+func (mysqlDialect) BindVar(int) string { return "?" }
+
+func (mysqlDialect) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) LimitOffset(limit, offset *uint64) string {
+	// MySQL has no OFFSET without LIMIT
+	if limit == nil && offset != nil {
+		all := uint64(math.MaxUint64)
+		limit = &all
+	}
+	return limitOffset(limit, offset)
+}
+
+func (mysqlDialect) SupportsReturning() bool { return false }
+
+func (mysqlDialect) Explain(query string) string { return "EXPLAIN " + query }
+
+// limitOffset returns the standard LIMIT/OFFSET clause.
+func limitOffset(limit, offset *uint64) string {
+	var parts []string
+	if limit != nil {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", *limit))
+	}
+	if offset != nil {
+		parts = append(parts, fmt.Sprintf("OFFSET %d", *offset))
+	}
+	return strings.Join(parts, " ")
+}
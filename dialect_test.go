@@ -0,0 +1,153 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useDialect sets the dialect of the default registry for the duration of
+// the test.
+func useDialect(t *testing.T, d Dialect) {
+	t.Helper()
+	SetDialect(d)
+	t.Cleanup(func() { SetDialect(nil) })
+}
+
+func TestDialect_LimitOffset(t *testing.T) {
+	ten, five := uint64(10), uint64(5)
+	tests := []struct {
+		name          string
+		limit, offset *uint64
+		postgres      string
+		mysql         string
+	}{
+		{name: "none"},
+		{name: "limit", limit: &ten, postgres: "LIMIT 10", mysql: "LIMIT 10"},
+		{name: "both", limit: &ten, offset: &five, postgres: "LIMIT 10 OFFSET 5", mysql: "LIMIT 10 OFFSET 5"},
+		{name: "offset", offset: &five, postgres: "OFFSET 5", mysql: "LIMIT 18446744073709551615 OFFSET 5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.postgres, Postgres.LimitOffset(tt.limit, tt.offset))
+			assert.Equal(t, tt.mysql, MySQL.LimitOffset(tt.limit, tt.offset))
+		})
+	}
+}
+
+func TestDialect_QuoteIdent(t *testing.T) {
+	assert.Equal(t, `"department.name"`, Postgres.QuoteIdent("department.name"))
+	assert.Equal(t, `"a""b"`, Postgres.QuoteIdent(`a"b`))
+	assert.Equal(t, "`department.name`", MySQL.QuoteIdent("department.name"))
+	assert.Equal(t, "`a``b`", MySQL.QuoteIdent("a`b"))
+}
+
+func TestBuildSelect_MySQL(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+	registry.SetDialect(MySQL)
+
+	offset := 20
+	query, err := buildSelect(employees, QueryRequest{
+		Select:  []string{"id", "department.name"},
+		Where:   map[string]interface{}{"department.name": "eng", "id": 3},
+		OrderBy: []OrderByClause{{Field: "id"}},
+		Offset:  &offset,
+	})
+	require.NoError(t, err)
+	sql, args, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT employees.id, department.name AS `department.name` FROM employees "+
+		"LEFT JOIN departments AS department ON department.id = employees.department_id "+
+		"WHERE department.name = ? AND employees.id = ? ORDER BY employees.id ASC LIMIT 18446744073709551615 OFFSET 20", sql)
+	assert.Equal(t, []interface{}{"eng", 3}, args)
+
+	summary, err := buildSummary(employees, QueryRequest{
+		Select:  []string{"id"},
+		Summary: []SummaryField{{Field: "id", Func: SummaryCount}},
+	})
+	require.NoError(t, err)
+	sql, _, err = summary.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(id) AS `id.count` FROM employees", sql)
+}
+
+func TestExecuteRaw_MySQL(t *testing.T) {
+	useDialect(t, MySQL)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, name, status, created_at FROM test_models WHERE id = \? OR parent_id = \? AND status = \?`).
+		WithArgs(1, 1, "active").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "created_at"}).AddRow(1, "Test Model", "active", now))
+
+	results, err := ExecuteRaw[QueryParams, TestQueryResult](context.Background(), db,
+		"SELECT id, name, status, created_at FROM test_models WHERE id = {{id}} OR parent_id = {{id}} AND status = {{status}}",
+		map[string]interface{}{"id": int64(1), "status": "active"})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMutations_MySQL(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	useDialect(t, MySQL)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO mutation_accounts \\(owner_name\\) VALUES \\(\\?\\)$").WithArgs("alice").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE mutation_accounts SET balance = \\? WHERE id = \\?$").WithArgs(5.0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	resp, err := Insert[MutationAccount](ctx, db, InsertRequest{
+		Values: map[string]interface{}{"owner": "alice"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+	assert.Empty(t, resp.Data)
+
+	_, err = Update[MutationAccount](ctx, db, UpdateRequest{
+		Set:   map[string]interface{}{"balance": 5.0},
+		Where: map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+
+	_, err = Insert[MutationAccount](ctx, db, InsertRequest{
+		Values:    map[string]interface{}{"owner": "alice"},
+		Returning: []string{"id"},
+	})
+	assert.ErrorContains(t, err, "returning is not supported by the mysql dialect")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_WithTimeoutMySQL(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+	useDialect(t, MySQL)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// No transaction and no statement timeout, only the context deadline
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+
+	_, err = Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select: []string{"id"},
+	}, WithTimeout(time.Second))
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
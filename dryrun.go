@@ -0,0 +1,84 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+)
+
+// Statement is a SQL statement with the values bound to its parameters.
+type Statement struct {
+	SQL  string        `json:"sql"`
+	Args []interface{} `json:"args"`
+}
+
+// DryRun holds the statements Execute would run for a request, as built by
+// BuildOnly and WithDryRun. The statements loading nested relations and
+// lookups depend on the rows returned and aren't included.
+type DryRun struct {
+	Query       Statement  `json:"query"`             // Query of the rows
+	Count       *Statement `json:"count,omitempty"`   // Count of the rows, for paginated requests
+	Summary     *Statement `json:"summary,omitempty"` // Aggregates of QueryRequest.Summary, if any
+	Fingerprint string     `json:"fingerprint"`       // FingerprintSQL of Query
+}
+
+// WithDryRun makes Execute, ExecuteTable and ExecuteTyped validate the
+// request and return the statements they would run in the DryRun of the
+// response, with no data, without reaching the database.
+func WithDryRun() ExecuteOption {
+	return func(c *executeConfig) { c.dryRun = true }
+}
+
+// BuildOnly validates req against model T and returns the statements
+// Execute would run for it, without reaching the database, e.g. to review
+// or to compare against golden files the queries built from the requests of
+// clients. Options other than UseVersion and WithMetadata are ignored.
+func BuildOnly[T Model](ctx context.Context, req QueryRequest, opts ...ExecuteOption) (*DryRun, error) {
+	var model T
+	cfg := newExecuteConfig(opts)
+	return buildDryRun(ctx, model, registryFromContext(ctx).callerVariant(ctx, model, cfg.version), req, cfg.metadata)
+}
+
+// buildDryRun builds the statements execute would run for req against the
+// variant of model.
+func buildDryRun(ctx context.Context, model Model, variant modelVariant, req QueryRequest, withMetadata bool) (*DryRun, error) {
+	metadata, req, err := registryFromContext(ctx).prepareQuery(ctx, model, variant, req)
+	if err != nil {
+		return nil, err
+	}
+	req = withPagination(req)
+	selectReq, _, _ := rowsRequest(metadata, req, withMetadata)
+
+	builder, err := buildSelect(metadata, selectReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sql: %w", err)
+	}
+	dryRun := &DryRun{Query: Statement{SQL: query, Args: args}, Fingerprint: FingerprintSQL(query)}
+
+	if req.Pagination != nil {
+		countBuilder, err := buildCount(metadata, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build count query: %w", err)
+		}
+		query, args, err := countBuilder.ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate count sql: %w", err)
+		}
+		dryRun.Count = &Statement{SQL: query, Args: args}
+	}
+	if len(req.Summary) > 0 {
+		summaryBuilder, err := buildSummary(metadata, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build summary query: %w", err)
+		}
+		query, args, err := summaryBuilder.ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate summary sql: %w", err)
+		}
+		dryRun.Summary = &Statement{SQL: query, Args: args}
+	}
+	return dryRun, nil
+}
@@ -0,0 +1,70 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildOnly(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	req := QueryRequest{
+		Select:     []string{"id", "name"},
+		Where:      map[string]interface{}{"age": 30},
+		Pagination: &PaginationRequest{Page: 2, PageSize: 5},
+		Summary:    []SummaryField{{Field: "age", Func: "avg"}},
+	}
+	dryRun, err := BuildOnly[BuilderTestModel](ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, Statement{SQL: "SELECT id, name FROM test_models WHERE age = $1 LIMIT 5 OFFSET 5", Args: []interface{}{30}}, dryRun.Query)
+	assert.Equal(t, &Statement{SQL: "SELECT COUNT(*) FROM test_models WHERE age = $1", Args: []interface{}{30}}, dryRun.Count)
+	assert.Equal(t, &Statement{SQL: `SELECT AVG(age) AS "age.avg" FROM test_models WHERE age = $1`, Args: []interface{}{30}}, dryRun.Summary)
+	assert.Equal(t, FingerprintSQL(dryRun.Query.SQL), dryRun.Fingerprint)
+
+	dryRun, err = BuildOnly[BuilderTestModel](ctx, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM test_models", dryRun.Query.SQL)
+	assert.Nil(t, dryRun.Count)
+	assert.Nil(t, dryRun.Summary)
+
+	_, err = BuildOnly[BuilderTestModel](ctx, QueryRequest{Select: []string{"nope"}})
+	assert.ErrorIs(t, err, ErrUnknownField)
+}
+
+func TestExecuteWithDryRun(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	// Nothing reaches the database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"email": "a@example.com"}}
+	resp, err := Execute[BuilderTestModel](ctx, db, req, WithDryRun())
+	require.NoError(t, err)
+	assert.Empty(t, resp.Data)
+	require.NotNil(t, resp.DryRun)
+	assert.Equal(t, "SELECT id FROM test_models WHERE email = $1", resp.DryRun.Query.SQL)
+	assert.Equal(t, []interface{}{"a@example.com"}, resp.DryRun.Query.Args)
+
+	encoded, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data": [], "dry_run": {
+		"query": {"sql": "SELECT id FROM test_models WHERE email = $1", "args": ["a@example.com"]},
+		"fingerprint": "`+resp.DryRun.Fingerprint+`"}}`, string(encoded))
+
+	typed, err := ExecuteTyped[BuilderTestModel](ctx, db, req, WithDryRun())
+	require.NoError(t, err)
+	assert.Empty(t, typed.Data)
+	assert.Equal(t, resp.DryRun, typed.DryRun)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
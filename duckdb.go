@@ -0,0 +1,51 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// DuckDB is the dialect of DuckDB, used through its database/sql driver for
+// analytics over local files: $1 parameters, "quoted" identifiers and
+// LIMIT/OFFSET. Registered models are mapped to Parquet files with
+// CreateParquetView.
+var DuckDB Dialect = duckdbDialect{}
+
+type duckdbDialect struct{}
+
+func (duckdbDialect) Name() string { return "duckdb" }
+
+func (duckdbDialect) Placeholder() squirrel.PlaceholderFormat { return squirrel.Dollar }
+
+func (duckdbDialect) BindVar(i int) string { return fmt.Sprintf("$%d", i) }
+
+func (duckdbDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (duckdbDialect) LimitOffset(limit, offset *uint64) string {
+	return limitOffset(limit, offset)
+}
+
+func (duckdbDialect) SupportsReturning() bool { return true }
+
+func (duckdbDialect) Explain(query string) string { return "EXPLAIN " + query }
+
+// CreateParquetView creates or replaces the DuckDB view name over the Parquet
+// files matching path, which may contain glob patterns such as
+// "events/*.parquet". Naming the view after the TableName of a registered
+// model lets Execute query the files like a table.
+func CreateParquetView(ctx context.Context, db Execer, name, path string) error {
+	if name == "" || path == "" {
+		return fmt.Errorf("parquet view requires a name and a path")
+	}
+	query := fmt.Sprintf("CREATE OR REPLACE VIEW %s AS SELECT * FROM read_parquet('%s')",
+		DuckDB.QuoteIdent(name), strings.ReplaceAll(path, "'", "''"))
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create parquet view %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"math/big"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateParquetView(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE OR REPLACE VIEW "test_models" AS SELECT \* FROM read_parquet\('data/o''brien/\*.parquet'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, CreateParquetView(context.Background(), db, "test_models", "data/o'brien/*.parquet"))
+	assert.Error(t, CreateParquetView(context.Background(), db, "test_models", ""))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_DuckDBSummary(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+	useDialect(t, DuckDB)
+
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(passthroughConverter{}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Integer sums come back as HUGEINT
+	mock.ExpectQuery(`SELECT SUM\(age\) AS "age.sum", AVG\(age\) AS "age.avg" FROM test_models WHERE name = \$1`).
+		WithArgs("alice").
+		WillReturnRows(mock.NewRows([]string{"age.sum", "age.avg"}).AddRow(big.NewInt(60), 30.0))
+	mock.ExpectQuery(`SELECT age FROM test_models WHERE name = \$1 LIMIT 1 OFFSET 1`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"age"}).AddRow(int64(30)))
+
+	limit, offset := 1, 1
+	resp, err := Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select:  []string{"age"},
+		Where:   map[string]interface{}{"name": "alice"},
+		Limit:   &limit,
+		Offset:  &offset,
+		Summary: []SummaryField{{Field: "age", Func: SummarySum}, {Field: "age", Func: SummaryAvg}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"sum": int64(60), "avg": 30.0}, resp.Summary["age"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// passthroughConverter lets mocked rows hold driver-specific values such as
+// the *big.Int of DuckDB HUGEINT columns.
+type passthroughConverter struct{}
+
+func (passthroughConverter) ConvertValue(v interface{}) (driver.Value, error) { return v, nil }
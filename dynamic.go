@@ -0,0 +1,122 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RegisterFromTable registers a model built at runtime from the columns of
+// table, as reported by the catalog of db, for deployments where a Go struct
+// for every table is impractical. Each column is exposed under its name with
+// a Go type following its SQL type; nullable columns get pointer types. Opts
+// restrict the fields like for Register. Query the table with ExecuteTable.
+//
+// The registry is the one of ctx, see WithRegistry.
+func RegisterFromTable(ctx context.Context, db interface{}, table string, opts ...RegisterOption) error {
+	return registryFromContext(ctx).RegisterFromTable(ctx, db, table, opts...)
+}
+
+// ExecuteTable runs the query against the model registered for table with
+// RegisterFromTable, like Execute does for models with a Go type.
+func ExecuteTable(ctx context.Context, db interface{}, table string, req QueryRequest, opts ...ExecuteOption) (QueryResponse[Model], error) {
+	model, ok := registryFromContext(ctx).TableModel(table)
+	if !ok {
+		return QueryResponse[Model]{}, fmt.Errorf("failed to get model metadata: %w", &ModelNotRegisteredError{Table: table})
+	}
+	return executeModel(ctx, db, model, req, opts)
+}
+
+// RegisterFromTable registers a model built from the columns of table, see
+// the package-level RegisterFromTable. Registering a table again replaces it.
+func (r *Registry) RegisterFromTable(ctx context.Context, db interface{}, table string, opts ...RegisterOption) error {
+	dialect := r.Dialect()
+	columns, err := queryCatalogColumns(ctx, db, dialect, catalogTableParts(dialect, table))
+	if err != nil {
+		return fmt.Errorf("failed to read the columns of %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("table %s does not exist", table)
+	}
+
+	model := reflect.New(tableModelType(dialect, table, columns)).Elem().Interface()
+	opts = append([]RegisterOption{func(c *registerConfig) { c.tableName = table }}, opts...)
+	metadata, err := r.newModelMetadata(model, opts)
+	if err != nil {
+		return fmt.Errorf("failed to register table %s: %w", table, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous, ok := r.tables[table]; ok {
+		delete(r.models, reflect.TypeOf(previous))
+	}
+	r.models[reflect.TypeOf(model)] = metadata
+	r.tables[table] = model
+	return nil
+}
+
+// TableModel returns the model registered for table with RegisterFromTable,
+// e.g. to Unregister it.
+func (r *Registry) TableModel(table string) (Model, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	model, ok := r.tables[table]
+	return model, ok
+}
+
+// tableModelType returns the struct type of the model of table with the given
+// columns. A leading empty field tagged with the table keeps the types of
+// tables with the same columns apart. Columns that can't be written unquoted
+// are left out.
+func tableModelType(dialect Dialect, table string, columns []catalogColumn) reflect.Type {
+	_, folds := dialect.(identFoldingDialect)
+	fields := []reflect.StructField{{
+		Name: "Table",
+		Type: reflect.TypeOf(struct{}{}),
+		Tag:  reflect.StructTag(fmt.Sprintf("table:%q", table)),
+	}}
+	for i, column := range columns {
+		if !identRegex.MatchString(column.ColumnName) || strings.Contains(column.ColumnName, ".") {
+			continue
+		}
+		jsonName := column.ColumnName
+		if folds {
+			jsonName = strings.ToLower(jsonName)
+		}
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("Column%d", i),
+			Type: catalogGoType(column),
+			Tag:  reflect.StructTag(fmt.Sprintf("json:%q db:%q", jsonName, column.ColumnName)),
+		})
+	}
+	return reflect.StructOf(fields)
+}
+
+// catalogGoType returns the Go type of the field backed by column.
+func catalogGoType(column catalogColumn) reflect.Type {
+	var t reflect.Type
+	switch sqlTypeFamily(column.DataType) {
+	case "number":
+		dataType := strings.ToLower(column.DataType)
+		if strings.Contains(dataType, "int") || strings.Contains(dataType, "serial") {
+			t = reflect.TypeOf(int64(0))
+		} else {
+			t = reflect.TypeOf(float64(0))
+		}
+	case "bool":
+		t = reflect.TypeOf(false)
+	case "time":
+		t = reflect.TypeOf(time.Time{})
+	case "text":
+		t = reflect.TypeOf("")
+	default:
+		return reflect.TypeOf((*interface{})(nil)).Elem()
+	}
+	if column.nullable() {
+		return reflect.PointerTo(t)
+	}
+	return t
+}
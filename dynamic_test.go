@@ -0,0 +1,88 @@
+package sqld
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectEmployeeColumns mocks the catalog of an employees table.
+func expectEmployeeColumns(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_name = \$1`).
+		WithArgs("employees").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "is_nullable"}).
+			AddRow("id", "bigint", "NO").
+			AddRow("first_name", "character varying", "NO").
+			AddRow("salary", "numeric", "YES").
+			AddRow("hired_at", "timestamp with time zone", "YES").
+			AddRow("is_active", "boolean", "NO").
+			AddRow("tags", "ARRAY", "YES").
+			AddRow("Odd Name", "text", "YES"))
+}
+
+func TestRegisterFromTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	registry := NewRegistry()
+	ctx := WithRegistry(context.Background(), registry)
+	expectEmployeeColumns(mock)
+	require.NoError(t, RegisterFromTable(ctx, db, "employees", WithoutFields("salary")))
+
+	model, ok := registry.TableModel("employees")
+	require.True(t, ok)
+	metadata, err := registry.GetModelMetadata(model)
+	require.NoError(t, err)
+	assert.Equal(t, "employees", metadata.TableName)
+	assert.Len(t, metadata.Fields, 5)
+	assert.Equal(t, reflect.TypeOf(int64(0)), metadata.Fields["id"].Type)
+	assert.Equal(t, reflect.TypeOf(""), metadata.Fields["first_name"].Type)
+	assert.Equal(t, reflect.TypeOf((*time.Time)(nil)), metadata.Fields["hired_at"].Type)
+	assert.Equal(t, reflect.TypeOf(false), metadata.Fields["is_active"].Type)
+	assert.Equal(t, reflect.TypeOf((*interface{})(nil)).Elem(), metadata.Fields["tags"].Type)
+
+	mock.ExpectQuery(`SELECT id, first_name FROM employees WHERE is_active = \$1`).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name"}).AddRow(1, "Ada"))
+	resp, err := ExecuteTable(ctx, db, "employees", QueryRequest{
+		Select: []string{"id", "first_name"},
+		Where:  map[string]interface{}{"is_active": true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "first_name": "Ada"}}, resp.Data)
+
+	_, err = ExecuteTable(ctx, db, "employees", QueryRequest{Select: []string{"salary"}})
+	assert.ErrorContains(t, err, "invalid field in select: salary")
+
+	require.NoError(t, registry.Unregister(model))
+	_, ok = registry.TableModel("employees")
+	assert.False(t, ok)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterFromTable_Errors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := WithRegistry(context.Background(), NewRegistry())
+	mock.ExpectQuery(`FROM information_schema.columns`).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "is_nullable"}))
+	assert.EqualError(t, RegisterFromTable(ctx, db, "missing"), "table missing does not exist")
+
+	_, err = ExecuteTable(ctx, db, "missing", QueryRequest{Select: []string{"id"}})
+	assert.ErrorContains(t, err, "table missing not registered")
+}
+
+func TestTableModelType_Distinct(t *testing.T) {
+	columns := []catalogColumn{{ColumnName: "id", DataType: "integer", IsNullable: "NO"}}
+	assert.NotEqual(t, tableModelType(Postgres, "a", columns), tableModelType(Postgres, "b", columns))
+	assert.Equal(t, tableModelType(Postgres, "a", columns), tableModelType(Postgres, "a", columns))
+}
@@ -0,0 +1,91 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RegisterEnum registers the allowed values of a named enum in the default registry.
+// Model fields opt into an enum with the enum struct tag, for example
+//
+//	HoldingNature int64 `json:"holding_nature" db:"holding_nature" enum:"UCC_HOLDING_TYPE"`
+//
+// Where conditions on such fields are then rejected unless the value is one of
+// the registered values. The registrations are usually generated from reference
+// tables with GenerateEnums so that code and database stay in sync.
+func RegisterEnum(name string, values ...interface{}) {
+	defaultRegistry.RegisterEnum(name, values...)
+}
+
+// RegisterEnum registers the allowed values of a named enum.
+// Registering the same name again replaces the previous values.
+func (r *Registry) RegisterEnum(name string, values ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enums[name] = append([]interface{}(nil), values...)
+}
+
+// GetEnum returns the values registered for the named enum.
+func (r *Registry) GetEnum(name string) ([]interface{}, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	values, ok := r.enums[name]
+	return values, ok
+}
+
+// validateEnumValues checks that every Where value on an enum field is one of
+// the values registered for that enum. It returns a ValidationErrors listing
+// every value rejected.
+func (r *Registry) validateEnumValues(metadata ModelMetadata, where map[string]interface{}) error {
+	var errs ValidationErrors
+	for _, jsonName := range sortedKeys(where) {
+		field, ok := metadata.Fields[jsonName]
+		if !ok || field.Enum == "" {
+			continue
+		}
+		allowed, ok := r.GetEnum(field.Enum)
+		if !ok {
+			errs = errs.add(fmt.Errorf("enum %s for field %s is not registered", field.Enum, jsonName))
+			continue
+		}
+		for _, value := range enumCandidates(where[jsonName]) {
+			if !enumContains(allowed, value) {
+				errs = errs.add(fmt.Errorf("invalid value for field %s: %v is not a member of enum %s", jsonName, value, field.Enum))
+			}
+		}
+	}
+	return errs.err()
+}
+
+// enumCandidates returns the values of value to check against an enum: the
+// elements of slices and arrays, which whereEq turns into IN conditions, or
+// value itself. Nil values, which match NULL, are left out.
+func enumCandidates(value interface{}) []interface{} {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() == reflect.Uint8 {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if elem := v.Index(i).Interface(); elem != nil {
+			values = append(values, elem)
+		}
+	}
+	return values
+}
+
+// enumContains reports whether value is one of allowed.
+// Values are compared by their formatted representation because JSON decoding
+// yields float64 for numbers while enums are typically registered as int64.
+func enumContains(allowed []interface{}, value interface{}) bool {
+	want := fmt.Sprint(value)
+	for _, a := range allowed {
+		if fmt.Sprint(a) == want {
+			return true
+		}
+	}
+	return false
+}
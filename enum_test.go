@@ -0,0 +1,142 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type EnumTestModel struct {
+	ID            int64 `json:"id" db:"id"`
+	HoldingNature int64 `json:"holding_nature" db:"holding_nature" enum:"UCC_HOLDING_TYPE"`
+}
+
+func (EnumTestModel) TableName() string {
+	return "ucc"
+}
+
+func TestRegistry_ValidateEnumValues(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(EnumTestModel{}))
+	metadata, err := registry.GetModelMetadata(EnumTestModel{})
+	require.NoError(t, err)
+	assert.Equal(t, "UCC_HOLDING_TYPE", metadata.Fields["holding_nature"].Enum)
+
+	// Enum referenced by the model but not registered yet
+	err = registry.validateEnumValues(metadata, map[string]interface{}{"holding_nature": 2})
+	assert.Error(t, err)
+
+	registry.RegisterEnum("UCC_HOLDING_TYPE", int64(2), int64(4))
+
+	tests := []struct {
+		name    string
+		where   map[string]interface{}
+		wantErr bool
+	}{
+		{name: "member", where: map[string]interface{}{"holding_nature": int64(2)}},
+		{name: "member decoded from JSON", where: map[string]interface{}{"holding_nature": float64(4)}},
+		{name: "non enum field", where: map[string]interface{}{"id": 99}},
+		{name: "not a member", where: map[string]interface{}{"holding_nature": 3}, wantErr: true},
+		{name: "IN list of members", where: map[string]interface{}{"holding_nature": []int{2, 4}}},
+		{name: "IN list with a non member", where: map[string]interface{}{"holding_nature": []int64{2, 3}}, wantErr: true},
+		{name: "array of members", where: map[string]interface{}{"holding_nature": [2]int{4, 2}}},
+		{name: "IS NULL", where: map[string]interface{}{"holding_nature": nil}},
+		{name: "IN list with NULL", where: map[string]interface{}{"holding_nature": []interface{}{nil, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.validateEnumValues(metadata, tt.where)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateEnumValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadEnumGroups(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"enum_group", "enum_key", "enum_value"}).
+		AddRow("UCC_ACC_STATUS", "ACTIVE", int64(3)).
+		AddRow("UCC_HOLDING_TYPE", "INDIVIDUAL", int64(2)).
+		AddRow("UCC_HOLDING_TYPE", "JOINT", int64(4))
+	mock.ExpectQuery("SELECT entity AS enum_group, entity_key AS enum_key, id AS enum_value FROM common_reference_master ORDER BY entity, id").
+		WillReturnRows(rows)
+
+	groups, err := LoadEnumGroups(context.Background(), db, EnumTable{
+		Table:       "common_reference_master",
+		GroupColumn: "entity",
+		KeyColumn:   "entity_key",
+		ValueColumn: "id",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []EnumGroup{
+		{Name: "UCC_ACC_STATUS", Values: []EnumValue{{Key: "ACTIVE", Value: int64(3)}}},
+		{Name: "UCC_HOLDING_TYPE", Values: []EnumValue{{Key: "INDIVIDUAL", Value: int64(2)}, {Key: "JOINT", Value: int64(4)}}},
+	}, groups)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = LoadEnumGroups(context.Background(), db, EnumTable{Table: "x; DROP TABLE y", GroupColumn: "a", KeyColumn: "b", ValueColumn: "c"})
+	assert.Error(t, err)
+}
+
+func TestGenerateEnums(t *testing.T) {
+	var buf bytes.Buffer
+	err := GenerateEnums(&buf, "refdata", []EnumGroup{
+		{Name: "UCC_HOLDING_TYPE", Values: []EnumValue{{Key: "INDIVIDUAL", Value: int64(2)}, {Key: "JOINT", Value: int64(4)}}},
+		{Name: "currency", Values: []EnumValue{{Key: "usd", Value: "USD"}}},
+	})
+	require.NoError(t, err)
+
+	src := buf.String()
+	assert.Contains(t, src, "package refdata")
+	assert.Contains(t, src, "type UccHoldingType int64")
+	assert.Contains(t, src, "UccHoldingTypeIndividual UccHoldingType = 2")
+	assert.Contains(t, src, "UccHoldingTypeJoint      UccHoldingType = 4")
+	assert.Contains(t, src, "type Currency string")
+	assert.Contains(t, src, `CurrencyUsd Currency = "USD"`)
+	assert.Contains(t, src, `sqld.RegisterEnum("UCC_HOLDING_TYPE", valuesUccHoldingType...)`)
+
+	err = GenerateEnums(&buf, "refdata", []EnumGroup{{Name: "EMPTY"}})
+	assert.Error(t, err)
+
+	// Names that map to the same Go identifier would not compile
+	err = GenerateEnums(&buf, "refdata", []EnumGroup{{Name: "status", Values: []EnumValue{
+		{Key: "in-progress", Value: "P"}, {Key: "in_progress", Value: "Q"},
+	}}})
+	assert.ErrorContains(t, err, `member "in-progress" of enum "status" and member "in_progress" of enum "status" both map to the Go name StatusInProgress`)
+	err = GenerateEnums(&buf, "refdata", []EnumGroup{{Name: "status", Values: []EnumValue{{Key: "---", Value: "P"}}}})
+	assert.ErrorContains(t, err, `cannot derive a Go name for member "---"`)
+	err = GenerateEnums(&buf, "refdata", []EnumGroup{{Name: "status", Values: []EnumValue{{Key: "values", Value: "V"}}}})
+	assert.ErrorContains(t, err, `the values of enum "status" and member "values" of enum "status" both map to the Go name StatusValues`)
+	err = GenerateEnums(&buf, "refdata", []EnumGroup{
+		{Name: "order", Values: []EnumValue{{Key: "status", Value: "S"}}},
+		{Name: "order_status", Values: []EnumValue{{Key: "open", Value: "O"}}},
+	})
+	assert.ErrorContains(t, err, `member "status" of enum "order" and enum "order_status" both map to the Go name OrderStatus`)
+	err = GenerateEnums(&buf, "refdata", []EnumGroup{{Name: "register_enums", Values: []EnumValue{{Key: "a", Value: "A"}}}})
+	assert.ErrorContains(t, err, "the RegisterEnums function")
+	err = GenerateEnums(&buf, "refdata", []EnumGroup{
+		{Name: "order-status", Values: []EnumValue{{Key: "open", Value: "O"}}},
+		{Name: "ORDER_STATUS", Values: []EnumValue{{Key: "open", Value: "O"}}},
+	})
+	assert.ErrorContains(t, err, `enum "order-status" and enum "ORDER_STATUS" both map to the Go name OrderStatus`)
+}
+
+func TestGoIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"UCC_HOLDING_TYPE": "UccHoldingType",
+		"first rank":       "FirstRank",
+		"2FA":              "N2fa",
+		"---":              "",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, goIdentifier(in), in)
+	}
+}
@@ -0,0 +1,228 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// EnumTable describes a reference table holding enum values, such as
+//
+//	common_reference_master(id, entity, entity_key)
+//
+// where entity groups the rows into enums, entity_key names each member and
+// id is the value stored in referencing columns.
+type EnumTable struct {
+	Table       string // Reference table name
+	GroupColumn string // Column naming the enum a row belongs to (e.g. entity)
+	KeyColumn   string // Column naming the enum member (e.g. entity_key)
+	ValueColumn string // Column holding the stored value (e.g. id)
+}
+
+// EnumGroup is a named enum read from a reference table.
+type EnumGroup struct {
+	Name   string
+	Values []EnumValue
+}
+
+// EnumValue is a single enum member.
+type EnumValue struct {
+	Key   string
+	Value interface{}
+}
+
+// identRegex matches identifiers that are safe to interpolate into SQL.
+var identRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// LoadEnumGroups reads all enums from the reference table described by src.
+// Groups and their values are returned in a stable order (by group, then value).
+func LoadEnumGroups(ctx context.Context, db interface{}, src EnumTable) ([]EnumGroup, error) {
+	for _, ident := range []string{src.Table, src.GroupColumn, src.KeyColumn, src.ValueColumn} {
+		if !identRegex.MatchString(ident) {
+			return nil, fmt.Errorf("invalid identifier in enum table: %q", ident)
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s AS enum_group, %s AS enum_key, %s AS enum_value FROM %s ORDER BY %s, %s",
+		src.GroupColumn, src.KeyColumn, src.ValueColumn, src.Table, src.GroupColumn, src.ValueColumn)
+
+	var rows []struct {
+		Group string      `db:"enum_group"`
+		Key   string      `db:"enum_key"`
+		Value interface{} `db:"enum_value"`
+	}
+	if err := selectAll(ctx, db, &rows, query); err != nil {
+		return nil, fmt.Errorf("failed to load enum values: %w", err)
+	}
+
+	var groups []EnumGroup
+	for _, row := range rows {
+		if len(groups) == 0 || groups[len(groups)-1].Name != row.Group {
+			groups = append(groups, EnumGroup{Name: row.Group})
+		}
+		last := &groups[len(groups)-1]
+		last.Values = append(last.Values, EnumValue{Key: row.Key, Value: row.Value})
+	}
+	return groups, nil
+}
+
+// GenerateEnums writes Go source for package pkg declaring, for every group,
+// a named type, one constant per member, a Values slice and a RegisterEnums
+// function that registers all groups with sqld under their group name.
+// The output is gofmt-formatted.
+func GenerateEnums(w io.Writer, pkg string, groups []EnumGroup) error {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// Code generated by sqld enums. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import \"github.com/remiges-sachin/sqld\"\n\n")
+
+	if err := checkEnumIdents(groups); err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		typeName := goIdentifier(group.Name)
+		goType, err := enumGoType(group.Values[0].Value)
+		if err != nil {
+			return fmt.Errorf("enum %s: %w", group.Name, err)
+		}
+
+		fmt.Fprintf(&buf, "// %s values come from the %s reference group.\n", typeName, group.Name)
+		fmt.Fprintf(&buf, "type %s %s\n\n", typeName, goType)
+		fmt.Fprintf(&buf, "const (\n")
+		for _, v := range group.Values {
+			literal, err := enumLiteral(v.Value, goType)
+			if err != nil {
+				return fmt.Errorf("enum %s member %s: %w", group.Name, v.Key, err)
+			}
+			fmt.Fprintf(&buf, "%s%s %s = %s\n", typeName, goIdentifier(v.Key), typeName, literal)
+		}
+		fmt.Fprintf(&buf, ")\n\n")
+
+		fmt.Fprintf(&buf, "// %sValues lists every %s member.\n", typeName, typeName)
+		fmt.Fprintf(&buf, "var %sValues = []%s{\n", typeName, typeName)
+		for _, v := range group.Values {
+			fmt.Fprintf(&buf, "%s%s,\n", typeName, goIdentifier(v.Key))
+		}
+		fmt.Fprintf(&buf, "}\n\n")
+	}
+
+	fmt.Fprintf(&buf, "// RegisterEnums registers every generated enum with sqld.\n")
+	fmt.Fprintf(&buf, "func RegisterEnums() {\n")
+	for _, group := range groups {
+		typeName := goIdentifier(group.Name)
+		goType, _ := enumGoType(group.Values[0].Value)
+		fmt.Fprintf(&buf, "values%s := make([]interface{}, len(%sValues))\n", typeName, typeName)
+		fmt.Fprintf(&buf, "for i, v := range %sValues {\nvalues%s[i] = %s(v)\n}\n", typeName, typeName, goType)
+		fmt.Fprintf(&buf, "sqld.RegisterEnum(%q, values%s...)\n", group.Name, typeName)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated enums: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// enumGoType returns the underlying Go type used for an enum value.
+func enumGoType(v interface{}) (string, error) {
+	switch v.(type) {
+	case int, int8, int16, int32, int64:
+		return "int64", nil
+	case string, []byte:
+		return "string", nil
+	default:
+		return "", fmt.Errorf("unsupported enum value type %T", v)
+	}
+}
+
+// enumLiteral renders v as a Go literal of type goType.
+func enumLiteral(v interface{}, goType string) (string, error) {
+	switch val := v.(type) {
+	case []byte:
+		v = string(val)
+	}
+	switch goType {
+	case "int64":
+		switch v.(type) {
+		case int, int8, int16, int32, int64:
+			return fmt.Sprint(v), nil
+		}
+	case "string":
+		if s, ok := v.(string); ok {
+			return fmt.Sprintf("%q", s), nil
+		}
+	}
+	return "", fmt.Errorf("value %v (%T) does not match enum type %s", v, v, goType)
+}
+
+// checkEnumIdents checks that the groups can be declared in one file: every
+// identifier GenerateEnums emits, the types, member constants, Values slices
+// and RegisterEnums, must be derivable and distinct.
+func checkEnumIdents(groups []EnumGroup) error {
+	idents := map[string]string{"RegisterEnums": "the RegisterEnums function"}
+	declare := func(ident, what string) error {
+		if other, ok := idents[ident]; ok {
+			return fmt.Errorf("%s and %s both map to the Go name %s", other, what, ident)
+		}
+		idents[ident] = what
+		return nil
+	}
+
+	for _, group := range groups {
+		typeName := goIdentifier(group.Name)
+		if typeName == "" {
+			return fmt.Errorf("cannot derive a Go name for enum %q", group.Name)
+		}
+		if len(group.Values) == 0 {
+			return fmt.Errorf("enum %s has no values", group.Name)
+		}
+		if err := declare(typeName, fmt.Sprintf("enum %q", group.Name)); err != nil {
+			return err
+		}
+		if err := declare(typeName+"Values", fmt.Sprintf("the values of enum %q", group.Name)); err != nil {
+			return err
+		}
+		for _, v := range group.Values {
+			name := goIdentifier(v.Key)
+			if name == "" {
+				return fmt.Errorf("enum %s: cannot derive a Go name for member %q", group.Name, v.Key)
+			}
+			if err := declare(typeName+name, fmt.Sprintf("member %q of enum %q", v.Key, group.Name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// goIdentifier converts names like UCC_HOLDING_TYPE or "first rank" into
+// exported Go identifiers such as UccHoldingType and FirstRank.
+func goIdentifier(s string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteRune('N')
+		}
+		if upperNext {
+			b.WriteRune(unicode.ToUpper(r))
+			upperNext = false
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package sqld
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of errors returned for requests that can't be run as given, matched
+// with errors.Is. The errors carrying them are typed, e.g. *UnknownFieldError,
+// so that errors.As gives the offending field or value, and API layers can
+// tell client mistakes (400) from failures (500).
+var (
+	ErrUnknownField       = errors.New("unknown field")
+	ErrInvalidOperator    = errors.New("invalid operator")
+	ErrTypeMismatch       = errors.New("type mismatch")
+	ErrModelNotRegistered = errors.New("model not registered")
+)
+
+// UnknownFieldError is returned for a field name that the model doesn't
+// have, or doesn't expose.
+type UnknownFieldError struct {
+	Field string // Name as given, e.g. "email" or "department.name"
+	In    string // Part of the request or registration naming it, e.g. "select", if known
+
+	// Suggestions are the fields of the model Field may be a typo of, closest
+	// first, e.g. first_name for frist_name.
+	Suggestions []string
+}
+
+func (e *UnknownFieldError) Error() string {
+	msg := fmt.Sprintf("invalid field: %s", e.Field)
+	if e.In != "" {
+		msg = fmt.Sprintf("invalid field in %s: %s", e.In, e.Field)
+	}
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(e.Suggestions, " or "))
+	}
+	return msg
+}
+
+func (e *UnknownFieldError) Is(target error) bool {
+	return target == ErrUnknownField
+}
+
+// OperatorError is returned for an operation that isn't supported, such as a
+// summary function or a pivot aggregate.
+type OperatorError struct {
+	Operator string // Operator as given
+	In       string // Kind of operator, e.g. "summary function"
+}
+
+func (e *OperatorError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.In, e.Operator)
+}
+
+func (e *OperatorError) Is(target error) bool {
+	return target == ErrInvalidOperator
+}
+
+// TypeMismatchError is returned for a value that doesn't fit the type of the
+// field or raw query parameter it is given for.
+type TypeMismatchError struct {
+	Field     string      // JSON name of the field, or name of the parameter
+	Parameter bool        // Field is a parameter of ExecuteRaw
+	Value     interface{} // Value as given
+	Err       error       // What is wrong with the value
+}
+
+func (e *TypeMismatchError) Error() string {
+	if e.Parameter {
+		return fmt.Sprintf("parameter %s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("invalid value for field %s: %v", e.Field, e.Err)
+}
+
+func (e *TypeMismatchError) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+func (e *TypeMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// ModelNotRegisteredError is returned for a model, a variant of a model or a
+// table that isn't registered.
+type ModelNotRegisteredError struct {
+	Model   string // Name of the model type, empty for a table
+	Variant string // Version or role projection of the model, if any
+	Table   string // Table looked up by name, see ExecuteTable
+}
+
+func (e *ModelNotRegisteredError) Error() string {
+	switch {
+	case e.Model == "":
+		return fmt.Sprintf("table %s not registered", e.Table)
+	case e.Variant != "":
+		return fmt.Sprintf("%s of model %s not registered", e.Variant, e.Model)
+	}
+	return fmt.Sprintf("model %s not registered", e.Model)
+}
+
+func (e *ModelNotRegisteredError) Is(target error) bool {
+	return target == ErrModelNotRegistered
+}
+
+// ValidationErrors lists every problem found in a request, such as unknown
+// fields, unsupported operators and mistyped values, so that clients can fix
+// them all at once. errors.Is and errors.As look into each of them.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// MarshalJSON encodes e as a list of FieldError, with the field of unknown
+// fields and mistyped values, for API responses.
+func (e ValidationErrors) MarshalJSON() ([]byte, error) {
+	list := make([]FieldError, len(e))
+	for i, err := range e {
+		list[i].Message = err.Error()
+		var unknown *UnknownFieldError
+		var mismatch *TypeMismatchError
+		switch {
+		case errors.As(err, &unknown):
+			list[i].Field = unknown.Field
+		case errors.As(err, &mismatch):
+			list[i].Field = mismatch.Field
+		}
+	}
+	return json.Marshal(list)
+}
+
+// add appends err to e, or the errors it lists if it is a ValidationErrors.
+// A nil err is skipped.
+func (e ValidationErrors) add(err error) ValidationErrors {
+	if err == nil {
+		return e
+	}
+	if list, ok := err.(ValidationErrors); ok {
+		return append(e, list...)
+	}
+	return append(e, err)
+}
+
+// err returns e, or nil when it lists no error.
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,88 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedErrors(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	require.NoError(t, registry.Register(MutationAccount{}))
+	ctx := WithRegistry(context.Background(), registry)
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id", "emial"}})
+	var unknown *UnknownFieldError
+	require.ErrorAs(t, err, &unknown)
+	assert.Equal(t, UnknownFieldError{Field: "emial", In: "select", Suggestions: []string{"email"}}, *unknown)
+	assert.ErrorIs(t, err, ErrUnknownField)
+	assert.EqualError(t, err, "failed to validate query: invalid field in select: emial (did you mean email?)")
+
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}, Summary: []SummaryField{{Field: "age", Func: "median"}}})
+	var operator *OperatorError
+	require.ErrorAs(t, err, &operator)
+	assert.Equal(t, "median", operator.Operator)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = Insert[MutationAccount](ctx, db, InsertRequest{Values: map[string]interface{}{"owner": "alice", "balance": "ten"}})
+	var mismatch *TypeMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "balance", mismatch.Field)
+	assert.Equal(t, "ten", mismatch.Value)
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+	assert.False(t, errors.Is(err, ErrUnknownField))
+
+	_, err = Execute[TypedOrder](ctx, db, QueryRequest{Select: []string{"id"}})
+	var notRegistered *ModelNotRegisteredError
+	require.ErrorAs(t, err, &notRegistered)
+	assert.Equal(t, "TypedOrder", notRegistered.Model)
+	assert.ErrorIs(t, err, ErrModelNotRegistered)
+
+	_, err = ExecuteTable(ctx, db, "missing", QueryRequest{Select: []string{"id"}})
+	assert.ErrorIs(t, err, ErrModelNotRegistered)
+	assert.EqualError(t, err, "failed to get model metadata: table missing not registered")
+}
+
+func TestValidationErrors(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	limit := -1
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{
+		Select:  []string{"id", "frist_name", "emial"},
+		Where:   map[string]interface{}{"nmae": "x"},
+		Summary: []SummaryField{{Field: "age", Func: "median"}},
+		Limit:   &limit,
+	})
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 5)
+	assert.EqualError(t, err, "failed to validate query: invalid field in select: frist_name; "+
+		"invalid field in select: emial (did you mean email?); invalid field in where clause: nmae (did you mean name?); "+
+		"limit must be non-negative; invalid summary function: median")
+	assert.ErrorIs(t, err, ErrUnknownField)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	encoded, err := json.Marshal(errs)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"field": "frist_name", "message": "invalid field in select: frist_name"},
+		{"field": "emial", "message": "invalid field in select: emial (did you mean email?)"},
+		{"field": "nmae", "message": "invalid field in where clause: nmae (did you mean name?)"},
+		{"field": "", "message": "limit must be non-negative"},
+		{"field": "", "message": "invalid summary function: median"}
+	]`, string(encoded))
+}
@@ -0,0 +1,50 @@
+package sqld
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type execModeKey struct{}
+
+// SetQueryExecMode sets the pgx query exec mode of the default registry,
+// used by every query run on a pgx handle. pgx.QueryExecModeSimpleProtocol
+// is needed behind PgBouncer in transaction pooling mode, which breaks the
+// prepared statements of the default extended protocol. database/sql
+// handles are configured through their connection string instead, e.g.
+// default_query_exec_mode=simple_protocol with the pgx stdlib driver.
+func SetQueryExecMode(mode pgx.QueryExecMode) {
+	defaultRegistry.SetQueryExecMode(mode)
+}
+
+// SetQueryExecMode sets the pgx query exec mode of the registry.
+func (r *Registry) SetQueryExecMode(mode pgx.QueryExecMode) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.execMode = &mode
+}
+
+// queryExecMode returns the pgx query exec mode of the registry, if set.
+func (r *Registry) queryExecMode() (pgx.QueryExecMode, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.execMode == nil {
+		return 0, false
+	}
+	return *r.execMode, true
+}
+
+// pgxArgs returns the arguments of a query run on a pgx handle, prefixed
+// with the query exec mode of the call or of the default registry. pgx
+// takes a QueryExecMode given as first argument as an option.
+func pgxArgs(ctx context.Context, args []interface{}) []interface{} {
+	mode, ok := ctx.Value(execModeKey{}).(pgx.QueryExecMode)
+	if !ok {
+		mode, ok = registryFromContext(ctx).queryExecMode()
+	}
+	if !ok {
+		return args
+	}
+	return append([]interface{}{mode}, args...)
+}
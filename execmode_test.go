@@ -0,0 +1,63 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPgx records the arguments of the queries run on it.
+type recordingPgx struct {
+	args [][]interface{}
+}
+
+func (r *recordingPgx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	r.args = append(r.args, args)
+	return nil, errors.New("not connected")
+}
+
+func (r *recordingPgx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	r.args = append(r.args, args)
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func TestQueryExecMode(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	t.Cleanup(func() {
+		defaultRegistry.mu.Lock()
+		defaultRegistry.execMode = nil
+		defaultRegistry.mu.Unlock()
+	})
+
+	ctx := context.Background()
+	req := QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"id": 1}}
+	db := &recordingPgx{}
+
+	// The default passes the arguments as-is
+	_, err := Execute[MutationAccount](ctx, db, req)
+	assert.Error(t, err)
+	assert.Equal(t, []interface{}{1}, db.args[0])
+
+	// Per call
+	_, err = Execute[MutationAccount](ctx, db, req, WithQueryExecMode(pgx.QueryExecModeExec))
+	assert.Error(t, err)
+	assert.Equal(t, []interface{}{pgx.QueryExecModeExec, 1}, db.args[1])
+
+	// Globally, including mutations
+	SetQueryExecMode(pgx.QueryExecModeSimpleProtocol)
+	_, err = InsertMany[MutationAccount](ctx, db, InsertManyRequest{
+		Rows: []map[string]interface{}{{"owner": "alice"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{pgx.QueryExecModeSimpleProtocol, "alice"}, db.args[2])
+
+	// The call overrides the global mode
+	_, err = Execute[MutationAccount](ctx, db, req, WithQueryExecMode(pgx.QueryExecModeCacheDescribe))
+	assert.Error(t, err)
+	assert.Equal(t, []interface{}{pgx.QueryExecModeCacheDescribe, 1}, db.args[3])
+}
@@ -4,153 +4,500 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"reflect"
+	"time"
 
-	"github.com/Masterminds/squirrel"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/georgysavva/scany/v2/sqlscan"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
-// Querier interface abstracts database operations
+// Querier is a database/sql handle that can run queries, such as *sql.DB,
+// *sql.Tx or a wrapper around them.
 type Querier interface {
 	// QueryContext is provided by sql.DB
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 }
 
+// Execer is a database/sql handle that can run statements returning no rows.
+type Execer interface {
+	// ExecContext is provided by sql.DB
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 // PgxQuerier interface for pgx operations
 type PgxQuerier interface {
 	// Query is provided by pgx.Conn
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 }
 
+// PgxExecer is a pgx handle that can run statements returning no rows.
+type PgxExecer interface {
+	// Exec is provided by pgx.Conn
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
 // Execute runs the query and returns properly scanned results.
-func Execute[T Model](ctx context.Context, db interface{}, req QueryRequest) (QueryResponse[T], error) {
-	// Get model metadata using type parameter T
+// Under a context prepared with WithMemo, identical calls are executed only once.
+// Under a context prepared with WithResultCache, results are shared across requests.
+func Execute[T Model](ctx context.Context, db interface{}, req QueryRequest, opts ...ExecuteOption) (QueryResponse[T], error) {
 	var model T
-	metadata, err := getModelMetadata(model)
+	resp, err := executeModel(ctx, db, model, req, opts)
+	return QueryResponse[T](resp), err
+}
+
+// executeModel implements Execute for the registered model.
+func executeModel(ctx context.Context, db interface{}, model Model, req QueryRequest, opts []ExecuteOption) (QueryResponse[Model], error) {
+	db, err := routeDB(ctx, db, req.Where)
 	if err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to get model metadata: %w", err)
+		return QueryResponse[Model]{}, err
+	}
+	cfg := newExecuteConfig(opts)
+	variant := registryFromContext(ctx).callerVariant(ctx, model, cfg.version)
+	if cfg.dryRun {
+		dryRun, err := buildDryRun(ctx, model, variant, req, cfg.metadata)
+		if err != nil {
+			return QueryResponse[Model]{}, err
+		}
+		return QueryResponse[Model]{Data: []QueryResult{}, DryRun: dryRun}, nil
+	}
+	start := time.Now()
+	run := func() (QueryResponse[Model], error) {
+		var resp QueryResponse[Model]
+		err := cfg.run(ctx, db, func(ctx context.Context, db interface{}) error {
+			var err error
+			resp, err = execute(ctx, db, model, variant, req, cfg.metadata)
+			return err
+		})
+		return resp, err
 	}
 
-	// Call the validator before building and executing the query.
-	validator := BasicValidator{}
-	if err := validator.ValidateQuery(req, metadata); err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to validate query: %w", err)
+	m, c := memoFromContext(ctx), resultCacheFromContext(ctx)
+	if m == nil && c == nil {
+		resp, err := run()
+		return withDuration(resp, start), err
+	}
+
+	// Requests are checked before the caches, so that hits can't bypass
+	// feature flags, projections or complexity limits
+	r := registryFromContext(ctx)
+	if _, _, err := r.prepareQuery(ctx, model, variant, req); err != nil {
+		return QueryResponse[Model]{}, err
+	}
+	kind := "execute"
+	if variant != (modelVariant{}) {
+		kind += "@" + variant.String()
+	}
+	if cfg.metadata {
+		kind += "+metadata"
+	}
+	// Optional includes are skipped while their flag is disabled
+	for _, name := range req.Include {
+		if lookup, ok := r.GetLookup(model, name); ok && !r.flagEnabled(ctx, lookup.Flag) {
+			kind += "-" + name
+		}
+	}
+	key, err := memoCallKey(r, kind, []reflect.Type{reflect.TypeOf(model)}, db, req)
+	if err != nil {
+		return QueryResponse[Model]{}, err
+	}
+	value, err := cachedCall(ctx, m, c, key, func() (interface{}, error) {
+		return run()
+	})
+	if err != nil {
+		return QueryResponse[Model]{}, err
+	}
+	resp := value.(QueryResponse[Model])
+	resp.Data = copyRows(resp.Data)
+	return withDuration(resp, start), nil
+}
+
+// withDuration sets the duration of the metadata of resp, if any, to the time
+// elapsed since start.
+func withDuration(resp QueryResponse[Model], start time.Time) QueryResponse[Model] {
+	if resp.Metadata != nil {
+		meta := *resp.Metadata
+		meta.DurationMS = float64(time.Since(start).Microseconds()) / 1000
+		resp.Metadata = &meta
+	}
+	return resp
+}
+
+// execute implements Execute for the variant of the model. The response has a
+// QueryMetadata, without duration, when withMetadata is set.
+func execute(ctx context.Context, db interface{}, model Model, variant modelVariant, req QueryRequest, withMetadata bool) (resp QueryResponse[Model], err error) {
+	r := registryFromContext(ctx)
+	metadata, req, err := r.prepareQuery(ctx, model, variant, req)
+	if err != nil {
+		return QueryResponse[Model]{}, err
+	}
+	start := time.Now()
+	defer func() { recordCall(ctx, metadata, OpSelect, start, int64(len(resp.Data)), err) }()
+
+	db, err = resolveDB(ctx, db)
+	if err != nil {
+		return QueryResponse[Model]{}, err
 	}
 
 	// Handle pagination if requested
 	var paginationResp *PaginationResponse
-	if req.Pagination != nil {
-		// If req.Pagination is provided, it will override any previously set limit/offset values.
-		// This ensures that page-based pagination always takes precedence over direct limit/offset parameters.
+	req = withPagination(req)
 
-		// Validate and normalize pagination parameters
-		req.Pagination = ValidatePagination(req.Pagination)
+	selectReq, hiddenFields, limit := rowsRequest(metadata, req, withMetadata)
 
-		// Set limit and offset based on pagination
-		limit := req.Pagination.PageSize
-		offset := CalculateOffset(req.Pagination.Page, req.Pagination.PageSize)
-		req.Limit = &limit
-		req.Offset = &offset
+	// Build query using the resolved metadata
+	builder, err := buildSelect(metadata, selectReq)
+	if err != nil {
+		return QueryResponse[Model]{}, fmt.Errorf("failed to build query: %w", err)
 	}
-
-	// Build query using the generic buildQuery
-	builder, err := buildQuery[T](req)
+	query, args, err := builder.ToSql()
 	if err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to build query: %w", err)
+		return QueryResponse[Model]{}, fmt.Errorf("failed to generate sql: %w", err)
+	}
+	if err := checkCost(ctx, db, query, args); err != nil {
+		return QueryResponse[Model]{}, err
 	}
 
 	// If pagination is requested, we need to get total count first
 	if req.Pagination != nil {
 		// Create a new count query builder with the same conditions
-		// Use Postgres placeholder format ($1, $2, etc)
-		builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-		countBuilder := builder.Select("COUNT(*)").From(model.TableName())
-
-		// Apply the same where conditions if they exist
-		if len(req.Where) > 0 {
-			eq := make(squirrel.Eq)
-			for jsonName, value := range req.Where {
-				field, ok := metadata.Fields[jsonName]
-				if !ok {
-					return QueryResponse[T]{}, fmt.Errorf("invalid field in where clause: %s", jsonName)
-				}
-				eq[field.Name] = value
-			}
-			countBuilder = countBuilder.Where(eq)
+		countBuilder, err := buildCount(metadata, req)
+		if err != nil {
+			return QueryResponse[Model]{}, fmt.Errorf("failed to build count query: %w", err)
 		}
 
 		countQuery, countArgs, err := countBuilder.ToSql()
 		if err != nil {
-			return QueryResponse[T]{}, fmt.Errorf("failed to generate count sql: %w", err)
+			return QueryResponse[Model]{}, fmt.Errorf("failed to generate count sql: %w", err)
 		}
 
-		// Log the query for debugging
-		log.Printf("Count Query: %s with args: %v", countQuery, countArgs)
-
 		var totalItems int
-		switch db := db.(type) {
-		case *sql.DB:
-			err = sqlscan.Get(ctx, db, &totalItems, countQuery, countArgs...)
-		case *pgx.Conn:
-			err = pgxscan.Get(ctx, db, &totalItems, countQuery, countArgs...)
-		default:
-			return QueryResponse[T]{}, fmt.Errorf("unsupported database type: %T", db)
+		if err := getOne(ctx, db, &totalItems, countQuery, countArgs...); err != nil {
+			return QueryResponse[Model]{}, fmt.Errorf("failed to get total count: %w", err)
 		}
 
+		paginationResp = CalculatePagination(totalItems, req.Pagination.PageSize, req.Pagination.Page)
+	}
+
+	var summary map[string]map[string]interface{}
+	if len(req.Summary) > 0 {
+		summary, err = executeSummary(ctx, db, metadata, req)
 		if err != nil {
-			return QueryResponse[T]{}, fmt.Errorf("failed to get total count: %w", err)
+			return QueryResponse[Model]{}, err
 		}
+	}
 
-		paginationResp = CalculatePagination(totalItems, req.Pagination.PageSize, req.Pagination.Page)
+	// Use appropriate scanner based on the database type
+	var results []map[string]interface{}
+	if err := selectAll(ctx, db, &results, query, args...); err != nil {
+		return QueryResponse[Model]{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	results, truncated, err := limit.apply(results)
+	if err != nil {
+		return QueryResponse[Model]{}, err
+	}
+	var meta *QueryMetadata
+	if withMetadata {
+		meta = &QueryMetadata{Fingerprint: FingerprintSQL(query), Pagination: paginationResp, Truncated: truncated}
 	}
+	// Only truncations by the cap are reported without metadata
+	capped := truncated && limit.capped
 
-	// Get the query and args for the main query
-	query, args, err := builder.ToSql()
+	// Convert the results to our QueryResult type
+	queryResults, err := toQueryResults(metadata, selectReq.Select, results)
 	if err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to generate sql: %w", err)
+		return QueryResponse[Model]{}, err
 	}
 
-	// Use appropriate scanner based on the database type
-	var results []map[string]interface{}
-	switch db := db.(type) {
-	case *sql.DB:
-		err = sqlscan.Select(ctx, db, &results, query, args...)
-	case *pgx.Conn:
-		err = pgxscan.Select(ctx, db, &results, query, args...)
-	default:
-		return QueryResponse[T]{}, fmt.Errorf("unsupported database type: %T", db)
+	for name, fields := range req.Nested {
+		if _, err := runLookup(ctx, db, name, nestedLookup(metadata, name, fields, req.IncludeDeleted), queryResults); err != nil {
+			return QueryResponse[Model]{}, err
+		}
+	}
+	for _, row := range queryResults {
+		for _, field := range hiddenFields {
+			delete(row, field)
+		}
 	}
 
+	warnings, err := r.applyLookups(ctx, db, model, req.Include, queryResults)
 	if err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to execute query: %w", err)
+		return QueryResponse[Model]{}, err
+	}
+	if err := transformResults(ctx, metadata, queryResults); err != nil {
+		return QueryResponse[Model]{}, err
 	}
 
-	// Convert the results to our QueryResult type
+	if req.Pivot != nil {
+		pivot, err := Pivot(queryResults, *req.Pivot)
+		if err != nil {
+			return QueryResponse[Model]{}, fmt.Errorf("failed to pivot results: %w", err)
+		}
+		if meta != nil {
+			meta.RowCount = len(pivot.Rows)
+		}
+		return QueryResponse[Model]{
+			Data:      pivot.Rows,
+			Columns:   pivot.Columns,
+			Summary:   summary,
+			Warnings:  warnings,
+			Metadata:  meta,
+			Truncated: capped,
+		}, nil
+	}
+
+	if meta != nil {
+		meta.RowCount = len(queryResults)
+	}
+	return QueryResponse[Model]{
+		Data:       queryResults,
+		Pagination: paginationResp,
+		Summary:    summary,
+		Warnings:   warnings,
+		Metadata:   meta,
+		Truncated:  capped,
+	}, nil
+}
+
+// rowsRequest returns the request of the rows of req, once paginated. Keys
+// needed to attach nested relations are fetched even when not selected, and
+// returned as hidden to be removed from the rows once the relations are
+// loaded. One more row than kept is read when the MaxRows of the model cap
+// the request, or with withMetadata to tell whether results are truncated
+// by its limit; limit then describes the rows kept.
+func rowsRequest(metadata ModelMetadata, req QueryRequest, withMetadata bool) (selectReq QueryRequest, hidden []string, limit *rowsLimit) {
+	selectReq = req
+	hidden = nestedKeyFields(metadata, req)
+	if len(hidden) > 0 {
+		selectReq.Select = append(append([]string(nil), req.Select...), hidden...)
+	}
+	if max := metadata.owner().rowsCap(metadata); max.Rows > 0 && (req.Limit == nil || *req.Limit > max.Rows) {
+		limit = &rowsLimit{keep: max.Rows, capped: true, reject: max.Reject}
+	} else if withMetadata && req.Limit != nil {
+		limit = &rowsLimit{keep: *req.Limit}
+	}
+	if limit != nil {
+		read := limit.keep + 1
+		selectReq.Limit = &read
+	}
+	return selectReq, hidden, limit
+}
+
+// prepareQuery resolves the metadata of the variant of model req is run
+// against and validates req, which is returned with its default select, and
+// checks it against the complexity limits of the model.
+func (r *Registry) prepareQuery(ctx context.Context, model Model, variant modelVariant, req QueryRequest) (ModelMetadata, QueryRequest, error) {
+	metadata, req, err := r.validateQuery(ctx, model, variant, req)
+	if err != nil {
+		return ModelMetadata{}, req, err
+	}
+	if err := r.checkComplexity(metadata, req); err != nil {
+		return ModelMetadata{}, req, err
+	}
+	return metadata, req, nil
+}
+
+// validateQuery implements prepareQuery, without checking the complexity of
+// the request.
+func (r *Registry) validateQuery(ctx context.Context, model Model, variant modelVariant, req QueryRequest) (ModelMetadata, QueryRequest, error) {
+	metadata, err := r.variantMetadata(model, variant)
+	if err != nil {
+		return ModelMetadata{}, req, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	req = withDefaultSelect(metadata, req)
+	metadata, err = r.resolveRequest(metadata, req)
+	if err != nil {
+		return ModelMetadata{}, req, fmt.Errorf("failed to validate query: %w", err)
+	}
+
+	// Call the validator before building and executing the query.
+	// Every problem of the request is reported at once
+	validator := BasicValidator{}
+	errs := ValidationErrors{}.add(validator.ValidateQuery(req, metadata))
+	errs = errs.add(r.validateEnumValues(metadata, req.Where))
+	if err := errs.err(); err != nil {
+		return ModelMetadata{}, req, fmt.Errorf("failed to validate query: %w", err)
+	}
+	for _, name := range req.Include {
+		lookup, ok := r.GetLookup(model, name)
+		if !ok {
+			return ModelMetadata{}, req, fmt.Errorf("failed to validate query: invalid include: %s", name)
+		}
+		if !lookup.Optional && !r.flagEnabled(ctx, lookup.Flag) {
+			return ModelMetadata{}, req, fmt.Errorf("failed to validate query: include %s is disabled", name)
+		}
+	}
+	if err := r.checkRelationFlags(ctx, metadata, req); err != nil {
+		return ModelMetadata{}, req, fmt.Errorf("failed to validate query: %w", err)
+	}
+	return metadata, req, nil
+}
+
+// withPagination returns req with the limit and offset of its page, if it
+// has pagination. Page-based pagination always takes precedence over direct
+// limit/offset parameters.
+func withPagination(req QueryRequest) QueryRequest {
+	if req.Pagination == nil {
+		return req
+	}
+	// Validate and normalize pagination parameters
+	req.Pagination = ValidatePagination(req.Pagination)
+
+	// Set limit and offset based on pagination
+	limit := req.Pagination.PageSize
+	offset := CalculateOffset(req.Pagination.Page, req.Pagination.PageSize)
+	req.Limit = &limit
+	req.Offset = &offset
+	return req
+}
+
+// toQueryResults converts scanned rows into QueryResults keyed by the JSON
+// names of the selected fields, decoding the values of custom field types with
+// scanFieldValue and converting them with outputValue. Null values follow the
+// null policy of the registry, see SetNullPolicy.
+func toQueryResults(metadata ModelMetadata, selected []string, results []map[string]interface{}) ([]QueryResult, error) {
+	r := metadata.owner()
 	queryResults := make([]QueryResult, len(results))
 	for i, result := range results {
 		queryResult := make(QueryResult)
-		for _, field := range req.Select {
-			if val, ok := result[field]; ok {
-				fieldMeta := metadata.Fields[field]
-				jsonName := fieldMeta.JSONName
-				if jsonName == "" {
-					jsonName = field
-				}
-				queryResult[jsonName] = val
+		for _, field := range selected {
+			ref, _ := metadata.lookupField(field)
+			val, ok, err := scannedValue(metadata, field, result)
+			if !ok {
+				continue
+			}
+			if err == nil {
+				val, err = r.outputValue(ref.Field, val)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan field %s: %w", field, err)
+			}
+			// Related fields are returned under the requested name
+			name := field
+			if ref.Relation == "" && ref.Field.JSONName != "" {
+				name = ref.Field.JSONName
 			}
+			r.setResult(queryResult, name, ref.Field, val)
 		}
 		queryResults[i] = queryResult
 	}
+	return queryResults, nil
+}
 
-	return QueryResponse[T]{
-		Data:       queryResults,
-		Pagination: paginationResp,
-	}, nil
+// scannedValue returns the value of the selected field in result, a row as
+// scanned, decoded into the Go type of the field but not yet converted for
+// output. ok is false when the row doesn't hold the field.
+func scannedValue(metadata ModelMetadata, field string, result map[string]interface{}) (val interface{}, ok bool, err error) {
+	ref, _ := metadata.lookupField(field)
+	dialect := metadata.dialect()
+	if ref.Relation != "" {
+		// Related fields are aliased with the requested name
+		val, ok = result[field]
+	} else {
+		// Columns are named after the database field
+		val, ok = result[ref.Field.Name]
+		if !ok {
+			val, ok = result[field]
+		}
+		if folder, folds := dialect.(identFoldingDialect); !ok && folds {
+			val, ok = result[folder.FoldIdent(ref.Field.Name)]
+		}
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	if value, isPgtype := pgtypeValue(val); isPgtype {
+		val = value
+	}
+	if converter, converts := dialect.(valueConvertingDialect); converts {
+		val = converter.ConvertValue(val, ref.Field.Type)
+	}
+	val, err = metadata.owner().scanFieldValue(ref.Field.Type, val)
+	return val, true, err
+}
+
+// selectAll runs query against db and scans every row into dest using the
+// scanner matching the interface db implements.
+func selectAll(ctx context.Context, db interface{}, dest interface{}, query string, args ...interface{}) error {
+	db, err := resolveDB(ctx, db)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	switch db := db.(type) {
+	case Querier:
+		err = sqlscan.Select(ctx, db, dest, query, args...)
+	case PgxQuerier:
+		err = pgxscan.Select(ctx, db, dest, query, pgxArgs(ctx, args)...)
+	default:
+		return fmt.Errorf("unsupported database type: %T", db)
+	}
+	err = dbError(err)
+	logQuery(ctx, query, args, start, err)
+	checkSlowQuery(ctx, db, query, args, start, err)
+	return err
+}
+
+// getOne runs query against db and scans its single row into dest.
+func getOne(ctx context.Context, db interface{}, dest interface{}, query string, args ...interface{}) error {
+	db, err := resolveDB(ctx, db)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	switch db := db.(type) {
+	case Querier:
+		err = sqlscan.Get(ctx, db, dest, query, args...)
+	case PgxQuerier:
+		err = pgxscan.Get(ctx, db, dest, query, pgxArgs(ctx, args)...)
+	default:
+		return fmt.Errorf("unsupported database type: %T", db)
+	}
+	err = dbError(err)
+	logQuery(ctx, query, args, start, err)
+	checkSlowQuery(ctx, db, query, args, start, err)
+	return err
+}
+
+// execAffected runs a statement that returns no rows and reports the number
+// of affected rows.
+func execAffected(ctx context.Context, db interface{}, query string, args ...interface{}) (int64, error) {
+	db, err := resolveDB(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	var n int64
+	switch db := db.(type) {
+	case Execer:
+		n, err = sqlRowsAffected(db.ExecContext(ctx, query, args...))
+	case PgxExecer:
+		n, err = pgxRowsAffected(db.Exec(ctx, query, pgxArgs(ctx, args)...))
+	default:
+		return 0, fmt.Errorf("unsupported database type: %T", db)
+	}
+	logQuery(ctx, query, args, start, err)
+	checkSlowQuery(ctx, db, query, args, start, err)
+	return n, err
+}
+
+// sqlRowsAffected returns the number of rows affected by a database/sql
+// statement.
+func sqlRowsAffected(result sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, dbError(err)
+	}
+	return result.RowsAffected()
+}
+
+// pgxRowsAffected returns the number of rows affected by a pgx statement.
+func pgxRowsAffected(tag pgconn.CommandTag, err error) (int64, error) {
+	if err != nil {
+		return 0, dbError(err)
+	}
+	return tag.RowsAffected(), nil
 }
 
 // TODO: Add connection pooling configuration
-// TODO: Add caching layer for frequently used queries
-// TODO: Add query execution timeout handling
 // TODO: Add detailed error context and error codes
@@ -0,0 +1,354 @@
+package sqld
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// FeedSource is one model contributing items to a feed, created with
+// NewFeedSource.
+type FeedSource struct {
+	Name string
+
+	model     Model
+	req       QueryRequest
+	timeField string
+	keyField  string
+}
+
+// FeedItem is one row of a feed together with the source it comes from.
+type FeedItem struct {
+	Source string      `json:"source"`
+	Data   QueryResult `json:"data"`
+}
+
+// FeedResponse is a page of a feed.
+type FeedResponse struct {
+	Items []FeedItem `json:"items"`
+
+	// NextCursor fetches the next page when passed to ExecuteFeed.
+	// Empty when the feed is exhausted.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// feedRows are the rows fetched from a source, with the positions of the rows
+// as scanned, before the times are converted for output.
+type feedRows struct {
+	rows      []QueryResult
+	positions []feedPosition
+	truncated bool // Rows were cut by the row cap of the model
+}
+
+// feedPosition is the last item of a source returned so far.
+type feedPosition struct {
+	Time time.Time   `json:"t"`
+	Key  interface{} `json:"k"`
+}
+
+// NewFeedSource declares model T as a feed source. Items are ordered by
+// timeField, newest first, with keyField breaking ties; both must be
+// time.Time and unique key fields of T respectively, and are always
+// returned. req selects the other fields returned and may filter them with
+// Where; its ordering and pagination settings are ignored. The source is
+// resolved and validated by ExecuteFeed, against the registry of its context.
+func NewFeedSource[T Model](name, timeField, keyField string, req QueryRequest) (FeedSource, error) {
+	if name == "" {
+		return FeedSource{}, fmt.Errorf("feed source name cannot be empty")
+	}
+	if timeField == "" || keyField == "" {
+		return FeedSource{}, fmt.Errorf("feed source %s needs a time and a key field", name)
+	}
+	var model T
+	return FeedSource{
+		Name:      name,
+		model:     model,
+		req:       req,
+		timeField: timeField,
+		keyField:  keyField,
+	}, nil
+}
+
+// feedQuery is a feed source resolved against a registry for one page.
+type feedQuery struct {
+	source   FeedSource
+	metadata ModelMetadata
+	req      QueryRequest
+	limit    *rowsLimit
+}
+
+// prepare resolves the source against r for the caller of ctx and validates
+// its request like Execute does, for pages of limit items.
+func (s FeedSource) prepare(ctx context.Context, r *Registry, limit int) (feedQuery, error) {
+	variant := r.callerVariant(ctx, s.model, "")
+	metadata, err := r.variantMetadata(s.model, variant)
+	if err != nil {
+		return feedQuery{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if _, ok := metadata.Fields[s.timeField]; !ok {
+		return feedQuery{}, fmt.Errorf("invalid time field: %s", s.timeField)
+	}
+	if _, ok := metadata.Fields[s.keyField]; !ok {
+		return feedQuery{}, fmt.Errorf("invalid key field: %s", s.keyField)
+	}
+
+	req := withDefaultSelect(metadata, s.req)
+	req.OrderBy = []OrderByClause{{Field: s.timeField, Desc: true}, {Field: s.keyField, Desc: true}}
+	req.Pagination, req.Offset = nil, nil
+	req.Limit = &limit
+	req.Select = appendMissing(req.Select, s.timeField, s.keyField)
+	metadata, req, err = r.prepareQuery(ctx, s.model, variant, req)
+	if err != nil {
+		return feedQuery{}, err
+	}
+	selectReq, _, rows := rowsRequest(metadata, req, false)
+	return feedQuery{source: s, metadata: metadata, req: selectReq, limit: rows}, nil
+}
+
+// ExecuteFeed returns up to limit items merged from all sources, newest
+// first. Each source is queried once per page for at most limit items
+// following its own position in cursor, and the results are merged. Pass an
+// empty cursor for the first page and FeedResponse.NextCursor afterwards.
+func ExecuteFeed(ctx context.Context, db interface{}, sources []FeedSource, limit int, cursor string) (*FeedResponse, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	seen := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		if seen[source.Name] {
+			return nil, fmt.Errorf("duplicate feed source: %s", source.Name)
+		}
+		seen[source.Name] = true
+	}
+
+	positions, err := decodeFeedCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every source is checked before any is queried
+	r := registryFromContext(ctx)
+	queries := make([]feedQuery, len(sources))
+	for i, source := range sources {
+		queries[i], err = source.prepare(ctx, r, limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid feed source %s: %w", source.Name, err)
+		}
+	}
+
+	fetched := make([]feedRows, len(sources))
+	for i, source := range sources {
+		pos, ok := positions[source.Name]
+		var after *feedPosition
+		if ok {
+			after = &pos
+		}
+		fetched[i], err = queries[i].fetch(ctx, db, after)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch feed source %s: %w", source.Name, err)
+		}
+	}
+
+	heads := mergeFeed(fetched, limit)
+	resp := &FeedResponse{Items: make([]FeedItem, len(heads))}
+	for i, head := range heads {
+		resp.Items[i] = FeedItem{Source: sources[head.source].Name, Data: fetched[head.source].rows[head.index]}
+	}
+	// A source cut by its row cap may hold more rows than it returned
+	exhausted := len(heads) < limit
+	for _, f := range fetched {
+		exhausted = exhausted && !f.truncated
+	}
+	if !exhausted {
+		// Heads are newest first, so the last head of each source is its
+		// new position
+		for _, head := range heads {
+			positions[sources[head.source].Name] = fetched[head.source].positions[head.index]
+		}
+		resp.NextCursor, err = encodeFeedCursor(positions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// fetch returns the next page of rows of the source older than after.
+func (q feedQuery) fetch(ctx context.Context, db interface{}, after *feedPosition) (feedRows, error) {
+	s, metadata, req := q.source, q.metadata, q.req
+	query, err := buildSelect(metadata, req)
+	if err != nil {
+		return feedRows{}, err
+	}
+	if after != nil {
+		qualify := len(referencedRelations(metadata, req)) > 0
+		timeRef, _ := metadata.lookupField(s.timeField)
+		keyRef, _ := metadata.lookupField(s.keyField)
+		query = query.Where(squirrel.Expr(
+			fmt.Sprintf("(%s, %s) < (?, ?)", timeRef.column(metadata, qualify), keyRef.column(metadata, qualify)),
+			after.Time, after.Key))
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return feedRows{}, fmt.Errorf("failed to generate sql: %w", err)
+	}
+	if err := checkCost(ctx, db, sql, args); err != nil {
+		return feedRows{}, err
+	}
+	var results []map[string]interface{}
+	if err := selectAll(ctx, db, &results, sql, args...); err != nil {
+		return feedRows{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	results, truncated, err := q.limit.apply(results)
+	if err != nil {
+		return feedRows{}, err
+	}
+	// Positions are taken from the rows as scanned, since the time output
+	// may have turned times into strings or numbers
+	positions := make([]feedPosition, len(results))
+	for i, result := range results {
+		val, _, err := scannedValue(metadata, s.timeField, result)
+		if err != nil {
+			return feedRows{}, err
+		}
+		t, ok := feedTime(val)
+		if !ok {
+			return feedRows{}, fmt.Errorf("field %s is %T, not a time", s.timeField, val)
+		}
+		key, _, err := scannedValue(metadata, s.keyField, result)
+		if err != nil {
+			return feedRows{}, err
+		}
+		positions[i] = feedPosition{Time: t, Key: key}
+	}
+	rows, err := toQueryResults(metadata, req.Select, results)
+	if err != nil {
+		return feedRows{}, err
+	}
+	if err := transformResults(ctx, metadata, rows); err != nil {
+		return feedRows{}, err
+	}
+	return feedRows{rows: rows, positions: positions, truncated: truncated}, nil
+}
+
+// feedTime returns val, the scanned value of a time field, as a time.
+// Drivers reading times as text return them as strings or bytes.
+func feedTime(val interface{}) (time.Time, bool) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v != nil {
+			return *v, true
+		}
+	case []byte:
+		return parseTimeText(string(v))
+	case string:
+		return parseTimeText(v)
+	}
+	return time.Time{}, false
+}
+
+// mergeFeed k-way merges the rows fetched for each source, newest first,
+// and returns the heads of at most limit items. Items with equal times keep
+// the order of sources.
+func mergeFeed(fetched []feedRows, limit int) []feedHead {
+	h := &feedHeap{}
+	for i := range fetched {
+		if len(fetched[i].rows) > 0 {
+			heap.Push(h, newFeedHead(fetched, i, 0))
+		}
+	}
+
+	heads := []feedHead{}
+	for h.Len() > 0 && len(heads) < limit {
+		head := heap.Pop(h).(feedHead)
+		heads = append(heads, head)
+		if next := head.index + 1; next < len(fetched[head.source].rows) {
+			heap.Push(h, newFeedHead(fetched, head.source, next))
+		}
+	}
+	return heads
+}
+
+// feedHead is the next unmerged row of a source.
+type feedHead struct {
+	time   time.Time
+	source int
+	index  int
+}
+
+func newFeedHead(fetched []feedRows, source, index int) feedHead {
+	return feedHead{time: fetched[source].positions[index].Time, source: source, index: index}
+}
+
+// feedHeap orders feed heads newest first.
+type feedHeap []feedHead
+
+func (h feedHeap) Len() int { return len(h) }
+func (h feedHeap) Less(i, j int) bool {
+	if !h[i].time.Equal(h[j].time) {
+		return h[i].time.After(h[j].time)
+	}
+	return h[i].source < h[j].source
+}
+func (h feedHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *feedHeap) Push(x interface{}) { *h = append(*h, x.(feedHead)) }
+func (h *feedHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// encodeFeedCursor encodes the source positions as an opaque cursor.
+func encodeFeedCursor(positions map[string]feedPosition) (string, error) {
+	encoded, err := json.Marshal(positions)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode feed cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// decodeFeedCursor decodes a cursor built by encodeFeedCursor.
+func decodeFeedCursor(cursor string) (map[string]feedPosition, error) {
+	positions := make(map[string]feedPosition)
+	if cursor == "" {
+		return positions, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed cursor: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(decoded))
+	dec.UseNumber()
+	if err := dec.Decode(&positions); err != nil {
+		return nil, fmt.Errorf("invalid feed cursor: %w", err)
+	}
+	return positions, nil
+}
+
+// appendMissing returns fields with each of extra appended unless present.
+func appendMissing(fields []string, extra ...string) []string {
+	out := append([]string(nil), fields...)
+	for _, e := range extra {
+		found := false
+		for _, f := range out {
+			if f == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, e)
+		}
+	}
+	return out
+}
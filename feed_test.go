@@ -0,0 +1,206 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type FeedAccountEvent struct {
+	ID        int64     `json:"id" db:"id"`
+	Kind      string    `json:"kind" db:"kind"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (FeedAccountEvent) TableName() string {
+	return "account_events"
+}
+
+func TestExecuteFeed(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+	require.NoError(t, Register(FeedAccountEvent{}))
+
+	people, err := NewFeedSource[BuilderTestModel]("people", "created_at", "id", QueryRequest{Select: []string{"name"}})
+	require.NoError(t, err)
+	events, err := NewFeedSource[FeedAccountEvent]("events", "created_at", "id", QueryRequest{
+		Select: []string{"kind"},
+		Where:  map[string]interface{}{"kind": "deposit"},
+	})
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	at := func(hour int) time.Time { return time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC) }
+
+	// First page
+	mock.ExpectQuery(`SELECT name, created_at, id FROM test_models ORDER BY created_at DESC, id DESC LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "created_at", "id"}).
+			AddRow("alice", at(9), int64(1)).
+			AddRow("bob", at(5), int64(2)))
+	mock.ExpectQuery(`SELECT kind, created_at, id FROM account_events WHERE kind = \$1 ORDER BY created_at DESC, id DESC LIMIT 3`).
+		WithArgs("deposit").
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "created_at", "id"}).
+			AddRow("deposit", at(8), int64(10)).
+			AddRow("deposit", at(7), int64(11)).
+			AddRow("deposit", at(1), int64(12)))
+
+	page, err := ExecuteFeed(context.Background(), db, []FeedSource{people, events}, 3, "")
+	require.NoError(t, err)
+	require.Len(t, page.Items, 3)
+	assert.Equal(t, "people", page.Items[0].Source)
+	assert.Equal(t, "alice", page.Items[0].Data["name"])
+	assert.Equal(t, "events", page.Items[1].Source)
+	assert.Equal(t, int64(10), page.Items[1].Data["id"])
+	assert.Equal(t, int64(11), page.Items[2].Data["id"])
+	require.NotEmpty(t, page.NextCursor)
+
+	// Second page continues each source from its own position
+	mock.ExpectQuery(`SELECT name, created_at, id FROM test_models WHERE \(created_at, id\) < \(\$1, \$2\) ORDER BY created_at DESC, id DESC LIMIT 3`).
+		WithArgs(at(9), "1").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "created_at", "id"}).
+			AddRow("bob", at(5), int64(2)))
+	mock.ExpectQuery(`SELECT kind, created_at, id FROM account_events WHERE kind = \$1 AND \(created_at, id\) < \(\$2, \$3\)`).
+		WithArgs("deposit", at(7), "11").
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "created_at", "id"}).
+			AddRow("deposit", at(1), int64(12)))
+
+	page, err = ExecuteFeed(context.Background(), db, []FeedSource{people, events}, 3, page.NextCursor)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "bob", page.Items[0].Data["name"])
+	assert.Equal(t, int64(12), page.Items[1].Data["id"])
+	assert.Empty(t, page.NextCursor)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewFeedSource_Invalid(t *testing.T) {
+	_, err := NewFeedSource[FeedAccountEvent]("", "created_at", "id", QueryRequest{Select: []string{"kind"}})
+	assert.Error(t, err)
+	_, err = NewFeedSource[FeedAccountEvent]("events", "", "id", QueryRequest{Select: []string{"kind"}})
+	assert.Error(t, err)
+	_, err = NewFeedSource[FeedAccountEvent]("events", "created_at", "", QueryRequest{Select: []string{"kind"}})
+	assert.Error(t, err)
+}
+
+func TestExecuteFeed_InvalidSource(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(FeedAccountEvent{}))
+	registry.SetComplexityLimits(ComplexityLimits{MaxPageSize: 50})
+	ctx := WithRegistry(context.Background(), registry)
+
+	tests := []struct {
+		name      string
+		timeField string
+		keyField  string
+		req       QueryRequest
+		limit     int
+	}{
+		{name: "unknown time field", timeField: "updated_at", keyField: "id", req: QueryRequest{Select: []string{"kind"}}, limit: 10},
+		{name: "unknown key field", timeField: "created_at", keyField: "uuid", req: QueryRequest{Select: []string{"kind"}}, limit: 10},
+		{name: "unknown select field", timeField: "created_at", keyField: "id", req: QueryRequest{Select: []string{"amount"}}, limit: 10},
+		{name: "page too large", timeField: "created_at", keyField: "id", req: QueryRequest{Select: []string{"kind"}}, limit: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := NewFeedSource[FeedAccountEvent]("events", tt.timeField, tt.keyField, tt.req)
+			require.NoError(t, err)
+			// No query is expected: sources are checked before running any
+			_, err = ExecuteFeed(ctx, nil, []FeedSource{events}, tt.limit, "")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExecuteFeed_Registry(t *testing.T) {
+	// The model is registered with the registry of the context only
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(FeedAccountEvent{}, WithMaxRows(MaxRows{Rows: 1})))
+	ctx := WithRegistry(context.Background(), registry)
+
+	events, err := NewFeedSource[FeedAccountEvent]("events", "created_at", "id", QueryRequest{Select: []string{"kind"}})
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	at := func(hour int) time.Time { return time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC) }
+
+	// The row cap reads one row more than it keeps
+	mock.ExpectQuery(`SELECT kind, created_at, id FROM account_events ORDER BY created_at DESC, id DESC LIMIT 2`).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "created_at", "id"}).
+			AddRow("deposit", at(8), int64(10)).
+			AddRow("deposit", at(7), int64(11)))
+
+	page, err := ExecuteFeed(ctx, db, []FeedSource{events}, 3, "")
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, int64(10), page.Items[0].Data["id"])
+	// Capped sources aren't exhausted
+	assert.NotEmpty(t, page.NextCursor)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteFeed_TimeOutput(t *testing.T) {
+	require.NoError(t, Register(FeedAccountEvent{}))
+	previous := defaultRegistry.timeOutput
+	SetTimeOutput(TimeOutput{Format: TimeFormatEpochMillis})
+	defer func() {
+		defaultRegistry.mu.Lock()
+		defaultRegistry.timeOutput = previous
+		defaultRegistry.mu.Unlock()
+	}()
+
+	events, err := NewFeedSource[FeedAccountEvent]("events", "created_at", "id", QueryRequest{Select: []string{"kind"}})
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	at := func(hour int) time.Time { return time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC) }
+
+	mock.ExpectQuery(`SELECT kind, created_at, id FROM account_events ORDER BY created_at DESC, id DESC LIMIT 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "created_at", "id"}).
+			AddRow("deposit", at(8), int64(10)))
+
+	page, err := ExecuteFeed(context.Background(), db, []FeedSource{events}, 1, "")
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, at(8).UnixMilli(), page.Items[0].Data["created_at"])
+	require.NotEmpty(t, page.NextCursor)
+
+	// The cursor holds the scanned time, not the emitted one
+	mock.ExpectQuery(`SELECT kind, created_at, id FROM account_events WHERE \(created_at, id\) < \(\$1, \$2\)`).
+		WithArgs(at(8), "10").
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "created_at", "id"}))
+
+	page, err = ExecuteFeed(context.Background(), db, []FeedSource{events}, 1, page.NextCursor)
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.Empty(t, page.NextCursor)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteFeed_InvalidArguments(t *testing.T) {
+	require.NoError(t, Register(FeedAccountEvent{}))
+	events, err := NewFeedSource[FeedAccountEvent]("events", "created_at", "id", QueryRequest{Select: []string{"kind"}})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = ExecuteFeed(ctx, nil, []FeedSource{events}, 0, "")
+	assert.Error(t, err)
+	_, err = ExecuteFeed(ctx, nil, []FeedSource{events, events}, 10, "")
+	assert.Error(t, err)
+	_, err = ExecuteFeed(ctx, nil, []FeedSource{events}, 10, "not a cursor!")
+	assert.Error(t, err)
+}
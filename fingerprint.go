@@ -0,0 +1,49 @@
+package sqld
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var (
+	// stringLiteralRegex matches quoted string literals, with '' escapes
+	stringLiteralRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// numberLiteralRegex matches numeric literals, not the digits of
+	// identifiers such as t1, keeping the character before them in group 1
+	numberLiteralRegex = regexp.MustCompile(`(^|[^\w$:.])\d+(?:\.\d+)?`)
+	// placeholderRegex matches the bind parameters of every dialect
+	placeholderRegex = regexp.MustCompile(`\$\d+|:\d+|\?`)
+	// placeholderListRegex matches lists of parameters, such as those of IN
+	placeholderListRegex = regexp.MustCompile(`\(\?(?:\s*,\s*\?)+\)`)
+)
+
+// FingerprintSQL returns a short, stable hash of the shape of query: the
+// same for queries differing only in their values, whether bound or written
+// as literals, in the length of their IN lists or in their whitespace. It
+// suits cache keys, rate limits and correlating the logs of several
+// services, and is the Fingerprint of QueryMetadata, QueryEvent and
+// SlowQuery.
+func FingerprintSQL(query string) string {
+	shape := strings.Join(strings.Fields(query), " ")
+	shape = stringLiteralRegex.ReplaceAllString(shape, "?")
+	shape = numberLiteralRegex.ReplaceAllString(shape, "${1}?")
+	shape = placeholderRegex.ReplaceAllString(shape, "?")
+	shape = placeholderListRegex.ReplaceAllString(shape, "(?)")
+	sum := sha256.Sum256([]byte(shape))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Fingerprint returns the FingerprintSQL of the query Execute would run for
+// req against model T, without running it, e.g. to rate limit requests by
+// shape before they reach the database. Options other than UseVersion are
+// ignored.
+func Fingerprint[T Model](ctx context.Context, req QueryRequest, opts ...ExecuteOption) (string, error) {
+	dryRun, err := BuildOnly[T](ctx, req, opts...)
+	if err != nil {
+		return "", err
+	}
+	return dryRun.Fingerprint, nil
+}
@@ -0,0 +1,53 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprintSQL(t *testing.T) {
+	a := FingerprintSQL("SELECT id FROM users WHERE id IN ($1,$2) AND name = $3")
+	assert.Equal(t, a, FingerprintSQL("SELECT id  FROM users\nWHERE id IN ($1, $2, $3, $4) AND name = $5"))
+	assert.Equal(t, a, FingerprintSQL("SELECT id FROM users WHERE id IN (?,?) AND name = ?"))
+	assert.Equal(t, a, FingerprintSQL("SELECT id FROM users WHERE id IN (1, 2.5) AND name = 'O''Brien'"))
+	assert.NotEqual(t, a, FingerprintSQL("SELECT id FROM users WHERE id IN ($1,$2) AND email = $3"))
+	assert.NotEqual(t, FingerprintSQL("SELECT c1 FROM t1"), FingerprintSQL("SELECT c2 FROM t1"))
+	assert.Len(t, a, 16)
+}
+
+func TestFingerprint(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	limit := 10
+	req := QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"email": "a@example.com"}, Limit: &limit}
+	fingerprint, err := Fingerprint[BuilderTestModel](ctx, req)
+	require.NoError(t, err)
+
+	// Values and limits don't change the shape
+	other := 20
+	same, err := Fingerprint[BuilderTestModel](ctx, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"email": "b@example.com"}, Limit: &other})
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, same)
+	different, err := Fingerprint[BuilderTestModel](ctx, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"name": "a"}, Limit: &limit})
+	require.NoError(t, err)
+	assert.NotEqual(t, fingerprint, different)
+
+	// It matches the fingerprint of the executed query
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1 LIMIT 11`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	resp, err := Execute[BuilderTestModel](ctx, db, req, WithMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, resp.Metadata.Fingerprint)
+
+	_, err = Fingerprint[BuilderTestModel](ctx, QueryRequest{Select: []string{"nope"}})
+	assert.ErrorIs(t, err, ErrUnknownField)
+}
@@ -0,0 +1,79 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// FlagProvider reports whether a feature flag is enabled. It is consulted at
+// execution time for every flagged lookup or relation a query uses, so
+// operators can switch off an expensive include or facet in production
+// without redeploying. Implementations should be fast and safe for
+// concurrent use; they typically read from an in-memory snapshot of a flag
+// service.
+type FlagProvider interface {
+	Enabled(ctx context.Context, flag string) bool
+}
+
+// FlagProviderFunc adapts a function to the FlagProvider interface.
+type FlagProviderFunc func(ctx context.Context, flag string) bool
+
+// Enabled calls f(ctx, flag).
+func (f FlagProviderFunc) Enabled(ctx context.Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+// SetFlagProvider sets the flag provider of the default registry.
+// With no provider, every flag is enabled.
+func SetFlagProvider(provider FlagProvider) {
+	defaultRegistry.SetFlagProvider(provider)
+}
+
+// SetFlagProvider sets the flag provider of the registry.
+func (r *Registry) SetFlagProvider(provider FlagProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flags = provider
+}
+
+// flagEnabled reports whether flag is enabled. Untagged features (empty flag)
+// are always enabled.
+func (r *Registry) flagEnabled(ctx context.Context, flag string) bool {
+	if flag == "" {
+		return true
+	}
+	r.mu.RLock()
+	provider := r.flags
+	r.mu.RUnlock()
+	return provider == nil || provider.Enabled(ctx, flag)
+}
+
+// checkRelationFlags returns an error when req uses a relation whose flag is
+// disabled, in its own fields, its CTEs or its nested selections.
+func (r *Registry) checkRelationFlags(ctx context.Context, metadata ModelMetadata, req QueryRequest) error {
+	used := make(map[string]bool)
+	for _, name := range referencedRelations(metadata, req) {
+		used[name] = true
+	}
+	for _, cte := range req.With {
+		for _, name := range referencedRelations(metadata, cte.Query) {
+			used[name] = true
+		}
+	}
+	for name := range req.Nested {
+		used[name] = true
+	}
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if rel, ok := metadata.Relations[name]; ok && !r.flagEnabled(ctx, rel.Flag) {
+			return fmt.Errorf("relation %s is disabled", name)
+		}
+	}
+	return nil
+}
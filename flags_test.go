@@ -0,0 +1,105 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_FlagEnabled(t *testing.T) {
+	registry := NewRegistry()
+	ctx := context.Background()
+	assert.True(t, registry.flagEnabled(ctx, "anything"), "no provider enables every flag")
+
+	registry.SetFlagProvider(FlagProviderFunc(func(ctx context.Context, flag string) bool {
+		return flag == "on"
+	}))
+	assert.True(t, registry.flagEnabled(ctx, ""), "untagged features are always enabled")
+	assert.True(t, registry.flagEnabled(ctx, "on"))
+	assert.False(t, registry.flagEnabled(ctx, "off"))
+}
+
+func TestExecute_FlaggedLookups(t *testing.T) {
+	require.NoError(t, Register(LookupTestModel{}))
+	load := func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error) {
+		return make([]interface{}, len(rows)), nil
+	}
+	require.NoError(t, RegisterLookup[LookupTestModel]("flagged_stats", Lookup{Load: load, Flag: "stats", Optional: true}))
+	require.NoError(t, RegisterLookup[LookupTestModel]("flagged_owner", Lookup{Load: load, Flag: "owner"}))
+
+	enabled := map[string]bool{"stats": false, "owner": false}
+	SetFlagProvider(FlagProviderFunc(func(ctx context.Context, flag string) bool { return enabled[flag] }))
+	defer SetFlagProvider(nil)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// A disabled optional lookup is skipped with a warning
+	mock.ExpectQuery("SELECT id FROM lookup_models").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	resp, err := Execute[LookupTestModel](context.Background(), db, QueryRequest{
+		Select:  []string{"id"},
+		Include: []string{"flagged_stats"},
+	})
+	require.NoError(t, err)
+	value, ok := resp.Data[0]["flagged_stats"]
+	assert.True(t, ok)
+	assert.Nil(t, value)
+	assert.Equal(t, []string{"lookup flagged_stats is disabled"}, resp.Warnings)
+
+	// A disabled required lookup fails before querying
+	_, err = Execute[LookupTestModel](context.Background(), db, QueryRequest{
+		Select:  []string{"id"},
+		Include: []string{"flagged_owner"},
+	})
+	assert.ErrorContains(t, err, "include flagged_owner is disabled")
+
+	// Once enabled, the lookup runs
+	enabled["owner"] = true
+	mock.ExpectQuery("SELECT id FROM lookup_models").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	resp, err = Execute[LookupTestModel](context.Background(), db, QueryRequest{
+		Select:  []string{"id"},
+		Include: []string{"flagged_owner"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Warnings)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckRelationFlags(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(RelEmployee{}))
+	require.NoError(t, registry.Register(RelDepartment{}))
+	require.NoError(t, registry.RegisterRelation(RelEmployee{}, RelDepartment{}, "department", Relation{
+		Kind: BelongsTo, LocalField: "department_id", ForeignField: "id", Flag: "department_facet",
+	}))
+	metadata, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	disabled := true
+	registry.SetFlagProvider(FlagProviderFunc(func(ctx context.Context, flag string) bool { return !disabled }))
+
+	ctx := context.Background()
+	assert.NoError(t, registry.checkRelationFlags(ctx, metadata, QueryRequest{Select: []string{"name"}}))
+	assert.Error(t, registry.checkRelationFlags(ctx, metadata, QueryRequest{Select: []string{"name", "department.name"}}))
+	assert.Error(t, registry.checkRelationFlags(ctx, metadata, QueryRequest{
+		Select: []string{"name"},
+		Where:  map[string]interface{}{"department.name": "eng"},
+	}))
+	assert.Error(t, registry.checkRelationFlags(ctx, metadata, QueryRequest{
+		Select: []string{"name"},
+		Nested: map[string][]string{"department": {"name"}},
+	}))
+	assert.Error(t, registry.checkRelationFlags(ctx, metadata, QueryRequest{
+		Select: []string{"name"},
+		With:   []CTE{{Name: "c", Query: QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"department.name": "eng"}}}},
+	}))
+
+	disabled = false
+	assert.NoError(t, registry.checkRelationFlags(ctx, metadata, QueryRequest{Select: []string{"name", "department.name"}}))
+}
@@ -0,0 +1,89 @@
+package sqld
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gzipWriter compresses what is written to it into w. Flushing it flushes
+// both the compressor and w, so that streamed rows reach clients as they
+// come.
+type gzipWriter struct {
+	gz *gzip.Writer
+	w  io.Writer
+}
+
+func (g *gzipWriter) Write(p []byte) (int, error) {
+	return g.gz.Write(p)
+}
+
+func (g *gzipWriter) Flush() error {
+	if err := g.gz.Flush(); err != nil {
+		return err
+	}
+	return flushUnderlying(g.w)
+}
+
+// Close writes the end of the gzip stream to w.
+func (g *gzipWriter) Close() error {
+	if err := g.gz.Close(); err != nil {
+		return err
+	}
+	return flushUnderlying(g.w)
+}
+
+// flushUnderlying flushes w if it can be.
+func flushUnderlying(w io.Writer) error {
+	switch w := w.(type) {
+	case flusher:
+		w.Flush()
+	case errorFlusher:
+		return w.Flush()
+	}
+	return nil
+}
+
+// exportWriter returns the writer the exporters write their output to, w
+// compressed when the call asks for it, and the function ending that output.
+func exportWriter(cfg executeConfig, w io.Writer) (io.Writer, func() error) {
+	rw, isResponse := w.(http.ResponseWriter)
+	if cfg.gzipRequest != nil && isResponse {
+		rw.Header().Add("Vary", "Accept-Encoding")
+	}
+	if !cfg.gzip || (cfg.gzipRequest != nil && !acceptsGzip(cfg.gzipRequest.Header.Get("Accept-Encoding"))) {
+		return w, func() error { return nil }
+	}
+	if isResponse {
+		rw.Header().Set("Content-Encoding", "gzip")
+		rw.Header().Del("Content-Length")
+	}
+	g := &gzipWriter{gz: gzip.NewWriter(w), w: w}
+	return g, g.Close
+}
+
+// acceptsGzip reports whether an Accept-Encoding header accepts gzip, named
+// or through *, with a non-zero quality.
+func acceptsGzip(header string) bool {
+	accepted := false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding == "gzip" {
+			return q > 0 // Takes precedence over *
+		}
+		accepted = q > 0
+	}
+	return accepted
+}
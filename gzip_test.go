@@ -0,0 +1,74 @@
+package sqld
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportGzip(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	req := QueryRequest{Select: []string{"id", "name"}}
+
+	// Compressed when the client accepts it
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b"))
+	r := httptest.NewRequest("GET", "/export", nil)
+	r.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	w := httptest.NewRecorder()
+	n, err := WriteNDJSON[BuilderTestModel](ctx, db, req, w, WithGzipAccepted(r))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n", string(body))
+
+	// Plain otherwise
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	r.Header.Set("Accept-Encoding", "gzip;q=0, *")
+	w = httptest.NewRecorder()
+	_, err = ExecuteCSV[BuilderTestModel](ctx, db, req, w, CSVOptions{}, WithGzipAccepted(r))
+	require.NoError(t, err)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Equal(t, "id,name\n1,a\n", w.Body.String())
+
+	// Failed queries leave the response uncompressed
+	_, err = WriteNDJSON[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"unknown"}}, w, WithGzip())
+	assert.Error(t, err)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, want := range map[string]bool{
+		"":                 false,
+		"gzip":             true,
+		"GZIP, deflate":    true,
+		"deflate, br":      false,
+		"*":                true,
+		"gzip;q=0":         false,
+		"gzip;q=0, *":      false,
+		"*;q=0.5":          true,
+		"br, gzip;q=0.001": true,
+	} {
+		assert.Equal(t, want, acceptsGzip(header), header)
+	}
+}
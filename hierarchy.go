@@ -0,0 +1,150 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Traversal directions accepted in TreeRequest.Direction.
+const (
+	Ancestors   = "ancestors"
+	Descendants = "descendants"
+)
+
+// MaxTreeDepth caps the depth of tree traversals. It also stops traversals
+// early when the data contains a cycle.
+const MaxTreeDepth = 100
+
+// treeCTE is the name of the recursive CTE generated for tree traversals.
+const treeCTE = "sqld_tree"
+
+// treeDepthColumn holds the distance of each row from the start node.
+const treeDepthColumn = "sqld_depth"
+
+// Hierarchy describes a parent_id style tree stored in a model's table.
+type Hierarchy struct {
+	IDField     string // JSON name of the node key
+	ParentField string // JSON name of the field referencing the parent node
+}
+
+// TreeRequest restricts a query to the ancestors or descendants of a node of
+// a model with a registered hierarchy.
+type TreeRequest struct {
+	// Node is the key of the node the traversal starts from.
+	Node interface{} `json:"node"`
+
+	// Direction is "ancestors" or "descendants".
+	Direction string `json:"direction"`
+
+	// MaxDepth limits the traversal to nodes at most this many levels away.
+	// Optional - 0 means MaxTreeDepth.
+	MaxDepth int `json:"max_depth,omitempty"`
+
+	// IncludeNode includes the start node itself in the results.
+	IncludeNode bool `json:"include_node,omitempty"`
+}
+
+// RegisterHierarchy declares the tree structure of model T in the default
+// registry, enabling QueryRequest.Tree for it.
+func RegisterHierarchy[T Model](h Hierarchy) error {
+	var model T
+	return defaultRegistry.RegisterHierarchy(model, h)
+}
+
+// RegisterHierarchy declares the tree structure of a registered model.
+func (r *Registry) RegisterHierarchy(model Model, h Hierarchy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(model)
+	metadata, ok := r.models[t]
+	if !ok {
+		return &ModelNotRegisteredError{Model: t.Name()}
+	}
+	if _, ok := metadata.Fields[h.IDField]; !ok {
+		return fmt.Errorf("invalid id field in hierarchy: %s", h.IDField)
+	}
+	if _, ok := metadata.Fields[h.ParentField]; !ok {
+		return fmt.Errorf("invalid parent field in hierarchy: %s", h.ParentField)
+	}
+	r.hierarchies[t] = h
+	return nil
+}
+
+// validate checks the tree request against the model metadata.
+func (tr *TreeRequest) validate(metadata ModelMetadata) error {
+	if metadata.Hierarchy == nil {
+		return fmt.Errorf("model %s has no registered hierarchy", metadata.TableName)
+	}
+	if tr.Node == nil {
+		return fmt.Errorf("tree node is required")
+	}
+	switch strings.ToLower(tr.Direction) {
+	case Ancestors, Descendants:
+	default:
+		return fmt.Errorf("invalid tree direction: %s", tr.Direction)
+	}
+	if tr.MaxDepth < 0 || tr.MaxDepth > MaxTreeDepth {
+		return fmt.Errorf("tree max depth must be between 0 and %d", MaxTreeDepth)
+	}
+	return nil
+}
+
+// applyTree replaces the FROM clause of query with a WITH RECURSIVE CTE
+// holding the nodes of the traversal. The CTE is aliased with the table name,
+// so columns, joins and conditions built for the aliased metadata apply
+// unchanged.
+func applyTree(query squirrel.SelectBuilder, metadata ModelMetadata, tr *TreeRequest) (squirrel.SelectBuilder, error) {
+	if tr == nil {
+		return query, nil
+	}
+	if err := tr.validate(metadata); err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+
+	columns := make([]string, 0, len(metadata.Fields))
+	for _, field := range metadata.Fields {
+		columns = append(columns, field.Name)
+	}
+	sort.Strings(columns)
+
+	id := metadata.Fields[metadata.Hierarchy.IDField].Name
+	parent := metadata.Fields[metadata.Hierarchy.ParentField].Name
+
+	// Descendants are the rows whose parent is a node found so far,
+	// ancestors are the parents of the nodes found so far.
+	childColumn, nodeColumn := parent, id
+	if strings.ToLower(tr.Direction) == Ancestors {
+		childColumn, nodeColumn = id, parent
+	}
+
+	maxDepth := tr.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = MaxTreeDepth
+	}
+
+	dialect := metadata.dialect()
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = "t." + column
+	}
+	cte := fmt.Sprintf("WITH RECURSIVE %[1]s AS ("+
+		"SELECT %[2]s, 0 AS %[3]s FROM %[4]s WHERE %[5]s = ? "+
+		"UNION ALL "+
+		"SELECT %[6]s, p.%[3]s + 1 FROM %[10]s JOIN %[11]s ON t.%[7]s = p.%[8]s WHERE p.%[3]s < %[9]d)",
+		treeCTE, strings.Join(columns, ", "), treeDepthColumn, metadata.TableName, id,
+		strings.Join(qualified, ", "), childColumn, nodeColumn, maxDepth,
+		tableAlias(dialect, metadata.TableName, "t"), tableAlias(dialect, treeCTE, "p"))
+
+	alias := metadata.aliased().TableName
+	query = query.Prefix(cte, tr.Node).
+		From(tableAlias(dialect, treeCTE, alias))
+	if !tr.IncludeNode {
+		query = query.Where(fmt.Sprintf("%s.%s > 0", alias, treeDepthColumn))
+	}
+	return query, nil
+}
@@ -0,0 +1,176 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TreeCategory struct {
+	ID       int64  `json:"id" db:"id"`
+	Name     string `json:"name" db:"name"`
+	ParentID *int64 `json:"parent_id" db:"parent_id"`
+}
+
+func (TreeCategory) TableName() string {
+	return "categories"
+}
+
+func TestRegisterHierarchy(t *testing.T) {
+	registry := NewRegistry()
+	assert.Error(t, registry.RegisterHierarchy(TreeCategory{}, Hierarchy{IDField: "id", ParentField: "parent_id"}),
+		"model must be registered first")
+
+	require.NoError(t, registry.Register(TreeCategory{}))
+	assert.Error(t, registry.RegisterHierarchy(TreeCategory{}, Hierarchy{IDField: "key", ParentField: "parent_id"}))
+	assert.Error(t, registry.RegisterHierarchy(TreeCategory{}, Hierarchy{IDField: "id", ParentField: "parent"}))
+
+	metadata, err := registry.GetModelMetadata(TreeCategory{})
+	require.NoError(t, err)
+	assert.Nil(t, metadata.Hierarchy)
+
+	require.NoError(t, registry.RegisterHierarchy(TreeCategory{}, Hierarchy{IDField: "id", ParentField: "parent_id"}))
+	metadata, err = registry.GetModelMetadata(TreeCategory{})
+	require.NoError(t, err)
+	assert.Equal(t, &Hierarchy{IDField: "id", ParentField: "parent_id"}, metadata.Hierarchy)
+}
+
+func TestBuildSelect_Tree(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(TreeCategory{}))
+	require.NoError(t, registry.RegisterHierarchy(TreeCategory{}, Hierarchy{IDField: "id", ParentField: "parent_id"}))
+	metadata, err := registry.GetModelMetadata(TreeCategory{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		tree     *TreeRequest
+		wantSQL  string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name: "descendants",
+			tree: &TreeRequest{Node: 1, Direction: Descendants, MaxDepth: 2},
+			wantSQL: "WITH RECURSIVE sqld_tree AS (SELECT id, name, parent_id, 0 AS sqld_depth FROM categories WHERE id = $1 " +
+				"UNION ALL SELECT t.id, t.name, t.parent_id, p.sqld_depth + 1 FROM categories AS t " +
+				"JOIN sqld_tree AS p ON t.parent_id = p.id WHERE p.sqld_depth < 2) " +
+				"SELECT id, name FROM sqld_tree AS categories WHERE categories.sqld_depth > 0 AND name = $2",
+			wantArgs: []interface{}{1, "books"},
+		},
+		{
+			name: "ancestors including the node",
+			tree: &TreeRequest{Node: 7, Direction: Ancestors, IncludeNode: true},
+			wantSQL: "WITH RECURSIVE sqld_tree AS (SELECT id, name, parent_id, 0 AS sqld_depth FROM categories WHERE id = $1 " +
+				"UNION ALL SELECT t.id, t.name, t.parent_id, p.sqld_depth + 1 FROM categories AS t " +
+				"JOIN sqld_tree AS p ON t.id = p.parent_id WHERE p.sqld_depth < 100) " +
+				"SELECT id, name FROM sqld_tree AS categories WHERE name = $2",
+			wantArgs: []interface{}{7, "books"},
+		},
+		{
+			name:    "invalid direction",
+			tree:    &TreeRequest{Node: 1, Direction: "siblings"},
+			wantErr: true,
+		},
+		{
+			name:    "missing node",
+			tree:    &TreeRequest{Direction: Descendants},
+			wantErr: true,
+		},
+		{
+			name:    "depth too large",
+			tree:    &TreeRequest{Node: 1, Direction: Descendants, MaxDepth: MaxTreeDepth + 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := QueryRequest{
+				Select: []string{"id", "name"},
+				Where:  map[string]interface{}{"name": "books"},
+				Tree:   tt.tree,
+			}
+			err := BasicValidator{}.ValidateQuery(req, metadata)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			query, err := buildSelect(metadata, req)
+			require.NoError(t, err)
+			sql, args, err := query.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, sql)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestBuildSelect_TreeWithSchema(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(TreeCategory{}, WithSchema("hr")))
+	require.NoError(t, registry.RegisterHierarchy(TreeCategory{}, Hierarchy{IDField: "id", ParentField: "parent_id"}))
+	metadata, err := registry.GetModelMetadata(TreeCategory{})
+	require.NoError(t, err)
+
+	// The CTE is aliased with the table alone, since "hr"."categories"
+	// isn't a valid alias
+	req := QueryRequest{Select: []string{"id"}, Tree: &TreeRequest{Node: 1, Direction: Descendants}}
+	query, err := buildSelect(metadata, req)
+	require.NoError(t, err)
+	sql, _, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `WITH RECURSIVE sqld_tree AS (SELECT id, name, parent_id, 0 AS sqld_depth FROM "hr"."categories" WHERE id = $1 `+
+		`UNION ALL SELECT t.id, t.name, t.parent_id, p.sqld_depth + 1 FROM "hr"."categories" AS t `+
+		`JOIN sqld_tree AS p ON t.parent_id = p.id WHERE p.sqld_depth < 100) `+
+		`SELECT id FROM sqld_tree AS "categories" WHERE "categories".sqld_depth > 0`, sql)
+
+	count, err := buildCount(metadata, req)
+	require.NoError(t, err)
+	sql, _, err = count.ToSql()
+	require.NoError(t, err)
+	assert.Contains(t, sql, `SELECT COUNT(*) FROM sqld_tree AS "categories" WHERE "categories".sqld_depth > 0`)
+}
+
+func TestBuildSelect_TreeWithoutHierarchy(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(TreeCategory{}))
+	metadata, err := registry.GetModelMetadata(TreeCategory{})
+	require.NoError(t, err)
+
+	req := QueryRequest{Select: []string{"id"}, Tree: &TreeRequest{Node: 1, Direction: Descendants}}
+	assert.Error(t, BasicValidator{}.ValidateQuery(req, metadata))
+	_, err = buildSelect(metadata, req)
+	assert.Error(t, err)
+}
+
+func TestExecute_Tree(t *testing.T) {
+	require.NoError(t, Register(TreeCategory{}))
+	require.NoError(t, RegisterHierarchy[TreeCategory](Hierarchy{IDField: "id", ParentField: "parent_id"}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`WITH RECURSIVE sqld_tree AS \(.*\) SELECT COUNT\(\*\) FROM sqld_tree AS categories WHERE categories.sqld_depth > 0`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`WITH RECURSIVE sqld_tree AS \(.*\) SELECT id, name FROM sqld_tree AS categories WHERE categories.sqld_depth > 0`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(2), "fiction").AddRow(int64(3), "poetry"))
+
+	resp, err := Execute[TreeCategory](context.Background(), db, QueryRequest{
+		Select:     []string{"id", "name"},
+		Tree:       &TreeRequest{Node: 1, Direction: Descendants},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 10},
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, 2, resp.Pagination.TotalItems)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,108 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Operations reported in MutationEvent.Operation.
+const (
+	OpInsert = "insert"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// MutationEvent describes a write passed to mutation hooks.
+type MutationEvent struct {
+	// Operation is OpInsert, OpUpdate or OpDelete. Patches are updates, and
+	// soft deletes are deletes even though they run an UPDATE.
+	Operation string
+
+	// Request is the request of the write: an InsertRequest,
+	// InsertManyRequest, UpdateRequest, PatchRequest or DeleteRequest.
+	Request interface{}
+
+	// SQL and Args are the statement about to run, or that ran.
+	SQL  string
+	Args []interface{}
+
+	// Response holds the outcome of the statement. It is only set for
+	// after hooks.
+	Response *MutationResponse
+}
+
+// MutationHook is called around the writes of a model. An error returned by
+// a before hook aborts the write. An error returned by an after hook is
+// returned to the caller, but the write has already happened.
+type MutationHook func(ctx context.Context, e MutationEvent) error
+
+// Hooks are the mutation hooks of a model, used to enforce invariants,
+// publish events or invalidate caches. Every hook is optional. InsertMany
+// calls the insert hooks once per statement.
+type Hooks struct {
+	BeforeInsert MutationHook
+	AfterInsert  MutationHook
+	BeforeUpdate MutationHook
+	AfterUpdate  MutationHook
+	BeforeDelete MutationHook
+	AfterDelete  MutationHook
+}
+
+// RegisterHooks sets the mutation hooks of model T in the default registry,
+// replacing any hooks set before.
+func RegisterHooks[T Model](h Hooks) error {
+	var model T
+	return defaultRegistry.RegisterHooks(model, h)
+}
+
+// RegisterHooks sets the mutation hooks of a registered model.
+func (r *Registry) RegisterHooks(model Model, h Hooks) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(model)
+	if _, ok := r.models[t]; !ok {
+		return &ModelNotRegisteredError{Model: t.Name()}
+	}
+	r.hooks[t] = h
+	return nil
+}
+
+// runBefore calls the before hook of e.Operation, if any.
+func (h *Hooks) runBefore(ctx context.Context, e MutationEvent) error {
+	if h == nil {
+		return nil
+	}
+	hook := map[string]MutationHook{
+		OpInsert: h.BeforeInsert,
+		OpUpdate: h.BeforeUpdate,
+		OpDelete: h.BeforeDelete,
+	}[e.Operation]
+	if hook == nil {
+		return nil
+	}
+	if err := hook(ctx, e); err != nil {
+		return fmt.Errorf("before %s hook: %w", e.Operation, err)
+	}
+	return nil
+}
+
+// runAfter calls the after hook of e.Operation, if any.
+func (h *Hooks) runAfter(ctx context.Context, e MutationEvent) error {
+	if h == nil {
+		return nil
+	}
+	hook := map[string]MutationHook{
+		OpInsert: h.AfterInsert,
+		OpUpdate: h.AfterUpdate,
+		OpDelete: h.AfterDelete,
+	}[e.Operation]
+	if hook == nil {
+		return nil
+	}
+	if err := hook(ctx, e); err != nil {
+		return fmt.Errorf("after %s hook: %w", e.Operation, err)
+	}
+	return nil
+}
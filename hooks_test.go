@@ -0,0 +1,122 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type HookedTask struct {
+	ID    int64  `json:"id" db:"id" sqld:"readonly"`
+	Title string `json:"title" db:"title"`
+}
+
+func (HookedTask) TableName() string {
+	return "tasks"
+}
+
+func TestRegisterHooks_Unregistered(t *testing.T) {
+	registry := NewRegistry()
+	assert.Error(t, registry.RegisterHooks(HookedTask{}, Hooks{}))
+}
+
+func TestMutationHooks(t *testing.T) {
+	require.NoError(t, Register(HookedTask{}))
+
+	var events []MutationEvent
+	record := func(_ context.Context, e MutationEvent) error {
+		events = append(events, e)
+		return nil
+	}
+	require.NoError(t, RegisterHooks[HookedTask](Hooks{
+		BeforeInsert: record,
+		AfterInsert:  record,
+		BeforeUpdate: record,
+		AfterDelete:  record,
+	}))
+	t.Cleanup(func() { require.NoError(t, RegisterHooks[HookedTask](Hooks{})) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO tasks`).WithArgs("write docs").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`UPDATE tasks`).WithArgs("review", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`DELETE FROM tasks`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	ctx := context.Background()
+	insert := InsertRequest{Values: map[string]interface{}{"title": "write docs"}, Returning: []string{"id"}}
+	_, err = Insert[HookedTask](ctx, db, insert)
+	require.NoError(t, err)
+	patch := PatchRequest{Patch: json.RawMessage(`{"title": "review"}`), Where: map[string]interface{}{"id": 1}, Returning: []string{"id"}}
+	_, err = Patch[HookedTask](ctx, db, patch)
+	require.NoError(t, err)
+	del := DeleteRequest{Where: map[string]interface{}{"id": 1}, Returning: []string{"id"}}
+	_, err = Delete[HookedTask](ctx, db, del)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, events, 4)
+	assert.Equal(t, OpInsert, events[0].Operation)
+	assert.Equal(t, insert, events[0].Request)
+	assert.Equal(t, "INSERT INTO tasks (title) VALUES ($1) RETURNING id", events[0].SQL)
+	assert.Equal(t, []interface{}{"write docs"}, events[0].Args)
+	assert.Nil(t, events[0].Response)
+	require.NotNil(t, events[1].Response)
+	assert.Equal(t, []QueryResult{{"id": int64(1)}}, events[1].Response.Data)
+	assert.Equal(t, OpUpdate, events[2].Operation)
+	assert.Equal(t, patch, events[2].Request)
+	assert.Equal(t, OpDelete, events[3].Operation)
+	assert.Equal(t, int64(1), events[3].Response.RowsAffected)
+}
+
+func TestMutationHooks_BeforeAborts(t *testing.T) {
+	require.NoError(t, Register(HookedTask{}))
+	require.NoError(t, RegisterHooks[HookedTask](Hooks{
+		BeforeDelete: func(context.Context, MutationEvent) error {
+			return errors.New("tasks are archived, not deleted")
+		},
+	}))
+	t.Cleanup(func() { require.NoError(t, RegisterHooks[HookedTask](Hooks{})) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = Delete[HookedTask](context.Background(), db, DeleteRequest{Where: map[string]interface{}{"id": 1}})
+	assert.ErrorContains(t, err, "before delete hook: tasks are archived")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMutationHooks_AfterError(t *testing.T) {
+	require.NoError(t, Register(HookedTask{}))
+	require.NoError(t, RegisterHooks[HookedTask](Hooks{
+		AfterInsert: func(context.Context, MutationEvent) error {
+			return errors.New("publish failed")
+		},
+	}))
+	t.Cleanup(func() { require.NoError(t, RegisterHooks[HookedTask](Hooks{})) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO tasks \(title\) VALUES \(\$1\),\(\$2\)`).
+		WithArgs("a", "b").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	resp, err := InsertMany[HookedTask](context.Background(), db, InsertManyRequest{
+		Rows: []map[string]interface{}{{"title": "a"}, {"title": "b"}},
+	})
+	assert.ErrorContains(t, err, "after insert hook: publish failed")
+	assert.Equal(t, int64(2), resp.RowsAffected)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,121 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isHstoreType reports whether fields of type t hold hstore columns:
+// map[string]string and map[string]*string, or pointers to them.
+func isHstoreType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.String
+}
+
+// hstoreValue returns val, the value of an hstore field, as a
+// map[string]interface{} of strings, nil for NULL values. database/sql
+// drivers return hstores as text such as "a"=>"1", "b"=>NULL, which is
+// parsed; pgx returns them decoded when the type is registered with it. Text
+// holding a JSON object is returned unchanged, see jsonValue.
+func hstoreValue(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case rawValue:
+		return hstoreValue(v.value)
+	case []byte:
+		return hstoreValue(string(v))
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "{") {
+			return val, nil
+		}
+		return parseHstore(v)
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return val, nil
+	}
+	values := make(map[string]interface{}, rv.Len())
+	for iter := rv.MapRange(); iter.Next(); {
+		value := iter.Value()
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				values[iter.Key().String()] = nil
+				continue
+			}
+			value = value.Elem()
+		}
+		values[iter.Key().String()] = value.Interface()
+	}
+	return values, nil
+}
+
+// parseHstore parses the text of an hstore, as Postgres writes it.
+func parseHstore(s string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		key, after, ok := cutHstoreString(rest)
+		if !ok {
+			return nil, fmt.Errorf("invalid hstore %q", s)
+		}
+		after, ok = strings.CutPrefix(strings.TrimSpace(after), "=>")
+		if !ok {
+			return nil, fmt.Errorf("invalid hstore %q", s)
+		}
+		after = strings.TrimSpace(after)
+		if null, ok := strings.CutPrefix(after, "NULL"); ok {
+			values[key] = nil
+			after = null
+		} else {
+			var value string
+			if value, after, ok = cutHstoreString(after); !ok {
+				return nil, fmt.Errorf("invalid hstore %q", s)
+			}
+			values[key] = value
+		}
+
+		after = strings.TrimSpace(after)
+		if after == "" {
+			break
+		}
+		if rest, ok = strings.CutPrefix(after, ","); !ok {
+			return nil, fmt.Errorf("invalid hstore %q", s)
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	return values, nil
+}
+
+// cutHstoreString returns the double-quoted string s starts with, without
+// its quotes and escapes, and the text after it.
+func cutHstoreString(s string) (value, after string, ok bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", false
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i++; i < len(s) {
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,162 @@
+package sqld
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Join types accepted in JoinClause.Type.
+const (
+	InnerJoin = "inner"
+	LeftJoin  = "left"
+)
+
+// JoinClause joins another registered model into a query without a
+// registered relation. Once joined, fields of the other model can be used as
+// "<model>.<field>" in Select, Where and OrderBy, exactly like relation fields.
+type JoinClause struct {
+	// Model is the table name of the registered model to join, unquoted and
+	// optionally qualified with its schema, e.g. employees or hr.employees.
+	Model string `json:"model"`
+
+	// Alias is the name the joined model is referred to by, defaulting to
+	// Model. It is required when a model is joined to itself, e.g. "manager"
+	// to select "manager.first_name" alongside employee fields.
+	Alias string `json:"alias,omitempty"`
+
+	// Type is "inner" (default) or "left".
+	Type string `json:"type,omitempty"`
+
+	// On lists the fields joined on.
+	On JoinOn `json:"on"`
+}
+
+// JoinOn is the equality condition of a JoinClause.
+type JoinOn struct {
+	Left  string `json:"left"`  // JSON field name on the queried model
+	Right string `json:"right"` // JSON field name on the joined model
+}
+
+// alias returns the name the joined table is referred to by.
+func (j JoinClause) alias() string {
+	if j.Alias != "" {
+		return j.Alias
+	}
+	return j.Model
+}
+
+// sqlJoinType returns the SQL keyword for the join type.
+func (j JoinClause) sqlJoinType() (string, error) {
+	switch strings.ToLower(j.Type) {
+	case "", InnerJoin:
+		return "JOIN", nil
+	case LeftJoin:
+		return "LEFT JOIN", nil
+	default:
+		return "", fmt.Errorf("invalid join type: %s", j.Type)
+	}
+}
+
+// resolveJoins returns a copy of metadata in which each explicit join of
+// joins is available as a relation, so that joined fields resolve like
+// relation fields during validation and query building.
+func (r *Registry) resolveJoins(metadata ModelMetadata, joins []JoinClause) (ModelMetadata, error) {
+	if len(joins) == 0 {
+		return metadata, nil
+	}
+
+	relations := make(map[string]Relation, len(metadata.Relations)+len(joins))
+	for name, rel := range metadata.Relations {
+		relations[name] = rel
+	}
+
+	for _, join := range joins {
+		joinType, err := join.sqlJoinType()
+		if err != nil {
+			return ModelMetadata{}, err
+		}
+		target, err := r.modelByTable(join.Model)
+		if err != nil {
+			return ModelMetadata{}, fmt.Errorf("invalid model in join: %w", err)
+		}
+
+		alias := join.alias()
+		if !identRegex.MatchString(alias) || strings.Contains(alias, ".") {
+			return ModelMetadata{}, fmt.Errorf("invalid join alias: %s", alias)
+		}
+		if metadata.hasTable(alias, metadata.dialect()) {
+			return ModelMetadata{}, fmt.Errorf("join of %s to itself requires an alias", alias)
+		}
+		if _, exists := relations[alias]; exists {
+			return ModelMetadata{}, fmt.Errorf("join %s clashes with a relation or another join", alias)
+		}
+		if _, exists := metadata.Fields[alias]; exists {
+			return ModelMetadata{}, fmt.Errorf("join %s clashes with a field", alias)
+		}
+		if _, ok := metadata.Fields[join.On.Left]; !ok {
+			return ModelMetadata{}, &UnknownFieldError{Field: join.On.Left, In: "join condition"}
+		}
+		if _, ok := target.Fields[join.On.Right]; !ok {
+			return ModelMetadata{}, &UnknownFieldError{Field: alias + "." + join.On.Right, In: "join condition"}
+		}
+
+		relations[alias] = Relation{
+			Kind:         BelongsTo,
+			LocalField:   join.On.Left,
+			ForeignField: join.On.Right,
+			target:       target,
+			joinType:     joinType,
+		}
+	}
+
+	metadata.Relations = relations
+	return metadata, nil
+}
+
+// modelByTable returns the metadata of the registered model backed by table.
+func (r *Registry) modelByTable(table string) (ModelMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dialect := r.dialect
+	if dialect == nil {
+		dialect = Postgres
+	}
+	var found []ModelMetadata
+	for t, metadata := range r.models {
+		if metadata.hasTable(table, dialect) {
+			metadata.SoftDelete = r.softDeleteFor(t)
+			found = append(found, metadata)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return ModelMetadata{}, fmt.Errorf("no model registered for table %s", table)
+	case 1:
+		return found[0], nil
+	default:
+		return ModelMetadata{}, fmt.Errorf("several models registered for table %s", table)
+	}
+}
+
+// hasTable reports whether metadata is backed by table, an unquoted table name
+// optionally qualified with its schema. Tables set with WithTable or
+// WithSchema are quoted in TableName, so their unquoted parts are compared,
+// folded like dialect folds them.
+func (m ModelMetadata) hasTable(table string, dialect Dialect) bool {
+	if m.tableIdent == nil {
+		return m.TableName == table
+	}
+	parts := strings.Split(table, ".")
+	if folder, folds := dialect.(identFoldingDialect); folds {
+		for i, part := range parts {
+			parts[i] = folder.FoldIdent(part)
+		}
+	}
+	ident := m.tableIdent
+	if len(parts) == 1 {
+		// An unqualified name matches the table in any schema
+		ident = ident[len(ident)-1:]
+	}
+	return strings.Join(parts, ".") == strings.Join(ident, ".")
+}
@@ -0,0 +1,227 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSelect_ExplicitJoins(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(RelEmployee{}))
+	require.NoError(t, registry.Register(RelDepartment{}))
+
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		req     QueryRequest
+		wantSQL string
+		wantErr bool
+	}{
+		{
+			name: "inner join by default",
+			req: QueryRequest{
+				Select: []string{"name", "departments.name"},
+				Joins:  []JoinClause{{Model: "departments", On: JoinOn{Left: "department_id", Right: "id"}}},
+				Where:  map[string]interface{}{"departments.name": "eng"},
+			},
+			wantSQL: `SELECT employees.name, departments.name AS "departments.name" FROM employees ` +
+				`JOIN departments AS departments ON departments.id = employees.department_id WHERE departments.name = $1`,
+		},
+		{
+			name: "left join applied even when no joined field is used",
+			req: QueryRequest{
+				Select: []string{"name"},
+				Joins:  []JoinClause{{Model: "departments", Type: LeftJoin, On: JoinOn{Left: "department_id", Right: "id"}}},
+			},
+			wantSQL: `SELECT employees.name FROM employees LEFT JOIN departments AS departments ON departments.id = employees.department_id`,
+		},
+		{
+			name: "unknown model",
+			req: QueryRequest{
+				Select: []string{"name"},
+				Joins:  []JoinClause{{Model: "teams", On: JoinOn{Left: "department_id", Right: "id"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown left field",
+			req: QueryRequest{
+				Select: []string{"name"},
+				Joins:  []JoinClause{{Model: "departments", On: JoinOn{Left: "dept", Right: "id"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown right field",
+			req: QueryRequest{
+				Select: []string{"name"},
+				Joins:  []JoinClause{{Model: "departments", On: JoinOn{Left: "department_id", Right: "code"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid join type",
+			req: QueryRequest{
+				Select: []string{"name"},
+				Joins:  []JoinClause{{Model: "departments", Type: "cross", On: JoinOn{Left: "department_id", Right: "id"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "same model joined twice",
+			req: QueryRequest{
+				Select: []string{"name"},
+				Joins: []JoinClause{
+					{Model: "departments", On: JoinOn{Left: "department_id", Right: "id"}},
+					{Model: "departments", On: JoinOn{Left: "department_id", Right: "id"}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := registry.resolveJoins(employees, tt.req.Joins)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, BasicValidator{}.ValidateQuery(tt.req, metadata))
+
+			query, err := buildSelect(metadata, tt.req)
+			require.NoError(t, err)
+			sql, _, err := query.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, sql)
+		})
+	}
+
+	assert.Nil(t, employees.Relations, "resolving joins must not modify the registered metadata")
+}
+
+func TestExecute_ExplicitJoin(t *testing.T) {
+	require.NoError(t, Register(RelEmployee{}))
+	require.NoError(t, Register(RelDepartment{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM employees JOIN departments AS departments ON departments.id = employees.department_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT employees.name, departments.name AS "departments.name" FROM employees JOIN departments`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "departments.name"}).AddRow("alice", "eng"))
+
+	resp, err := Execute[RelEmployee](context.Background(), db, QueryRequest{
+		Select:     []string{"name", "departments.name"},
+		Joins:      []JoinClause{{Model: "departments", On: JoinOn{Left: "department_id", Right: "id"}}},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"name": "alice", "departments.name": "eng"}}, resp.Data)
+	assert.Equal(t, 1, resp.Pagination.TotalItems)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildSelect_JoinQualifiedTable(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(RelEmployee{}))
+	require.NoError(t, registry.Register(RelDepartment{}, WithSchema("hr")))
+
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	for _, model := range []string{"departments", "hr.departments"} {
+		t.Run(model, func(t *testing.T) {
+			req := QueryRequest{
+				Select: []string{"name", "department.name"},
+				Joins:  []JoinClause{{Model: model, Alias: "department", On: JoinOn{Left: "department_id", Right: "id"}}},
+			}
+			metadata, err := registry.resolveJoins(employees, req.Joins)
+			require.NoError(t, err)
+
+			query, err := buildSelect(metadata, req)
+			require.NoError(t, err)
+			sql, _, err := query.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, `SELECT employees.name, department.name AS "department.name" FROM employees `+
+				`JOIN "hr"."departments" AS department ON department.id = employees.department_id`, sql)
+		})
+	}
+
+	_, err = registry.resolveJoins(employees, []JoinClause{{Model: "sales.departments", Alias: "department", On: JoinOn{Left: "department_id", Right: "id"}}})
+	assert.Error(t, err, "the schema must match")
+}
+
+type StaffMember struct {
+	ID        int64  `json:"id" db:"id"`
+	FirstName string `json:"first_name" db:"first_name"`
+	ManagerID int64  `json:"manager_id" db:"manager_id"`
+}
+
+func (StaffMember) TableName() string {
+	return "staff"
+}
+
+func TestBuildSelect_SelfJoin(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(StaffMember{}))
+
+	staff, err := registry.GetModelMetadata(StaffMember{})
+	require.NoError(t, err)
+
+	_, err = registry.resolveJoins(staff, []JoinClause{{Model: "staff", On: JoinOn{Left: "manager_id", Right: "id"}}})
+	assert.Error(t, err, "joining a model to itself requires an alias")
+	_, err = registry.resolveJoins(staff, []JoinClause{{Model: "staff", Alias: "bad alias", On: JoinOn{Left: "manager_id", Right: "id"}}})
+	assert.Error(t, err)
+
+	req := QueryRequest{
+		Select:  []string{"first_name", "manager.first_name"},
+		Joins:   []JoinClause{{Model: "staff", Alias: "manager", Type: LeftJoin, On: JoinOn{Left: "manager_id", Right: "id"}}},
+		Where:   map[string]interface{}{"manager.first_name": "alice"},
+		OrderBy: []OrderByClause{{Field: "manager.first_name"}},
+	}
+	metadata, err := registry.resolveJoins(staff, req.Joins)
+	require.NoError(t, err)
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+
+	query, err := buildSelect(metadata, req)
+	require.NoError(t, err)
+	sql, args, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT staff.first_name, manager.first_name AS "manager.first_name" FROM staff `+
+		`LEFT JOIN staff AS manager ON manager.id = staff.manager_id WHERE manager.first_name = $1 ORDER BY manager.first_name ASC`, sql)
+	assert.Equal(t, []interface{}{"alice"}, args)
+}
+
+func TestRegisterRelation_SelfRelation(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(StaffMember{}))
+
+	assert.Error(t, registry.RegisterRelation(StaffMember{}, StaffMember{}, "staff", Relation{
+		Kind: BelongsTo, LocalField: "manager_id", ForeignField: "id",
+	}), "relation named like the table is ambiguous")
+	require.NoError(t, registry.RegisterRelation(StaffMember{}, StaffMember{}, "manager", Relation{
+		Kind: BelongsTo, LocalField: "manager_id", ForeignField: "id",
+	}))
+
+	metadata, err := registry.GetModelMetadata(StaffMember{})
+	require.NoError(t, err)
+	req := QueryRequest{Select: []string{"id", "manager.first_name"}}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+
+	query, err := buildSelect(metadata, req)
+	require.NoError(t, err)
+	sql, _, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT staff.id, manager.first_name AS "manager.first_name" FROM staff `+
+		`LEFT JOIN staff AS manager ON manager.id = staff.manager_id`, sql)
+}
@@ -0,0 +1,59 @@
+package sqld
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// isJSONType reports whether fields of type t may hold json or jsonb
+// columns: json.RawMessage, maps and slices other than byte slices, or
+// pointers to them.
+func isJSONType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeOf(json.RawMessage{}):
+		return true
+	case t.Kind() == reflect.Map:
+		return true
+	case t.Kind() == reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// jsonValue returns val, the value of a JSON field read as text, as the
+// nested objects and arrays it encodes, numbers being json.Number so that
+// none loses precision. With raw it returns the text as a json.RawMessage
+// instead, embedded as is in encoded responses. Values the driver already
+// decoded and text that isn't JSON, such as Postgres array literals, are
+// returned unchanged.
+func jsonValue(val interface{}, raw bool) interface{} {
+	var text []byte
+	switch v := val.(type) {
+	case json.RawMessage:
+		text = v
+	case []byte:
+		text = v
+	case string:
+		text = []byte(v)
+	default:
+		return val
+	}
+	if !json.Valid(text) {
+		return val
+	}
+	if raw {
+		return json.RawMessage(bytes.Clone(text))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(text))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return val
+	}
+	return decoded
+}
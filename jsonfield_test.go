@@ -0,0 +1,73 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type JSONProfile struct {
+	ID       int64                  `json:"id" db:"id"`
+	Settings map[string]interface{} `json:"settings" db:"settings"`
+	Tags     []string               `json:"tags" db:"tags"`
+	Document json.RawMessage        `json:"document" db:"document"`
+}
+
+func (JSONProfile) TableName() string { return "profiles" }
+
+func TestExecute_JSONFields(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []RegisterOption
+		want QueryResult
+	}{
+		{
+			name: "decoded",
+			want: QueryResult{
+				"id":       int64(1),
+				"settings": map[string]interface{}{"theme": "dark", "limit": json.Number("12345678901234567890")},
+				"tags":     []interface{}{"a", "b"},
+				"document": []interface{}{true, nil},
+			},
+		},
+		{
+			name: "raw",
+			opts: []RegisterOption{WithRawJSONFields("settings", "document")},
+			want: QueryResult{
+				"id":       int64(1),
+				"settings": json.RawMessage(`{"theme": "dark", "limit": 12345678901234567890}`),
+				"tags":     []interface{}{"a", "b"},
+				"document": json.RawMessage(`[true, null]`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			require.NoError(t, registry.Register(JSONProfile{}, tt.opts...))
+			ctx := WithRegistry(context.Background(), registry)
+
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Tags is a text[] column, see TestExecute_Arrays
+			mock.ExpectQuery(`SELECT id, settings, tags, document FROM profiles`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "settings", "tags", "document"}).
+					AddRow(int64(1), []byte(`{"theme": "dark", "limit": 12345678901234567890}`), "{a,b}", `[true, null]`))
+			resp, err := Execute[JSONProfile](ctx, db, QueryRequest{Select: []string{"id", "settings", "tags", "document"}})
+			require.NoError(t, err)
+			assert.Equal(t, []QueryResult{tt.want}, resp.Data)
+		})
+	}
+}
+
+func TestWithRawJSONFields_NotJSON(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.Register(JSONProfile{}, WithRawJSONFields("id"))
+	assert.ErrorContains(t, err, "field id of type int64 can't hold JSON")
+}
@@ -0,0 +1,218 @@
+package sqld
+
+import (
+	"reflect"
+	"sort"
+)
+
+// JSONSchema is a JSON Schema document (draft 2020-12). It encodes to JSON
+// with encoding/json.
+type JSONSchema map[string]interface{}
+
+// jsonSchemaDialect is the $schema of the generated documents.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// decimalPattern matches the strings of decimal fields.
+const decimalPattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+// RequestSchema returns the JSON Schema of the QueryRequests accepted for
+// model T by the default registry, so that API consumers can validate
+// requests client-side and generate typed clients. It lists the fields that
+// can be selected, filtered and ordered by, the values accepted in where
+// conditions and the pagination settings. Other request properties are
+// allowed without being described.
+func RequestSchema[T Model]() (JSONSchema, error) {
+	var model T
+	return defaultRegistry.RequestSchema(model)
+}
+
+// ResponseSchema returns the JSON Schema of the QueryResponses of model T,
+// as registered with the default registry.
+func ResponseSchema[T Model]() (JSONSchema, error) {
+	var model T
+	return defaultRegistry.ResponseSchema(model)
+}
+
+// RequestSchema returns the JSON Schema of the QueryRequests accepted for
+// model, see the package-level RequestSchema.
+func (r *Registry) RequestSchema(model Model) (JSONSchema, error) {
+	metadata, err := r.GetModelMetadata(model)
+	if err != nil {
+		return nil, err
+	}
+	fields := r.schemaFields(metadata)
+
+	var selectable, filterable []interface{}
+	where := make(map[string]interface{})
+	for _, f := range fields {
+		if f.selectable {
+			selectable = append(selectable, f.key)
+		}
+		if !f.filterable {
+			continue
+		}
+		filterable = append(filterable, f.key)
+		value := f.valueSchema()
+		if f.enum != nil {
+			where[f.key] = value // Enum conditions take a single member
+			continue
+		}
+		where[f.key] = JSONSchema{"oneOf": []interface{}{value, JSONSchema{"type": "array", "items": value}}}
+	}
+
+	schema := JSONSchema{
+		"$schema": jsonSchemaDialect,
+		"title":   reflect.TypeOf(model).Name() + " query request",
+		"type":    "object",
+		"properties": JSONSchema{
+			"select": JSONSchema{
+				"type":        "array",
+				"items":       JSONSchema{"enum": selectable},
+				"uniqueItems": true,
+			},
+			"where": JSONSchema{
+				"type":                 "object",
+				"properties":           where,
+				"additionalProperties": false,
+			},
+			"order_by": JSONSchema{
+				"type": "array",
+				"items": JSONSchema{
+					"type": "object",
+					"properties": JSONSchema{
+						"field": JSONSchema{"enum": filterable},
+						"desc":  JSONSchema{"type": "boolean"},
+					},
+					"required":             []string{"field"},
+					"additionalProperties": false,
+				},
+			},
+			"limit":  JSONSchema{"type": "integer", "minimum": 0},
+			"offset": JSONSchema{"type": "integer", "minimum": 0},
+			"pagination": JSONSchema{
+				"type": "object",
+				"properties": JSONSchema{
+					"page":      JSONSchema{"type": "integer", "minimum": 1},
+					"page_size": JSONSchema{"type": "integer", "minimum": 1, "maximum": MaxPageSize},
+				},
+				"additionalProperties": false,
+			},
+		},
+	}
+	if len(metadata.DefaultSelect) == 0 {
+		schema["required"] = []string{"select"}
+	}
+	return schema, nil
+}
+
+// ResponseSchema returns the JSON Schema of the QueryResponses of model, see
+// the package-level ResponseSchema.
+func (r *Registry) ResponseSchema(model Model) (JSONSchema, error) {
+	metadata, err := r.GetModelMetadata(model)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	millis := r.timeOutput != nil && r.timeOutput.Format == TimeFormatEpochMillis
+	r.mu.RUnlock()
+
+	row := make(map[string]interface{})
+	for _, f := range r.schemaFields(metadata) {
+		f.millis = millis
+		if f.selectable {
+			row[f.key] = f.valueSchema()
+		}
+	}
+
+	return JSONSchema{
+		"$schema": jsonSchemaDialect,
+		"title":   reflect.TypeOf(model).Name() + " query response",
+		"type":    "object",
+		"properties": JSONSchema{
+			"data": JSONSchema{
+				"type": "array",
+				"items": JSONSchema{
+					"type":                 "object",
+					"properties":           row,
+					"additionalProperties": false,
+				},
+			},
+			"pagination": JSONSchema{
+				"type": "object",
+				"properties": JSONSchema{
+					"page":        JSONSchema{"type": "integer"},
+					"page_size":   JSONSchema{"type": "integer"},
+					"total_items": JSONSchema{"type": "integer"},
+					"total_pages": JSONSchema{"type": "integer"},
+				},
+			},
+			"error":    JSONSchema{"type": "string"},
+			"warnings": JSONSchema{"type": "array", "items": JSONSchema{"type": "string"}},
+			"summary":  JSONSchema{"type": "object"},
+		},
+		"required": []string{"data"},
+	}, nil
+}
+
+// schemaField is a field described in JSON Schemas: a field of the model or,
+// keyed "<relation>.<key>", of a related model.
+type schemaField struct {
+	key        string
+	field      Field
+	selectable bool
+	filterable bool
+	enum       []interface{}
+	millis     bool // Times of results are epoch milliseconds, see SetTimeOutput
+}
+
+// valueSchema returns the schema of the values of the field.
+func (f schemaField) valueSchema() JSONSchema {
+	schema := JSONSchema{}
+	switch typ := FieldType(f.field.Type); typ {
+	case "datetime":
+		if f.millis {
+			schema["type"] = "integer"
+			break
+		}
+		schema["type"] = "string"
+		schema["format"] = "date-time"
+	case "string":
+		schema["type"] = typ
+		if isUUIDType(f.field.Type) {
+			schema["format"] = "uuid"
+		}
+	case "any":
+	default:
+		schema["type"] = typ
+	}
+	if f.enum != nil {
+		schema = JSONSchema{"enum": f.enum}
+	}
+	if typ, ok := schema["type"]; ok && isNullableType(f.field.Type) {
+		schema["type"] = []interface{}{typ, "null"}
+	}
+	return schema
+}
+
+// schemaFields returns the fields of metadata and of its related models,
+// sorted by key.
+func (r *Registry) schemaFields(metadata ModelMetadata) []schemaField {
+	var fields []schemaField
+	add := func(key string, field Field) {
+		f := schemaField{key: key, field: field, selectable: !field.FilterOnly, filterable: !field.SelectOnly}
+		if field.Enum != "" {
+			f.enum, _ = r.GetEnum(field.Enum)
+		}
+		fields = append(fields, f)
+	}
+	for key, field := range metadata.Fields {
+		add(key, field)
+	}
+	for name, rel := range metadata.Relations {
+		for key, field := range rel.target.Fields {
+			add(name+"."+key, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+	return fields
+}
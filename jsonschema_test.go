@@ -0,0 +1,65 @@
+package sqld
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SupportTicket struct {
+	ID       int64      `json:"id" db:"id"`
+	Status   string     `json:"status" db:"status" enum:"TICKET_STATUS"`
+	ClosedAt *time.Time `json:"closed_at" db:"closed_at"`
+	Notes    string     `json:"notes" db:"notes"`
+}
+
+func (SupportTicket) TableName() string { return "support_tickets" }
+
+func TestRequestSchema(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterEnum("TICKET_STATUS", "open", "closed")
+	require.NoError(t, registry.Register(SupportTicket{}, WithSelectOnlyFields("notes")))
+
+	schema, err := registry.RequestSchema(SupportTicket{})
+	require.NoError(t, err)
+	assert.Equal(t, "SupportTicket query request", schema["title"])
+	assert.Equal(t, []string{"select"}, schema["required"])
+
+	properties := schema["properties"].(JSONSchema)
+	assert.Equal(t, []interface{}{"closed_at", "id", "notes", "status"}, properties["select"].(JSONSchema)["items"].(JSONSchema)["enum"])
+
+	where := properties["where"].(JSONSchema)["properties"].(map[string]interface{})
+	assert.NotContains(t, where, "notes")
+	assert.Equal(t, JSONSchema{"enum": []interface{}{"open", "closed"}}, where["status"])
+	closedAt := JSONSchema{"type": []interface{}{"string", "null"}, "format": "date-time"}
+	assert.Equal(t, JSONSchema{"oneOf": []interface{}{closedAt, JSONSchema{"type": "array", "items": closedAt}}}, where["closed_at"])
+
+	orderBy := properties["order_by"].(JSONSchema)["items"].(JSONSchema)["properties"].(JSONSchema)
+	assert.Equal(t, []interface{}{"closed_at", "id", "status"}, orderBy["field"].(JSONSchema)["enum"])
+
+	_, err = json.Marshal(schema)
+	assert.NoError(t, err)
+
+	require.NoError(t, registry.Register(SupportTicket{}, WithDefaultSelect("id")))
+	schema, err = registry.RequestSchema(SupportTicket{})
+	require.NoError(t, err)
+	assert.NotContains(t, schema, "required")
+
+	_, err = registry.RequestSchema(JobCategory{})
+	assert.ErrorContains(t, err, "model JobCategory not registered")
+}
+
+func TestResponseSchema(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+
+	schema, err := registry.ResponseSchema(RelEmployee{})
+	require.NoError(t, err)
+	row := schema["properties"].(JSONSchema)["data"].(JSONSchema)["items"].(JSONSchema)["properties"].(map[string]interface{})
+	assert.Equal(t, JSONSchema{"type": "integer"}, row["id"])
+	assert.Equal(t, JSONSchema{"type": "string"}, row["department.name"])
+	assert.Contains(t, row, "projects.title")
+}
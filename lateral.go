@@ -0,0 +1,116 @@
+package sqld
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// LateralJoin joins the top rows of a has-many relation to each row, using
+// LEFT JOIN LATERAL. It expresses top-N-per-group queries such as "each
+// department with its 3 highest-paid employees". Fields of the relation are
+// then referenced as "<relation>.<field>" like any related field, and each
+// row of the model is repeated once per joined row.
+type LateralJoin struct {
+	// Relation is the name of a registered has-many relation.
+	Relation string `json:"relation"`
+
+	// Where filters the related rows. Keys are fields of the related model.
+	Where map[string]interface{} `json:"where,omitempty"`
+
+	// OrderBy picks which related rows come first. Fields are fields of the
+	// related model.
+	OrderBy []OrderByClause `json:"order_by,omitempty"`
+
+	// Limit is the number of related rows joined to each row.
+	Limit int `json:"limit"`
+}
+
+// resolveRequest returns a copy of metadata with the explicit joins and
+// lateral joins of req resolved into relations.
+func (r *Registry) resolveRequest(metadata ModelMetadata, req QueryRequest) (ModelMetadata, error) {
+	metadata, err := r.resolveJoins(metadata, req.Joins)
+	if err != nil {
+		return ModelMetadata{}, err
+	}
+	return resolveLaterals(metadata, req.Lateral)
+}
+
+// resolveLaterals validates laterals and returns a copy of metadata in which
+// the relation of each lateral join is marked as such.
+func resolveLaterals(metadata ModelMetadata, laterals []LateralJoin) (ModelMetadata, error) {
+	if len(laterals) == 0 {
+		return metadata, nil
+	}
+
+	relations := make(map[string]Relation, len(metadata.Relations))
+	for name, rel := range metadata.Relations {
+		relations[name] = rel
+	}
+
+	for i := range laterals {
+		lateral := laterals[i]
+		rel, ok := relations[lateral.Relation]
+		if !ok {
+			return ModelMetadata{}, fmt.Errorf("invalid relation in lateral join: %s", lateral.Relation)
+		}
+		if rel.Kind != HasMany || rel.joinType != "" {
+			return ModelMetadata{}, fmt.Errorf("lateral join %s requires a has-many relation", lateral.Relation)
+		}
+		if rel.lateral != nil {
+			return ModelMetadata{}, fmt.Errorf("duplicate lateral join: %s", lateral.Relation)
+		}
+		if lateral.Limit <= 0 {
+			return ModelMetadata{}, fmt.Errorf("lateral join %s requires a positive limit", lateral.Relation)
+		}
+		for field := range lateral.Where {
+			f, ok := rel.target.Fields[field]
+			if !ok {
+				return ModelMetadata{}, &UnknownFieldError{Field: lateral.Relation + "." + field, In: "lateral join where clause"}
+			}
+			if err := f.checkFilterable(lateral.Relation + "." + field); err != nil {
+				return ModelMetadata{}, err
+			}
+		}
+		for _, orderBy := range lateral.OrderBy {
+			f, ok := rel.target.Fields[orderBy.Field]
+			if !ok {
+				return ModelMetadata{}, &UnknownFieldError{Field: lateral.Relation + "." + orderBy.Field, In: "lateral join order by clause"}
+			}
+			if err := f.checkFilterable(lateral.Relation + "." + orderBy.Field); err != nil {
+				return ModelMetadata{}, err
+			}
+		}
+		rel.lateral = &lateral
+		relations[lateral.Relation] = rel
+	}
+
+	metadata.Relations = relations
+	return metadata, nil
+}
+
+// applyLateralJoin adds the LEFT JOIN LATERAL of a relation resolved by
+// resolveLaterals. The subquery is aliased with the relation name.
+func applyLateralJoin(query squirrel.SelectBuilder, metadata ModelMetadata, name string, rel Relation, includeDeleted bool) (squirrel.SelectBuilder, error) {
+	target := rel.target
+	local := metadata.TableName + "." + metadata.Fields[rel.LocalField].Name
+	foreign := target.TableName + "." + target.Fields[rel.ForeignField].Name
+
+	sub := squirrel.Select("*").From(target.TableName).Where(fmt.Sprintf("%s = %s", foreign, local))
+	sub, err := applyWhere(sub, target, rel.lateral.Where, false)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	sub = applySoftDelete(sub, target, includeDeleted, false)
+	for _, orderBy := range rel.lateral.OrderBy {
+		column := target.Fields[orderBy.Field].Name
+		if orderBy.Desc {
+			sub = sub.OrderBy(column + " DESC")
+		} else {
+			sub = sub.OrderBy(column + " ASC")
+		}
+	}
+	sub = sub.Limit(uint64(rel.lateral.Limit))
+
+	return query.JoinClause(squirrel.Expr(fmt.Sprintf("LEFT JOIN LATERAL %s ON true", tableAlias(metadata.dialect(), "(?)", name)), sub)), nil
+}
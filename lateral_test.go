@@ -0,0 +1,105 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSelect_Lateral(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	departments, err := registry.GetModelMetadata(RelDepartment{})
+	require.NoError(t, err)
+
+	req := QueryRequest{
+		Select: []string{"name", "employees.name"},
+		Lateral: []LateralJoin{{
+			Relation: "employees",
+			Where:    map[string]interface{}{"name": []string{"alice", "bob", "carol"}},
+			OrderBy:  []OrderByClause{{Field: "id", Desc: true}},
+			Limit:    3,
+		}},
+		Where: map[string]interface{}{"id": 1},
+	}
+	metadata, err := registry.resolveRequest(departments, req)
+	require.NoError(t, err)
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+
+	query, err := buildSelect(metadata, req)
+	require.NoError(t, err)
+	sql, args, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT departments.name, employees.name AS "employees.name" FROM departments `+
+		"LEFT JOIN LATERAL (SELECT * FROM employees WHERE employees.department_id = departments.id "+
+		"AND name IN ($1,$2,$3) ORDER BY id DESC LIMIT 3) AS employees ON true WHERE departments.id = $4", sql)
+	assert.Equal(t, []interface{}{"alice", "bob", "carol", 1}, args)
+
+	count, err := buildCount(metadata, req)
+	require.NoError(t, err)
+	sql, _, err = count.ToSql()
+	require.NoError(t, err)
+	assert.Contains(t, sql, "LEFT JOIN LATERAL (SELECT * FROM employees")
+}
+
+func TestResolveRequest_LateralErrors(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	departments, err := registry.GetModelMetadata(RelDepartment{})
+	require.NoError(t, err)
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		metadata ModelMetadata
+		lateral  []LateralJoin
+	}{
+		{"unknown relation", departments, []LateralJoin{{Relation: "teams", Limit: 1}}},
+		{"not has-many", employees, []LateralJoin{{Relation: "department", Limit: 1}}},
+		{"missing limit", departments, []LateralJoin{{Relation: "employees"}}},
+		{"duplicate", departments, []LateralJoin{{Relation: "employees", Limit: 1}, {Relation: "employees", Limit: 2}}},
+		{"unknown where field", departments, []LateralJoin{{Relation: "employees", Limit: 1, Where: map[string]interface{}{"salary": 1}}}},
+		{"unknown order by field", departments, []LateralJoin{{Relation: "employees", Limit: 1, OrderBy: []OrderByClause{{Field: "salary"}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.resolveRequest(tt.metadata, QueryRequest{Select: []string{"name"}, Lateral: tt.lateral})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExecute_Lateral(t *testing.T) {
+	require.NoError(t, Register(RelEmployee{}))
+	require.NoError(t, Register(RelDepartment{}))
+	require.NoError(t, RegisterRelation[RelDepartment, RelEmployee]("employees", Relation{
+		Kind:         HasMany,
+		LocalField:   "id",
+		ForeignField: "department_id",
+	}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT departments.name, employees.name AS "employees.name" FROM departments ` +
+		`LEFT JOIN LATERAL \(SELECT \* FROM employees WHERE employees.department_id = departments.id ORDER BY id ASC LIMIT 2\) AS employees ON true`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "employees.name"}).
+			AddRow("eng", "alice").
+			AddRow("eng", "bob"))
+
+	resp, err := Execute[RelDepartment](context.Background(), db, QueryRequest{
+		Select:  []string{"name", "employees.name"},
+		Lateral: []LateralJoin{{Relation: "employees", OrderBy: []OrderByClause{{Field: "id"}}, Limit: 2}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{
+		{"name": "eng", "employees.name": "alice"},
+		{"name": "eng", "employees.name": "bob"},
+	}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,76 @@
+package sqld
+
+import "fmt"
+
+// Lock strengths supported by LockRequest.
+const (
+	LockUpdate      = "update"
+	LockNoKeyUpdate = "no_key_update"
+	LockShare       = "share"
+	LockKeyShare    = "key_share"
+)
+
+// Wait policies supported by LockRequest.
+const (
+	LockSkipLocked = "skip_locked"
+	LockNoWait     = "nowait"
+)
+
+var lockStrengths = map[string]string{
+	LockUpdate:      "FOR UPDATE",
+	LockNoKeyUpdate: "FOR NO KEY UPDATE",
+	LockShare:       "FOR SHARE",
+	LockKeyShare:    "FOR KEY SHARE",
+}
+
+var lockWaits = map[string]string{
+	"":             "",
+	LockSkipLocked: " SKIP LOCKED",
+	LockNoWait:     " NOWAIT",
+}
+
+// LockRequest locks the rows returned by a query until the end of the
+// transaction, e.g. to claim jobs from a queue table:
+//
+//	{"strength": "update", "wait": "skip_locked"}
+//
+// Only the rows of the model are locked, not those of joined relations.
+// Locks are only useful inside a transaction, see InTx.
+type LockRequest struct {
+	// Strength is one of update, no_key_update, share or key_share.
+	Strength string `json:"strength"`
+	// Wait is skip_locked to skip rows locked by other transactions, nowait
+	// to fail on them, or empty to wait for them.
+	Wait string `json:"wait,omitempty"`
+}
+
+// validate checks the lock request and the parts of req it can't be
+// combined with.
+func (l *LockRequest) validate(req QueryRequest) error {
+	if _, ok := lockStrengths[l.Strength]; !ok {
+		return fmt.Errorf("invalid lock strength: %s", l.Strength)
+	}
+	if _, ok := lockWaits[l.Wait]; !ok {
+		return fmt.Errorf("invalid lock wait policy: %s", l.Wait)
+	}
+	if req.From != "" {
+		return fmt.Errorf("lock cannot be combined with from")
+	}
+	if req.Tree != nil {
+		return fmt.Errorf("lock cannot be combined with tree")
+	}
+	return nil
+}
+
+// clause returns the locking clause for a query on metadata. When related
+// tables are joined, only the table of the model is locked.
+func (l *LockRequest) clause(metadata ModelMetadata, req QueryRequest, qualify bool) (string, error) {
+	if err := l.validate(req); err != nil {
+		return "", err
+	}
+	clause := lockStrengths[l.Strength]
+	if qualify {
+		clause += " OF " + metadata.TableName
+	}
+	return clause + lockWaits[l.Wait], nil
+}
@@ -0,0 +1,159 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSelect_Lock(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	limit := 1
+	tests := []struct {
+		name    string
+		req     QueryRequest
+		wantSQL string
+	}{
+		{
+			name: "for update",
+			req: QueryRequest{
+				Select: []string{"id"},
+				Lock:   &LockRequest{Strength: LockUpdate},
+			},
+			wantSQL: `SELECT id FROM employees FOR UPDATE`,
+		},
+		{
+			name: "claim a row with skip locked",
+			req: QueryRequest{
+				Select:  []string{"id"},
+				Where:   map[string]interface{}{"name": "pending"},
+				OrderBy: []OrderByClause{{Field: "id"}},
+				Limit:   &limit,
+				Lock:    &LockRequest{Strength: LockUpdate, Wait: LockSkipLocked},
+			},
+			wantSQL: `SELECT id FROM employees WHERE name = $1 ORDER BY id ASC LIMIT 1 FOR UPDATE SKIP LOCKED`,
+		},
+		{
+			name: "share nowait",
+			req: QueryRequest{
+				Select: []string{"id"},
+				Lock:   &LockRequest{Strength: LockShare, Wait: LockNoWait},
+			},
+			wantSQL: `SELECT id FROM employees FOR SHARE NOWAIT`,
+		},
+		{
+			name: "joined relations are not locked",
+			req: QueryRequest{
+				Select: []string{"id", "department.name"},
+				Lock:   &LockRequest{Strength: LockNoKeyUpdate},
+			},
+			wantSQL: `SELECT employees.id, department.name AS "department.name" FROM employees ` +
+				`LEFT JOIN departments AS department ON department.id = employees.department_id FOR NO KEY UPDATE OF employees`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := buildSelect(employees, tt.req)
+			require.NoError(t, err)
+			sql, _, err := query.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, sql)
+		})
+	}
+}
+
+func TestBuildSelect_LockInvalid(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		req     QueryRequest
+		wantErr string
+	}{
+		{
+			name:    "unknown strength",
+			req:     QueryRequest{Select: []string{"id"}, Lock: &LockRequest{Strength: "exclusive"}},
+			wantErr: "invalid lock strength: exclusive",
+		},
+		{
+			name:    "unknown wait policy",
+			req:     QueryRequest{Select: []string{"id"}, Lock: &LockRequest{Strength: LockUpdate, Wait: "later"}},
+			wantErr: "invalid lock wait policy: later",
+		},
+		{
+			name: "from a cte",
+			req: QueryRequest{
+				Select: []string{"id"},
+				With:   []CTE{{Name: "recent", Query: QueryRequest{Select: []string{"id"}}}},
+				From:   "recent",
+				Lock:   &LockRequest{Strength: LockUpdate},
+			},
+			wantErr: "lock cannot be combined with from",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, BasicValidator{}.ValidateQuery(tt.req, employees), tt.wantErr)
+			_, err := buildSelect(employees, tt.req)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestExecute_LockInTx(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE name = \$1 LIMIT 1 FOR UPDATE SKIP LOCKED`).
+		WithArgs("pending").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+	mock.ExpectCommit()
+
+	limit := 1
+	err = InTx(context.Background(), db, func(tx Runner) error {
+		resp, err := Execute[BuilderTestModel](context.Background(), tx, QueryRequest{
+			Select: []string{"id"},
+			Where:  map[string]interface{}{"name": "pending"},
+			Limit:  &limit,
+			Lock:   &LockRequest{Strength: LockUpdate, Wait: LockSkipLocked},
+		})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, []QueryResult{{"id": int64(7)}}, resp.Data)
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteUnion_Lock(t *testing.T) {
+	registerUnionModels(t)
+
+	locked, err := NewUnionPart[UnionActiveAccount](QueryRequest{
+		Select: []string{"id"},
+		Lock:   &LockRequest{Strength: LockUpdate},
+	})
+	require.NoError(t, err)
+	plain, err := NewUnionPart[UnionArchivedAccount](QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+
+	_, err = ExecuteUnion(context.Background(), nil, UnionRequest{Parts: []UnionPart{locked, plain}})
+	assert.ErrorContains(t, err, "part 1: lock is not supported in unions")
+}
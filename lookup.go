@@ -0,0 +1,179 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Lookup loads secondary data for the rows of a query, such as values from a
+// joined reference table, a view or another service. Lookups are registered
+// per model with RegisterLookup and requested by name through
+// QueryRequest.Include. The loaded value is stored in each row under the
+// lookup name.
+type Lookup struct {
+	// Load returns one value per row, in the same order as rows.
+	Load func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error)
+
+	// Optional marks the lookup as non-essential. When an optional lookup
+	// fails (e.g. the view it reads is missing for one tenant) the core rows
+	// are still returned, the lookup key is set to nil in every row and the
+	// failure is reported in QueryResponse.Warnings. Failures of required
+	// lookups fail the whole request. On Postgres, optional lookups given a
+	// transaction run in a savepoint, so that a failure doesn't abort it.
+	Optional bool
+
+	// Flag names a feature flag gating the lookup, checked with the
+	// registry's FlagProvider at execution time. While the flag is disabled,
+	// an optional lookup is skipped like a failed one, and a required lookup
+	// fails the request. Optional - empty means always enabled.
+	Flag string
+}
+
+// RegisterLookup registers a named lookup for model T in the default registry.
+func RegisterLookup[T Model](name string, lookup Lookup) error {
+	var model T
+	return defaultRegistry.RegisterLookup(model, name, lookup)
+}
+
+// RegisterLookup registers a named lookup for a model.
+// The model must already be registered and the name must not clash with one
+// of its fields.
+func (r *Registry) RegisterLookup(model Model, name string, lookup Lookup) error {
+	if lookup.Load == nil {
+		return fmt.Errorf("lookup %s has no Load function", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(model)
+	metadata, ok := r.models[t]
+	if !ok {
+		return &ModelNotRegisteredError{Model: t.Name()}
+	}
+	if _, exists := metadata.Fields[name]; exists {
+		return fmt.Errorf("lookup %s clashes with a field of model %s", name, t.Name())
+	}
+
+	if r.lookups[t] == nil {
+		r.lookups[t] = make(map[string]Lookup)
+	}
+	r.lookups[t][name] = lookup
+	return nil
+}
+
+// GetLookup returns the named lookup registered for a model.
+func (r *Registry) GetLookup(model Model, name string) (Lookup, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	lookup, ok := r.lookups[reflect.TypeOf(model)][name]
+	return lookup, ok
+}
+
+// applyLookups runs the lookups named in include and stores their values in
+// rows. It returns the warnings produced by failed optional lookups.
+func (r *Registry) applyLookups(ctx context.Context, db interface{}, model Model, include []string, rows []QueryResult) ([]string, error) {
+	var warnings []string
+	for _, name := range include {
+		lookup, ok := r.GetLookup(model, name)
+		if !ok {
+			return nil, fmt.Errorf("invalid include: %s", name)
+		}
+
+		if !r.flagEnabled(ctx, lookup.Flag) {
+			if !lookup.Optional {
+				return nil, fmt.Errorf("include %s is disabled", name)
+			}
+			for _, row := range rows {
+				row[name] = nil
+			}
+			warnings = append(warnings, fmt.Sprintf("lookup %s is disabled", name))
+			continue
+		}
+
+		run := runLookup
+		if lookup.Optional && r.Dialect() == Postgres {
+			run = runInSavepoint
+		}
+		warning, err := run(ctx, db, name, lookup, rows)
+		if err != nil {
+			return nil, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings, nil
+}
+
+// runLookup loads a lookup and stores its values in rows under name.
+// Failures of optional lookups are returned as a warning instead of an error.
+func runLookup(ctx context.Context, db interface{}, name string, lookup Lookup, rows []QueryResult) (string, error) {
+	values, err := lookup.Load(ctx, db, rows)
+	if err == nil && len(values) != len(rows) {
+		err = fmt.Errorf("returned %d values for %d rows", len(values), len(rows))
+	}
+	if err != nil {
+		if !lookup.Optional {
+			return "", fmt.Errorf("lookup %s failed: %w", name, err)
+		}
+		for _, row := range rows {
+			row[name] = nil
+		}
+		return fmt.Sprintf("lookup %s failed: %v", name, err), nil
+	}
+
+	for i, row := range rows {
+		row[name] = values[i]
+	}
+	return "", nil
+}
+
+// lookupSavepoint is the savepoint optional lookups run in on a
+// database/sql transaction.
+const lookupSavepoint = "sqld_lookup"
+
+// runInSavepoint runs an optional lookup like runLookup. When db is a
+// transaction, the lookup runs in a savepoint that is rolled back if it
+// fails: Postgres aborts a transaction on the first failed statement, which
+// would otherwise fail every later query of the caller.
+func runInSavepoint(ctx context.Context, db interface{}, name string, lookup Lookup, rows []QueryResult) (string, error) {
+	switch tx := db.(type) {
+	case pgx.Tx:
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return "", fmt.Errorf("lookup %s: failed to create savepoint: %w", name, err)
+		}
+		warning, err := runLookup(ctx, sp, name, lookup, rows)
+		if err != nil || warning != "" {
+			if rbErr := sp.Rollback(ctx); rbErr != nil {
+				return "", fmt.Errorf("lookup %s: failed to roll back savepoint: %w", name, rbErr)
+			}
+			return warning, err
+		}
+		if err := sp.Commit(ctx); err != nil {
+			return "", fmt.Errorf("lookup %s: failed to release savepoint: %w", name, err)
+		}
+		return "", nil
+	case *sql.Tx:
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT "+lookupSavepoint); err != nil {
+			return "", fmt.Errorf("lookup %s: failed to create savepoint: %w", name, err)
+		}
+		warning, err := runLookup(ctx, tx, name, lookup, rows)
+		if err != nil || warning != "" {
+			if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+lookupSavepoint); rbErr != nil {
+				return "", fmt.Errorf("lookup %s: failed to roll back savepoint: %w", name, rbErr)
+			}
+			return warning, err
+		}
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+lookupSavepoint); err != nil {
+			return "", fmt.Errorf("lookup %s: failed to release savepoint: %w", name, err)
+		}
+		return "", nil
+	}
+	return runLookup(ctx, db, name, lookup, rows)
+}
@@ -0,0 +1,161 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LookupTestModel struct {
+	ID   int64  `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+}
+
+func (LookupTestModel) TableName() string {
+	return "lookup_models"
+}
+
+func TestExecute_Lookups(t *testing.T) {
+	require.NoError(t, Register(LookupTestModel{}))
+
+	require.NoError(t, RegisterLookup[LookupTestModel]("badge", Lookup{
+		Load: func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error) {
+			values := make([]interface{}, len(rows))
+			for i, row := range rows {
+				values[i] = map[string]interface{}{"owner": row["id"]}
+			}
+			return values, nil
+		},
+	}))
+	require.NoError(t, RegisterLookup[LookupTestModel]("tenant_view", Lookup{
+		Optional: true,
+		Load: func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error) {
+			return nil, errors.New(`relation "tenant_view" does not exist`)
+		},
+	}))
+	require.NoError(t, RegisterLookup[LookupTestModel]("required_view", Lookup{
+		Load: func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error) {
+			return nil, errors.New("boom")
+		},
+	}))
+
+	assert.Error(t, RegisterLookup[LookupTestModel]("name", Lookup{
+		Load: func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error) { return nil, nil },
+	}), "lookup names must not clash with fields")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Run("optional lookup failure degrades", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, name FROM lookup_models").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "a").AddRow(int64(2), "b"))
+
+		resp, err := Execute[LookupTestModel](context.Background(), db, QueryRequest{
+			Select:  []string{"id", "name"},
+			Include: []string{"badge", "tenant_view"},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, map[string]interface{}{"owner": int64(2)}, resp.Data[1]["badge"])
+		value, ok := resp.Data[0]["tenant_view"]
+		assert.True(t, ok)
+		assert.Nil(t, value)
+		require.Len(t, resp.Warnings, 1)
+		assert.Contains(t, resp.Warnings[0], "tenant_view")
+	})
+
+	t.Run("required lookup failure fails the request", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, name FROM lookup_models").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "a"))
+
+		_, err := Execute[LookupTestModel](context.Background(), db, QueryRequest{
+			Select:  []string{"id", "name"},
+			Include: []string{"required_view"},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown include is rejected before querying", func(t *testing.T) {
+		_, err := Execute[LookupTestModel](context.Background(), db, QueryRequest{
+			Select:  []string{"id"},
+			Include: []string{"missing"},
+		})
+		assert.Error(t, err)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_OptionalLookupInTransaction(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(LookupTestModel{}))
+	require.NoError(t, registry.RegisterLookup(LookupTestModel{}, "tenant_view", Lookup{
+		Optional: true,
+		Load: func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error) {
+			var names []map[string]interface{}
+			if err := selectAll(ctx, db, &names, "SELECT name FROM tenant_view"); err != nil {
+				return nil, err
+			}
+			return make([]interface{}, len(rows)), nil
+		},
+	}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id"}, Include: []string{"tenant_view"}}
+
+	t.Run("failure rolls back to the savepoint", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM lookup_models").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+		mock.ExpectExec("SAVEPOINT sqld_lookup").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT name FROM tenant_view").WillReturnError(errors.New(`relation "tenant_view" does not exist`))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT sqld_lookup").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		resp, err := Execute[LookupTestModel](ctx, tx, req)
+		require.NoError(t, err)
+		assert.Equal(t, []QueryResult{{"id": int64(1), "tenant_view": nil}}, resp.Data)
+		require.Len(t, resp.Warnings, 1)
+		require.NoError(t, tx.Commit())
+	})
+
+	t.Run("success releases the savepoint", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM lookup_models").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+		mock.ExpectExec("SAVEPOINT sqld_lookup").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT name FROM tenant_view").WillReturnRows(sqlmock.NewRows([]string{"name"}))
+		mock.ExpectExec("RELEASE SAVEPOINT sqld_lookup").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		resp, err := Execute[LookupTestModel](ctx, tx, req)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Warnings)
+		require.NoError(t, tx.Commit())
+	})
+
+	t.Run("no savepoint outside transactions", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id FROM lookup_models").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+		mock.ExpectQuery("SELECT name FROM tenant_view").WillReturnError(errors.New(`relation "tenant_view" does not exist`))
+
+		resp, err := Execute[LookupTestModel](ctx, db, req)
+		require.NoError(t, err)
+		require.Len(t, resp.Warnings, 1)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,84 @@
+package sqld
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyRows is returned, by a *MaxRowsError, for queries matching more
+// rows than the MaxRows of their model allow, when set to reject them.
+var ErrTooManyRows = errors.New("too many rows")
+
+// MaxRows caps the rows returned by Execute and the calls built on it,
+// whatever the limit or page size of the request. One more row than the cap
+// is read to tell whether more match.
+type MaxRows struct {
+	Rows   int  // Rows returned at most, 0 for no cap
+	Reject bool // Fail with a *MaxRowsError instead of truncating the rows
+}
+
+// MaxRowsError is returned for a query matching more rows than its MaxRows
+// allow.
+type MaxRowsError struct {
+	Max int
+}
+
+func (e *MaxRowsError) Error() string {
+	return fmt.Sprintf("query returned more than %d rows", e.Max)
+}
+
+func (e *MaxRowsError) Is(target error) bool {
+	return target == ErrTooManyRows
+}
+
+// SetMaxRows caps the rows returned for the models of the default registry
+// registered without WithMaxRows. Responses truncated by the cap have
+// Truncated set, unless the cap rejects them. Streams and exports aren't
+// capped.
+func SetMaxRows(max MaxRows) {
+	defaultRegistry.SetMaxRows(max)
+}
+
+// SetMaxRows caps the rows returned for the models of the registry
+// registered without WithMaxRows.
+func (r *Registry) SetMaxRows(max MaxRows) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxRows = max
+}
+
+// WithMaxRows caps the rows returned for the model, replacing the cap set
+// with SetMaxRows.
+func WithMaxRows(max MaxRows) RegisterOption {
+	return func(c *registerConfig) { c.maxRows = &max }
+}
+
+// rowsCap returns the cap of the rows returned for the model of metadata.
+func (r *Registry) rowsCap(metadata ModelMetadata) MaxRows {
+	if metadata.MaxRows != nil {
+		return *metadata.MaxRows
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.maxRows
+}
+
+// rowsLimit describes a query read with one more row than it keeps, to tell
+// whether more rows match.
+type rowsLimit struct {
+	keep   int  // Rows kept
+	capped bool // keep is the MaxRows of the model rather than the limit of the request
+	reject bool // More rows fail the query rather than being dropped
+}
+
+// apply drops the rows of results beyond l, reporting whether there were
+// any, or fails when l rejects them.
+func (l *rowsLimit) apply(results []map[string]interface{}) ([]map[string]interface{}, bool, error) {
+	if l == nil || len(results) <= l.keep {
+		return results, false, nil
+	}
+	if l.reject {
+		return nil, false, &MaxRowsError{Max: l.keep}
+	}
+	return results[:l.keep], true, nil
+}
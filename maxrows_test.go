@@ -0,0 +1,62 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxRows(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	require.NoError(t, registry.Register(MutationAccount{}, WithMaxRows(MaxRows{Rows: 1, Reject: true})))
+	ctx := WithRegistry(context.Background(), registry)
+	registry.SetMaxRows(MaxRows{Rows: 2})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// One more row than the cap tells that rows were dropped
+	mock.ExpectQuery(`SELECT id FROM test_models LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+	resp, err := Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	assert.Len(t, resp.Data, 2)
+	assert.True(t, resp.Truncated)
+
+	mock.ExpectQuery(`SELECT id FROM test_models LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	resp, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	assert.Len(t, resp.Data, 2)
+	assert.False(t, resp.Truncated)
+
+	// Limits within the cap are left alone
+	limit := 1
+	mock.ExpectQuery(`SELECT id FROM test_models LIMIT 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	resp, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}, Limit: &limit})
+	require.NoError(t, err)
+	assert.False(t, resp.Truncated)
+
+	// Truncation by the cap is reported in the metadata too
+	mock.ExpectQuery(`SELECT id FROM test_models LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+	resp, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}}, WithMetadata())
+	require.NoError(t, err)
+	assert.True(t, resp.Truncated)
+	assert.True(t, resp.Metadata.Truncated)
+	assert.Equal(t, 2, resp.Metadata.RowCount)
+
+	// The cap of the model replaces that of the registry
+	mock.ExpectQuery(`SELECT id FROM mutation_accounts LIMIT 2`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	_, err = Execute[MutationAccount](ctx, db, QueryRequest{Select: []string{"id"}})
+	require.ErrorIs(t, err, ErrTooManyRows)
+	assert.EqualError(t, err, "query returned more than 1 rows")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
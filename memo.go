@@ -0,0 +1,110 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// memoKey is the context key under which the memo cache is stored.
+type memoKey struct{}
+
+// memo caches results of sqld calls for the lifetime of a context.
+type memo struct {
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+}
+
+// memoEntry holds one cached result. done is closed once the result is
+// available, so concurrent callers with the same key wait for the first one.
+type memoEntry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// WithMemo returns a context under which identical Execute and ExecuteRaw
+// calls hit the database only once. It is meant to wrap a single HTTP
+// request, where several service functions often fetch the same lookup
+// independently:
+//
+//	ctx := sqld.WithMemo(r.Context())
+//
+// Calls are identical when they use the same model (or parameter/result
+// types), database handle and request. Failed calls are cached as well, so a
+// failing query is not retried within the same context. Results are copied
+// row by row before being returned, so callers may modify them freely.
+func WithMemo(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(memoKey{}).(*memo); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, memoKey{}, &memo{entries: make(map[string]*memoEntry)})
+}
+
+// memoFromContext returns the memo cache of ctx, if any.
+func memoFromContext(ctx context.Context) *memo {
+	m, _ := ctx.Value(memoKey{}).(*memo)
+	return m
+}
+
+// do returns the cached result for key, calling fn to compute it on the
+// first call.
+func (m *memo) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	m.mu.Lock()
+	if entry, ok := m.entries[key]; ok {
+		m.mu.Unlock()
+		<-entry.done
+		return entry.value, entry.err
+	}
+	entry := &memoEntry{done: make(chan struct{})}
+	m.entries[key] = entry
+	m.mu.Unlock()
+
+	entry.value, entry.err = fn()
+	close(entry.done)
+	return entry.value, entry.err
+}
+
+// memoCallKey builds the cache key of a call from its kind, the registry, the
+// types involved, the database handle and the request.
+func memoCallKey(r *Registry, kind string, types []reflect.Type, db interface{}, req interface{}) (string, error) {
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to build memo key: %w", err)
+	}
+	return fmt.Sprintf("%s|%p|%v|%T:%p|%s", kind, r, types, db, db, encoded), nil
+}
+
+// cachedCall runs fn through the result cache c and the memo m, either of
+// which may be nil.
+func cachedCall(ctx context.Context, m *memo, c *ResultCache, key string, fn func() (interface{}, error)) (interface{}, error) {
+	if c != nil {
+		uncached := fn
+		fn = func() (interface{}, error) {
+			return c.do(ctx, key, uncached)
+		}
+	}
+	if m == nil {
+		return fn()
+	}
+	return m.do(key, fn)
+}
+
+// copyRows returns a copy of rows with every row map copied, so that cached
+// results can't be modified through a returned value.
+func copyRows[M ~map[string]interface{}](rows []M) []M {
+	if rows == nil {
+		return nil
+	}
+	out := make([]M, len(rows))
+	for i, row := range rows {
+		c := make(M, len(row))
+		for k, v := range row {
+			c[k] = v
+		}
+		out[i] = c
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package sqld
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_Memo(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Only one query is expected for the two identical calls
+	mock.ExpectQuery("SELECT id, name FROM test_models WHERE age = \\$1").
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "alice"))
+	mock.ExpectQuery("SELECT id, name FROM test_models WHERE age = \\$1").
+		WithArgs(40).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(2), "bob"))
+
+	ctx := WithMemo(context.Background())
+	assert.Equal(t, ctx, WithMemo(ctx), "wrapping twice reuses the memo")
+
+	req := QueryRequest{Select: []string{"id", "name"}, Where: map[string]interface{}{"age": 30}}
+	first, err := Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	first.Data[0]["name"] = "modified"
+
+	second, err := Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "name": "alice"}}, second.Data)
+
+	other, err := Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id", "name"}, Where: map[string]interface{}{"age": 40}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(2), "name": "bob"}}, other.Data)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type MemoRawParams struct {
+	ID CustomID `db:"id" json:"id"`
+}
+
+func TestExecuteRaw_Memo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id, name FROM test_custom WHERE id = \\$1").
+		WithArgs("1:user").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1:user", "Alice"))
+
+	ctx := WithMemo(context.Background())
+	params := map[string]interface{}{"id": CustomID{ID: 1, Type: "user"}}
+	query := "SELECT id, name FROM test_custom WHERE id = {{id}}"
+	for i := 0; i < 2; i++ {
+		results, err := ExecuteRaw[MemoRawParams, TestCustomResult](ctx, db, query, params)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Alice", results[0]["name"])
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMemo_ConcurrentCallsShareResult(t *testing.T) {
+	m := memoFromContext(WithMemo(context.Background()))
+	require.NotNil(t, m)
+
+	var calls int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := m.do("key", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return 42, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+		}()
+	}
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestMemoFromContext_None(t *testing.T) {
+	assert.Nil(t, memoFromContext(context.Background()))
+}
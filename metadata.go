@@ -0,0 +1,18 @@
+package sqld
+
+// QueryMetadata describes how a query ran. Responses only hold it when the
+// call is made WithMetadata, so that payloads don't change otherwise.
+type QueryMetadata struct {
+	DurationMS  float64             `json:"duration_ms"`          // Time the call took, retries and count included
+	RowCount    int                 `json:"row_count"`            // Number of rows returned
+	Truncated   bool                `json:"truncated"`            // More rows match than were returned, e.g. beyond the limit or page
+	Fingerprint string              `json:"fingerprint"`          // Identifies the shape of the query, whatever its values
+	Pagination  *PaginationResponse `json:"pagination,omitempty"` // Page of the rows, if paginated
+}
+
+// WithMetadata adds a QueryMetadata to the response of the call. To tell
+// whether results were truncated by a limit, one more row is read than
+// requested.
+func WithMetadata() ExecuteOption {
+	return func(c *executeConfig) { c.metadata = true }
+}
@@ -0,0 +1,53 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteWithMetadata(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// One more row than the limit is read and dropped
+	limit := 2
+	req := QueryRequest{Select: []string{"id", "name"}, Limit: &limit}
+	mock.ExpectQuery(`SELECT id, name FROM test_models LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b").AddRow(3, "c"))
+	resp, err := Execute[BuilderTestModel](ctx, db, req, WithMetadata())
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 2)
+	require.NotNil(t, resp.Metadata)
+	assert.Equal(t, 2, resp.Metadata.RowCount)
+	assert.True(t, resp.Metadata.Truncated)
+	assert.Len(t, resp.Metadata.Fingerprint, 16)
+	assert.GreaterOrEqual(t, resp.Metadata.DurationMS, 0.0)
+
+	mock.ExpectQuery(`SELECT id, name FROM test_models LIMIT 3`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	resp2, err := Execute[BuilderTestModel](ctx, db, req, WithMetadata())
+	require.NoError(t, err)
+	assert.False(t, resp2.Metadata.Truncated)
+	assert.Equal(t, resp.Metadata.Fingerprint, resp2.Metadata.Fingerprint)
+
+	// Payloads don't change without it
+	mock.ExpectQuery(`SELECT id, name FROM test_models LIMIT 2`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	resp, err = Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Nil(t, resp.Metadata)
+	encoded, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "metadata")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
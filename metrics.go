@@ -0,0 +1,111 @@
+package sqld
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Operations reported by MetricsCollector, besides the OpInsert, OpUpdate
+// and OpDelete of the writes.
+const (
+	OpSelect = "select"
+	OpStream = "stream"
+)
+
+// MetricsCollector is a prometheus.Collector of the calls run with the
+// registries it is set on with SetMetrics, labelled by the table of the
+// model and the operation:
+//
+//   - <namespace>_query_duration_seconds, a histogram of the time spent
+//     running the statements of Execute, the streams and the writes;
+//   - <namespace>_query_errors_total, the number of those that failed;
+//   - <namespace>_query_rows, a histogram of the number of rows they
+//     returned or wrote.
+//
+// Requests rejected before reaching the database aren't counted.
+type MetricsCollector struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	rows     *prometheus.HistogramVec
+}
+
+// NewMetricsCollector returns a MetricsCollector whose metrics are prefixed
+// with namespace, "sqld" when empty. It must be registered, e.g. with
+// prometheus.MustRegister, for its metrics to be exported.
+func NewMetricsCollector(namespace string) *MetricsCollector {
+	if namespace == "" {
+		namespace = "sqld"
+	}
+	labels := []string{"model", "operation"}
+	return &MetricsCollector{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "query_duration_seconds",
+			Help:      "Time spent running queries, by model and operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "query_errors_total",
+			Help:      "Number of queries that failed, by model and operation.",
+		}, labels),
+		rows: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "query_rows",
+			Help:      "Number of rows returned or written by queries, by model and operation.",
+			Buckets:   prometheus.ExponentialBuckets(1, 10, 6),
+		}, labels),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.duration.Describe(ch)
+	c.errors.Describe(ch)
+	c.rows.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.duration.Collect(ch)
+	c.errors.Collect(ch)
+	c.rows.Collect(ch)
+}
+
+// observe records a call of operation on the table of metadata, started at
+// start, that returned or wrote rows rows and failed with err if not nil.
+func (c *MetricsCollector) observe(metadata ModelMetadata, operation string, start time.Time, rows int64, err error) {
+	c.duration.WithLabelValues(metadata.TableName, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		c.errors.WithLabelValues(metadata.TableName, operation).Inc()
+		return
+	}
+	c.rows.WithLabelValues(metadata.TableName, operation).Observe(float64(rows))
+}
+
+// SetMetrics sets the collector of the calls run with the default registry,
+// nil for none.
+func SetMetrics(c *MetricsCollector) {
+	defaultRegistry.SetMetrics(c)
+}
+
+// SetMetrics sets the collector of the calls run with the registry.
+func (r *Registry) SetMetrics(c *MetricsCollector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = c
+}
+
+// recordCall passes the outcome of a call to the collector of the registry of
+// ctx, if any. See MetricsCollector.observe.
+func recordCall(ctx context.Context, metadata ModelMetadata, operation string, start time.Time, rows int64, err error) {
+	r := registryFromContext(ctx)
+	r.mu.RLock()
+	c := r.metrics
+	r.mu.RUnlock()
+	if c != nil {
+		c.observe(metadata, operation, start, rows, err)
+	}
+}
@@ -0,0 +1,80 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsCollector(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	collector := NewMetricsCollector("")
+	registry.SetMetrics(collector)
+	prom := prometheus.NewRegistry()
+	require.NoError(t, prom.Register(collector))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`SELECT id FROM test_models`).WillReturnError(errors.New("connection reset"))
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}})
+	require.Error(t, err)
+
+	// Rejected requests don't reach the database and aren't counted
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"nope"}})
+	require.Error(t, err)
+
+	mock.ExpectQuery(`DELETE FROM test_models WHERE id = \$1 RETURNING id`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	_, err = Delete[BuilderTestModel](ctx, db, DeleteRequest{Where: map[string]interface{}{"id": 1}, Returning: []string{"id"}})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	families, err := prom.Gather()
+	require.NoError(t, err)
+	metrics := make(map[string][]*dto.Metric)
+	for _, family := range families {
+		metrics[family.GetName()] = family.GetMetric()
+	}
+
+	duration := metrics["sqld_query_duration_seconds"]
+	require.Len(t, duration, 2)
+	assert.Equal(t, map[string]string{"model": "test_models", "operation": "delete"}, labels(duration[0]))
+	assert.EqualValues(t, 1, duration[0].GetHistogram().GetSampleCount())
+	assert.Equal(t, map[string]string{"model": "test_models", "operation": "select"}, labels(duration[1]))
+	assert.EqualValues(t, 2, duration[1].GetHistogram().GetSampleCount())
+
+	errs := metrics["sqld_query_errors_total"]
+	require.Len(t, errs, 1)
+	assert.Equal(t, "select", labels(errs[0])["operation"])
+	assert.EqualValues(t, 1, errs[0].GetCounter().GetValue())
+
+	rows := metrics["sqld_query_rows"]
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 1, rows[0].GetHistogram().GetSampleSum())
+	assert.EqualValues(t, 2, rows[1].GetHistogram().GetSampleSum())
+}
+
+func labels(m *dto.Metric) map[string]string {
+	labels := make(map[string]string)
+	for _, pair := range m.GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+	return labels
+}
@@ -0,0 +1,199 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TableSchema is a table read from the database catalog by LoadTableSchemas.
+type TableSchema struct {
+	Name    string
+	Columns []ColumnSchema
+}
+
+// ColumnSchema is a column of a TableSchema.
+type ColumnSchema struct {
+	Name     string
+	DataType string // SQL type as reported by the catalog, e.g. bigint
+	Nullable bool
+}
+
+// LoadTableSchemas reads the tables of schema, or only those named in tables
+// when given, from information_schema.columns. Tables are returned by name,
+// with their columns in their declaration order.
+func LoadTableSchemas(ctx context.Context, db interface{}, schema string, tables ...string) ([]TableSchema, error) {
+	query := fmt.Sprintf("SELECT table_name, column_name, data_type, is_nullable FROM information_schema.columns WHERE table_schema = %s ORDER BY table_name, ordinal_position",
+		registryFromContext(ctx).Dialect().BindVar(1))
+	var rows []struct {
+		TableName string `db:"table_name"`
+		catalogColumn
+	}
+	if err := selectAll(ctx, db, &rows, query, schema); err != nil {
+		return nil, fmt.Errorf("failed to load table schemas: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		wanted[table] = true
+	}
+	var schemas []TableSchema
+	for _, row := range rows {
+		if len(tables) > 0 && !wanted[row.TableName] {
+			continue
+		}
+		if len(schemas) == 0 || schemas[len(schemas)-1].Name != row.TableName {
+			schemas = append(schemas, TableSchema{Name: row.TableName})
+		}
+		last := &schemas[len(schemas)-1]
+		last.Columns = append(last.Columns, ColumnSchema{Name: row.ColumnName, DataType: row.DataType, Nullable: row.nullable()})
+	}
+	for _, table := range tables {
+		if !containsTable(schemas, table) {
+			return nil, fmt.Errorf("table %s does not exist in schema %s", table, schema)
+		}
+	}
+	return schemas, nil
+}
+
+// containsTable reports whether schemas has the table named name.
+func containsTable(schemas []TableSchema, name string) bool {
+	for _, schema := range schemas {
+		if schema.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateModels writes Go source for package pkg declaring, for every table,
+// a model struct with db and json tags and a TableName method, and a
+// RegisterModels function that registers them all with sqld. Nullable
+// columns get pointer types, and columns that can't be written unquoted are
+// left out. The output is gofmt-formatted.
+func GenerateModels(w io.Writer, pkg string, tables []TableSchema) error {
+	var body bytes.Buffer
+	usesTime := false
+	typeNames := make([]string, 0, len(tables))
+	for _, table := range tables {
+		typeName := goIdentifier(singularize(table.Name))
+		if typeName == "" {
+			return fmt.Errorf("cannot derive a Go name for table %q", table.Name)
+		}
+		typeNames = append(typeNames, typeName)
+
+		fmt.Fprintf(&body, "// %s is a row of the %s table.\n", typeName, table.Name)
+		fmt.Fprintf(&body, "type %s struct {\n", typeName)
+		seen := make(map[string]bool)
+		for _, column := range table.Columns {
+			if !identRegex.MatchString(column.Name) || strings.Contains(column.Name, ".") {
+				continue
+			}
+			fieldName := goFieldName(column.Name)
+			if seen[fieldName] {
+				return fmt.Errorf("table %s: columns map to the same Go field %s", table.Name, fieldName)
+			}
+			seen[fieldName] = true
+
+			goType := catalogGoType(catalogColumn{DataType: column.DataType, IsNullable: nullableFlag(column.Nullable)}).String()
+			if goType == "interface {}" {
+				goType = "interface{}"
+			}
+			usesTime = usesTime || strings.Contains(goType, "time.Time")
+			fmt.Fprintf(&body, "%s %s `db:%q json:%q`\n", fieldName, goType, column.Name, column.Name)
+		}
+		fmt.Fprintf(&body, "}\n\n")
+		fmt.Fprintf(&body, "func (%s) TableName() string { return %q }\n\n", typeName, table.Name)
+	}
+	if dup := duplicateName(typeNames); dup != "" {
+		return fmt.Errorf("several tables map to the Go type %s", dup)
+	}
+
+	fmt.Fprintf(&body, "// RegisterModels registers every generated model with sqld.\n")
+	fmt.Fprintf(&body, "func RegisterModels() error {\n")
+	for _, typeName := range typeNames {
+		fmt.Fprintf(&body, "if err := sqld.Register(%s{}); err != nil {\nreturn err\n}\n", typeName)
+	}
+	fmt.Fprintf(&body, "return nil\n}\n")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by sqld gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	if usesTime {
+		fmt.Fprintf(&buf, "import (\n\"time\"\n\n\"github.com/remiges-sachin/sqld\"\n)\n\n")
+	} else {
+		fmt.Fprintf(&buf, "import \"github.com/remiges-sachin/sqld\"\n\n")
+	}
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated models: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// nullableFlag returns the is_nullable catalog value for nullable.
+func nullableFlag(nullable bool) string {
+	if nullable {
+		return "YES"
+	}
+	return "NO"
+}
+
+// duplicateName returns a name appearing twice in names, or "".
+func duplicateName(names []string) string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i] == sorted[i-1] {
+			return sorted[i]
+		}
+	}
+	return ""
+}
+
+// goInitialisms are written in upper case in Go field names.
+var goInitialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "api": true, "http": true,
+	"json": true, "uuid": true, "ip": true, "sql": true, "html": true, "xml": true,
+}
+
+// goFieldName converts a column name such as user_id into the Go field name
+// UserID.
+func goFieldName(column string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(column, "_") {
+		switch {
+		case goInitialisms[strings.ToLower(part)]:
+			b.WriteString(strings.ToUpper(part))
+		case i > 0 && part != "" && part[0] >= '0' && part[0] <= '9':
+			b.WriteString(strings.ToLower(part)) // address_2 is Address2
+		default:
+			b.WriteString(goIdentifier(part))
+		}
+	}
+	return b.String()
+}
+
+// singularize returns the English singular of the snake case noun name, the
+// inverse of pluralize, e.g. category for categories.
+func singularize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "uses"), strings.HasSuffix(name, "xes"),
+		strings.HasSuffix(name, "zes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "ss"), strings.HasSuffix(name, "us"):
+		return name
+	case strings.HasSuffix(name, "s"):
+		return name[:len(name)-1]
+	}
+	return name
+}
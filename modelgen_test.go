@@ -0,0 +1,79 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTableSchemas(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"table_name", "column_name", "data_type", "is_nullable"}).
+		AddRow("audit_logs", "id", "bigint", "NO").
+		AddRow("employees", "id", "bigint", "NO").
+		AddRow("employees", "hired_at", "date", "YES")
+	mock.ExpectQuery(`SELECT table_name, column_name, data_type, is_nullable FROM information_schema.columns WHERE table_schema = \$1 ORDER BY table_name, ordinal_position`).
+		WithArgs("hr").
+		WillReturnRows(rows)
+
+	schemas, err := LoadTableSchemas(context.Background(), db, "hr", "employees")
+	require.NoError(t, err)
+	assert.Equal(t, []TableSchema{{Name: "employees", Columns: []ColumnSchema{
+		{Name: "id", DataType: "bigint"},
+		{Name: "hired_at", DataType: "date", Nullable: true},
+	}}}, schemas)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectQuery(`FROM information_schema.columns`).
+		WithArgs("hr").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "data_type", "is_nullable"}))
+	_, err = LoadTableSchemas(context.Background(), db, "hr", "missing")
+	assert.EqualError(t, err, "table missing does not exist in schema hr")
+}
+
+func TestGenerateModels(t *testing.T) {
+	var buf bytes.Buffer
+	err := GenerateModels(&buf, "models", []TableSchema{
+		{Name: "employees", Columns: []ColumnSchema{
+			{Name: "id", DataType: "bigint"},
+			{Name: "manager_id", DataType: "integer", Nullable: true},
+			{Name: "address_2", DataType: "text", Nullable: true},
+			{Name: "hired_at", DataType: "timestamp with time zone"},
+			{Name: "profile", DataType: "jsonb"},
+			{Name: "Odd Name", DataType: "text"},
+		}},
+		{Name: "job_categories", Columns: []ColumnSchema{{Name: "is_open", DataType: "boolean"}}},
+	})
+	require.NoError(t, err)
+
+	src := buf.String()
+	assert.Contains(t, src, "// Code generated by sqld gen. DO NOT EDIT.")
+	assert.Contains(t, src, "package models")
+	assert.Contains(t, src, `"time"`)
+	assert.Contains(t, src, "type Employee struct {")
+	assert.Contains(t, src, "ID        int64       `db:\"id\" json:\"id\"`")
+	assert.Contains(t, src, "ManagerID *int64      `db:\"manager_id\" json:\"manager_id\"`")
+	assert.Contains(t, src, "Address2  *string     `db:\"address_2\" json:\"address_2\"`")
+	assert.Contains(t, src, "HiredAt   time.Time   `db:\"hired_at\" json:\"hired_at\"`")
+	assert.Contains(t, src, "Profile   interface{} `db:\"profile\" json:\"profile\"`")
+	assert.NotContains(t, src, "Odd Name")
+	assert.Contains(t, src, `func (Employee) TableName() string { return "employees" }`)
+	assert.Contains(t, src, "type JobCategory struct {")
+	assert.Contains(t, src, "if err := sqld.Register(JobCategory{}); err != nil {")
+
+	err = GenerateModels(&buf, "models", []TableSchema{{Name: "boxes"}, {Name: "box"}})
+	assert.EqualError(t, err, "several tables map to the Go type Box")
+}
+
+func TestSingularize(t *testing.T) {
+	for _, name := range []string{"employee", "job_category", "address", "box", "branch", "status", "holiday"} {
+		assert.Equal(t, name, singularize(pluralize(name)), name)
+	}
+}
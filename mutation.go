@@ -0,0 +1,664 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// InsertRequest describes a row to insert.
+type InsertRequest struct {
+	// Values maps JSON field names to the values to insert. Fields tagged
+	// sqld:"required" must be present, fields tagged sqld:"readonly" must not.
+	Values map[string]interface{} `json:"values"`
+
+	// Returning lists the fields returned for each inserted row, such as
+	// generated ids, defaults and columns modified by triggers.
+	// Optional - by default, every field of the model is returned.
+	Returning []string `json:"returning,omitempty"`
+}
+
+// maxInsertParams is the number of bind parameters Postgres accepts in one
+// statement. InsertMany splits rows into statements staying under it.
+const maxInsertParams = 65535
+
+// InsertManyRequest describes rows to insert in bulk.
+type InsertManyRequest struct {
+	// Rows maps JSON field names to values, as in InsertRequest.Values.
+	// Every row must set the same fields.
+	Rows []map[string]interface{} `json:"rows"`
+
+	// Returning lists the fields returned for each inserted row, typically
+	// the generated id. Nothing is returned when it is empty.
+	Returning []string `json:"returning,omitempty"`
+
+	// BatchSize is the maximum number of rows per INSERT statement. It is
+	// lowered when needed to stay under the parameter limit of Postgres.
+	// Defaults to as many rows as the limit allows.
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// UpdateRequest describes an update of the rows matching a condition.
+type UpdateRequest struct {
+	// Set maps JSON field names to their new values. Fields tagged
+	// sqld:"readonly" can't be set.
+	Set map[string]interface{} `json:"set"`
+
+	// Where selects the rows to update, with the same grammar as
+	// QueryRequest.Where. Only fields of the model itself can be used.
+	Where map[string]interface{} `json:"where"`
+
+	// AllowFullTable must be set to update every row when Where is empty.
+	// Without it, an update without conditions is refused.
+	AllowFullTable bool `json:"allow_full_table,omitempty"`
+
+	// Returning lists the fields returned for each updated row, such as
+	// generated ids, defaults and columns modified by triggers.
+	// Optional - by default, every field of the model is returned.
+	Returning []string `json:"returning,omitempty"`
+}
+
+// PatchRequest describes a partial update from a JSON document, as sent by a
+// PATCH endpoint.
+type PatchRequest struct {
+	// Patch is a JSON object keyed by JSON field names. Only the keys present
+	// are updated: an absent key leaves the field unchanged, while an
+	// explicit null sets it to NULL.
+	Patch json.RawMessage `json:"patch"`
+
+	// Where selects the rows to patch, as in UpdateRequest. It can't be
+	// empty.
+	Where map[string]interface{} `json:"where"`
+
+	// Returning lists the fields returned for each patched row, such as
+	// generated ids, defaults and columns modified by triggers.
+	// Optional - by default, every field of the model is returned.
+	Returning []string `json:"returning,omitempty"`
+}
+
+// DeleteRequest describes a deletion of the rows matching a condition.
+type DeleteRequest struct {
+	// Where selects the rows to delete, with the same grammar as
+	// QueryRequest.Where. Only fields of the model itself can be used. It
+	// can't be empty: deleting a whole table is not supported.
+	Where map[string]interface{} `json:"where"`
+
+	// By is stored in the deleted-by field of models with soft deletion.
+	// Defaults to the actor of the context; see SetActorExtractor.
+	By interface{} `json:"by,omitempty"`
+
+	// Hard removes the rows of models with soft deletion instead of marking
+	// them as deleted.
+	Hard bool `json:"hard,omitempty"`
+
+	// Returning lists the fields returned for each deleted row, such as
+	// generated ids, defaults and columns modified by triggers.
+	// Optional - by default, every field of the model is returned.
+	Returning []string `json:"returning,omitempty"`
+}
+
+// MutationResponse holds the outcome of a write operation.
+type MutationResponse struct {
+	// Data holds the rows written, as returned by RETURNING and keyed by
+	// JSON field name.
+	Data []QueryResult `json:"data"`
+
+	// RowsAffected is the number of rows written.
+	RowsAffected int64 `json:"rows_affected"`
+}
+
+// Insert validates req against model T and inserts one row with a
+// parameterized INSERT ... RETURNING. The response holds the inserted row with
+// every field of the model, including those set by the database.
+func Insert[T Model](ctx context.Context, db interface{}, req InsertRequest) (*MutationResponse, error) {
+	var model T
+	metadata, err := registryFromContext(ctx).GetModelMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := validateInsert(metadata, req); err != nil {
+		return nil, fmt.Errorf("failed to validate insert: %w", err)
+	}
+	db, err = routeDB(ctx, db, req.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	returning := returningFields(metadata, req.Returning)
+	set := withValues(req.Values, metadata.owner().auditValues(ctx, metadata, true))
+	names := sortedKeys(set)
+	columns := make([]string, len(names))
+	values := make([]interface{}, len(names))
+	for i, name := range names {
+		columns[i] = metadata.Fields[name].Name
+		values[i] = set[name]
+	}
+	query := squirrel.Insert(metadata.TableName).
+		Columns(columns...).
+		Values(values...).
+		PlaceholderFormat(metadata.dialect().Placeholder())
+	query = applyReturning(query, metadata, returning)
+
+	return runMutation(ctx, db, metadata, MutationEvent{Operation: OpInsert, Request: req}, returning, query)
+}
+
+// InsertMany validates every row of req against model T and inserts them with
+// multi-row INSERT statements, each holding at most req.BatchSize rows.
+// Statements run one after the other: pass a connection inside a transaction
+// when the rows must be inserted atomically. RowsAffected counts the inserted
+// rows, and Data holds the fields of req.Returning for each of them. When a
+// statement fails, the response covers the statements that succeeded.
+func InsertMany[T Model](ctx context.Context, db interface{}, req InsertManyRequest) (*MutationResponse, error) {
+	var model T
+	metadata, err := registryFromContext(ctx).GetModelMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if len(req.Rows) == 0 {
+		return nil, fmt.Errorf("failed to validate insert: rows cannot be empty")
+	}
+	names := sortedKeys(req.Rows[0])
+	for i, row := range req.Rows {
+		if err := validateInsert(metadata, InsertRequest{Values: row}); err != nil {
+			return nil, fmt.Errorf("failed to validate insert: row %d: %w", i+1, err)
+		}
+		if len(row) != len(names) {
+			return nil, fmt.Errorf("failed to validate insert: row %d sets different fields than row 1", i+1)
+		}
+		for _, name := range names {
+			if _, ok := row[name]; !ok {
+				return nil, fmt.Errorf("failed to validate insert: row %d sets different fields than row 1", i+1)
+			}
+		}
+	}
+	if err := validateReturning(metadata, req.Returning); err != nil {
+		return nil, fmt.Errorf("failed to validate insert: %w", err)
+	}
+	db, err = routeRows(ctx, db, req.Rows)
+	if err != nil {
+		return nil, err
+	}
+
+	audit := metadata.owner().auditValues(ctx, metadata, true)
+	names = sortedKeys(withValues(req.Rows[0], audit))
+	columns := make([]string, len(names))
+	for i, name := range names {
+		columns[i] = metadata.Fields[name].Name
+	}
+	batchSize := maxInsertParams / len(columns)
+	if req.BatchSize > 0 && req.BatchSize < batchSize {
+		batchSize = req.BatchSize
+	}
+
+	resp := &MutationResponse{Data: []QueryResult{}}
+	for start := 0; start < len(req.Rows); start += batchSize {
+		end := start + batchSize
+		if end > len(req.Rows) {
+			end = len(req.Rows)
+		}
+		query := squirrel.Insert(metadata.TableName).Columns(columns...).PlaceholderFormat(metadata.dialect().Placeholder())
+		for _, row := range req.Rows[start:end] {
+			values := make([]interface{}, len(names))
+			for i, name := range names {
+				if value, ok := audit[name]; ok {
+					values[i] = value
+				} else {
+					values[i] = row[name]
+				}
+			}
+			query = query.Values(values...)
+		}
+
+		query = applyReturning(query, metadata, req.Returning)
+
+		batch, err := runMutation(ctx, db, metadata, MutationEvent{Operation: OpInsert, Request: req}, req.Returning, query)
+		if batch != nil {
+			resp.Data = append(resp.Data, batch.Data...)
+			resp.RowsAffected += batch.RowsAffected
+		}
+		if err != nil {
+			return resp, fmt.Errorf("failed to insert rows %d to %d: %w", start+1, end, err)
+		}
+	}
+	return resp, nil
+}
+
+// Update validates req against model T and updates the matching rows with a
+// parameterized UPDATE ... RETURNING. The response holds the updated rows with
+// every field of the model.
+func Update[T Model](ctx context.Context, db interface{}, req UpdateRequest) (*MutationResponse, error) {
+	var model T
+	metadata, err := registryFromContext(ctx).GetModelMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	return update(ctx, db, metadata, req, req)
+}
+
+// update implements Update. hookRequest is the request passed to hooks, which
+// differs from req for patches.
+func update(ctx context.Context, db interface{}, metadata ModelMetadata, req UpdateRequest, hookRequest interface{}) (*MutationResponse, error) {
+	if err := validateUpdate(metadata, req); err != nil {
+		return nil, fmt.Errorf("failed to validate update: %w", err)
+	}
+	db, err := routeDB(ctx, db, req.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	set := withValues(req.Set, metadata.owner().auditValues(ctx, metadata, false))
+	query := squirrel.Update(metadata.TableName).PlaceholderFormat(metadata.dialect().Placeholder())
+	for _, name := range sortedKeys(set) {
+		query = query.Set(metadata.Fields[name].Name, set[name])
+	}
+	if len(req.Where) > 0 {
+		eq, err := whereEq(metadata, req.Where, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build update: %w", err)
+		}
+		query = query.Where(eq)
+	}
+	returning := returningFields(metadata, req.Returning)
+	query = applyReturning(query, metadata, returning)
+
+	return runMutation(ctx, db, metadata, MutationEvent{Operation: OpUpdate, Request: hookRequest}, returning, query)
+}
+
+// Patch updates the fields present in req.Patch on the rows matching
+// req.Where. Each value is decoded into the Go type of its field, so e.g.
+// timestamps given as strings are parsed before being sent. The patch is
+// then validated and run like an Update.
+func Patch[T Model](ctx context.Context, db interface{}, req PatchRequest) (*MutationResponse, error) {
+	var model T
+	metadata, err := registryFromContext(ctx).GetModelMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	set, err := decodePatch(metadata, req.Patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate update: %w", err)
+	}
+	return update(ctx, db, metadata, UpdateRequest{Set: set, Where: req.Where, Returning: req.Returning}, req)
+}
+
+// decodePatch decodes the JSON object patch into the values of the fields of
+// metadata. Explicit nulls are kept as nil values.
+func decodePatch(metadata ModelMetadata, patch json.RawMessage) (map[string]interface{}, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &raw); err != nil {
+		return nil, fmt.Errorf("patch must be a JSON object: %w", err)
+	}
+	set := make(map[string]interface{}, len(raw))
+	for _, name := range sortedKeys(raw) {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return nil, unknownField(metadata, name, "")
+		}
+		if string(raw[name]) == "null" {
+			set[name] = nil
+			continue
+		}
+		// Wrappers such as sql.NullInt64 don't decode JSON; their value binds as is
+		t := field.Type
+		if underlying, ok := nullValueType(t); ok {
+			t = underlying
+		}
+		value := reflect.New(t)
+		if err := json.Unmarshal(raw[name], value.Interface()); err != nil {
+			return nil, &TypeMismatchError{Field: name, Value: raw[name], Err: err}
+		}
+		set[name] = value.Elem().Interface()
+	}
+	return set, nil
+}
+
+// Delete validates req against model T and deletes the matching rows with a
+// parameterized DELETE ... RETURNING. The response holds the deleted rows with
+// every field of the model, e.g. for an audit trail.
+//
+// For models registered with RegisterSoftDelete, the rows are marked as
+// deleted with an UPDATE setting the deleted-at field to the current time and
+// the deleted-by field to req.By. Rows already deleted are left untouched.
+// Set req.Hard to remove the rows instead.
+func Delete[T Model](ctx context.Context, db interface{}, req DeleteRequest) (*MutationResponse, error) {
+	var model T
+	metadata, err := registryFromContext(ctx).GetModelMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := checkWritable(metadata); err != nil {
+		return nil, fmt.Errorf("failed to validate delete: %w", err)
+	}
+	if err := validateMutationWhere(metadata, req.Where, false); err != nil {
+		return nil, fmt.Errorf("failed to validate delete: %w", err)
+	}
+	if sd := metadata.SoftDelete; sd != nil && sd.DeletedByField != "" && req.By != nil {
+		if err := validateValue(metadata.Fields[sd.DeletedByField], req.By); err != nil {
+			return nil, fmt.Errorf("failed to validate delete: %w", err)
+		}
+	}
+
+	if err := validateReturning(metadata, req.Returning); err != nil {
+		return nil, fmt.Errorf("failed to validate delete: %w", err)
+	}
+	db, err = routeDB(ctx, db, req.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	returning := returningFields(metadata, req.Returning)
+	eq, err := whereEq(metadata, req.Where, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build delete: %w", err)
+	}
+	if sd := metadata.SoftDelete; sd != nil && !req.Hard {
+		query := squirrel.Update(metadata.TableName).
+			Set(metadata.Fields[sd.DeletedAtField].Name, currentTime(metadata.dialect()))
+		if sd.DeletedByField != "" {
+			by := req.By
+			if by == nil {
+				by, _ = metadata.owner().actorFromContext(ctx)
+			}
+			query = query.Set(metadata.Fields[sd.DeletedByField].Name, by)
+		}
+		query = query.
+			Where(eq).
+			Where(notDeleted(metadata, "")).
+			PlaceholderFormat(metadata.dialect().Placeholder())
+		query = applyReturning(query, metadata, returning)
+		return runMutation(ctx, db, metadata, MutationEvent{Operation: OpDelete, Request: req}, returning, query)
+	}
+	query := squirrel.Delete(metadata.TableName).
+		Where(eq).
+		PlaceholderFormat(metadata.dialect().Placeholder())
+	query = applyReturning(query, metadata, returning)
+
+	return runMutation(ctx, db, metadata, MutationEvent{Operation: OpDelete, Request: req}, returning, query)
+}
+
+// validateInsert checks the values of req against the fields of metadata.
+func validateInsert(metadata ModelMetadata, req InsertRequest) error {
+	if err := checkWritable(metadata); err != nil {
+		return err
+	}
+	if len(req.Values) == 0 {
+		return fmt.Errorf("insert values cannot be empty")
+	}
+	if err := validateReturning(metadata, req.Returning); err != nil {
+		return err
+	}
+	if err := validateValues(metadata, req.Values); err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(metadata.Fields) {
+		field := metadata.Fields[name]
+		if field.Required && req.Values[name] == nil && !isAuditField(metadata, name) {
+			return fmt.Errorf("missing required field: %s", name)
+		}
+	}
+	return validateConstraints(metadata, req.Values, true)
+}
+
+// validateUpdate checks the values and conditions of req against the fields
+// of metadata.
+func validateUpdate(metadata ModelMetadata, req UpdateRequest) error {
+	if err := checkWritable(metadata); err != nil {
+		return err
+	}
+	if len(req.Set) == 0 {
+		return fmt.Errorf("update values cannot be empty")
+	}
+	if err := validateReturning(metadata, req.Returning); err != nil {
+		return err
+	}
+	if err := validateValues(metadata, req.Set); err != nil {
+		return err
+	}
+	if err := validateConstraints(metadata, req.Set, false); err != nil {
+		return err
+	}
+	return validateMutationWhere(metadata, req.Where, req.AllowFullTable)
+}
+
+// validateMutationWhere checks the conditions of a write operation. They may
+// only be empty when allowFullTable is set.
+func validateMutationWhere(metadata ModelMetadata, where map[string]interface{}, allowFullTable bool) error {
+	if len(where) == 0 && !allowFullTable {
+		return fmt.Errorf("where clause cannot be empty")
+	}
+	for _, name := range sortedKeys(where) {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return unknownField(metadata, name, "where clause")
+		}
+		if err := field.checkFilterable(name); err != nil {
+			return err
+		}
+	}
+	return metadata.owner().validateEnumValues(metadata, where)
+}
+
+// validateValues checks that values only writes known, writable fields with
+// values of a compatible type.
+func validateValues(metadata ModelMetadata, values map[string]interface{}) error {
+	for _, name := range sortedKeys(values) {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return fmt.Errorf("invalid field: %s", name)
+		}
+		if field.ReadOnly {
+			return fmt.Errorf("field %s is read-only and can't be written", name)
+		}
+		if isAuditField(metadata, name) {
+			return fmt.Errorf("field %s is set automatically", name)
+		}
+		if err := validateValue(field, values[name]); err != nil {
+			return err
+		}
+	}
+	return metadata.owner().validateEnumValues(metadata, values)
+}
+
+// validateValue checks that value can be stored in field. Numbers decoded
+// from JSON are accepted for any numeric field as long as they fit, e.g. a
+// float64 with no fractional part for an integer field.
+func validateValue(field Field, value interface{}) error {
+	t := field.Type
+	if value == nil {
+		if isNullableType(t) {
+			return nil
+		}
+		return fmt.Errorf("field %s cannot be null", field.JSONName)
+	}
+	if convert := canonicalValue(t); convert != nil {
+		if _, err := convert(value); err != nil {
+			return &TypeMismatchError{Field: field.JSONName, Value: value, Err: err}
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(t) {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.Type().AssignableTo(t) {
+			return nil
+		}
+	}
+	// Wrappers such as sql.NullInt64 accept the value they hold
+	if underlying, ok := nullValueType(t); ok {
+		return validateValue(Field{JSONName: field.JSONName, Type: underlying}, value)
+	}
+	// Other scanner types accept what the driver accepts
+	if reflect.PointerTo(t).Implements(sqlScannerType) {
+		return nil
+	}
+
+	switch {
+	case isNumericType(t) && isNumericType(v.Type()):
+		if isIntegerKind(t.Kind()) && (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) && v.Float() != math.Trunc(v.Float()) {
+			return &TypeMismatchError{Field: field.JSONName, Value: value, Err: fmt.Errorf("%v is not an integer", value)}
+		}
+		return nil
+	case isNumericType(t) && v.Type() == reflect.TypeOf(json.Number("")):
+		n := value.(json.Number)
+		if isIntegerKind(t.Kind()) {
+			if _, err := n.Int64(); err != nil {
+				return &TypeMismatchError{Field: field.JSONName, Value: value, Err: fmt.Errorf("%v is not an integer", value)}
+			}
+		}
+		return nil
+	case t.Kind() == reflect.String && v.Kind() == reflect.String,
+		t.Kind() == reflect.Bool && v.Kind() == reflect.Bool:
+		return nil
+	}
+	return &TypeMismatchError{Field: field.JSONName, Value: value, Err: fmt.Errorf("expected %s, got %T", field.Type, value)}
+}
+
+// sqlScannerType is the type of the sql.Scanner interface.
+var sqlScannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// isNullableType reports whether a field of type t can hold NULL: pointers,
+// slices and maps, and scanner types such as sql.NullString.
+func isNullableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return reflect.PointerTo(t).Implements(sqlScannerType)
+}
+
+// isIntegerKind reports whether k is a signed or unsigned integer kind.
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// validateReturning checks that the fields requested in a RETURNING clause
+// exist and that the dialect supports it.
+func validateReturning(metadata ModelMetadata, returning []string) error {
+	if dialect := metadata.dialect(); len(returning) > 0 && !dialect.SupportsReturning() {
+		return fmt.Errorf("returning is not supported by the %s dialect", dialect.Name())
+	}
+	for _, name := range returning {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return unknownField(metadata, name, "returning")
+		}
+		if err := field.checkSelectable(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returningFields returns the JSON names of the fields to return: those
+// requested, or every field of metadata but filter-only ones, sorted, when
+// none are. Nothing is returned when the dialect doesn't support RETURNING.
+func returningFields(metadata ModelMetadata, requested []string) []string {
+	if len(requested) > 0 {
+		return requested
+	}
+	if !metadata.dialect().SupportsReturning() {
+		return nil
+	}
+	var fields []string
+	for _, name := range sortedKeys(metadata.Fields) {
+		if !metadata.Fields[name].FilterOnly {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// returningClause returns the RETURNING clause listing fields.
+func returningClause(metadata ModelMetadata, fields []string) string {
+	columns := make([]string, len(fields))
+	for i, name := range fields {
+		columns[i] = metadata.Fields[name].Name
+	}
+	return "RETURNING " + strings.Join(columns, ", ")
+}
+
+// suffixer is a statement builder accepting a suffix, such as
+// squirrel.InsertBuilder.
+type suffixer[B any] interface {
+	Suffix(sql string, args ...interface{}) B
+}
+
+// applyReturning ends query with the RETURNING clause of fields, if any.
+func applyReturning[B suffixer[B]](query B, metadata ModelMetadata, fields []string) B {
+	if len(fields) == 0 {
+		return query
+	}
+	return query.Suffix(returningClause(metadata, fields))
+}
+
+// runMutation executes a write statement between the hooks of metadata for
+// e. When fields is not empty, the statement ends with the RETURNING clause
+// of fields and the returned rows are converted; otherwise only the number
+// of affected rows is reported. When an after hook fails, the response is
+// returned along with the error.
+func runMutation(ctx context.Context, db interface{}, metadata ModelMetadata, e MutationEvent, fields []string, query squirrel.Sqlizer) (resp *MutationResponse, err error) {
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sql: %w", err)
+	}
+	e.SQL, e.Args = sqlQuery, args
+	if err := metadata.Hooks.runBefore(ctx, e); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() {
+		var rows int64
+		if resp != nil {
+			rows = resp.RowsAffected
+		}
+		recordCall(ctx, metadata, e.Operation, start, rows, err)
+	}()
+	resp = &MutationResponse{Data: []QueryResult{}}
+	if len(fields) == 0 {
+		resp.RowsAffected, err = execAffected(ctx, db, sqlQuery, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+	} else {
+		var results []map[string]interface{}
+		if err := selectAll(ctx, db, &results, sqlQuery, args...); err != nil {
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+		if resp.Data, err = toQueryResults(metadata, fields, results); err != nil {
+			return nil, err
+		}
+		resp.RowsAffected = int64(len(results))
+	}
+
+	e.Response = resp
+	return resp, metadata.Hooks.runAfter(ctx, e)
+}
+
+// sortedKeys returns the keys of m in sorted order, so that generated SQL is
+// stable.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
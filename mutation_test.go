@@ -0,0 +1,415 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MutationAccount struct {
+	ID      int64   `json:"id" db:"id" sqld:"readonly"`
+	Owner   string  `json:"owner" db:"owner_name" sqld:"required"`
+	Balance float64 `json:"balance" db:"balance"`
+	Note    *string `json:"note" db:"note"`
+}
+
+func (MutationAccount) TableName() string {
+	return "mutation_accounts"
+}
+
+func TestRegister_FieldOptions(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(MutationAccount{}))
+	metadata, err := registry.GetModelMetadata(MutationAccount{})
+	require.NoError(t, err)
+
+	assert.True(t, metadata.Fields["id"].ReadOnly)
+	assert.False(t, metadata.Fields["id"].Required)
+	assert.True(t, metadata.Fields["owner"].Required)
+	assert.False(t, metadata.Fields["balance"].Required)
+	assert.False(t, metadata.Fields["balance"].ReadOnly)
+}
+
+func TestInsert(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO mutation_accounts \(balance,owner_name\) VALUES \(\$1,\$2\) RETURNING balance, id, note, owner_name`).
+		WithArgs(10.5, "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "id", "note", "owner_name"}).AddRow(10.5, 7, nil, "alice"))
+
+	resp, err := Insert[MutationAccount](context.Background(), db, InsertRequest{
+		Values: map[string]interface{}{"owner": "alice", "balance": 10.5},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+	assert.Equal(t, []QueryResult{{"balance": 10.5, "id": int64(7), "note": nil, "owner": "alice"}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsert_Invalid(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	require.NoError(t, Register(SalesReport{}))
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+	}{
+		{"empty", nil},
+		{"unknown field", map[string]interface{}{"owner": "alice", "color": "red"}},
+		{"read-only field", map[string]interface{}{"owner": "alice", "id": 1}},
+		{"missing required field", map[string]interface{}{"balance": 1}},
+		{"null required field", map[string]interface{}{"owner": nil}},
+		{"wrong type", map[string]interface{}{"owner": 42}},
+		{"null for non-nullable field", map[string]interface{}{"owner": "alice", "balance": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Insert[MutationAccount](context.Background(), nil, InsertRequest{Values: tt.values})
+			assert.ErrorContains(t, err, "failed to validate insert")
+		})
+	}
+
+	_, err := Insert[SalesReport](context.Background(), nil, InsertRequest{Values: map[string]interface{}{"region": "north"}})
+	assert.ErrorContains(t, err, "read-only")
+}
+
+func TestValidateValue(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(MutationAccount{}))
+	accounts, err := registry.GetModelMetadata(MutationAccount{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		field   string
+		value   interface{}
+		wantErr bool
+	}{
+		{"exact type", "id", int64(1), false},
+		{"other integer type", "id", 1, false},
+		{"integral float for integer", "id", float64(3), false},
+		{"fractional float for integer", "id", 3.5, true},
+		{"json number for integer", "id", json.Number("12"), false},
+		{"fractional json number for integer", "id", json.Number("1.5"), true},
+		{"integer for float", "balance", 3, false},
+		{"string for float", "balance", "3", true},
+		{"value for pointer", "note", "hello", false},
+		{"null for pointer", "note", nil, false},
+		{"null for string", "owner", nil, true},
+		{"bool for string", "owner", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateValue(accounts.Fields[tt.field], tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`UPDATE mutation_accounts SET balance = \$1, note = \$2 WHERE id IN \(\$3,\$4\) RETURNING balance, id, note, owner_name`).
+		WithArgs(0.0, nil, 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "id", "note", "owner_name"}).
+			AddRow(0.0, 1, nil, "alice").
+			AddRow(0.0, 2, nil, "bob"))
+
+	resp, err := Update[MutationAccount](context.Background(), db, UpdateRequest{
+		Set:   map[string]interface{}{"balance": 0.0, "note": nil},
+		Where: map[string]interface{}{"id": []int{1, 2}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.RowsAffected)
+	assert.Equal(t, "bob", resp.Data[1]["owner"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_FullTable(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = Update[MutationAccount](context.Background(), db, UpdateRequest{
+		Set: map[string]interface{}{"balance": 0},
+	})
+	assert.ErrorContains(t, err, "where clause cannot be empty")
+
+	mock.ExpectQuery(`UPDATE mutation_accounts SET balance = \$1 RETURNING`).
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "id", "note", "owner_name"}))
+
+	resp, err := Update[MutationAccount](context.Background(), db, UpdateRequest{
+		Set:            map[string]interface{}{"balance": 0},
+		AllowFullTable: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), resp.RowsAffected)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_Invalid(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	tests := []struct {
+		name string
+		req  UpdateRequest
+	}{
+		{"empty set", UpdateRequest{Where: map[string]interface{}{"id": 1}}},
+		{"read-only field", UpdateRequest{Set: map[string]interface{}{"id": 2}, Where: map[string]interface{}{"id": 1}}},
+		{"wrong type", UpdateRequest{Set: map[string]interface{}{"owner": 1}, Where: map[string]interface{}{"id": 1}}},
+		{"unknown where field", UpdateRequest{Set: map[string]interface{}{"owner": "bob"}, Where: map[string]interface{}{"color": 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Update[MutationAccount](context.Background(), nil, tt.req)
+			assert.ErrorContains(t, err, "failed to validate update")
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`DELETE FROM mutation_accounts WHERE owner_name = \$1 RETURNING balance, id, note, owner_name`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "id", "note", "owner_name"}).AddRow(3.0, 1, nil, "alice"))
+
+	resp, err := Delete[MutationAccount](context.Background(), db, DeleteRequest{
+		Where: map[string]interface{}{"owner": "alice"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+	assert.Equal(t, []QueryResult{{"balance": 3.0, "id": int64(1), "note": nil, "owner": "alice"}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelete_Invalid(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	require.NoError(t, Register(SalesReport{}))
+
+	_, err := Delete[MutationAccount](context.Background(), nil, DeleteRequest{})
+	assert.ErrorContains(t, err, "where clause cannot be empty")
+
+	_, err = Delete[MutationAccount](context.Background(), nil, DeleteRequest{Where: map[string]interface{}{"color": "red"}})
+	assert.ErrorContains(t, err, "invalid field")
+
+	_, err = Delete[SalesReport](context.Background(), nil, DeleteRequest{Where: map[string]interface{}{"region": "north"}})
+	assert.ErrorContains(t, err, "read-only")
+}
+
+func TestInsertMany(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO mutation_accounts \(balance,owner_name\) VALUES \(\$1,\$2\),\(\$3,\$4\) RETURNING id`).
+		WithArgs(1, "a", 2, "b").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery(`INSERT INTO mutation_accounts \(balance,owner_name\) VALUES \(\$1,\$2\) RETURNING id`).
+		WithArgs(3, "c").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	resp, err := InsertMany[MutationAccount](context.Background(), db, InsertManyRequest{
+		Rows: []map[string]interface{}{
+			{"owner": "a", "balance": 1},
+			{"owner": "b", "balance": 2},
+			{"owner": "c", "balance": 3},
+		},
+		Returning: []string{"id"},
+		BatchSize: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.RowsAffected)
+	assert.Equal(t, []QueryResult{{"id": int64(1)}, {"id": int64(2)}, {"id": int64(3)}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertMany_WithoutReturning(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO mutation_accounts \(owner_name\) VALUES \(\$1\),\(\$2\)`).
+		WithArgs("a", "b").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	resp, err := InsertMany[MutationAccount](context.Background(), db, InsertManyRequest{
+		Rows: []map[string]interface{}{{"owner": "a"}, {"owner": "b"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.RowsAffected)
+	assert.Empty(t, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertMany_Invalid(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	tests := []struct {
+		name string
+		req  InsertManyRequest
+	}{
+		{"no rows", InsertManyRequest{}},
+		{"invalid row", InsertManyRequest{Rows: []map[string]interface{}{{"owner": "a"}, {"owner": 2}}}},
+		{"different fields", InsertManyRequest{Rows: []map[string]interface{}{{"owner": "a"}, {"owner": "b", "balance": 1}}}},
+		{"same count, different fields", InsertManyRequest{Rows: []map[string]interface{}{{"owner": "a", "note": nil}, {"owner": "b", "balance": 1}}}},
+		{"unknown returning field", InsertManyRequest{Rows: []map[string]interface{}{{"owner": "a"}}, Returning: []string{"uuid"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := InsertMany[MutationAccount](context.Background(), nil, tt.req)
+			assert.ErrorContains(t, err, "failed to validate insert")
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// balance is absent and left unchanged, note is cleared
+	mock.ExpectQuery(`UPDATE mutation_accounts SET note = \$1, owner_name = \$2 WHERE id = \$3 RETURNING balance, id, note, owner_name`).
+		WithArgs(nil, "carol", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "id", "note", "owner_name"}).AddRow(5.0, 4, nil, "carol"))
+
+	resp, err := Patch[MutationAccount](context.Background(), db, PatchRequest{
+		Patch: json.RawMessage(`{"owner": "carol", "note": null}`),
+		Where: map[string]interface{}{"id": 4},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDecodePatch(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+	metadata, err := getModelMetadata(MutationAccount{})
+	require.NoError(t, err)
+
+	set, err := decodePatch(metadata, json.RawMessage(`{"balance": 12, "note": "hi"}`))
+	require.NoError(t, err)
+	note := "hi"
+	assert.Equal(t, map[string]interface{}{"balance": 12.0, "note": &note}, set)
+
+	set, err = decodePatch(metadata, json.RawMessage(`{"note": null}`))
+	require.NoError(t, err)
+	value, present := set["note"]
+	assert.True(t, present)
+	assert.Nil(t, value)
+
+	_, err = decodePatch(metadata, json.RawMessage(`{"balance": "twelve"}`))
+	assert.ErrorContains(t, err, "invalid value for field balance")
+	_, err = decodePatch(metadata, json.RawMessage(`{"color": "red"}`))
+	assert.ErrorContains(t, err, "invalid field")
+	_, err = decodePatch(metadata, json.RawMessage(`[1, 2]`))
+	assert.ErrorContains(t, err, "JSON object")
+}
+
+func TestPatch_Invalid(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	tests := []struct {
+		name string
+		req  PatchRequest
+	}{
+		{"empty patch", PatchRequest{Patch: json.RawMessage(`{}`), Where: map[string]interface{}{"id": 1}}},
+		{"no where", PatchRequest{Patch: json.RawMessage(`{"owner": "bob"}`)}},
+		{"null for non-nullable field", PatchRequest{Patch: json.RawMessage(`{"owner": null}`), Where: map[string]interface{}{"id": 1}}},
+		{"read-only field", PatchRequest{Patch: json.RawMessage(`{"id": 9}`), Where: map[string]interface{}{"id": 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Patch[MutationAccount](context.Background(), nil, tt.req)
+			assert.ErrorContains(t, err, "failed to validate update")
+		})
+	}
+}
+
+func TestMutation_Returning(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO mutation_accounts \(owner_name\) VALUES \(\$1\) RETURNING id, balance$`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(9, 0.0))
+	mock.ExpectQuery(`UPDATE mutation_accounts SET balance = \$1 WHERE id = \$2 RETURNING balance$`).
+		WithArgs(5.0, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(5.0))
+	mock.ExpectQuery(`DELETE FROM mutation_accounts WHERE id = \$1 RETURNING owner_name$`).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_name"}).AddRow("alice"))
+
+	ctx := context.Background()
+	resp, err := Insert[MutationAccount](ctx, db, InsertRequest{
+		Values:    map[string]interface{}{"owner": "alice"},
+		Returning: []string{"id", "balance"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(9), "balance": 0.0}}, resp.Data)
+
+	resp, err = Patch[MutationAccount](ctx, db, PatchRequest{
+		Patch:     json.RawMessage(`{"balance": 5}`),
+		Where:     map[string]interface{}{"id": 9},
+		Returning: []string{"balance"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"balance": 5.0}}, resp.Data)
+
+	resp, err = Delete[MutationAccount](ctx, db, DeleteRequest{
+		Where:     map[string]interface{}{"id": 9},
+		Returning: []string{"owner"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"owner": "alice"}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = Insert[MutationAccount](ctx, db, InsertRequest{
+		Values:    map[string]interface{}{"owner": "alice"},
+		Returning: []string{"uuid"},
+	})
+	assert.ErrorContains(t, err, "invalid field in returning")
+	_, err = Update[MutationAccount](ctx, db, UpdateRequest{
+		Set:       map[string]interface{}{"balance": 1},
+		Where:     map[string]interface{}{"id": 9},
+		Returning: []string{"uuid"},
+	})
+	assert.ErrorContains(t, err, "invalid field in returning")
+	_, err = Delete[MutationAccount](ctx, db, DeleteRequest{
+		Where:     map[string]interface{}{"id": 9},
+		Returning: []string{"uuid"},
+	})
+	assert.ErrorContains(t, err, "invalid field in returning")
+}
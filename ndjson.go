@@ -0,0 +1,78 @@
+package sqld
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// exportFlushRows is the number of rows WriteNDJSON and ExecuteCSV write
+// between flushes.
+const exportFlushRows = 100
+
+// flusher is implemented by writers buffering what is written to them, such
+// as http.ResponseWriter.
+type flusher interface {
+	Flush()
+}
+
+// errorFlusher is implemented by writers buffering what is written to them
+// whose flush can fail, such as *bufio.Writer.
+type errorFlusher interface {
+	Flush() error
+}
+
+// WriteNDJSON runs req like ExecuteStream and writes its rows to w as
+// newline-delimited JSON, one object per line, as they are read, so that
+// large exports never hold the full payload. Rows are flushed every 100 rows
+// and at the end when w can be flushed, e.g. an http.ResponseWriter, so that
+// clients receive them as they come. It returns the number of rows written;
+// an error after the first row leaves a truncated output behind.
+func WriteNDJSON[T Model](ctx context.Context, db interface{}, req QueryRequest, w io.Writer, opts ...ExecuteOption) (written int, err error) {
+	stream, err := ExecuteStream[T](ctx, db, req, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+
+	w, closeOutput := exportWriter(newExecuteConfig(opts), w)
+	defer func() {
+		if closeErr := closeOutput(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to write rows: %w", closeErr)
+		}
+	}()
+
+	buf := bufio.NewWriter(w)
+	flush := func() error { return flushWriter(buf, w) }
+
+	encoder := json.NewEncoder(buf)
+	for stream.Next() {
+		if err := encoder.Encode(stream.Row()); err != nil {
+			return written, fmt.Errorf("failed to write row: %w", err)
+		}
+		written++
+		if written%exportFlushRows == 0 {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		flush() // What was read is still written
+		return written, err
+	}
+	return written, flush()
+}
+
+// flushWriter writes what buf holds to w, and flushes w if it can be.
+func flushWriter(buf *bufio.Writer, w io.Writer) error {
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	if err := flushUnderlying(w); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name"})
+	for i := 1; i <= 150; i++ {
+		rows.AddRow(i, "n")
+	}
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).WillReturnRows(rows).RowsWillBeClosed()
+	w := httptest.NewRecorder()
+	n, err := WriteNDJSON[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id", "name"}}, w)
+	require.NoError(t, err)
+	assert.Equal(t, 150, n)
+	assert.True(t, w.Flushed)
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 150)
+	assert.Equal(t, `{"id":1,"name":"n"}`, lines[0])
+	assert.Equal(t, `{"id":150,"name":"n"}`, lines[149])
+
+	// Rows read before a failure are still written
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, errors.New("connection reset")))
+	var out strings.Builder
+	n, err = WriteNDJSON[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}}, &out)
+	assert.EqualError(t, err, "failed to execute query: connection reset")
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "{\"id\":1}\n", out.String())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
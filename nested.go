@@ -0,0 +1,196 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// UnmarshalJSON decodes a QueryRequest. In addition to plain field names,
+// entries of "select" may be objects mapping a relation to the fields to load
+// from it, which are collected into Nested:
+//
+//	{"select": ["id", "first_name", {"department": ["id", "name"]}]}
+func (q *QueryRequest) UnmarshalJSON(data []byte) error {
+	type plain QueryRequest
+	var raw struct {
+		plain
+		Select []json.RawMessage `json:"select"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*q = QueryRequest(raw.plain)
+
+	q.Select = nil
+	for _, entry := range raw.Select {
+		var name string
+		if err := json.Unmarshal(entry, &name); err == nil {
+			q.Select = append(q.Select, name)
+			continue
+		}
+		var nested map[string][]string
+		if err := json.Unmarshal(entry, &nested); err != nil {
+			return fmt.Errorf("select entries must be field names or {relation: [fields]} objects: %s", entry)
+		}
+		if q.Nested == nil {
+			q.Nested = make(map[string][]string)
+		}
+		for relation, fields := range nested {
+			q.Nested[relation] = append(q.Nested[relation], fields...)
+		}
+	}
+	return nil
+}
+
+// validateNested checks that every nested selection names a relation of the
+// model and fields of the related model.
+func validateNested(metadata ModelMetadata, nested map[string][]string) error {
+	for name, fields := range nested {
+		rel, ok := metadata.Relations[name]
+		if !ok {
+			return fmt.Errorf("invalid relation in select: %s", name)
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("select fields for relation %s cannot be empty", name)
+		}
+		for _, field := range fields {
+			f, ok := rel.target.Fields[field]
+			if !ok {
+				return &UnknownFieldError{Field: name + "." + field, In: "select"}
+			}
+			if err := f.checkSelectable(name + "." + field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nestedKeyFields returns the local key fields required by the nested
+// selections of req that are not already selected.
+func nestedKeyFields(metadata ModelMetadata, req QueryRequest) []string {
+	selected := make(map[string]bool, len(req.Select))
+	for _, field := range req.Select {
+		selected[field] = true
+	}
+	var hidden []string
+	for name := range req.Nested {
+		local := metadata.Relations[name].LocalField
+		if !selected[local] {
+			selected[local] = true
+			hidden = append(hidden, local)
+		}
+	}
+	return hidden
+}
+
+// nestedLookup returns a Lookup that eager loads the given fields of a
+// related model for a page of rows. All children are fetched with a single
+// query per relation (WHERE key IN (...)) to avoid N+1 queries.
+// BelongsTo relations yield an object (or nil) per row, HasMany and ManyToMany
+// relations yield an array. Children are converted and transformed like rows
+// of the related model, and soft-deleted children are skipped unless
+// includeDeleted is set.
+func nestedLookup(metadata ModelMetadata, name string, fields []string, includeDeleted bool) Lookup {
+	rel := metadata.Relations[name]
+	localKey := metadata.Fields[rel.LocalField].JSONName
+
+	return Lookup{
+		Load: func(ctx context.Context, db interface{}, rows []QueryResult) ([]interface{}, error) {
+			values := make([]interface{}, len(rows))
+			if rel.Kind != BelongsTo {
+				for i := range values {
+					values[i] = []QueryResult{}
+				}
+			}
+
+			var keys []interface{}
+			seen := make(map[string]bool)
+			for _, row := range rows {
+				key := row[localKey]
+				if key == nil || seen[fmt.Sprint(key)] {
+					continue
+				}
+				seen[fmt.Sprint(key)] = true
+				keys = append(keys, key)
+			}
+			if len(keys) == 0 {
+				return values, nil
+			}
+
+			// The key linking each child back to its parent is selected under
+			// a reserved alias and stripped from the nested objects.
+			const parentKey = "__sqld_parent_key"
+			target := rel.target
+			foreign := target.TableName + "." + target.Fields[rel.ForeignField].Name
+
+			dialect := metadata.dialect()
+			columns := make([]string, 0, len(fields)+1)
+			for _, field := range fields {
+				f := target.Fields[field]
+				columns = append(columns, fmt.Sprintf(`%s.%s AS %s`, target.TableName, f.Name, dialect.QuoteIdent(f.JSONName)))
+			}
+
+			builder := squirrel.StatementBuilder.PlaceholderFormat(dialect.Placeholder())
+			var query squirrel.SelectBuilder
+			switch rel.Kind {
+			case ManyToMany:
+				through := rel.Through.Table + "." + rel.Through.LocalColumn
+				columns = append(columns, fmt.Sprintf(`%s AS %s`, through, dialect.QuoteIdent(parentKey)))
+				query = builder.Select(columns...).
+					From(target.TableName).
+					Join(fmt.Sprintf("%s ON %s.%s = %s", rel.Through.Table, rel.Through.Table, rel.Through.ForeignColumn, foreign)).
+					Where(squirrel.Eq{through: keys})
+			default:
+				columns = append(columns, fmt.Sprintf(`%s AS %s`, foreign, dialect.QuoteIdent(parentKey)))
+				query = builder.Select(columns...).
+					From(target.TableName).
+					Where(squirrel.Eq{foreign: keys})
+			}
+			query = applySoftDelete(query, target, includeDeleted, true)
+
+			sqlStr, args, err := query.ToSql()
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate sql: %w", err)
+			}
+			var children []map[string]interface{}
+			if err := selectAll(ctx, db, &children, sqlStr, args...); err != nil {
+				return nil, fmt.Errorf("failed to execute query: %w", err)
+			}
+
+			parents := make([]string, len(children))
+			for i, child := range children {
+				parents[i] = fmt.Sprint(child[parentKey])
+			}
+			converted, err := toQueryResults(target, fields, children)
+			if err != nil {
+				return nil, err
+			}
+			if err := transformResults(ctx, target, converted); err != nil {
+				return nil, err
+			}
+
+			grouped := make(map[string][]QueryResult)
+			for i, child := range converted {
+				grouped[parents[i]] = append(grouped[parents[i]], child)
+			}
+
+			for i, row := range rows {
+				matches := grouped[fmt.Sprint(row[localKey])]
+				if rel.Kind == BelongsTo {
+					if len(matches) > 0 {
+						values[i] = matches[0]
+					}
+					continue
+				}
+				if matches != nil {
+					values[i] = matches
+				}
+			}
+			return values, nil
+		},
+	}
+}
@@ -0,0 +1,156 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRequest_UnmarshalNestedSelect(t *testing.T) {
+	var req QueryRequest
+	err := json.Unmarshal([]byte(`{
+		"select": ["id", "name", {"department": ["id", "name"]}],
+		"where": {"id": 1},
+		"pagination": {"page": 2, "page_size": 5}
+	}`), &req)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"id", "name"}, req.Select)
+	assert.Equal(t, map[string][]string{"department": {"id", "name"}}, req.Nested)
+	assert.Equal(t, map[string]interface{}{"id": float64(1)}, req.Where)
+	assert.Equal(t, &PaginationRequest{Page: 2, PageSize: 5}, req.Pagination)
+
+	err = json.Unmarshal([]byte(`{"select": [42]}`), &req)
+	assert.Error(t, err)
+}
+
+func TestExecute_NestedRelations(t *testing.T) {
+	require.NoError(t, Register(RelEmployee{}))
+	require.NoError(t, Register(RelDepartment{}))
+	require.NoError(t, RegisterRelation[RelEmployee, RelDepartment]("department", Relation{
+		Kind:         BelongsTo,
+		LocalField:   "department_id",
+		ForeignField: "id",
+	}))
+	require.NoError(t, RegisterRelation[RelDepartment, RelEmployee]("employees", Relation{
+		Kind:         HasMany,
+		LocalField:   "id",
+		ForeignField: "department_id",
+	}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Run("belongs to loads one object per row", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT name, department_id FROM employees`).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "department_id"}).
+				AddRow("alice", int64(1)).
+				AddRow("bob", int64(1)).
+				AddRow("carol", int64(7)))
+		mock.ExpectQuery(`SELECT departments.name AS "name", departments.id AS "__sqld_parent_key" FROM departments WHERE departments.id IN \(\$1,\$2\)`).
+			WithArgs(int64(1), int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "__sqld_parent_key"}).AddRow("eng", int64(1)))
+
+		resp, err := Execute[RelEmployee](context.Background(), db, QueryRequest{
+			Select: []string{"name"},
+			Nested: map[string][]string{"department": {"name"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []QueryResult{
+			{"name": "alice", "department": QueryResult{"name": "eng"}},
+			{"name": "bob", "department": QueryResult{"name": "eng"}},
+			{"name": "carol", "department": nil},
+		}, resp.Data)
+	})
+
+	t.Run("has many loads arrays", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, name FROM departments`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+				AddRow(int64(1), "eng").
+				AddRow(int64(2), "ops"))
+		mock.ExpectQuery(`SELECT employees.name AS "name", employees.department_id AS "__sqld_parent_key" FROM employees WHERE employees.department_id IN \(\$1,\$2\)`).
+			WithArgs(int64(1), int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "__sqld_parent_key"}).
+				AddRow("alice", int64(1)).
+				AddRow("bob", int64(1)))
+
+		resp, err := Execute[RelDepartment](context.Background(), db, QueryRequest{
+			Select: []string{"id", "name"},
+			Nested: map[string][]string{"employees": {"name"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []QueryResult{
+			{"id": int64(1), "name": "eng", "employees": []QueryResult{{"name": "alice"}, {"name": "bob"}}},
+			{"id": int64(2), "name": "ops", "employees": []QueryResult{}},
+		}, resp.Data)
+	})
+
+	t.Run("invalid nested selection", func(t *testing.T) {
+		_, err := Execute[RelEmployee](context.Background(), db, QueryRequest{
+			Select: []string{"name"},
+			Nested: map[string][]string{"department": {"budget"}},
+		})
+		assert.Error(t, err)
+
+		_, err = Execute[RelEmployee](context.Background(), db, QueryRequest{
+			Select: []string{"name"},
+			Nested: map[string][]string{"team": {"name"}},
+		})
+		assert.Error(t, err)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_NestedChildrenLikeRelatedRows(t *testing.T) {
+	registry := NewRegistry()
+	registerSoftDeleteModels(t, registry)
+	require.NoError(t, registry.AddModelResultTransformer(SoftUser{}, func(ctx context.Context, metadata ModelMetadata, row QueryResult) error {
+		row["name"] = strings.ToUpper(row["name"].(string))
+		return nil
+	}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{
+		Select: []string{"amount"},
+		Nested: map[string][]string{"owner": {"name"}},
+	}
+
+	t.Run("deleted children skipped", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT amount, user_id FROM holdings WHERE deleted_at IS NULL`).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "user_id"}).AddRow(10.5, int64(3)))
+		mock.ExpectQuery(`SELECT users.name AS "name", users.id AS "__sqld_parent_key" FROM users WHERE users.id IN \(\$1\) AND users.deleted_at IS NULL`).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "__sqld_parent_key"}).AddRow("alice", int64(3)))
+
+		resp, err := Execute[SoftHolding](ctx, db, req)
+		require.NoError(t, err)
+		assert.Equal(t, []QueryResult{{"amount": 10.5, "owner": QueryResult{"name": "ALICE"}}}, resp.Data)
+	})
+
+	t.Run("deleted children included", func(t *testing.T) {
+		withDeleted := req
+		withDeleted.IncludeDeleted = true
+		mock.ExpectQuery(`SELECT amount, user_id FROM holdings$`).
+			WillReturnRows(sqlmock.NewRows([]string{"amount", "user_id"}).AddRow(10.5, int64(3)))
+		mock.ExpectQuery(`SELECT users.name AS "name", users.id AS "__sqld_parent_key" FROM users WHERE users.id IN \(\$1\)$`).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "__sqld_parent_key"}).AddRow("alice", int64(3)))
+
+		resp, err := Execute[SoftHolding](ctx, db, withDeleted)
+		require.NoError(t, err)
+		assert.Equal(t, []QueryResult{{"amount": 10.5, "owner": QueryResult{"name": "ALICE"}}}, resp.Data)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
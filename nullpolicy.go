@@ -0,0 +1,49 @@
+package sqld
+
+// NullPolicy is how null values are returned in the results of Execute,
+// ExecuteRaw and the other calls, see SetNullPolicy.
+type NullPolicy int
+
+const (
+	// NullAsJSON returns null values as nil, encoded as JSON null.
+	NullAsJSON NullPolicy = iota
+	// NullOmit leaves the keys of null values out of results.
+	NullOmit
+	// NullDefault returns the default set with WithNullDefault for null
+	// values, and nil for fields without one.
+	NullDefault
+)
+
+// SetNullPolicy sets how the default registry returns null values. Without
+// it they are nil. ExecuteRaw only sees the nulls of nullable fields of its
+// result type, such as pointers and sql.NullString; other fields hold the
+// zero value of their type.
+func SetNullPolicy(policy NullPolicy) {
+	defaultRegistry.SetNullPolicy(policy)
+}
+
+// SetNullPolicy sets how the registry returns null values.
+func (r *Registry) SetNullPolicy(policy NullPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nulls = policy
+}
+
+// setResult sets key of row to val, the value returned for field, following
+// the null policy of the registry.
+func (r *Registry) setResult(row map[string]interface{}, key string, field Field, val interface{}) {
+	if val != nil {
+		row[key] = val
+		return
+	}
+	r.mu.RLock()
+	policy := r.nulls
+	r.mu.RUnlock()
+	switch policy {
+	case NullOmit:
+	case NullDefault:
+		row[key] = field.NullDefault
+	default:
+		row[key] = nil
+	}
+}
@@ -0,0 +1,61 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type NullableContact struct {
+	ID       int64   `json:"id" db:"id"`
+	Phone    *string `json:"phone" db:"phone"`
+	Nickname *string `json:"nickname" db:"nickname"`
+}
+
+func (NullableContact) TableName() string { return "contacts" }
+
+func TestSetNullPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy NullPolicy
+		want   map[string]interface{}
+	}{
+		{name: "JSON null", policy: NullAsJSON, want: map[string]interface{}{"id": int64(1), "phone": nil, "nickname": nil}},
+		{name: "omit", policy: NullOmit, want: map[string]interface{}{"id": int64(1)}},
+		{name: "default", policy: NullDefault, want: map[string]interface{}{"id": int64(1), "phone": "unlisted", "nickname": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			require.NoError(t, registry.Register(NullableContact{}, WithNullDefault("phone", "unlisted")))
+			registry.SetNullPolicy(tt.policy)
+			ctx := WithRegistry(context.Background(), registry)
+
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT id, phone, nickname FROM contacts`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "phone", "nickname"}).AddRow(int64(1), nil, nil))
+			resp, err := Execute[NullableContact](ctx, db, QueryRequest{Select: []string{"id", "phone", "nickname"}})
+			require.NoError(t, err)
+			assert.Equal(t, []QueryResult{tt.want}, resp.Data)
+
+			// ExecuteRaw follows the same policy
+			mock.ExpectQuery(`SELECT id, phone, nickname FROM contacts`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "phone", "nickname"}).AddRow(int64(1), nil, nil))
+			results, err := ExecuteRaw[struct{}, NullableContact](ctx, db, "SELECT id, phone, nickname FROM contacts", nil)
+			require.NoError(t, err)
+			assert.Equal(t, []map[string]interface{}{tt.want}, results)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWithNullDefault_InvalidField(t *testing.T) {
+	err := NewRegistry().Register(NullableContact{}, WithNullDefault("email", ""))
+	assert.ErrorContains(t, err, "invalid field in null default: email")
+}
@@ -0,0 +1,53 @@
+package sqld
+
+import "reflect"
+
+// nullValueField returns the index of the field holding the value of t when
+// t is a nullable wrapper in the style of sql.NullInt64 or sql.Null[T]: a
+// scanner struct with a Valid bool field and a single value field.
+func nullValueField(t reflect.Type) (int, bool) {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 || !reflect.PointerTo(t).Implements(sqlScannerType) {
+		return 0, false
+	}
+	if _, ok := pgtypeFieldTypes[t]; ok {
+		return 0, false // pgtype.UUID holds bytes but is written as a string
+	}
+	valid, ok := t.FieldByName("Valid")
+	if !ok || valid.Type.Kind() != reflect.Bool {
+		return 0, false
+	}
+	return 1 - valid.Index[0], true
+}
+
+// nullValueType returns the type of the value held by the nullable wrapper t,
+// e.g. int64 for sql.NullInt64.
+func nullValueType(t reflect.Type) (reflect.Type, bool) {
+	i, ok := nullValueField(t)
+	if !ok {
+		return nil, false
+	}
+	return t.Field(i).Type, true
+}
+
+// resultValue returns the value of a field of a scanned struct as it is
+// emitted in results: nil for nil pointers and invalid wrappers such as
+// sql.NullString, so that they encode as JSON null rather than a zero value or
+// an object, the wrapped value of valid wrappers, and the value of pgtype
+// values, see pgtypeValue.
+func resultValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	if v.CanInterface() {
+		if value, ok := pgtypeValue(v.Interface()); ok {
+			return value
+		}
+	}
+	if i, ok := nullValueField(v.Type()); ok {
+		if !v.FieldByName("Valid").Bool() {
+			return nil
+		}
+		return v.Field(i).Interface()
+	}
+	return v.Interface()
+}
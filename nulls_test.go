@@ -0,0 +1,110 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NullableParams has optional raw query parameters.
+type NullableParams struct {
+	Manager *int64         `db:"manager" json:"manager"`
+	Team    sql.NullString `db:"team" json:"team"`
+}
+
+// NullableResult has columns that may be NULL.
+type NullableResult struct {
+	ID       int64            `db:"id" json:"id"`
+	Nickname *string          `db:"nickname" json:"nickname"`
+	Manager  sql.NullInt64    `db:"manager" json:"manager"`
+	LeftAt   sql.NullTime     `db:"left_at" json:"left_at"`
+	Team     sql.Null[string] `db:"team" json:"team"`
+}
+
+func TestNullValueType(t *testing.T) {
+	tests := []struct {
+		t    reflect.Type
+		want reflect.Type
+	}{
+		{reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(int64(0))},
+		{reflect.TypeOf(sql.NullTime{}), reflect.TypeOf(time.Time{})},
+		{reflect.TypeOf(sql.Null[string]{}), reflect.TypeOf("")},
+		{reflect.TypeOf(CustomID{}), nil},
+		{reflect.TypeOf(""), nil},
+	}
+	for _, tt := range tests {
+		got, ok := nullValueType(tt.t)
+		assert.Equal(t, tt.want != nil, ok, tt.t.String())
+		assert.Equal(t, tt.want, got, tt.t.String())
+	}
+}
+
+func TestExecuteRaw_Nullable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "nickname", "manager", "left_at", "team"}).
+		AddRow(1, nil, nil, nil, nil).
+		AddRow(2, "Al", 7, now, "core")
+	mock.ExpectQuery(`SELECT (.+) FROM people WHERE manager = \$1 AND team = \$2`).
+		WithArgs(nil, "core").
+		WillReturnRows(rows)
+
+	results, err := ExecuteRaw[NullableParams, NullableResult](context.Background(), db,
+		"SELECT id, nickname, manager, left_at, team FROM people WHERE manager = {{manager}} AND team = {{team}}",
+		map[string]interface{}{"manager": nil, "team": "core"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	encoded, err := json.Marshal(results[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": 1, "nickname": null, "manager": null, "left_at": null, "team": null}`, string(encoded))
+
+	assert.Equal(t, "Al", *results[1]["nickname"].(*string))
+	assert.Equal(t, int64(7), results[1]["manager"])
+	assert.Equal(t, now, results[1]["left_at"])
+	assert.Equal(t, "core", results[1]["team"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateMapParams_Nullable(t *testing.T) {
+	_, err := ValidateMapParamsAgainstStructNamed[NullableParams](map[string]interface{}{"manager": int64(3), "team": nil}, []string{"manager", "team"})
+	assert.NoError(t, err)
+
+	_, err = ValidateMapParamsAgainstStructNamed[NullableParams](map[string]interface{}{"team": 3}, []string{"team"})
+	assert.ErrorContains(t, err, "parameter team: type mismatch")
+
+	_, err = ValidateMapParamsAgainstStructNamed[QueryParams](map[string]interface{}{"id": nil}, []string{"id"})
+	assert.ErrorContains(t, err, "parameter id: type mismatch: got nil")
+}
+
+func TestValidateValue_Nullable(t *testing.T) {
+	manager := Field{JSONName: "manager", Type: reflect.TypeOf(sql.NullInt64{})}
+	assert.NoError(t, validateValue(manager, nil))
+	assert.NoError(t, validateValue(manager, float64(3)))
+	assert.ErrorContains(t, validateValue(manager, "three"), "invalid value for field manager")
+	assert.ErrorContains(t, validateValue(manager, 3.5), "is not an integer")
+
+	nickname := Field{JSONName: "nickname", Type: reflect.TypeOf((*string)(nil))}
+	assert.NoError(t, validateValue(nickname, nil))
+	assert.NoError(t, validateValue(nickname, "Al"))
+}
+
+func TestDecodePatch_Nullable(t *testing.T) {
+	metadata := ModelMetadata{Fields: map[string]Field{
+		"manager": {Name: "manager", JSONName: "manager", Type: reflect.TypeOf(sql.NullInt64{})},
+		"team":    {Name: "team", JSONName: "team", Type: reflect.TypeOf(sql.Null[string]{})},
+	}}
+	set, err := decodePatch(metadata, json.RawMessage(`{"manager": 4, "team": null}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"manager": int64(4), "team": nil}, set)
+}
@@ -0,0 +1,93 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OpenAPIEndpoint is an HTTP endpoint that decodes a QueryRequest from the
+// body of POST requests and responds with the encoded QueryResponse of
+// executing it against Model.
+type OpenAPIEndpoint struct {
+	Path  string
+	Model Model
+}
+
+// OpenAPIDocument returns an OpenAPI 3.1 document describing endpoints, with
+// the models registered with the default registry. Requests and responses
+// are described by the schemas of RequestSchema and ResponseSchema, declared
+// once per model in the components of the document. The document encodes to
+// JSON with encoding/json.
+func OpenAPIDocument(title, version string, endpoints ...OpenAPIEndpoint) (JSONSchema, error) {
+	return defaultRegistry.OpenAPIDocument(title, version, endpoints...)
+}
+
+// OpenAPIDocument returns an OpenAPI 3.1 document describing endpoints, see
+// the package-level OpenAPIDocument.
+func (r *Registry) OpenAPIDocument(title, version string, endpoints ...OpenAPIEndpoint) (JSONSchema, error) {
+	paths := JSONSchema{}
+	schemas := JSONSchema{}
+	operations := make(map[string]int) // Endpoints per model
+	for _, endpoint := range endpoints {
+		if _, ok := paths[endpoint.Path]; ok {
+			return nil, fmt.Errorf("duplicate OpenAPI path %s", endpoint.Path)
+		}
+		name := reflect.TypeOf(endpoint.Model).Name()
+		if _, ok := schemas[name+"Request"]; !ok {
+			request, err := r.RequestSchema(endpoint.Model)
+			if err != nil {
+				return nil, err
+			}
+			response, err := r.ResponseSchema(endpoint.Model)
+			if err != nil {
+				return nil, err
+			}
+			delete(request, "$schema") // Implied by the OpenAPI version
+			delete(response, "$schema")
+			schemas[name+"Request"] = request
+			schemas[name+"Response"] = response
+		}
+
+		operationID := "query" + name
+		if operations[name]++; operations[name] > 1 {
+			operationID += fmt.Sprint(operations[name])
+		}
+		paths[endpoint.Path] = JSONSchema{
+			"post": JSONSchema{
+				"operationId": operationID,
+				"summary":     "Query " + name,
+				"requestBody": JSONSchema{
+					"required": true,
+					"content":  openAPIJSONContent(name + "Request"),
+				},
+				"responses": JSONSchema{
+					"200": JSONSchema{
+						"description": "Query results",
+						"content":     openAPIJSONContent(name + "Response"),
+					},
+					"default": JSONSchema{"description": "Invalid request or query failure"},
+				},
+			},
+		}
+	}
+
+	return JSONSchema{
+		"openapi": "3.1.0",
+		"info": JSONSchema{
+			"title":   title,
+			"version": version,
+		},
+		"paths":      paths,
+		"components": JSONSchema{"schemas": schemas},
+	}, nil
+}
+
+// openAPIJSONContent returns the content of a request or response body
+// holding the JSON of the component schema named name.
+func openAPIJSONContent(name string) JSONSchema {
+	return JSONSchema{
+		"application/json": JSONSchema{
+			"schema": JSONSchema{"$ref": "#/components/schemas/" + name},
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package sqld
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(SupportTicket{}))
+
+	doc, err := registry.OpenAPIDocument("Tickets API", "1.0",
+		OpenAPIEndpoint{Path: "/tickets/query", Model: SupportTicket{}},
+		OpenAPIEndpoint{Path: "/admin/tickets/query", Model: SupportTicket{}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	paths := doc["paths"].(JSONSchema)
+	post := paths["/tickets/query"].(JSONSchema)["post"].(JSONSchema)
+	assert.Equal(t, "querySupportTicket", post["operationId"])
+	assert.Equal(t, "querySupportTicket2", paths["/admin/tickets/query"].(JSONSchema)["post"].(JSONSchema)["operationId"])
+
+	encoded, err := json.Marshal(post)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"$ref":"#/components/schemas/SupportTicketRequest"`)
+	assert.Contains(t, string(encoded), `"$ref":"#/components/schemas/SupportTicketResponse"`)
+
+	schemas := doc["components"].(JSONSchema)["schemas"].(JSONSchema)
+	assert.Len(t, schemas, 2)
+	assert.NotContains(t, schemas["SupportTicketRequest"], "$schema")
+
+	_, err = registry.OpenAPIDocument("Tickets API", "1.0",
+		OpenAPIEndpoint{Path: "/tickets", Model: SupportTicket{}},
+		OpenAPIEndpoint{Path: "/tickets", Model: SupportTicket{}},
+	)
+	assert.ErrorContains(t, err, "duplicate OpenAPI path /tickets")
+
+	_, err = registry.OpenAPIDocument("Tickets API", "1.0", OpenAPIEndpoint{Path: "/jobs", Model: JobCategory{}})
+	assert.ErrorContains(t, err, "model JobCategory not registered")
+}
@@ -0,0 +1,229 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrQueryTimeout is returned, wrapping the database error, when a call made
+// with WithTimeout runs out of time.
+var ErrQueryTimeout = errors.New("query timeout")
+
+// ExecuteOption configures a single Execute call.
+type ExecuteOption func(*executeConfig)
+
+type executeConfig struct {
+	// txOpts runs the call in its own transaction when not nil.
+	txOpts []TxOption
+	// timeout bounds each attempt of the call when positive.
+	timeout time.Duration
+	// retries is the number of times a call failing with a transient error is
+	// retried, waiting backoff before the first retry.
+	retries int
+	backoff time.Duration
+	// execMode overrides the pgx query exec mode of the registry when not nil.
+	execMode *pgx.QueryExecMode
+	// version selects the version of the model registered WithVersion.
+	version string
+	// gzip compresses the output of the exporters, unless gzipRequest is set
+	// and doesn't accept it.
+	gzip        bool
+	gzipRequest *http.Request
+	// metadata adds a QueryMetadata to the response, see WithMetadata.
+	metadata bool
+	// debug logs the statements of the call at debug level, see WithDebug.
+	debug bool
+	// dryRun builds the statements of the call without running them, see
+	// WithDryRun.
+	dryRun bool
+	// costLimits overrides the CostLimits of the registry when not nil.
+	costLimits *CostLimits
+}
+
+// WithReadOnlyTx runs the call in a read-only transaction at the given isolation level, so the
+// data, count and summary queries of one Execute call all see the same snapshot when level is
+// sql.LevelRepeatableRead or stronger. sql.LevelDefault keeps the database default.
+func WithReadOnlyTx(level sql.IsolationLevel) ExecuteOption {
+	return func(c *executeConfig) {
+		c.txOpts = []TxOption{WithTxIsolation(level), WithTxReadOnly()}
+	}
+}
+
+// WithTimeout bounds the call to d. The context of the call gets a deadline and, with the
+// Postgres dialect and a db that can begin a transaction, the call runs in one with a local
+// statement_timeout so the database also stops working on it. Running out of time returns an
+// error wrapping ErrQueryTimeout.
+func WithTimeout(d time.Duration) ExecuteOption {
+	return func(c *executeConfig) { c.timeout = d }
+}
+
+// WithRetry retries the call up to n times when it fails with a transient error: a
+// serialization failure, a deadlock or a lost connection. The pause before the first retry is
+// backoff and doubles after every attempt. Retries stop as soon as the context is done, and
+// WithTimeout applies to each attempt.
+func WithRetry(n int, backoff time.Duration) ExecuteOption {
+	return func(c *executeConfig) {
+		c.retries = n
+		c.backoff = backoff
+	}
+}
+
+// WithQueryExecMode runs the queries of the call on pgx handles with mode, overriding the mode
+// set with SetQueryExecMode. It has no effect on database/sql handles.
+func WithQueryExecMode(mode pgx.QueryExecMode) ExecuteOption {
+	return func(c *executeConfig) { c.execMode = &mode }
+}
+
+// UseVersion runs the call against the version of the model registered WithVersion, so that
+// e.g. a v1 endpoint only exposes the fields of v1.
+func UseVersion(version string) ExecuteOption {
+	return func(c *executeConfig) { c.version = version }
+}
+
+// WithGzip compresses the output of WriteNDJSON, ExecuteCSV and WriteArrow with gzip. When the
+// writer is an http.ResponseWriter, the Content-Encoding header is set once the query succeeds,
+// so that errors can still be answered uncompressed. Other calls ignore it.
+func WithGzip() ExecuteOption {
+	return func(c *executeConfig) {
+		c.gzip = true
+		c.gzipRequest = nil
+	}
+}
+
+// WithGzipAccepted is WithGzip for the response to r: the output is compressed only when the
+// Accept-Encoding header of r accepts gzip, and Vary: Accept-Encoding is set on http.ResponseWriter
+// writers.
+func WithGzipAccepted(r *http.Request) ExecuteOption {
+	return func(c *executeConfig) {
+		c.gzip = true
+		c.gzipRequest = r
+	}
+}
+
+// WithDebug logs the SQL and the values bound of every statement of the call at debug level,
+// with the logger set with SetSlogLogger or slog.Default. SetDebug does so for every call.
+func WithDebug() ExecuteOption {
+	return func(c *executeConfig) { c.debug = true }
+}
+
+func newExecuteConfig(opts []ExecuteOption) executeConfig {
+	var cfg executeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// run calls fn with db, or with a transaction begun on db when the call asks for one,
+// retrying transient failures as configured.
+func (c executeConfig) run(ctx context.Context, db interface{}, fn func(ctx context.Context, db interface{}) error) error {
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		err := c.runOnce(ctx, db, fn)
+		if err == nil || attempt >= c.retries || !isTransientError(err) || ctx.Err() != nil {
+			return err
+		}
+		if err := sleepContext(ctx, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// runOnce makes a single attempt of the call.
+func (c executeConfig) runOnce(ctx context.Context, db interface{}, fn func(ctx context.Context, db interface{}) error) error {
+	if c.execMode != nil {
+		ctx = context.WithValue(ctx, execModeKey{}, *c.execMode)
+	}
+	if c.debug {
+		ctx = context.WithValue(ctx, debugKey{}, true)
+	}
+	if c.costLimits != nil {
+		ctx = context.WithValue(ctx, costLimitsKey{}, *c.costLimits)
+	}
+	if c.timeout <= 0 {
+		return c.runOnceTx(ctx, db, fn)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	err := c.runWithTimeout(timeoutCtx, db, fn)
+	if err != nil && isTimeout(ctx, timeoutCtx, err) {
+		return fmt.Errorf("%w after %s: %w", ErrQueryTimeout, c.timeout, err)
+	}
+	return err
+}
+
+// runOnceTx calls fn with db, or with a transaction begun on db when the call asks for one.
+func (c executeConfig) runOnceTx(ctx context.Context, db interface{}, fn func(ctx context.Context, db interface{}) error) error {
+	if c.txOpts == nil {
+		return fn(ctx, db)
+	}
+	return InTx(ctx, db, func(tx Runner) error { return fn(ctx, tx) }, c.txOpts...)
+}
+
+// runWithTimeout runs fn in a transaction with a local statement timeout, when the dialect
+// has one. When db is already a transaction, setting the timeout would outlive the call, so
+// only the deadline of ctx applies.
+func (c executeConfig) runWithTimeout(ctx context.Context, db interface{}, fn func(ctx context.Context, db interface{}) error) error {
+	db, err := resolveDB(ctx, db)
+	if err != nil {
+		return err
+	}
+	dialect, ok := registryFromContext(ctx).Dialect().(statementTimeoutDialect)
+	if !ok {
+		return c.runOnceTx(ctx, db, fn)
+	}
+	txOpts := c.txOpts
+	if txOpts == nil {
+		switch db.(type) {
+		case sqlBeginner, pgxTxBeginner:
+			txOpts = []TxOption{WithTxRetries(0)}
+		default:
+			return fn(ctx, db)
+		}
+	}
+	return InTx(ctx, db, func(tx Runner) error {
+		if _, err := execAffected(ctx, tx, dialect.StatementTimeout(c.timeout)); err != nil {
+			return fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+		return fn(ctx, tx)
+	}, txOpts...)
+}
+
+// isTimeout reports whether err was caused by the deadline of timeoutCtx, derived from ctx,
+// or by the statement_timeout of the database.
+func isTimeout(ctx, timeoutCtx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "57014"
+}
+
+// isTransientError reports whether err is likely to go away when the call is
+// made again: serialization failures, deadlocks and lost connections.
+func isTransientError(err error) bool {
+	if isRetryableTxError(err) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 holds the connection exceptions
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+	return pgconn.SafeToRetry(err)
+}
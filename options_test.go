@@ -0,0 +1,256 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// txOptionsDB records the options of the transactions it begins.
+type txOptionsDB struct {
+	*sql.DB
+	opts *sql.TxOptions
+}
+
+func (d *txOptionsDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	d.opts = opts
+	return d.DB.BeginTx(ctx, opts)
+}
+
+func TestExecute_WithReadOnlyTx(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM test_models WHERE name = \$1`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+	mock.ExpectQuery(`SELECT id, age FROM test_models WHERE name = \$1 LIMIT 10 OFFSET 0`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "age"}).AddRow(int64(1), int64(30)))
+	mock.ExpectCommit()
+
+	wrapped := &txOptionsDB{DB: db}
+	resp, err := Execute[BuilderTestModel](context.Background(), wrapped, QueryRequest{
+		Select:     []string{"id", "age"},
+		Where:      map[string]interface{}{"name": "alice"},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 10},
+	}, WithReadOnlyTx(sql.LevelRepeatableRead))
+	require.NoError(t, err)
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, wrapped.opts)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_WithReadOnlyTxRollsBackOnError(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM test_models`).WillReturnError(errors.New("boom"))
+	mock.ExpectRollback()
+
+	_, err = Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select: []string{"id"},
+	}, WithReadOnlyTx(sql.LevelDefault))
+	assert.ErrorContains(t, err, "boom")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// pgxOptionsDB records the options of the pgx transactions it is asked to begin.
+type pgxOptionsDB struct {
+	opts pgx.TxOptions
+}
+
+func (d *pgxOptionsDB) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	d.opts = opts
+	return nil, errors.New("not connected")
+}
+
+func TestBeginPgx_Options(t *testing.T) {
+	db := &pgxOptionsDB{}
+	_, err := beginPgx(context.Background(), db, txConfig{isolation: sql.LevelSerializable, readOnly: true})
+	assert.EqualError(t, err, "not connected")
+	assert.Equal(t, pgx.TxOptions{IsoLevel: pgx.Serializable, AccessMode: pgx.ReadOnly}, db.opts)
+
+	_, err = beginPgx(context.Background(), db, txConfig{isolation: sql.LevelWriteCommitted})
+	assert.EqualError(t, err, "unsupported isolation level: Write Committed")
+}
+
+// savepointDB can only begin savepoints, like pgx.Tx.
+type savepointDB struct{}
+
+func (savepointDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, errors.New("not connected")
+}
+
+func TestBeginPgx_SavepointRejectsOptions(t *testing.T) {
+	_, err := beginPgx(context.Background(), savepointDB{}, txConfig{readOnly: true})
+	assert.EqualError(t, err, "transaction options are not supported by sqld.savepointDB")
+}
+
+func TestExecute_WithTimeout(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 1500`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectCommit()
+
+	resp, err := Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select: []string{"id"},
+	}, WithTimeout(1500*time.Millisecond))
+	require.NoError(t, err)
+	assert.Len(t, resp.Data, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_WithTimeoutStatementTimeout(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 1000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillReturnError(&pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"})
+	mock.ExpectRollback()
+
+	_, err = Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select: []string{"id"},
+	}, WithTimeout(time.Second))
+	assert.ErrorIs(t, err, ErrQueryTimeout)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_WithTimeoutDeadline(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// A transaction of the caller only gets the context deadline
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = Execute[BuilderTestModel](context.Background(), tx, QueryRequest{
+		Select: []string{"id"},
+	}, WithTimeout(10*time.Millisecond))
+	assert.ErrorIs(t, err, ErrQueryTimeout)
+}
+
+func TestExecute_WithTimeoutOtherErrors(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 1000`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT id FROM test_models`).WillReturnError(errors.New("boom"))
+	mock.ExpectRollback()
+
+	_, err = Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select: []string{"id"},
+	}, WithTimeout(time.Second))
+	assert.ErrorContains(t, err, "boom")
+	assert.NotErrorIs(t, err, ErrQueryTimeout)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_WithRetry(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id FROM test_models`).WillReturnError(&pgconn.PgError{Code: "40P01"})
+	mock.ExpectQuery(`SELECT id FROM test_models`).WillReturnError(&pgconn.PgError{Code: "08006"})
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+
+	resp, err := Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select: []string{"id"},
+	}, WithRetry(2, time.Millisecond))
+	require.NoError(t, err)
+	assert.Len(t, resp.Data, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_WithRetryGivesUp(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	tests := []struct {
+		name     string
+		opts     []ExecuteOption
+		err      error
+		attempts int
+	}{
+		{name: "off by default", err: &pgconn.PgError{Code: "40001"}, attempts: 1},
+		{name: "not transient", opts: []ExecuteOption{WithRetry(3, 0)}, err: &pgconn.PgError{Code: "23505"}, attempts: 1},
+		{name: "retries exhausted", opts: []ExecuteOption{WithRetry(2, 0)}, err: &pgconn.PgError{Code: "40001"}, attempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			for i := 0; i < tt.attempts; i++ {
+				mock.ExpectQuery(`SELECT id FROM test_models`).WillReturnError(tt.err)
+			}
+
+			_, err = Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+				Select: []string{"id"},
+			}, tt.opts...)
+			assert.ErrorIs(t, err, tt.err)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExecute_WithRetryStopsWithContext(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id FROM test_models`).WillReturnError(&pgconn.PgError{Code: "40001"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{
+		Select: []string{"id"},
+	}, WithRetry(5, time.Hour))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
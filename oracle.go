@@ -0,0 +1,160 @@
+package sqld
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Oracle is the dialect of Oracle Database 12c and later: :1 parameters,
+// "quoted" identifiers and OFFSET/FETCH row limiting. Mutations can't return
+// rows. Unquoted column names, which Oracle reports in upper case, and NUMBER
+// and DATE values, which drivers return as strings or floats, are mapped back
+// to the registered fields.
+var Oracle Dialect = oracleDialect{}
+
+// identFoldingDialect is implemented by dialects reporting unquoted column
+// names in another case than they are written in.
+type identFoldingDialect interface {
+	// FoldIdent returns the name the database reports for the unquoted
+	// identifier name.
+	FoldIdent(name string) string
+}
+
+// valueConvertingDialect is implemented by dialects whose drivers return
+// values in other Go types than those of the model fields.
+type valueConvertingDialect interface {
+	// ConvertValue converts a scanned value to t when possible, and returns
+	// it unchanged otherwise.
+	ConvertValue(value interface{}, t reflect.Type) interface{}
+}
+
+type oracleDialect struct{}
+
+func (oracleDialect) Name() string { return "oracle" }
+
+func (oracleDialect) Placeholder() squirrel.PlaceholderFormat { return squirrel.Colon }
+
+func (oracleDialect) BindVar(i int) string { return fmt.Sprintf(":%d", i) }
+
+func (oracleDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (oracleDialect) LimitOffset(limit, offset *uint64) string {
+	var parts []string
+	if offset != nil {
+		parts = append(parts, fmt.Sprintf("OFFSET %d ROWS", *offset))
+	}
+	if limit != nil {
+		if offset != nil {
+			parts = append(parts, fmt.Sprintf("FETCH NEXT %d ROWS ONLY", *limit))
+		} else {
+			parts = append(parts, fmt.Sprintf("FETCH FIRST %d ROWS ONLY", *limit))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (oracleDialect) SupportsReturning() bool { return false }
+
+func (oracleDialect) FoldIdent(name string) string { return strings.ToUpper(name) }
+
+func (oracleDialect) CurrentTime() string { return "SYSTIMESTAMP" }
+
+// TableAlias omits AS, which Oracle only accepts before column aliases.
+func (oracleDialect) TableAlias(table, alias string) string { return table + " " + alias }
+
+func (oracleDialect) ColumnsQuery(qualified bool) string {
+	if qualified {
+		return `SELECT column_name AS "column_name", data_type AS "data_type", nullable AS "is_nullable" FROM all_tab_columns WHERE owner = :1 AND table_name = :2 ORDER BY column_id`
+	}
+	return `SELECT column_name AS "column_name", data_type AS "data_type", nullable AS "is_nullable" FROM user_tab_columns WHERE table_name = :1 ORDER BY column_id`
+}
+
+// ConstraintsQuery reads the constraints of the columns of a table from the
+// dictionary views, like ColumnsQuery.
+func (oracleDialect) ConstraintsQuery(qualified bool) string {
+	if qualified {
+		return `SELECT column_name AS "column_name", nullable AS "is_nullable", data_default AS "column_default", char_length AS "character_maximum_length" FROM all_tab_columns WHERE owner = :1 AND table_name = :2`
+	}
+	return `SELECT column_name AS "column_name", nullable AS "is_nullable", data_default AS "column_default", char_length AS "character_maximum_length" FROM user_tab_columns WHERE table_name = :1`
+}
+
+// oracleDateLayouts are the layouts of DATE and TIMESTAMP values returned as
+// strings.
+var oracleDateLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+// ConvertValue converts NUMBER values, returned as strings (e.g.
+// godror.Number) or floats, to integer, float and bool fields, as Oracle has
+// no BOOLEAN before 23ai, and DATE values returned as strings to time fields.
+func (oracleDialect) ConvertValue(value interface{}, t reflect.Type) interface{} {
+	if value == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	v := reflect.ValueOf(value)
+	if v.Type() == t {
+		return value
+	}
+
+	var text string
+	switch {
+	case v.Kind() == reflect.String:
+		text = v.String()
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		text = string(v.Bytes())
+	case v.CanFloat():
+		text = strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case v.CanInt():
+		text = strconv.FormatInt(v.Int(), 10)
+	default:
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil || reflect.Zero(t).OverflowInt(n) {
+			return value
+		}
+		return reflect.ValueOf(n).Convert(t).Interface()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, 64)
+		if err != nil || reflect.Zero(t).OverflowUint(n) {
+			return value
+		}
+		return reflect.ValueOf(n).Convert(t).Interface()
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil || (t.Kind() == reflect.Float32 && math.Abs(f) > math.MaxFloat32) {
+			return value
+		}
+		return reflect.ValueOf(f).Convert(t).Interface()
+	case reflect.String:
+		return reflect.ValueOf(text).Convert(t).Interface()
+	case reflect.Bool:
+		switch text {
+		case "0":
+			return false
+		case "1":
+			return true
+		}
+		return value
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		for _, layout := range oracleDateLayouts {
+			if parsed, err := time.Parse(layout, text); err == nil {
+				return parsed
+			}
+		}
+	}
+	return value
+}
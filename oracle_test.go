@@ -0,0 +1,211 @@
+package sqld
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOracle_LimitOffset(t *testing.T) {
+	ten, five := uint64(10), uint64(5)
+	assert.Equal(t, "", Oracle.LimitOffset(nil, nil))
+	assert.Equal(t, "FETCH FIRST 10 ROWS ONLY", Oracle.LimitOffset(&ten, nil))
+	assert.Equal(t, "OFFSET 5 ROWS", Oracle.LimitOffset(nil, &five))
+	assert.Equal(t, "OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY", Oracle.LimitOffset(&ten, &five))
+}
+
+// oracleNumber mimics the string type NUMBER values are returned as by
+// drivers such as godror.
+type oracleNumber string
+
+func TestOracle_ConvertValue(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value interface{}
+		t     reflect.Type
+		want  interface{}
+	}{
+		{name: "number to int64", value: oracleNumber("42"), t: reflect.TypeOf(int64(0)), want: int64(42)},
+		{name: "float to int", value: 7.0, t: reflect.TypeOf(0), want: 7},
+		{name: "bytes to float", value: []byte("1.5"), t: reflect.TypeOf(0.0), want: 1.5},
+		{name: "number to bool", value: oracleNumber("1"), t: reflect.TypeOf(false), want: true},
+		{name: "number to pointer field", value: oracleNumber("3"), t: reflect.TypeOf((*int32)(nil)), want: int32(3)},
+		{name: "number to string", value: oracleNumber("3"), t: reflect.TypeOf(""), want: "3"},
+		{name: "date string to time", value: "2024-03-01", t: reflect.TypeOf(time.Time{}), want: day},
+		{name: "time unchanged", value: day, t: reflect.TypeOf(time.Time{}), want: day},
+		{name: "fraction kept for int", value: oracleNumber("1.5"), t: reflect.TypeOf(int64(0)), want: oracleNumber("1.5")},
+		{name: "overflow kept", value: oracleNumber("300"), t: reflect.TypeOf(int8(0)), want: oracleNumber("300")},
+		{name: "nil", value: nil, t: reflect.TypeOf(0), want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Oracle.(valueConvertingDialect).ConvertValue(tt.value, tt.t))
+		})
+	}
+}
+
+func TestBuildSelect_Oracle(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+	registry.SetDialect(Oracle)
+
+	limit, offset := 10, 20
+	query, err := buildSelect(employees, QueryRequest{
+		Select:  []string{"id", "department.name"},
+		Where:   map[string]interface{}{"department.name": "eng", "id": 3},
+		OrderBy: []OrderByClause{{Field: "id"}},
+		Limit:   &limit,
+		Offset:  &offset,
+	})
+	require.NoError(t, err)
+	sql, args, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT employees.id, department.name AS "department.name" FROM employees `+
+		`LEFT JOIN departments department ON department.id = employees.department_id `+
+		`WHERE department.name = :1 AND employees.id = :2 ORDER BY employees.id ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`, sql)
+	assert.Equal(t, []interface{}{"eng", 3}, args)
+}
+
+func TestExecute_Oracle(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+	useDialect(t, Oracle)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Unquoted columns come back in upper case, NUMBER values as strings
+	mock.ExpectQuery(`SELECT id, name, age FROM test_models WHERE name = :1 FETCH FIRST 5 ROWS ONLY`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"ID", "NAME", "AGE"}).AddRow("1", "alice", "30"))
+
+	limit := 5
+	resp, err := Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select: []string{"id", "name", "age"},
+		Where:  map[string]interface{}{"name": "alice"},
+		Limit:  &limit,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": 1, "name": "alice", "age": 30}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteRaw_Oracle(t *testing.T) {
+	useDialect(t, Oracle)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id AS "id" FROM test_models WHERE id = :1 OR parent_id = :1 AND status = :2`).
+		WithArgs(1, "active").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err = ExecuteRaw[QueryParams, TestQueryResult](context.Background(), db,
+		`SELECT id AS "id" FROM test_models WHERE id = {{id}} OR parent_id = {{id}} AND status = {{status}}`,
+		map[string]interface{}{"id": int64(1), "status": "active"})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildOnly_OracleAliases(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetDialect(Oracle)
+	registerRelationModels(t, registry)
+	ctx := WithRegistry(context.Background(), registry)
+
+	// Oracle rejects AS before table aliases
+	dryRun, err := BuildOnly[RelEmployee](ctx, QueryRequest{
+		Select:     []string{"id", "department.name"},
+		Where:      map[string]interface{}{"department.name": "eng"},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT employees.id, department.name AS "department.name" FROM employees `+
+		`LEFT JOIN departments department ON department.id = employees.department_id `+
+		`WHERE department.name = :1 OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY`, dryRun.Query.SQL)
+	assert.Equal(t, `SELECT COUNT(*) FROM employees `+
+		`LEFT JOIN departments department ON department.id = employees.department_id `+
+		`WHERE department.name = :1`, dryRun.Count.SQL)
+
+	dryRun, err = BuildOnly[RelEmployee](ctx, QueryRequest{
+		With:   []CTE{{Name: "staff", Query: QueryRequest{Select: []string{"id", "name"}}}},
+		Select: []string{"id"},
+		From:   "staff",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `WITH staff AS (SELECT id, name FROM employees) SELECT id FROM staff employees`, dryRun.Query.SQL)
+}
+
+func TestExecuteUnion_Oracle(t *testing.T) {
+	useDialect(t, Oracle)
+	registerUnionModels(t)
+
+	active, err := NewUnionPart[UnionActiveAccount](QueryRequest{
+		Select: []string{"id", "owner"},
+		Where:  map[string]interface{}{"state": "open"},
+	})
+	require.NoError(t, err)
+	archived, err := NewUnionPart[UnionArchivedAccount](QueryRequest{Select: []string{"id", "owner"}})
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	union := `\(SELECT id, owner_name FROM accounts WHERE state = :1\) UNION ALL \(SELECT id, owner FROM archived_accounts\)`
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM \(` + union + `\) sqld_union$`).
+		WithArgs("open").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow("1"))
+	mock.ExpectQuery(union + ` ORDER BY 1 ASC OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY`).
+		WithArgs("open").
+		WillReturnRows(sqlmock.NewRows([]string{"ID", "OWNER_NAME"}).AddRow("9", "bob"))
+
+	resp, err := ExecuteUnion(context.Background(), db, UnionRequest{
+		Parts:      []UnionPart{active, archived},
+		All:        true,
+		OrderBy:    []OrderByClause{{Field: "id"}},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 5},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Pagination.TotalItems)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMutations_OracleTimestamps(t *testing.T) {
+	setupAudit(t)
+	require.NoError(t, Register(SoftHolding{}))
+	require.NoError(t, RegisterSoftDelete[SoftHolding](SoftDelete{DeletedAtField: "deleted_at", DeletedByField: "deleted_by"}))
+	useDialect(t, Oracle)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Oracle has no now(), and mutations can't return rows
+	mock.ExpectExec(`INSERT INTO notes \(body,created_at,created_by,updated_at,updated_by\) VALUES \(:1,SYSTIMESTAMP,:2,SYSTIMESTAMP,:3\)$`).
+		WithArgs("hello", "alice", "alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE holdings SET deleted_at = SYSTIMESTAMP, deleted_by = :1 WHERE id = :2 AND deleted_at IS NULL$`).
+		WithArgs("admin", 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.WithValue(context.Background(), actorKey{}, "alice")
+	_, err = Insert[AuditedNote](ctx, db, InsertRequest{Values: map[string]interface{}{"body": "hello"}})
+	require.NoError(t, err)
+	resp, err := Delete[SoftHolding](context.Background(), db, DeleteRequest{
+		Where: map[string]interface{}{"id": 5},
+		By:    "admin",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
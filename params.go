@@ -0,0 +1,101 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// ParamsFromStruct converts a parameter struct, such as a sqlc-generated
+// Params struct, into the parameter map expected by ExecuteRaw. Each exported
+// field is stored under its parameter name: the db tag, else the json tag,
+// else the field name in snake_case (ClientCode -> client_code), which is how
+// sqlc derives field names from columns. Fields tagged "-" are skipped.
+// Values keep their field type, so they pass the type validation of
+// ExecuteRaw against the same struct.
+func ParamsFromStruct[P any](params P) (map[string]interface{}, error) {
+	v := reflect.ValueOf(params)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("params must not be nil")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("params must be a struct, got %s", v.Kind())
+	}
+
+	t := v.Type()
+	paramMap := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := paramName(field)
+		if !ok {
+			continue
+		}
+		if _, exists := paramMap[name]; exists {
+			return nil, fmt.Errorf("duplicate parameter name %s in %s", name, t.Name())
+		}
+		paramMap[name] = v.Field(i).Interface()
+	}
+	return paramMap, nil
+}
+
+// ExecuteRawParams is ExecuteRaw with its parameters taken from a struct of
+// type P, typically a sqlc-generated Params struct, instead of a hand-built
+// map. See ParamsFromStruct for how fields map to {{param_name}} placeholders.
+func ExecuteRawParams[P, R any](
+	ctx context.Context,
+	db interface{},
+	query string,
+	params P,
+) ([]map[string]interface{}, error) {
+	paramMap, err := ParamsFromStruct(params)
+	if err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+	return runRaw[P, R](ctx, db, query, paramMap, true)
+}
+
+// paramName returns the raw query parameter name of a struct field, and
+// false for fields that are not parameters.
+func paramName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	for _, key := range []string{"db", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	return toSnakeCase(field.Name), true
+}
+
+// toSnakeCase converts a Go identifier to snake_case, keeping initialisms
+// together: UserID -> user_id, HTTPStatus -> http_status.
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			startsWord := i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])))
+			if startsWord {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
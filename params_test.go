@@ -0,0 +1,95 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SqlcStyleParams mimics a sqlc-generated params struct: json tags only, or
+// no tags at all.
+type SqlcStyleParams struct {
+	ClientCode string `json:"client_code"`
+	MemberID   int64
+	Limit      int32  `json:"limit,omitempty"`
+	Internal   string `json:"-"`
+	Status     string `db:"status" json:"state"`
+	unexported string
+}
+
+func TestParamsFromStruct(t *testing.T) {
+	params := SqlcStyleParams{ClientCode: "C1", MemberID: 7, Limit: 10, Internal: "x", Status: "active", unexported: "y"}
+
+	paramMap, err := ParamsFromStruct(params)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"client_code": "C1",
+		"member_id":   int64(7),
+		"limit":       int32(10),
+		"status":      "active",
+	}, paramMap)
+
+	fromPointer, err := ParamsFromStruct(&params)
+	require.NoError(t, err)
+	assert.Equal(t, paramMap, fromPointer)
+
+	_, err = ParamsFromStruct((*SqlcStyleParams)(nil))
+	assert.Error(t, err)
+	_, err = ParamsFromStruct(42)
+	assert.Error(t, err)
+}
+
+func TestToSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"ClientCode": "client_code",
+		"ID":         "id",
+		"UserID":     "user_id",
+		"HTTPStatus": "http_status",
+		"Limit":      "limit",
+	} {
+		assert.Equal(t, want, toSnakeCase(in), in)
+	}
+}
+
+func TestExecuteRawParams(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, name FROM clients WHERE client_code = \$1 AND member_id = \$2 LIMIT \$3`).
+		WithArgs("C1", int64(7), int32(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "Alice"))
+
+	results, err := ExecuteRawParams[SqlcStyleParams, TestQueryResult](context.Background(), db,
+		"SELECT id, name FROM clients WHERE client_code = {{client_code}} AND member_id = {{member_id}} LIMIT {{limit}}",
+		SqlcStyleParams{ClientCode: "C1", MemberID: 7, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Alice", results[0]["name"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteRaw_StrictParams(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Only ExecuteRawParams names untagged and json-only fields
+	_, err = ExecuteRaw[SqlcStyleParams, TestQueryResult](context.Background(), db,
+		"SELECT id FROM clients WHERE client_code = {{client_code}}",
+		map[string]interface{}{"client_code": "C1"})
+	assert.ErrorContains(t, err, "no type info for param client_code")
+	_, err = ExecuteRaw[SqlcStyleParams, TestQueryResult](context.Background(), db,
+		"SELECT id FROM clients WHERE member_id = {{member_id}}",
+		map[string]interface{}{"member_id": int64(7)})
+	assert.ErrorContains(t, err, "no type info for param member_id")
+
+	type untaggedJSON struct {
+		ID int64 `db:"id"`
+	}
+	_, err = ValidateMapParamsAgainstStructNamed[untaggedJSON](map[string]interface{}{"id": int64(1)}, []string{"id"})
+	assert.ErrorContains(t, err, "field ID has db tag but missing json tag")
+}
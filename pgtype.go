@@ -0,0 +1,38 @@
+package sqld
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pgtypePkgPath is the import path of the pgtype package, whose types are
+// used by sqlc for models generated with the pgx driver.
+var pgtypePkgPath = reflect.TypeOf(pgtype.Text{}).PkgPath()
+
+// pgtypeFieldTypes are the FieldType of pgtype types that are not nullable
+// wrappers of a single value.
+var pgtypeFieldTypes = map[reflect.Type]string{
+	reflect.TypeOf(pgtype.Timestamptz{}): "datetime",
+	reflect.TypeOf(pgtype.Timestamp{}):   "datetime",
+	reflect.TypeOf(pgtype.Date{}):        "datetime",
+	reflect.TypeOf(pgtype.UUID{}):        "string",
+	reflect.TypeOf(pgtype.Numeric{}):     "string", // Exact, unlike JSON numbers
+}
+
+// pgtypeValue returns the value of the pgtype value v as it is emitted in
+// results: the value it binds as, e.g. the time of a pgtype.Timestamptz, the
+// canonical string of a pgtype.UUID, the decimal string of a pgtype.Numeric,
+// or nil when it is not valid. It returns false for other values.
+func pgtypeValue(v interface{}) (interface{}, bool) {
+	valuer, ok := v.(driver.Valuer)
+	if !ok || reflect.TypeOf(v).PkgPath() != pgtypePkgPath {
+		return nil, false
+	}
+	value, err := valuer.Value()
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,72 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PgtypeInvoice has the pgtype fields of a model generated by sqlc for pgx.
+type PgtypeInvoice struct {
+	ID       pgtype.Int8        `db:"id" json:"id"`
+	Number   pgtype.Text        `db:"number" json:"number"`
+	Amount   pgtype.Numeric     `db:"amount" json:"amount"`
+	IssuedAt pgtype.Timestamptz `db:"issued_at" json:"issued_at"`
+	Customer pgtype.UUID        `db:"customer" json:"customer"`
+}
+
+type PgtypeInvoiceParams struct {
+	ID int64 `db:"id" json:"id"`
+}
+
+func TestExecuteRaw_Pgtype(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	issued := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT id, number, amount, issued_at, customer FROM invoices WHERE id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "amount", "issued_at", "customer"}).
+			AddRow(int64(1), "INV-1", "1234.50", issued, "6ba7b810-9dad-11d1-80b4-00c04fd430c8").
+			AddRow(int64(2), nil, nil, nil, nil))
+
+	results, err := ExecuteRaw[PgtypeInvoiceParams, PgtypeInvoice](context.Background(), db,
+		"SELECT id, number, amount, issued_at, customer FROM invoices WHERE id = {{id}}",
+		map[string]interface{}{"id": int64(1)})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, map[string]interface{}{
+		"id":        int64(1),
+		"number":    "INV-1",
+		"amount":    "1234.50",
+		"issued_at": issued,
+		"customer":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	}, results[0])
+	encoded, err := json.Marshal(results[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": 2, "number": null, "amount": null, "issued_at": null, "customer": null}`, string(encoded))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFieldType_Pgtype(t *testing.T) {
+	tests := map[string]interface{}{
+		"integer":  pgtype.Int4{},
+		"string":   pgtype.Text{},
+		"datetime": pgtype.Timestamptz{},
+		"boolean":  pgtype.Bool{},
+	}
+	for want, v := range tests {
+		assert.Equal(t, want, FieldType(reflect.TypeOf(v)), "%T", v)
+	}
+	assert.Equal(t, "string", FieldType(reflect.TypeOf(pgtype.Numeric{})))
+	assert.Equal(t, "string", FieldType(reflect.TypeOf(pgtype.UUID{})))
+}
@@ -0,0 +1,196 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Aggregate functions supported by PivotRequest.
+const (
+	PivotSum   = "sum"
+	PivotCount = "count"
+	PivotAvg   = "avg"
+	PivotMin   = "min"
+	PivotMax   = "max"
+)
+
+// PivotRequest turns grouped rows into a matrix. Every distinct value of
+// ColumnField becomes a column, every distinct value of RowField becomes a row
+// and each cell holds the aggregate of ValueField for that row/column pair.
+// For example, with RowField "department", ColumnField "month" and ValueField
+// "salary" the result has one row per department and one column per month.
+//
+// All three fields must be JSON field names of the model and must be part of
+// the Select list. Pivots aggregate every matching row, so they can't be
+// combined with pagination, limit or offset.
+type PivotRequest struct {
+	RowField    string `json:"row_field"`
+	ColumnField string `json:"column_field"`
+	ValueField  string `json:"value_field"`
+	// Aggregate is one of sum, count, avg, min or max. Defaults to sum.
+	Aggregate string `json:"aggregate,omitempty"`
+}
+
+// PivotResult is the matrix produced by Pivot.
+type PivotResult struct {
+	// Columns lists the pivoted column keys in the order they were first seen.
+	Columns []string
+	// Rows holds one QueryResult per distinct row value. Each row contains the
+	// row field itself plus one key per entry in Columns.
+	Rows []QueryResult
+}
+
+// validate checks the pivot request against the model metadata and the query
+// it reshapes.
+func (p *PivotRequest) validate(metadata ModelMetadata, req QueryRequest) error {
+	if req.Pagination != nil || req.Limit != nil || req.Offset != nil {
+		return fmt.Errorf("pivot cannot be combined with pagination, limit or offset")
+	}
+	inSelect := make(map[string]bool, len(req.Select))
+	for _, s := range req.Select {
+		inSelect[s] = true
+	}
+	for _, name := range []string{p.RowField, p.ColumnField, p.ValueField} {
+		if name == "" {
+			return fmt.Errorf("pivot requires row_field, column_field and value_field")
+		}
+		if _, ok := metadata.Fields[name]; !ok {
+			return &UnknownFieldError{Field: name, In: "pivot"}
+		}
+		if !inSelect[name] {
+			return fmt.Errorf("pivot field %s must be selected", name)
+		}
+	}
+	switch p.Aggregate {
+	case "", PivotSum, PivotCount, PivotAvg, PivotMin, PivotMax:
+		return nil
+	default:
+		return &OperatorError{Operator: p.Aggregate, In: "pivot aggregate"}
+	}
+}
+
+// pivotCell accumulates the values of a single matrix cell.
+type pivotCell struct {
+	count int
+	sum   float64
+	min   float64
+	max   float64
+}
+
+// Pivot reshapes rows according to req. Row and column order follow the order
+// in which values first appear in rows, so an ORDER BY on the source query
+// controls the layout of the matrix. Cells without any source row are nil. A
+// column value whose key equals RowField is an error, as it would overwrite
+// the row field.
+func Pivot(rows []QueryResult, req PivotRequest) (*PivotResult, error) {
+	aggregate := req.Aggregate
+	if aggregate == "" {
+		aggregate = PivotSum
+	}
+
+	var rowKeys, colKeys []string
+	rowValues := make(map[string]interface{})
+	seenCols := make(map[string]bool)
+	cells := make(map[string]map[string]*pivotCell)
+
+	for _, row := range rows {
+		rowKey := fmt.Sprint(row[req.RowField])
+		colKey := fmt.Sprint(row[req.ColumnField])
+
+		if _, ok := cells[rowKey]; !ok {
+			cells[rowKey] = make(map[string]*pivotCell)
+			rowKeys = append(rowKeys, rowKey)
+			rowValues[rowKey] = row[req.RowField]
+		}
+		if !seenCols[colKey] {
+			if colKey == req.RowField {
+				return nil, fmt.Errorf("pivot column %s clashes with row field %s", colKey, req.RowField)
+			}
+			seenCols[colKey] = true
+			colKeys = append(colKeys, colKey)
+		}
+
+		value := row[req.ValueField]
+		if value == nil {
+			continue
+		}
+
+		cell, ok := cells[rowKey][colKey]
+		if !ok {
+			cell = &pivotCell{}
+			cells[rowKey][colKey] = cell
+		}
+
+		if aggregate == PivotCount {
+			cell.count++
+			continue
+		}
+
+		n, err := toFloat64(value)
+		if err != nil {
+			return nil, fmt.Errorf("pivot value field %s: %w", req.ValueField, err)
+		}
+		if cell.count == 0 || n < cell.min {
+			cell.min = n
+		}
+		if cell.count == 0 || n > cell.max {
+			cell.max = n
+		}
+		cell.sum += n
+		cell.count++
+	}
+
+	result := &PivotResult{
+		Columns: colKeys,
+		Rows:    make([]QueryResult, 0, len(rowKeys)),
+	}
+	for _, rowKey := range rowKeys {
+		out := QueryResult{req.RowField: rowValues[rowKey]}
+		for _, colKey := range colKeys {
+			cell, ok := cells[rowKey][colKey]
+			if !ok {
+				out[colKey] = nil
+				continue
+			}
+			switch aggregate {
+			case PivotCount:
+				out[colKey] = cell.count
+			case PivotSum:
+				out[colKey] = cell.sum
+			case PivotAvg:
+				out[colKey] = cell.sum / float64(cell.count)
+			case PivotMin:
+				out[colKey] = cell.min
+			case PivotMax:
+				out[colKey] = cell.max
+			}
+		}
+		result.Rows = append(result.Rows, out)
+	}
+
+	return result, nil
+}
+
+// toFloat64 converts a scanned numeric value into a float64.
+// Drivers return numeric columns as ints, floats, strings or []byte
+// depending on the column type, so all of them are accepted.
+func toFloat64(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case string:
+		return strconv.ParseFloat(n, 64)
+	case []byte:
+		return strconv.ParseFloat(string(n), 64)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return 0, fmt.Errorf("value of type %T is not numeric", v)
+}
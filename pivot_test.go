@@ -0,0 +1,162 @@
+package sqld
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPivot(t *testing.T) {
+	rows := []QueryResult{
+		{"department": "eng", "month": "jan", "salary": int64(100)},
+		{"department": "eng", "month": "jan", "salary": int64(50)},
+		{"department": "eng", "month": "feb", "salary": 120.5},
+		{"department": "ops", "month": "feb", "salary": "80"},
+		{"department": "ops", "month": "mar", "salary": nil},
+	}
+
+	tests := []struct {
+		name      string
+		aggregate string
+		want      []QueryResult
+	}{
+		{
+			name: "default sum",
+			want: []QueryResult{
+				{"department": "eng", "jan": float64(150), "feb": 120.5, "mar": nil},
+				{"department": "ops", "jan": nil, "feb": float64(80), "mar": nil},
+			},
+		},
+		{
+			name:      "count",
+			aggregate: PivotCount,
+			want: []QueryResult{
+				{"department": "eng", "jan": 2, "feb": 1, "mar": nil},
+				{"department": "ops", "jan": nil, "feb": 1, "mar": nil},
+			},
+		},
+		{
+			name:      "avg",
+			aggregate: PivotAvg,
+			want: []QueryResult{
+				{"department": "eng", "jan": float64(75), "feb": 120.5, "mar": nil},
+				{"department": "ops", "jan": nil, "feb": float64(80), "mar": nil},
+			},
+		},
+		{
+			name:      "max",
+			aggregate: PivotMax,
+			want: []QueryResult{
+				{"department": "eng", "jan": float64(100), "feb": 120.5, "mar": nil},
+				{"department": "ops", "jan": nil, "feb": float64(80), "mar": nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Pivot(rows, PivotRequest{
+				RowField:    "department",
+				ColumnField: "month",
+				ValueField:  "salary",
+				Aggregate:   tt.aggregate,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"jan", "feb", "mar"}, result.Columns)
+			assert.Equal(t, tt.want, result.Rows)
+		})
+	}
+}
+
+func TestPivot_NonNumericValue(t *testing.T) {
+	rows := []QueryResult{
+		{"department": "eng", "month": "jan", "salary": true},
+	}
+	_, err := Pivot(rows, PivotRequest{RowField: "department", ColumnField: "month", ValueField: "salary"})
+	assert.Error(t, err)
+}
+
+func TestPivot_ColumnClashesWithRowField(t *testing.T) {
+	rows := []QueryResult{
+		{"department": "eng", "month": "jan", "salary": 100},
+		{"department": "ops", "month": "department", "salary": 200},
+	}
+	_, err := Pivot(rows, PivotRequest{RowField: "department", ColumnField: "month", ValueField: "salary"})
+	assert.Error(t, err)
+}
+
+func TestPivotRequest_Validate(t *testing.T) {
+	metadata := ModelMetadata{
+		TableName: "employees",
+		Fields: map[string]Field{
+			"department": {Name: "department", JSONName: "department", Type: reflect.TypeOf("")},
+			"month":      {Name: "month", JSONName: "month", Type: reflect.TypeOf("")},
+			"salary":     {Name: "salary", JSONName: "salary", Type: reflect.TypeOf(float64(0))},
+		},
+	}
+	query := QueryRequest{Select: []string{"department", "month", "salary"}}
+	limit := 10
+
+	tests := []struct {
+		name    string
+		req     PivotRequest
+		query   func(QueryRequest) QueryRequest
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			req:  PivotRequest{RowField: "department", ColumnField: "month", ValueField: "salary", Aggregate: PivotAvg},
+		},
+		{
+			name:    "missing field",
+			req:     PivotRequest{RowField: "department", ValueField: "salary"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			req:     PivotRequest{RowField: "department", ColumnField: "year", ValueField: "salary"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid aggregate",
+			req:     PivotRequest{RowField: "department", ColumnField: "month", ValueField: "salary", Aggregate: "median"},
+			wantErr: true,
+		},
+		{
+			name: "paginated",
+			req:  PivotRequest{RowField: "department", ColumnField: "month", ValueField: "salary"},
+			query: func(q QueryRequest) QueryRequest {
+				q.Pagination = &PaginationRequest{Page: 1, PageSize: 10}
+				return q
+			},
+			wantErr: true,
+		},
+		{
+			name: "limited",
+			req:  PivotRequest{RowField: "department", ColumnField: "month", ValueField: "salary"},
+			query: func(q QueryRequest) QueryRequest {
+				q.Limit = &limit
+				return q
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := query
+			if tt.query != nil {
+				q = tt.query(q)
+			}
+			err := tt.req.validate(metadata, q)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	req := PivotRequest{RowField: "department", ColumnField: "month", ValueField: "salary"}
+	assert.Error(t, req.validate(metadata, QueryRequest{Select: []string{"department", "month"}}), "value field must be selected")
+}
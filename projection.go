@@ -0,0 +1,71 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// modelVariant identifies a registration of a model made WithVersion or
+// ForRole. The zero value is the registration made without them.
+type modelVariant struct {
+	version string
+	role    string
+}
+
+func (v modelVariant) String() string {
+	switch {
+	case v.role == "":
+		return fmt.Sprintf("version %s", v.version)
+	case v.version == "":
+		return fmt.Sprintf("projection for role %s", v.role)
+	}
+	return fmt.Sprintf("version %s projection for role %s", v.version, v.role)
+}
+
+// RoleExtractor returns the role of the caller of a request, such as the
+// role of the authenticated user, from its context. It returns false when
+// there is none.
+type RoleExtractor func(ctx context.Context) (string, bool)
+
+// SetRoleExtractor sets the role extractor of the default registry.
+// Without one, Execute ignores the projections registered ForRole.
+func SetRoleExtractor(extractor RoleExtractor) {
+	defaultRegistry.SetRoleExtractor(extractor)
+}
+
+// SetRoleExtractor sets the role extractor of the registry.
+func (r *Registry) SetRoleExtractor(extractor RoleExtractor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.role = extractor
+}
+
+// roleFromContext returns the role of the caller of ctx, if any.
+func (r *Registry) roleFromContext(ctx context.Context) (string, bool) {
+	r.mu.RLock()
+	extractor := r.role
+	r.mu.RUnlock()
+	if extractor == nil {
+		return "", false
+	}
+	return extractor(ctx)
+}
+
+// callerVariant returns the variant of model used for the caller of ctx
+// asking for version: the projection for the role of the caller when one is
+// registered, or the version itself.
+func (r *Registry) callerVariant(ctx context.Context, model Model, version string) modelVariant {
+	variant := modelVariant{version: version}
+	role, ok := r.roleFromContext(ctx)
+	if !ok {
+		return variant
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	projection := modelVariant{version: version, role: role}
+	if _, ok := r.variants[reflect.TypeOf(model)][projection]; ok {
+		return projection
+	}
+	return variant
+}
@@ -0,0 +1,55 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roleKey struct{}
+
+func TestForRole(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetRoleExtractor(func(ctx context.Context) (string, bool) {
+		role, ok := ctx.Value(roleKey{}).(string)
+		return role, ok
+	})
+	require.NoError(t, registry.Register(SensitiveUser{}, WithFields("id", "email")))
+	require.NoError(t, registry.Register(SensitiveUser{}, ForRole("admin"), WithoutFields("password_hash")))
+	require.NoError(t, registry.Register(SensitiveUser{}, ForRole("admin"), WithVersion("v1"), WithFields("id", "ssn")))
+	ctx := WithRegistry(context.Background(), registry)
+	admin := context.WithValue(ctx, roleKey{}, "admin")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id", "ssn"}}
+	_, err = Execute[SensitiveUser](ctx, db, req)
+	assert.ErrorContains(t, err, "invalid field in select: ssn")
+	_, err = Execute[SensitiveUser](context.WithValue(ctx, roleKey{}, "clerk"), db, req)
+	assert.ErrorContains(t, err, "invalid field in select: ssn")
+
+	mock.ExpectQuery(`SELECT id, ssn FROM sensitive_users$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ssn"}).AddRow(int64(1), "123"))
+	mock.ExpectQuery(`SELECT id, ssn FROM sensitive_users$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ssn"}).AddRow(int64(1), "123"))
+	_, err = Execute[SensitiveUser](admin, db, req)
+	require.NoError(t, err)
+	_, err = Execute[SensitiveUser](admin, db, req, UseVersion("v1"))
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = Execute[SensitiveUser](admin, db, QueryRequest{Select: []string{"email"}}, UseVersion("v1"))
+	assert.ErrorContains(t, err, "invalid field in select: email")
+
+	// Roles without a projection of the version use the version itself
+	_, err = Execute[SensitiveUser](ctx, db, req, UseVersion("v1"))
+	assert.ErrorContains(t, err, "version v1 of model SensitiveUser not registered")
+
+	assert.ErrorContains(t, registry.Replace(SensitiveUser{}, ForRole("clerk")),
+		"projection for role clerk of model SensitiveUser not registered")
+}
@@ -0,0 +1,96 @@
+package sqld
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// QueryEvent describes a statement sent to the database, passed to the
+// QueryLogger of the registry once it has run.
+type QueryEvent struct {
+	SQL         string        // Statement as sent, with the bind parameters of the dialect
+	Fingerprint string        // FingerprintSQL of the statement
+	ArgCount    int           // Number of values bound
+	Args        []interface{} // Values bound, only when logged with SetLogQueryArgs
+	Duration    time.Duration // Time the statement took
+	Err         error         // Error of the statement, nil when it succeeded
+}
+
+// QueryLogger is called with every statement sqld runs: the queries of
+// Execute, ExecuteRaw and the streams, their count and summary queries, and
+// the writes of Insert, Update and Delete.
+type QueryLogger interface {
+	LogQuery(ctx context.Context, e QueryEvent)
+}
+
+// QueryLoggerFunc adapts a function to QueryLogger.
+type QueryLoggerFunc func(ctx context.Context, e QueryEvent)
+
+// LogQuery calls f(ctx, e).
+func (f QueryLoggerFunc) LogQuery(ctx context.Context, e QueryEvent) {
+	f(ctx, e)
+}
+
+// SetQueryLogger sets the logger of the statements run with the default
+// registry, nil for none. The values bound are left out unless
+// SetLogQueryArgs includes them, since they may hold personal data.
+func SetQueryLogger(logger QueryLogger) {
+	defaultRegistry.SetQueryLogger(logger)
+}
+
+// SetQueryLogger sets the logger of the statements run with the registry.
+func (r *Registry) SetQueryLogger(logger QueryLogger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logger = logger
+}
+
+// SetLogQueryArgs sets whether the QueryEvents of the default registry hold
+// the values bound.
+func SetLogQueryArgs(include bool) {
+	defaultRegistry.SetLogQueryArgs(include)
+}
+
+// SetLogQueryArgs sets whether the QueryEvents of the registry hold the
+// values bound.
+func (r *Registry) SetLogQueryArgs(include bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logArgs = include
+}
+
+// logQuery passes the statement query, run with args since start and
+// failed with err if not nil, to the logger of the registry of ctx, and logs
+// it at debug level when the call or the registry asks for it.
+func logQuery(ctx context.Context, query string, args []interface{}, start time.Time, err error) {
+	r := registryFromContext(ctx)
+	r.mu.RLock()
+	logger, logArgs := r.logger, r.logArgs
+	r.mu.RUnlock()
+	debug := r.debugLogger(ctx)
+	if logger == nil && debug == nil {
+		return
+	}
+	e := QueryEvent{
+		SQL:         query,
+		Fingerprint: FingerprintSQL(query),
+		ArgCount:    len(args),
+		Args:        args,
+		Duration:    time.Since(start),
+		Err:         err,
+	}
+	if debug != nil {
+		attrs := queryAttrs(e)
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		debug.LogAttrs(ctx, slog.LevelDebug, "sqld query", attrs...)
+	}
+	if logger != nil {
+		if !logArgs {
+			e.Args = nil
+		}
+		logger.LogQuery(ctx, e)
+	}
+}
@@ -0,0 +1,133 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryLogger(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	var events []QueryEvent
+	registry.SetQueryLogger(QueryLoggerFunc(func(_ context.Context, e QueryEvent) {
+		events = append(events, e)
+	}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"email": "a@example.com"}}
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1`).
+		WithArgs("a@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "SELECT id FROM test_models WHERE email = $1", events[0].SQL)
+	assert.Equal(t, FingerprintSQL(events[0].SQL), events[0].Fingerprint)
+	assert.Equal(t, 1, events[0].ArgCount)
+	assert.Nil(t, events[0].Args, "values are redacted by default")
+	assert.NoError(t, events[0].Err)
+
+	registry.SetLogQueryArgs(true)
+	failure := errors.New("connection reset")
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1`).
+		WithArgs("a@example.com").
+		WillReturnError(failure)
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	require.Error(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, []interface{}{"a@example.com"}, events[1].Args)
+	assert.ErrorIs(t, events[1].Err, failure)
+
+	registry.SetQueryLogger(nil)
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryLoggerMutations(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(MutationAccount{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	var events []QueryEvent
+	registry.SetQueryLogger(QueryLoggerFunc(func(_ context.Context, e QueryEvent) {
+		events = append(events, e)
+	}))
+	var std bytes.Buffer
+	log.SetOutput(&std)
+	defer log.SetOutput(os.Stderr)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO mutation_accounts \(balance,owner_name\) VALUES \(\$1,\$2\) RETURNING id`).
+		WithArgs(10.5, "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	_, err = Insert[MutationAccount](ctx, db, InsertRequest{
+		Values:    map[string]interface{}{"owner": "alice", "balance": 10.5},
+		Returning: []string{"id"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, events, 1)
+	assert.Equal(t, 2, events[0].ArgCount)
+	assert.Nil(t, events[0].Args)
+	assert.NotContains(t, std.String(), "alice", "values never reach the standard logger")
+}
+
+func TestQueryLoggerCountAndSummary(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	var events []QueryEvent
+	registry.SetQueryLogger(QueryLoggerFunc(func(_ context.Context, e QueryEvent) {
+		events = append(events, e)
+	}))
+	var std bytes.Buffer
+	log.SetOutput(&std)
+	defer log.SetOutput(os.Stderr)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM test_models WHERE email = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT MAX\(age\) AS "age.max" FROM test_models WHERE email = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"age.max"}).AddRow(40))
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1 LIMIT 10 OFFSET 0`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	_, err = Execute[BuilderTestModel](ctx, db, QueryRequest{
+		Select:     []string{"id"},
+		Where:      map[string]interface{}{"email": "a@example.com"},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 10},
+		Summary:    []SummaryField{{Field: "age", Func: "max"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, events, 3)
+	for _, e := range events {
+		assert.Nil(t, e.Args)
+	}
+	assert.NotContains(t, std.String(), "a@example.com", "values never reach the standard logger")
+}
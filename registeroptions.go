@@ -0,0 +1,426 @@
+package sqld
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegisterOption configures how Register and Replace expose a model.
+type RegisterOption func(*registerConfig)
+
+type registerConfig struct {
+	// aliases maps JSON names of fields to their API names.
+	aliases map[string]string
+	// only, when not nil, lists the only fields exposed.
+	only       []string
+	excluded   []string
+	selectOnly []string
+	filterOnly []string
+	// defaultSelect is the projection of requests without Select.
+	defaultSelect []string
+	readOnly      []string
+	rawJSON       []string
+	constraints   map[string]Constraint
+	marshalers    map[string]FieldMarshaler
+	nullDefaults  map[string]interface{}
+	// naming infers the columns of fields without a db tag.
+	naming NamingStrategy
+	table  string
+	schema string
+	// tableName, when set, replaces the inferred table name verbatim.
+	tableName string
+	// version and role, when set, register the metadata as a version or a
+	// projection of the model.
+	version string
+	role    string
+	// complexity caps the requests run against the model when not nil.
+	complexity *ComplexityLimits
+	// maxRows caps the rows returned for the model when not nil.
+	maxRows *MaxRows
+}
+
+// NamingStrategy maps the Go name of a struct field without a db tag to its
+// column name.
+type NamingStrategy func(fieldName string) string
+
+// SnakeCase is the default NamingStrategy: it maps e.g. HireDate to hire_date
+// and UserID to user_id, as sqlc and scany do.
+func SnakeCase(fieldName string) string {
+	return toSnakeCase(fieldName)
+}
+
+// WithNamingStrategy sets how the columns of fields without a db tag are
+// inferred from their Go names, e.g. strings.ToLower for a schema without
+// separators. The default is SnakeCase.
+func WithNamingStrategy(strategy NamingStrategy) RegisterOption {
+	return func(c *registerConfig) { c.naming = strategy }
+}
+
+// WithFieldAlias exposes the field with JSON name field under the API name
+// alias, e.g. fullName for a field backed by the first_name column. Requests
+// and responses use the alias, and so do the other options and the
+// registrations referring to the fields of the model.
+func WithFieldAlias(field, alias string) RegisterOption {
+	return func(c *registerConfig) {
+		if c.aliases == nil {
+			c.aliases = make(map[string]string)
+		}
+		c.aliases[field] = alias
+	}
+}
+
+// WithFields exposes only the given fields of the model, by JSON name. The
+// other fields can't be used in any request, so their columns never appear
+// in generated SQL.
+func WithFields(names ...string) RegisterOption {
+	return func(c *registerConfig) { c.only = append(c.only, names...) }
+}
+
+// WithoutFields removes the given fields of the model, by JSON name, from the
+// dynamic API, e.g. password_hash. Their columns never appear in generated
+// SQL.
+func WithoutFields(names ...string) RegisterOption {
+	return func(c *registerConfig) { c.excluded = append(c.excluded, names...) }
+}
+
+// WithSelectOnlyFields lets the given fields be returned but not used in
+// Where, OrderBy or LimitBy conditions.
+func WithSelectOnlyFields(names ...string) RegisterOption {
+	return func(c *registerConfig) { c.selectOnly = append(c.selectOnly, names...) }
+}
+
+// WithFilterOnlyFields lets the given fields be used in Where, OrderBy and
+// LimitBy conditions but never returned, e.g. an ssn searched by exact value.
+func WithFilterOnlyFields(names ...string) RegisterOption {
+	return func(c *registerConfig) { c.filterOnly = append(c.filterOnly, names...) }
+}
+
+// WithReadOnlyFields marks the given fields as set by the database, like the
+// sqld:"readonly" tag, e.g. ids, created_at or computed columns of models
+// that can't be tagged. They can be selected and filtered, but Insert, Update
+// and the other writes reject them.
+func WithReadOnlyFields(names ...string) RegisterOption {
+	return func(c *registerConfig) { c.readOnly = append(c.readOnly, names...) }
+}
+
+// WithRawJSONFields returns the given JSON fields as stored, like the
+// sqld:"rawjson" tag, instead of decoding them into nested objects and
+// arrays: their text is embedded as is in encoded responses, e.g. to pass
+// documents through without changing the order of their keys.
+func WithRawJSONFields(names ...string) RegisterOption {
+	return func(c *registerConfig) { c.rawJSON = append(c.rawJSON, names...) }
+}
+
+// WithConstraint declares the column constraints of the field with JSON name
+// field, checked by Insert and Update: a not-null field without a default
+// must be given on insert and is never null, and strings can't exceed the
+// maximum length. LoadConstraints reads them from the catalog instead.
+func WithConstraint(field string, constraint Constraint) RegisterOption {
+	return func(c *registerConfig) {
+		if c.constraints == nil {
+			c.constraints = make(map[string]Constraint)
+		}
+		c.constraints[field] = constraint
+	}
+}
+
+// FieldMarshaler converts a non-null value of a field, as it would be
+// returned, to the value returned instead, e.g. an amount formatted as money
+// or a masked email. An error fails the query.
+type FieldMarshaler func(value interface{}) (interface{}, error)
+
+// WithFieldMarshaler converts the values returned for the field with JSON
+// name field with marshal, in Execute, mutation and feed results alike, so
+// that presentation tweaks don't need post-processing in every handler:
+//
+//	sqld.WithFieldMarshaler("created_at", func(v interface{}) (interface{}, error) {
+//	    return v.(time.Time).Format(time.DateOnly), nil
+//	})
+func WithFieldMarshaler(field string, marshal FieldMarshaler) RegisterOption {
+	return func(c *registerConfig) {
+		if c.marshalers == nil {
+			c.marshalers = make(map[string]FieldMarshaler)
+		}
+		c.marshalers[field] = marshal
+	}
+}
+
+// WithNullDefault sets the value returned for null values of the field with
+// JSON name field under the NullDefault policy, see SetNullPolicy, e.g. 0
+// for a missing count or "" for a missing name.
+func WithNullDefault(field string, value interface{}) RegisterOption {
+	return func(c *registerConfig) {
+		if c.nullDefaults == nil {
+			c.nullDefaults = make(map[string]interface{})
+		}
+		c.nullDefaults[field] = value
+	}
+}
+
+// WithDefaultSelect sets the fields returned by requests with an empty Select,
+// e.g. the light columns of a model, keeping heavy ones opt-in. Without it, a
+// Select is required.
+func WithDefaultSelect(names ...string) RegisterOption {
+	return func(c *registerConfig) { c.defaultSelect = append(c.defaultSelect, names...) }
+}
+
+// WithTable sets the table of the model, overriding its TableName method or
+// the name inferred from its struct name. The name may be qualified with a
+// schema, e.g. hr.employees; each part is quoted with the dialect of the
+// registry when the model is registered.
+func WithTable(name string) RegisterOption {
+	return func(c *registerConfig) { c.table = name }
+}
+
+// WithSchema qualifies the table of the model with schema, e.g. hr for
+// hr.employees. The identifiers are quoted with the dialect of the registry
+// when the model is registered.
+func WithSchema(schema string) RegisterOption {
+	return func(c *registerConfig) { c.schema = schema }
+}
+
+// WithVersion registers the metadata as version of the model, next to its
+// other versions instead of replacing its registration, e.g. a v1 exposing
+// fewer fields than v2. Execute selects a version with UseVersion; calls
+// without one use the registration made without WithVersion. Relations,
+// hooks and the other registrations of the model apply to every version.
+func WithVersion(version string) RegisterOption {
+	return func(c *registerConfig) { c.version = version }
+}
+
+// ForRole registers the metadata as the projection of the model for callers
+// with role, as reported by the RoleExtractor of the registry, e.g. an admin
+// projection of employees exposing the salary that other callers can't see.
+// Execute uses the projection of the role of the caller, or the registration
+// made without ForRole for callers without a projection, which should
+// therefore expose only what every caller may see. With WithVersion, it is
+// the projection of that version.
+func ForRole(role string) RegisterOption {
+	return func(c *registerConfig) { c.role = role }
+}
+
+func newRegisterConfig(opts []RegisterOption) registerConfig {
+	var cfg registerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// variant returns the variant of the model registered with the config.
+func (c registerConfig) variant() modelVariant {
+	return modelVariant{version: c.version, role: c.role}
+}
+
+// columnName returns the column inferred for the struct field fieldName.
+func (c registerConfig) columnName(fieldName string) string {
+	if c.naming == nil {
+		return SnakeCase(fieldName)
+	}
+	return c.naming(fieldName)
+}
+
+// apply renames and restricts the fields of metadata as configured.
+func (c registerConfig) apply(metadata *ModelMetadata) error {
+	if c.tableName != "" {
+		metadata.TableName = c.tableName
+	}
+	if err := c.applyTable(metadata); err != nil {
+		return err
+	}
+	if err := c.applyAliases(metadata); err != nil {
+		return err
+	}
+	for _, list := range [][]string{c.only, c.excluded, c.selectOnly, c.filterOnly, c.readOnly, c.rawJSON} {
+		for _, name := range list {
+			if _, ok := metadata.Fields[name]; !ok {
+				return &UnknownFieldError{Field: name, In: "register option"}
+			}
+		}
+	}
+
+	if c.only != nil {
+		kept := make(map[string]Field, len(c.only))
+		for _, name := range c.only {
+			kept[name] = metadata.Fields[name]
+		}
+		metadata.Fields = kept
+	}
+	for _, name := range c.excluded {
+		delete(metadata.Fields, name)
+	}
+	for _, name := range c.selectOnly {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return fmt.Errorf("select-only field %s is not exposed", name)
+		}
+		field.SelectOnly = true
+		metadata.Fields[name] = field
+	}
+	for _, name := range c.filterOnly {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return fmt.Errorf("filter-only field %s is not exposed", name)
+		}
+		if field.SelectOnly {
+			return fmt.Errorf("field %s can't be both select-only and filter-only", name)
+		}
+		field.FilterOnly = true
+		metadata.Fields[name] = field
+	}
+	for _, name := range c.readOnly {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return fmt.Errorf("read-only field %s is not exposed", name)
+		}
+		field.ReadOnly = true
+		metadata.Fields[name] = field
+	}
+	for _, name := range c.rawJSON {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return fmt.Errorf("raw JSON field %s is not exposed", name)
+		}
+		if !isJSONType(field.Type) {
+			return fmt.Errorf("field %s of type %s can't hold JSON", name, field.Type)
+		}
+		field.RawJSON = true
+		metadata.Fields[name] = field
+	}
+	for _, name := range sortedKeys(c.constraints) {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return &UnknownFieldError{Field: name, In: "constraint"}
+		}
+		constraint := c.constraints[name]
+		if constraint.MaxLength < 0 {
+			return fmt.Errorf("invalid maximum length of field %s: %d", name, constraint.MaxLength)
+		}
+		field.NotNull = constraint.NotNull
+		field.HasDefault = constraint.HasDefault
+		field.MaxLength = constraint.MaxLength
+		metadata.Fields[name] = field
+	}
+	for _, name := range sortedKeys(c.marshalers) {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return &UnknownFieldError{Field: name, In: "marshaler"}
+		}
+		field.Marshal = c.marshalers[name]
+		metadata.Fields[name] = field
+	}
+	for _, name := range sortedKeys(c.nullDefaults) {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return &UnknownFieldError{Field: name, In: "null default"}
+		}
+		field.NullDefault = c.nullDefaults[name]
+		metadata.Fields[name] = field
+	}
+	for _, name := range c.defaultSelect {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return &UnknownFieldError{Field: name, In: "default select"}
+		}
+		if err := field.checkSelectable(name); err != nil {
+			return err
+		}
+	}
+	metadata.DefaultSelect = c.defaultSelect
+	metadata.ComplexityLimits = c.complexity
+	metadata.MaxRows = c.maxRows
+	return nil
+}
+
+// applyTable sets the quoted, schema-qualified table of metadata when
+// WithTable or WithSchema is used.
+func (c registerConfig) applyTable(metadata *ModelMetadata) error {
+	if c.table == "" && c.schema == "" {
+		return nil
+	}
+	table := c.table
+	if table == "" {
+		table = metadata.TableName
+	}
+	parts := strings.Split(table, ".")
+	if c.schema != "" {
+		if len(parts) > 1 {
+			return fmt.Errorf("table %s already has a schema", table)
+		}
+		parts = append([]string{c.schema}, parts...)
+	}
+	if len(parts) > 2 {
+		return fmt.Errorf("invalid table name: %s", table)
+	}
+
+	dialect := metadata.dialect()
+	folder, folds := dialect.(identFoldingDialect)
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return fmt.Errorf("invalid table name: %s", strings.Join(parts, "."))
+		}
+		if folds {
+			parts[i] = folder.FoldIdent(part)
+		}
+		quoted[i] = dialect.QuoteIdent(parts[i])
+	}
+	metadata.TableName = strings.Join(quoted, ".")
+	metadata.tableIdent = parts
+	return nil
+}
+
+// applyAliases renames the aliased fields of metadata.
+func (c registerConfig) applyAliases(metadata *ModelMetadata) error {
+	if len(c.aliases) == 0 {
+		return nil
+	}
+	fields := make(map[string]Field, len(metadata.Fields))
+	for name, field := range metadata.Fields {
+		if alias, ok := c.aliases[name]; ok {
+			if !identRegex.MatchString(alias) || strings.Contains(alias, ".") {
+				return fmt.Errorf("invalid alias for field %s: %q", name, alias)
+			}
+			name = alias
+			field.JSONName = alias
+		}
+		if _, exists := fields[name]; exists {
+			return fmt.Errorf("alias %s clashes with another field", name)
+		}
+		fields[name] = field
+	}
+	for name := range c.aliases {
+		if _, ok := metadata.Fields[name]; !ok {
+			return &UnknownFieldError{Field: name, In: "register option"}
+		}
+	}
+	metadata.Fields = fields
+	return nil
+}
+
+// withDefaultSelect returns req with the default select list of metadata
+// when it selects no field.
+func withDefaultSelect(metadata ModelMetadata, req QueryRequest) QueryRequest {
+	if len(req.Select) == 0 {
+		req.Select = append([]string(nil), metadata.DefaultSelect...)
+	}
+	return req
+}
+
+// checkSelectable returns an error when the field named name can't be
+// returned.
+func (f Field) checkSelectable(name string) error {
+	if f.FilterOnly {
+		return fmt.Errorf("field %s is filter-only and can't be selected", name)
+	}
+	return nil
+}
+
+// checkFilterable returns an error when the field named name can't be used
+// in conditions or ordering.
+func (f Field) checkFilterable(name string) error {
+	if f.SelectOnly {
+		return fmt.Errorf("field %s is select-only and can't be used in conditions", name)
+	}
+	return nil
+}
@@ -0,0 +1,426 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SensitiveUser has columns that must not be exposed freely.
+type SensitiveUser struct {
+	ID           int64  `json:"id"`
+	Email        string `json:"email"`
+	SSN          string `json:"ssn"`
+	PasswordHash string `json:"password_hash" db:"password_hash"`
+	Bio          string `json:"bio"`
+}
+
+func (SensitiveUser) TableName() string {
+	return "sensitive_users"
+}
+
+func TestRegisterOptions(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(SensitiveUser{},
+		WithoutFields("password_hash"),
+		WithFilterOnlyFields("ssn"),
+		WithSelectOnlyFields("bio"),
+	))
+	metadata, err := registry.GetModelMetadata(SensitiveUser{})
+	require.NoError(t, err)
+
+	assert.NotContains(t, metadata.Fields, "password_hash")
+	assert.True(t, metadata.Fields["ssn"].FilterOnly)
+	assert.True(t, metadata.Fields["bio"].SelectOnly)
+
+	tests := []struct {
+		name    string
+		req     QueryRequest
+		wantErr string
+	}{
+		{name: "excluded", req: QueryRequest{Select: []string{"password_hash"}}, wantErr: "invalid field in select: password_hash"},
+		{name: "excluded in where", req: QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"password_hash": "x"}}, wantErr: "invalid field in where clause: password_hash"},
+		{name: "filter-only selected", req: QueryRequest{Select: []string{"ssn"}}, wantErr: "field ssn is filter-only and can't be selected"},
+		{name: "filter-only summarized", req: QueryRequest{Select: []string{"id"}, Summary: []SummaryField{{Field: "ssn", Func: SummaryMax}}}, wantErr: "field ssn is filter-only"},
+		{name: "select-only filtered", req: QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"bio": "x"}}, wantErr: "field bio is select-only and can't be used in conditions"},
+		{name: "select-only ordered", req: QueryRequest{Select: []string{"id"}, OrderBy: []OrderByClause{{Field: "bio"}}}, wantErr: "field bio is select-only"},
+		{name: "valid", req: QueryRequest{Select: []string{"id", "bio"}, Where: map[string]interface{}{"ssn": "123"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BasicValidator{}.ValidateQuery(tt.req, metadata)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterOptions_Invalid(t *testing.T) {
+	registry := NewRegistry()
+	assert.ErrorContains(t, registry.Register(SensitiveUser{}, WithoutFields("salary")),
+		"failed to register model SensitiveUser: invalid field in register option: salary")
+	assert.ErrorContains(t, registry.Register(SensitiveUser{}, WithoutFields("ssn"), WithFilterOnlyFields("ssn")),
+		"filter-only field ssn is not exposed")
+	assert.ErrorContains(t, registry.Register(SensitiveUser{}, WithSelectOnlyFields("bio"), WithFilterOnlyFields("bio")),
+		"field bio can't be both select-only and filter-only")
+
+	require.NoError(t, registry.Register(SensitiveUser{}, WithFields("id", "email")))
+	metadata, err := registry.GetModelMetadata(SensitiveUser{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email", "id"}, sortedKeys(metadata.Fields))
+}
+
+func TestRegisterOptions_Mutations(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(SensitiveUser{},
+		WithoutFields("password_hash"),
+		WithFilterOnlyFields("ssn"),
+		WithSelectOnlyFields("bio"),
+	))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Filter-only fields are left out of the default RETURNING list
+	mock.ExpectQuery(`INSERT INTO sensitive_users \(email,ssn\) VALUES \(\$1,\$2\) RETURNING bio, email, id$`).
+		WithArgs("a@example.com", "123").
+		WillReturnRows(sqlmock.NewRows([]string{"bio", "email", "id"}).AddRow("", "a@example.com", int64(1)))
+
+	_, err = Insert[SensitiveUser](ctx, db, InsertRequest{
+		Values: map[string]interface{}{"email": "a@example.com", "ssn": "123"},
+	})
+	require.NoError(t, err)
+
+	_, err = Insert[SensitiveUser](ctx, db, InsertRequest{
+		Values: map[string]interface{}{"password_hash": "x"},
+	})
+	assert.ErrorContains(t, err, "invalid field: password_hash")
+	_, err = Update[SensitiveUser](ctx, db, UpdateRequest{
+		Set:   map[string]interface{}{"email": "b@example.com"},
+		Where: map[string]interface{}{"bio": "x"},
+	})
+	assert.ErrorContains(t, err, "field bio is select-only")
+	_, err = Delete[SensitiveUser](ctx, db, DeleteRequest{
+		Where:     map[string]interface{}{"id": 1},
+		Returning: []string{"ssn"},
+	})
+	assert.ErrorContains(t, err, "field ssn is filter-only")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithReadOnlyFields(t *testing.T) {
+	registry := NewRegistry()
+	assert.ErrorContains(t, registry.Register(SensitiveUser{}, WithReadOnlyFields("created_at")),
+		"invalid field in register option: created_at")
+	require.NoError(t, registry.Register(SensitiveUser{}, WithReadOnlyFields("id")))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id FROM sensitive_users WHERE id = \$1$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	_, err = Execute[SensitiveUser](ctx, db, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"id": 1}})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = Insert[SensitiveUser](ctx, db, InsertRequest{Values: map[string]interface{}{"id": 1, "email": "a@example.com"}})
+	assert.ErrorContains(t, err, "field id is read-only and can't be written")
+	_, err = Update[SensitiveUser](ctx, db, UpdateRequest{
+		Set:   map[string]interface{}{"id": 2},
+		Where: map[string]interface{}{"id": 1},
+	})
+	assert.ErrorContains(t, err, "field id is read-only and can't be written")
+}
+
+func TestWithFieldMarshaler(t *testing.T) {
+	maskEmail := func(v interface{}) (interface{}, error) {
+		local, domain, ok := strings.Cut(v.(string), "@")
+		if !ok {
+			return nil, fmt.Errorf("invalid email %q", v)
+		}
+		return local[:1] + "***@" + domain, nil
+	}
+	registry := NewRegistry()
+	assert.ErrorContains(t, registry.Register(SensitiveUser{}, WithFieldMarshaler("phone", maskEmail)),
+		"invalid field in marshaler: phone")
+	require.NoError(t, registry.Register(SensitiveUser{}, WithFieldMarshaler("email", maskEmail)))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, email FROM sensitive_users`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(int64(1), "alice@example.com").AddRow(int64(2), nil))
+	resp, err := Execute[SensitiveUser](ctx, db, QueryRequest{Select: []string{"id", "email"}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "email": "a***@example.com"}, {"id": int64(2), "email": nil}}, resp.Data)
+
+	mock.ExpectQuery(`SELECT email FROM sensitive_users`).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("nobody"))
+	_, err = Execute[SensitiveUser](ctx, db, QueryRequest{Select: []string{"email"}})
+	assert.ErrorContains(t, err, `failed to scan field email: invalid email "nobody"`)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithDefaultSelect(t *testing.T) {
+	registry := NewRegistry()
+	assert.ErrorContains(t, registry.Register(SensitiveUser{}, WithFilterOnlyFields("ssn"), WithDefaultSelect("id", "ssn")),
+		"field ssn is filter-only")
+	assert.ErrorContains(t, registry.Register(SensitiveUser{}, WithDefaultSelect("salary")),
+		"invalid field in default select: salary")
+	require.NoError(t, registry.Register(SensitiveUser{}, WithDefaultSelect("id", "email")))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, email FROM sensitive_users WHERE id = \$1$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(int64(1), "a@example.com"))
+	mock.ExpectQuery(`SELECT bio FROM sensitive_users$`).
+		WillReturnRows(sqlmock.NewRows([]string{"bio"}).AddRow("hi"))
+
+	resp, err := Execute[SensitiveUser](ctx, db, QueryRequest{Where: map[string]interface{}{"id": 1}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "email": "a@example.com"}}, resp.Data)
+
+	// Heavy columns stay available when selected explicitly
+	resp, err = Execute[SensitiveUser](ctx, db, QueryRequest{Select: []string{"bio"}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"bio": "hi"}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// Models without a default still require a select list
+	require.NoError(t, registry.Register(ScopedModel{}))
+	_, err = Execute[ScopedModel](ctx, db, QueryRequest{})
+	assert.ErrorContains(t, err, "select fields cannot be empty")
+}
+
+func TestWithVersion(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(SensitiveUser{}, WithVersion("v1"), WithFields("id", "email")))
+	require.NoError(t, registry.Register(SensitiveUser{}, WithoutFields("password_hash")))
+	ctx := WithRegistry(context.Background(), registry)
+
+	metadata, err := registry.GetModelVersion(SensitiveUser{}, "v1")
+	require.NoError(t, err)
+	assert.Len(t, metadata.Fields, 2)
+	metadata, err = registry.GetModelMetadata(SensitiveUser{})
+	require.NoError(t, err)
+	assert.Len(t, metadata.Fields, 4)
+	_, err = registry.GetModelVersion(SensitiveUser{}, "v3")
+	assert.ErrorContains(t, err, "version v3 of model SensitiveUser not registered")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id", "bio"}}
+	_, err = Execute[SensitiveUser](ctx, db, req, UseVersion("v1"))
+	assert.ErrorContains(t, err, "invalid field in select: bio")
+
+	mock.ExpectQuery(`SELECT id, bio FROM sensitive_users$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "bio"}).AddRow(int64(1), "hi"))
+	resp, err := Execute[SensitiveUser](ctx, db, req)
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "bio": "hi"}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.ErrorContains(t, registry.Replace(SensitiveUser{}, WithVersion("v3")), "version v3 of model SensitiveUser not registered")
+	require.NoError(t, registry.Replace(SensitiveUser{}, WithVersion("v1"), WithFields("id", "email", "bio")))
+	metadata, err = registry.GetModelVersion(SensitiveUser{}, "v1")
+	require.NoError(t, err)
+	assert.Contains(t, metadata.Fields, "bio")
+
+	require.NoError(t, registry.Unregister(SensitiveUser{}))
+	_, err = registry.GetModelVersion(SensitiveUser{}, "v1")
+	assert.Error(t, err)
+}
+
+// AliasedPerson has fields exposed under API names.
+type AliasedPerson struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name" db:"first_name"`
+}
+
+func (AliasedPerson) TableName() string {
+	return "people"
+}
+
+func TestWithFieldAlias(t *testing.T) {
+	registry := NewRegistry()
+	assert.ErrorContains(t, registry.Register(AliasedPerson{}, WithFieldAlias("first_name", "id")),
+		"alias id clashes with another field")
+	assert.ErrorContains(t, registry.Register(AliasedPerson{}, WithFieldAlias("first_name", "full.name")),
+		`invalid alias for field first_name: "full.name"`)
+	assert.ErrorContains(t, registry.Register(AliasedPerson{}, WithFieldAlias("last_name", "lastName")),
+		"invalid field in register option: last_name")
+	require.NoError(t, registry.Register(AliasedPerson{},
+		WithFieldAlias("first_name", "fullName"),
+		WithDefaultSelect("id", "fullName"),
+	))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, first_name FROM people WHERE first_name = \$1 ORDER BY first_name ASC$`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name"}).AddRow(int64(1), "alice"))
+	mock.ExpectQuery(`UPDATE people SET first_name = \$1 WHERE id = \$2 RETURNING first_name$`).
+		WithArgs("bob", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"first_name"}).AddRow("bob"))
+
+	resp, err := Execute[AliasedPerson](ctx, db, QueryRequest{
+		Where:   map[string]interface{}{"fullName": "alice"},
+		OrderBy: []OrderByClause{{Field: "fullName"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "fullName": "alice"}}, resp.Data)
+
+	updated, err := Update[AliasedPerson](ctx, db, UpdateRequest{
+		Set:       map[string]interface{}{"fullName": "bob"},
+		Where:     map[string]interface{}{"id": 1},
+		Returning: []string{"fullName"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"fullName": "bob"}}, updated.Data)
+
+	_, err = Execute[AliasedPerson](ctx, db, QueryRequest{Select: []string{"first_name"}})
+	assert.ErrorContains(t, err, "invalid field in select: first_name")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// UntaggedEmployee relies on inferred column names.
+type UntaggedEmployee struct {
+	ID         int64  `json:"id"`
+	HireDate   string `json:"hireDate"`
+	ManagerID  int64  `json:"managerId"`
+	HTTPStatus int    `json:"httpStatus"`
+	Email      string `json:"email" db:"email_address"`
+}
+
+func (UntaggedEmployee) TableName() string {
+	return "untagged_employees"
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"ID":            "id",
+		"HireDate":      "hire_date",
+		"UserID":        "user_id",
+		"HTTPStatus":    "http_status",
+		"Address2":      "address2",
+		"Already_Cased": "already_cased",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, SnakeCase(in), in)
+	}
+}
+
+func TestColumnNameInference(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(UntaggedEmployee{}))
+	metadata, err := registry.GetModelMetadata(UntaggedEmployee{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "hire_date", metadata.Fields["hireDate"].Name)
+	assert.Equal(t, "manager_id", metadata.Fields["managerId"].Name)
+	assert.Equal(t, "http_status", metadata.Fields["httpStatus"].Name)
+	assert.Equal(t, "email_address", metadata.Fields["email"].Name)
+
+	builder, err := buildSelect(metadata, QueryRequest{Select: []string{"id", "hireDate"}, Where: map[string]interface{}{"managerId": 7}})
+	require.NoError(t, err)
+	query, _, err := builder.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id, hire_date FROM untagged_employees WHERE manager_id = $1", query)
+
+	t.Run("custom strategy", func(t *testing.T) {
+		registry := NewRegistry()
+		require.NoError(t, registry.Register(UntaggedEmployee{}, WithNamingStrategy(strings.ToLower)))
+		metadata, err := registry.GetModelMetadata(UntaggedEmployee{})
+		require.NoError(t, err)
+		assert.Equal(t, "hiredate", metadata.Fields["hireDate"].Name)
+		assert.Equal(t, "email_address", metadata.Fields["email"].Name)
+	})
+}
+
+// JobCategory has no TableName method.
+type JobCategory struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"employee":     "employees",
+		"job_category": "job_categories",
+		"holiday":      "holidays",
+		"address":      "addresses",
+		"box":          "boxes",
+		"branch":       "branches",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, pluralize(in), in)
+	}
+}
+
+func TestTableNameOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   Model
+		opts    []RegisterOption
+		dialect Dialect
+		want    string
+		wantErr string
+	}{
+		{name: "inferred", model: JobCategory{}, want: "SELECT id FROM job_categories"},
+		{name: "table method", model: UntaggedEmployee{}, want: "SELECT id FROM untagged_employees"},
+		{name: "override", model: JobCategory{}, opts: []RegisterOption{WithTable("categories")}, want: `SELECT id FROM "categories"`},
+		{name: "schema", model: UntaggedEmployee{}, opts: []RegisterOption{WithSchema("hr")}, want: `SELECT id FROM "hr"."untagged_employees"`},
+		{name: "qualified table", model: JobCategory{}, opts: []RegisterOption{WithTable("hr.Job Categories")}, want: `SELECT id FROM "hr"."Job Categories"`},
+		{name: "mysql", model: JobCategory{}, opts: []RegisterOption{WithSchema("hr")}, dialect: MySQL, want: "SELECT id FROM `hr`.`job_categories`"},
+		{name: "oracle", model: JobCategory{}, opts: []RegisterOption{WithSchema("hr")}, dialect: Oracle, want: `SELECT id FROM "HR"."JOB_CATEGORIES"`},
+		{name: "two schemas", model: JobCategory{}, opts: []RegisterOption{WithTable("hr.categories"), WithSchema("ops")}, wantErr: "table hr.categories already has a schema"},
+		{name: "empty part", model: JobCategory{}, opts: []RegisterOption{WithTable("hr.")}, wantErr: "invalid table name: hr."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			registry.SetDialect(tt.dialect)
+			err := registry.Register(tt.model, tt.opts...)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			metadata, err := registry.GetModelMetadata(tt.model)
+			require.NoError(t, err)
+			builder, err := buildSelect(metadata, QueryRequest{Select: []string{"id"}})
+			require.NoError(t, err)
+			query, _, err := builder.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, query)
+		})
+	}
+}
+
+func TestRegister_NotStruct(t *testing.T) {
+	assert.ErrorContains(t, NewRegistry().Register(CustomInt(0)), "must be a struct")
+}
@@ -1,33 +1,121 @@
 package sqld
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
-// Registry is a type-safe registry for model metadata and scanners
+// Registry is a type-safe registry for model metadata and scanners.
+// The package-level functions use a default instance; other instances let one
+// process serve several APIs with different model configurations, and are
+// selected for the calls made under a context prepared with WithRegistry.
+//
+// A Registry is safe for concurrent use: models, scanners, relations and
+// settings may be registered while Execute and the other calls read them,
+// e.g. from a request handler. A call sees each registration either entirely
+// or not at all.
 type Registry struct {
-	models   map[reflect.Type]ModelMetadata
-	scanners map[reflect.Type]func() sql.Scanner
-	mu       sync.RWMutex
+	models      map[reflect.Type]ModelMetadata
+	variants    map[reflect.Type]map[modelVariant]ModelMetadata // Registered WithVersion or ForRole
+	scanners    map[reflect.Type]func() sql.Scanner
+	composites  map[reflect.Type][]taggedField // Attributes of registered composite types
+	enums       map[string][]interface{}
+	lookups     map[reflect.Type]map[string]Lookup
+	relations   map[reflect.Type]map[string]relationEntry
+	hierarchies map[reflect.Type]Hierarchy
+	softDeletes map[reflect.Type]SoftDelete
+	audits      map[reflect.Type]Audit
+	hooks       map[reflect.Type]Hooks
+	transforms  map[reflect.Type][]ResultTransformer
+	tables      map[string]Model // Models registered with RegisterFromTable
+	flags       FlagProvider
+	actor       ActorExtractor
+	role        RoleExtractor
+	execMode    *pgx.QueryExecMode
+	timeOutput  *TimeOutput
+	binary      BinaryOutput
+	nulls       NullPolicy
+	logger      QueryLogger
+	logArgs     bool
+	slog        *slog.Logger
+	debug       bool
+	metrics     *MetricsCollector
+	slowAfter   time.Duration
+	slowHook    SlowQueryHook
+	costLimits  CostLimits
+	complexity  ComplexityLimits
+	maxRows     MaxRows
+	dialect     Dialect
+	mu          sync.RWMutex
 }
 
 // NewRegistry returns a new instance of the registry
 func NewRegistry() *Registry {
 	return &Registry{
-		models:   make(map[reflect.Type]ModelMetadata),
-		scanners: make(map[reflect.Type]func() sql.Scanner),
+		models:      make(map[reflect.Type]ModelMetadata),
+		variants:    make(map[reflect.Type]map[modelVariant]ModelMetadata),
+		scanners:    make(map[reflect.Type]func() sql.Scanner),
+		composites:  make(map[reflect.Type][]taggedField),
+		enums:       make(map[string][]interface{}),
+		lookups:     make(map[reflect.Type]map[string]Lookup),
+		relations:   make(map[reflect.Type]map[string]relationEntry),
+		hierarchies: make(map[reflect.Type]Hierarchy),
+		softDeletes: make(map[reflect.Type]SoftDelete),
+		audits:      make(map[reflect.Type]Audit),
+		hooks:       make(map[reflect.Type]Hooks),
+		transforms:  make(map[reflect.Type][]ResultTransformer),
+		tables:      make(map[string]Model),
 	}
 }
 
 // defaultRegistry is the default global registry instance
 var defaultRegistry = NewRegistry()
 
+// registryKey is the context key under which the registry of WithRegistry is stored.
+type registryKey struct{}
+
+// WithRegistry returns a context under which Execute, ExecuteRaw, Insert,
+// Update, Delete and the other functions taking a context read models,
+// relations, hooks and settings such as the dialect from r instead of the
+// default registry:
+//
+//	admin := sqld.NewRegistry()
+//	admin.Register(Employee{})
+//	resp, err := sqld.Execute[Employee](sqld.WithRegistry(ctx, admin), db, req)
+func WithRegistry(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, registryKey{}, r)
+}
+
+// registryFromContext returns the registry of ctx, or the default registry.
+func registryFromContext(ctx context.Context) *Registry {
+	if r, ok := ctx.Value(registryKey{}).(*Registry); ok && r != nil {
+		return r
+	}
+	return defaultRegistry
+}
+
 // Register adds a model's metadata to the registry
-func Register[T Model](model T) error {
-	return defaultRegistry.Register(model)
+func Register[T Model](model T, opts ...RegisterOption) error {
+	return defaultRegistry.Register(model, opts...)
+}
+
+// Replace re-registers model T in the default registry. See Registry.Replace.
+func Replace[T Model](model T, opts ...RegisterOption) error {
+	return defaultRegistry.Replace(model, opts...)
+}
+
+// Unregister removes model T from the default registry. See Registry.Unregister.
+func Unregister[T Model]() error {
+	var model T
+	return defaultRegistry.Unregister(model)
 }
 
 // RegisterScanner registers a function that creates scanners for a specific type
@@ -35,47 +123,314 @@ func RegisterScanner(t reflect.Type, scannerFactory func() sql.Scanner) {
 	defaultRegistry.RegisterScanner(t, scannerFactory)
 }
 
+// RegisterScannerFor registers a function that creates scanners for type T in
+// the default registry, without spelling out its reflect.Type:
+//
+//	sqld.RegisterScannerFor[EmployeeID](func() sql.Scanner { return &EmployeeIDScanner{} })
+//
+// Other registries take reflect.TypeFor[T]() in Registry.RegisterScanner.
+func RegisterScannerFor[T any](scannerFactory func() sql.Scanner) {
+	defaultRegistry.RegisterScanner(reflect.TypeFor[T](), scannerFactory)
+}
+
 // getModelMetadata retrieves metadata for a model type
 func getModelMetadata(model Model) (ModelMetadata, error) {
 	return defaultRegistry.GetModelMetadata(model)
 }
 
-// Register adds a model's metadata to the registry
-func (r *Registry) Register(model Model) error {
+// Register adds a model's metadata to the registry. Options restrict how
+// its fields can be used; registering the model again replaces them.
+func (r *Registry) Register(model Model, opts ...RegisterOption) error {
+	metadata, err := r.newModelMetadata(model, opts)
+	if err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	t := reflect.TypeOf(model)
+	if variant := newRegisterConfig(opts).variant(); variant != (modelVariant{}) {
+		if r.variants[t] == nil {
+			r.variants[t] = make(map[modelVariant]ModelMetadata)
+		}
+		r.variants[t][variant] = metadata
+		return nil
+	}
+	r.models[t] = metadata
+	return nil
+}
 
+// Replace re-registers a registered model with opts, e.g. to reload its
+// metadata without restarting. Its relations, hierarchy, soft delete, audit columns,
+// hooks and lookups are kept and must still refer to fields of the new
+// metadata. Calls running during the swap complete with the old metadata.
+// With WithVersion or ForRole, the version or projection is replaced.
+func (r *Registry) Replace(model Model, opts ...RegisterOption) error {
 	t := reflect.TypeOf(model)
-	metadata := ModelMetadata{
-		TableName: model.TableName(),
-		Fields:    make(map[string]Field),
+	metadata, err := r.newModelMetadata(model, opts)
+	if err != nil {
+		return err
 	}
 
-	// Reflect over the struct fields
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	variant := newRegisterConfig(opts).variant()
+	if _, ok := r.variants[t][variant]; variant != (modelVariant{}) && !ok {
+		return &ModelNotRegisteredError{Model: t.Name(), Variant: variant.String()}
+	}
+	if _, ok := r.models[t]; variant == (modelVariant{}) && !ok {
+		return &ModelNotRegisteredError{Model: t.Name()}
+	}
+	if err := r.checkDependents(t, metadata); err != nil {
+		return fmt.Errorf("failed to replace model %s: %w", t.Name(), err)
+	}
+	if variant != (modelVariant{}) {
+		r.variants[t][variant] = metadata
+		return nil
+	}
+	r.models[t] = metadata
+	return nil
+}
 
-		// Use json tag for field naming
-		jsonName := field.Tag.Get("json")
-		if jsonName == "" {
-			continue // Skip fields without json tags
+// Unregister removes a model from the registry with its versions,
+// projections, relations, hierarchy, soft delete, audit columns, hooks and
+// lookups. Models that are the target of a relation of another model can't
+// be removed.
+func (r *Registry) Unregister(model Model) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(model)
+	_, ok := r.models[t]
+	if _, varied := r.variants[t]; !ok && !varied {
+		return &ModelNotRegisteredError{Model: t.Name()}
+	}
+	for from, entries := range r.relations {
+		if from == t {
+			continue
+		}
+		for name, entry := range entries {
+			if entry.target == t {
+				return fmt.Errorf("model %s is the target of relation %s of model %s", t.Name(), name, from.Name())
+			}
 		}
+	}
+
+	delete(r.models, t)
+	delete(r.variants, t)
+	delete(r.relations, t)
+	delete(r.lookups, t)
+	delete(r.hierarchies, t)
+	delete(r.softDeletes, t)
+	delete(r.audits, t)
+	delete(r.hooks, t)
+	delete(r.transforms, t)
+	for table, m := range r.tables {
+		if reflect.TypeOf(m) == t {
+			delete(r.tables, table)
+		}
+	}
+	return nil
+}
 
-		// Get database column name from db tag, fallback to json name if not specified
-		dbName := field.Tag.Get("db")
+// checkDependents checks that the registrations depending on the fields of
+// model type t still hold with metadata. The caller must hold r.mu.
+func (r *Registry) checkDependents(t reflect.Type, metadata ModelMetadata) error {
+	hasField := func(name string) bool {
+		_, ok := metadata.Fields[name]
+		return ok
+	}
+	for from, entries := range r.relations {
+		for name, entry := range entries {
+			if from == t && !hasField(entry.relation.LocalField) {
+				return fmt.Errorf("invalid local field in relation %s: %s", name, entry.relation.LocalField)
+			}
+			if entry.target == t && !hasField(entry.relation.ForeignField) {
+				return fmt.Errorf("invalid foreign field in relation %s of model %s: %s", name, from.Name(), entry.relation.ForeignField)
+			}
+		}
+	}
+	if h, ok := r.hierarchies[t]; ok {
+		for _, name := range []string{h.IDField, h.ParentField} {
+			if !hasField(name) {
+				return &UnknownFieldError{Field: name, In: "hierarchy"}
+			}
+		}
+	}
+	if sd, ok := r.softDeletes[t]; ok {
+		if !hasField(sd.DeletedAtField) {
+			return fmt.Errorf("invalid deleted at field in soft delete: %s", sd.DeletedAtField)
+		}
+		if sd.DeletedByField != "" && !hasField(sd.DeletedByField) {
+			return fmt.Errorf("invalid deleted by field in soft delete: %s", sd.DeletedByField)
+		}
+	}
+	if a, ok := r.audits[t]; ok {
+		for _, name := range a.fields() {
+			if !hasField(name) {
+				return &UnknownFieldError{Field: name, In: "audit"}
+			}
+		}
+	}
+	for name := range r.lookups[t] {
+		if hasField(name) {
+			return fmt.Errorf("lookup %s clashes with a field", name)
+		}
+	}
+	return nil
+}
+
+// newModelMetadata reads the metadata of model from its struct tags and
+// applies opts.
+func (r *Registry) newModelMetadata(model Model, opts []RegisterOption) (ModelMetadata, error) {
+	t := reflect.TypeOf(model)
+	if t == nil || t.Kind() != reflect.Struct {
+		return ModelMetadata{}, fmt.Errorf("model %v must be a struct", t)
+	}
+	cfg := newRegisterConfig(opts)
+	metadata := ModelMetadata{
+		TableName: inferTableName(model),
+		Fields:    make(map[string]Field),
+		ReadOnly:  isReadOnly(model),
+		registry:  r,
+	}
+	// Reflect over the struct fields, including promoted ones
+	for _, field := range modelFields(t) {
+		// Get database column name from db tag, inferring it from the field name if not specified
+		dbName := field.column
 		if dbName == "" {
-			dbName = jsonName
+			dbName = cfg.columnName(field.Name)
+		}
+
+		var required, readOnly, rawJSON bool
+		for _, option := range field.options {
+			switch option {
+			case "required":
+				required = true
+			case "readonly":
+				readOnly = true
+			case "rawjson":
+				rawJSON = true
+			}
 		}
 
-		metadata.Fields[jsonName] = Field{
-			Name:     dbName,   // Use db tag name for database column
-			JSONName: jsonName, // Use json tag for JSON field name
+		metadata.Fields[field.jsonName] = Field{
+			Name:     dbName,         // Use db tag name for database column
+			JSONName: field.jsonName, // Use json tag for JSON field name
 			Type:     field.Type,
+			Enum:     field.Tag.Get("enum"),
+			Required: required,
+			ReadOnly: readOnly,
+			RawJSON:  rawJSON,
+			index:    field.Index,
 		}
 	}
 
-	r.models[t] = metadata
-	return nil
+	if err := cfg.apply(&metadata); err != nil {
+		return ModelMetadata{}, fmt.Errorf("failed to register model %s: %w", t.Name(), err)
+	}
+	return metadata, nil
+}
+
+// taggedField is a struct field of a model with its parsed tags.
+type taggedField struct {
+	reflect.StructField
+	jsonName string   // Name from the json tag
+	column   string   // Name from the db tag, empty when inferred
+	options  []string // Options of the db and sqld tags, e.g. readonly
+}
+
+// parseTag splits a struct tag into its name and options, e.g. email and
+// [omitempty] for `json:"email,omitempty"`.
+func parseTag(tag string) (string, []string) {
+	name, options, _ := strings.Cut(tag, ",")
+	if options == "" {
+		return name, nil
+	}
+	return name, strings.Split(options, ",")
+}
+
+// modelFields returns the fields of the struct type t with a json tag, with
+// those of embedded structs without a json name promoted in their place as
+// encoding/json does, e.g. the columns of an AuditFields struct shared by
+// several models. Fields tagged json:"-" or db:"-" are left out, and so is a
+// promoted field when an outer field has its json name.
+func modelFields(t reflect.Type) []taggedField {
+	var fields []taggedField
+	depths := make(map[string]int) // Shallowest depth of each json name
+	var walk func(t reflect.Type, index []int, path map[reflect.Type]bool)
+	walk = func(t reflect.Type, index []int, path map[reflect.Type]bool) {
+		path[t] = true
+		defer delete(path, t)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			field.Index = append(append([]int(nil), index...), i)
+			jsonTag, tagged := field.Tag.Lookup("json")
+			jsonName, _ := parseTag(jsonTag)
+			if field.Anonymous && jsonName == "" {
+				embedded := field.Type
+				if embedded.Kind() == reflect.Pointer {
+					embedded = embedded.Elem()
+				}
+				if embedded.Kind() == reflect.Struct {
+					if !path[embedded] {
+						walk(embedded, field.Index, path)
+					}
+					continue // Its fields are promoted
+				}
+			}
+			column, dbOptions := parseTag(field.Tag.Get("db"))
+			if !tagged || jsonName == "-" || column == "-" {
+				continue // Skip fields without json tags or excluded
+			}
+			if jsonName == "" {
+				jsonName = field.Name // As encoding/json does for json:",omitempty"
+			}
+			fields = append(fields, taggedField{
+				StructField: field,
+				jsonName:    jsonName,
+				column:      column,
+				options:     append(dbOptions, strings.Split(field.Tag.Get("sqld"), ",")...),
+			})
+			if depth, ok := depths[jsonName]; !ok || len(field.Index) < depth {
+				depths[jsonName] = len(field.Index)
+			}
+		}
+	}
+	walk(t, nil, make(map[reflect.Type]bool))
+
+	visible := fields[:0]
+	for _, field := range fields {
+		if len(field.Index) == depths[field.jsonName] {
+			visible = append(visible, field)
+		}
+	}
+	return visible
+}
+
+// inferTableName returns the table of model: the result of its TableName
+// method, or its struct name in snake case and plural otherwise.
+func inferTableName(model Model) string {
+	if namer, ok := model.(TableNamer); ok {
+		return namer.TableName()
+	}
+	return pluralize(SnakeCase(reflect.TypeOf(model).Name()))
+}
+
+// pluralize returns the English plural of the snake case noun name, e.g.
+// categories for category and boxes for box.
+func pluralize(name string) string {
+	switch {
+	case name == "":
+		return name
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	default:
+		return name + "s"
+	}
 }
 
 // RegisterScanner registers a function that creates scanners for a specific type
@@ -87,17 +442,57 @@ func (r *Registry) RegisterScanner(t reflect.Type, scannerFactory func() sql.Sca
 
 // GetModelMetadata retrieves metadata for a model type
 func (r *Registry) GetModelMetadata(model Model) (ModelMetadata, error) {
+	return r.GetModelVersion(model, "")
+}
+
+// GetModelVersion retrieves the metadata of the version of a model type
+// registered WithVersion, or of its registration without a version when
+// version is empty.
+func (r *Registry) GetModelVersion(model Model, version string) (ModelMetadata, error) {
+	return r.variantMetadata(model, modelVariant{version: version})
+}
+
+// variantMetadata retrieves the metadata of the variant of a model type.
+func (r *Registry) variantMetadata(model Model, variant modelVariant) (ModelMetadata, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	t := reflect.TypeOf(model)
-	metadata, ok := r.models[reflect.TypeOf(model)]
+	metadata, ok := r.models[t]
+	if variant != (modelVariant{}) {
+		metadata, ok = r.variants[t][variant]
+		if !ok {
+			return ModelMetadata{}, &ModelNotRegisteredError{Model: t.Name(), Variant: variant.String()}
+		}
+	}
 	if !ok {
-		return ModelMetadata{}, fmt.Errorf("model %s not registered", t.Name())
+		return ModelMetadata{}, &ModelNotRegisteredError{Model: t.Name()}
 	}
+	metadata.Relations = r.relationsFor(t)
+	if h, ok := r.hierarchies[t]; ok {
+		metadata.Hierarchy = &h
+	}
+	metadata.SoftDelete = r.softDeleteFor(t)
+	if a, ok := r.audits[t]; ok {
+		metadata.Audit = &a
+	}
+	if h, ok := r.hooks[t]; ok {
+		metadata.Hooks = &h
+	}
+	metadata.Transformers = r.transformersFor(t)
 	return metadata, nil
 }
 
+// transformersFor returns the transformers run on rows of the model of type
+// t: those of every model, then its own. The caller must hold r.mu.
+func (r *Registry) transformersFor(t reflect.Type) []ResultTransformer {
+	n := len(r.transforms[nil]) + len(r.transforms[t])
+	if n == 0 {
+		return nil
+	}
+	return append(append(make([]ResultTransformer, 0, n), r.transforms[nil]...), r.transforms[t]...)
+}
+
 // GetScanner returns a scanner factory for the given type, if registered
 func (r *Registry) GetScanner(t reflect.Type) (func() sql.Scanner, bool) {
 	r.mu.RLock()
@@ -1,12 +1,15 @@
 package sqld
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
 	"sync"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestModel is a simple model for testing
@@ -117,6 +120,19 @@ func TestRegistry_RegisterScanner(t *testing.T) {
 	assert.IsType(t, &CustomScanner{}, scanner)
 }
 
+func TestRegisterScannerFor(t *testing.T) {
+	RegisterScannerFor[CustomInt](func() sql.Scanner { return &CustomScanner{} })
+	defer func() {
+		defaultRegistry.mu.Lock()
+		delete(defaultRegistry.scanners, reflect.TypeOf(CustomInt(0)))
+		defaultRegistry.mu.Unlock()
+	}()
+
+	factory, ok := defaultRegistry.GetScanner(reflect.TypeOf(CustomInt(0)))
+	require.True(t, ok)
+	assert.IsType(t, &CustomScanner{}, factory())
+}
+
 func TestRegistry_GetModelMetadata_NotFound(t *testing.T) {
 	registry := NewRegistry()
 	model := TestModel{}
@@ -322,3 +338,209 @@ func TestRegistry_Concurrency2(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// ScopedModel is only registered with instance registries.
+type ScopedModel struct {
+	ID   int64  `json:"id" db:"id"`
+	Name string `json:"name" db:"full_name"`
+}
+
+func (ScopedModel) TableName() string {
+	return "scoped_models"
+}
+
+func TestWithRegistry(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(ScopedModel{}))
+	registry.SetDialect(MySQL)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id, full_name FROM scoped_models WHERE id = \\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "full_name"}).AddRow(int64(1), "alice"))
+	mock.ExpectExec("DELETE FROM scoped_models WHERE id = \\?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := WithRegistry(context.Background(), registry)
+	resp, err := Execute[ScopedModel](ctx, db, QueryRequest{
+		Select: []string{"id", "name"},
+		Where:  map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "name": "alice"}}, resp.Data)
+
+	deleted, err := Delete[ScopedModel](ctx, db, DeleteRequest{Where: map[string]interface{}{"id": 1}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted.RowsAffected)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// The default registry is unaffected
+	_, err = Execute[ScopedModel](context.Background(), db, QueryRequest{Select: []string{"id"}})
+	assert.ErrorContains(t, err, "model ScopedModel not registered")
+	assert.Equal(t, Postgres, defaultRegistry.Dialect())
+}
+
+func TestConcurrentRegisterAndExecute(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(ScopedModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	const workers = 20
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+	for i := 0; i < workers; i++ {
+		mock.ExpectQuery("SELECT id, full_name FROM scoped_models").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "full_name"}).AddRow(int64(1), "alice"))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers * 3)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			resp, err := Execute[ScopedModel](ctx, db, QueryRequest{Select: []string{"id", "name"}})
+			assert.NoError(t, err)
+			assert.Len(t, resp.Data, 1)
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, registry.Register(ScopedModel{}))
+			assert.NoError(t, registry.Register(TestModel{}))
+		}()
+		go func(i int) {
+			defer wg.Done()
+			registry.RegisterScanner(reflect.TypeOf(CustomInt(0)), func() sql.Scanner { return &CustomScanner{} })
+			registry.RegisterEnum("status", i)
+			registry.SetDialect(Postgres)
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// reloadedTable is the table of ReloadedModel, changed by tests.
+var reloadedTable = "reports_v1"
+
+// ReloadedModel reads its table name from configuration.
+type ReloadedModel struct {
+	ID int64 `json:"id"`
+}
+
+func (ReloadedModel) TableName() string {
+	return reloadedTable
+}
+
+func TestRegistry_Replace(t *testing.T) {
+	registry := NewRegistry()
+	assert.ErrorContains(t, registry.Replace(ReloadedModel{}), "model ReloadedModel not registered")
+
+	require.NoError(t, registry.Register(ReloadedModel{}))
+	require.NoError(t, registry.RegisterHooks(ReloadedModel{}, Hooks{}))
+	t.Cleanup(func() { reloadedTable = "reports_v1" })
+	reloadedTable = "reports_v2"
+	require.NoError(t, registry.Replace(ReloadedModel{}))
+
+	metadata, err := registry.GetModelMetadata(ReloadedModel{})
+	require.NoError(t, err)
+	assert.Equal(t, "reports_v2", metadata.TableName)
+	assert.NotNil(t, metadata.Hooks)
+}
+
+func TestRegistry_Unregister(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	assert.ErrorContains(t, registry.Unregister(ScopedModel{}), "model ScopedModel not registered")
+
+	// Departments are the target of a relation of employees
+	assert.ErrorContains(t, registry.Unregister(RelDepartment{}),
+		"model RelDepartment is the target of relation department of model RelEmployee")
+
+	require.NoError(t, registry.Register(ScopedModel{}))
+	require.NoError(t, registry.RegisterHooks(ScopedModel{}, Hooks{}))
+	require.NoError(t, registry.Unregister(ScopedModel{}))
+	_, err := registry.GetModelMetadata(ScopedModel{})
+	assert.ErrorContains(t, err, "model ScopedModel not registered")
+
+	// Registering again starts afresh
+	require.NoError(t, registry.Register(ScopedModel{}))
+	metadata, err := registry.GetModelMetadata(ScopedModel{})
+	require.NoError(t, err)
+	assert.Nil(t, metadata.Hooks)
+}
+
+// AuditFields is a column set shared by several models.
+type AuditFields struct {
+	CreatedBy string `json:"created_by"`
+	UpdatedBy string `json:"updated_by"`
+	Note      string `json:"note" db:"audit_note"`
+}
+
+// AuditedModel reuses AuditFields and shadows one of its fields.
+type AuditedModel struct {
+	ID int64 `json:"id"`
+	AuditFields
+	Note string `json:"note"`
+}
+
+func (AuditedModel) TableName() string {
+	return "audited_models"
+}
+
+func TestRegistry_EmbeddedStruct(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(AuditedModel{}))
+	metadata, err := registry.GetModelMetadata(AuditedModel{})
+	require.NoError(t, err)
+
+	assert.Len(t, metadata.Fields, 4)
+	assert.Equal(t, "created_by", metadata.Fields["created_by"].Name)
+	assert.Equal(t, []int{1, 1}, metadata.Fields["updated_by"].index)
+	assert.Equal(t, "note", metadata.Fields["note"].Name, "outer field wins")
+
+	metaMap, err := BuildMetadataMap[AuditedModel]()
+	require.NoError(t, err)
+	// Raw results only map fields with a db tag, and the outer note hides
+	// audit_note
+	assert.NotContains(t, metaMap, "updated_by")
+	assert.NotContains(t, metaMap, "audit_note")
+}
+
+// SharedModel carries the tags of other libraries.
+type SharedModel struct {
+	ID       int64  `json:"id,omitempty"`
+	Email    string `json:"email,omitempty" db:"email,readonly"`
+	Token    string `json:"token" db:"-"`
+	Internal string `json:"-" db:"internal"`
+	Nick     string `json:",omitempty" db:"nick_name,required"`
+}
+
+func (SharedModel) TableName() string {
+	return "shared_models"
+}
+
+func TestRegistry_TagOptions(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(SharedModel{}))
+	metadata, err := registry.GetModelMetadata(SharedModel{})
+	require.NoError(t, err)
+
+	assert.Len(t, metadata.Fields, 3)
+	assert.Equal(t, "id", metadata.Fields["id"].Name)
+	assert.Equal(t, "email", metadata.Fields["email"].Name)
+	assert.True(t, metadata.Fields["email"].ReadOnly)
+	assert.Equal(t, "nick_name", metadata.Fields["Nick"].Name)
+	assert.True(t, metadata.Fields["Nick"].Required)
+	assert.NotContains(t, metadata.Fields, "token")
+
+	metaMap, err := BuildMetadataMap[SharedModel]()
+	require.NoError(t, err)
+	assert.Equal(t, "email", metaMap["email"].jsonKey)
+	assert.NotContains(t, metaMap, "token")
+	assert.NotContains(t, metaMap, "internal")
+}
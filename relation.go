@@ -0,0 +1,274 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// RelationKind describes how two models are related.
+type RelationKind string
+
+const (
+	// BelongsTo: the source model holds a foreign key to the target model
+	// (employee.department_id -> departments.id).
+	BelongsTo RelationKind = "belongs_to"
+	// HasMany: the target model holds a foreign key to the source model
+	// (departments.id <- employees.department_id).
+	HasMany RelationKind = "has_many"
+	// ManyToMany: source and target are linked through a join table
+	// (employees.id <- employee_projects -> projects.id).
+	ManyToMany RelationKind = "many_to_many"
+)
+
+// Relation declares a relation from one registered model to another.
+// Fields are JSON field names of the respective models.
+type Relation struct {
+	Kind RelationKind
+
+	// LocalField is the field on the source model used by the join.
+	// For BelongsTo it is the foreign key; for HasMany and ManyToMany it is
+	// usually the primary key.
+	LocalField string
+
+	// ForeignField is the field on the target model used by the join.
+	// For BelongsTo and ManyToMany it is usually the primary key; for HasMany
+	// it is the foreign key pointing back at the source.
+	ForeignField string
+
+	// Through describes the join table of a ManyToMany relation.
+	Through *ThroughTable
+
+	// Flag names a feature flag gating the relation, checked with the
+	// registry's FlagProvider at execution time. Queries using the relation
+	// while the flag is disabled fail. Optional - empty means always enabled.
+	Flag string
+
+	// target holds the metadata of the related model. It is filled in when
+	// the source metadata is retrieved from the registry.
+	target ModelMetadata
+
+	// joinType is set for joins requested explicitly through
+	// QueryRequest.Joins. Such joins are always applied, using this type.
+	joinType string
+
+	// lateral is set for relations joined through QueryRequest.Lateral.
+	// Such joins are always applied, as a LEFT JOIN LATERAL.
+	lateral *LateralJoin
+}
+
+// ThroughTable is the join table of a many-to-many relation.
+type ThroughTable struct {
+	Table         string // Join table name
+	LocalColumn   string // Column referencing the source model's LocalField
+	ForeignColumn string // Column referencing the target model's ForeignField
+}
+
+// RegisterRelation declares a named relation from model From to model To in the
+// default registry. Once declared, queries on From can reference fields of To
+// as "<name>.<field>" in Select, Where and OrderBy, and the builder generates
+// the JOINs.
+func RegisterRelation[From, To Model](name string, rel Relation) error {
+	var from From
+	var to To
+	return defaultRegistry.RegisterRelation(from, to, name, rel)
+}
+
+// RegisterRelation declares a named relation between two registered models.
+func (r *Registry) RegisterRelation(from, to Model, name string, rel Relation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fromType := reflect.TypeOf(from)
+	toType := reflect.TypeOf(to)
+	fromMeta, ok := r.models[fromType]
+	if !ok {
+		return &ModelNotRegisteredError{Model: fromType.Name()}
+	}
+	toMeta, ok := r.models[toType]
+	if !ok {
+		return &ModelNotRegisteredError{Model: toType.Name()}
+	}
+
+	if !identRegex.MatchString(name) || strings.Contains(name, ".") {
+		return fmt.Errorf("invalid relation name: %q", name)
+	}
+	if name == fromMeta.TableName {
+		return fmt.Errorf("relation %s clashes with the table name of model %s", name, fromType.Name())
+	}
+	if _, exists := fromMeta.Fields[name]; exists {
+		return fmt.Errorf("relation %s clashes with a field of model %s", name, fromType.Name())
+	}
+	if _, ok := fromMeta.Fields[rel.LocalField]; !ok {
+		return fmt.Errorf("invalid local field in relation %s: %s", name, rel.LocalField)
+	}
+	if _, ok := toMeta.Fields[rel.ForeignField]; !ok {
+		return fmt.Errorf("invalid foreign field in relation %s: %s", name, rel.ForeignField)
+	}
+
+	switch rel.Kind {
+	case BelongsTo, HasMany:
+		if rel.Through != nil {
+			return fmt.Errorf("relation %s: through table is only valid for many-to-many relations", name)
+		}
+	case ManyToMany:
+		if rel.Through == nil {
+			return fmt.Errorf("relation %s: many-to-many relations require a through table", name)
+		}
+		for _, ident := range []string{rel.Through.Table, rel.Through.LocalColumn, rel.Through.ForeignColumn} {
+			if !identRegex.MatchString(ident) {
+				return fmt.Errorf("relation %s: invalid identifier in through table: %q", name, ident)
+			}
+		}
+	default:
+		return fmt.Errorf("relation %s: invalid kind %q", name, rel.Kind)
+	}
+
+	if r.relations[fromType] == nil {
+		r.relations[fromType] = make(map[string]relationEntry)
+	}
+	r.relations[fromType][name] = relationEntry{relation: rel, target: toType}
+	return nil
+}
+
+// relationEntry is a relation as stored in the registry.
+type relationEntry struct {
+	relation Relation
+	target   reflect.Type
+}
+
+// relationsFor returns the relations of a model with their target metadata
+// resolved. The caller must hold r.mu.
+func (r *Registry) relationsFor(t reflect.Type) map[string]Relation {
+	entries := r.relations[t]
+	if len(entries) == 0 {
+		return nil
+	}
+	relations := make(map[string]Relation, len(entries))
+	for name, entry := range entries {
+		rel := entry.relation
+		rel.target = r.models[entry.target]
+		rel.target.SoftDelete = r.softDeleteFor(entry.target)
+		rel.target.Transformers = r.transformersFor(entry.target)
+		relations[name] = rel
+	}
+	return relations
+}
+
+// fieldRef is a field referenced by a query, either on the model itself or,
+// for "<relation>.<field>" names, on a related model.
+type fieldRef struct {
+	Field    Field
+	Relation string // Empty for fields of the model itself
+}
+
+// lookupField resolves a field name used in a query.
+func (m ModelMetadata) lookupField(name string) (fieldRef, bool) {
+	if field, ok := m.Fields[name]; ok {
+		return fieldRef{Field: field}, true
+	}
+	relName, fieldName, ok := strings.Cut(name, ".")
+	if !ok {
+		return fieldRef{}, false
+	}
+	rel, ok := m.Relations[relName]
+	if !ok {
+		return fieldRef{}, false
+	}
+	field, ok := rel.target.Fields[fieldName]
+	if !ok {
+		return fieldRef{}, false
+	}
+	return fieldRef{Field: field, Relation: relName}, true
+}
+
+// column returns the SQL column expression for the reference. Columns are
+// qualified with the table (or relation alias) when the query joins other
+// tables, so that identically named columns stay unambiguous.
+func (f fieldRef) column(metadata ModelMetadata, qualify bool) string {
+	if f.Relation != "" {
+		return f.Relation + "." + f.Field.Name
+	}
+	if qualify {
+		return metadata.TableName + "." + f.Field.Name
+	}
+	return f.Field.Name
+}
+
+// referencedRelations returns the relations referenced by the query, in the
+// order they first appear.
+func referencedRelations(metadata ModelMetadata, req QueryRequest) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if ref, ok := metadata.lookupField(name); ok && ref.Relation != "" && !seen[ref.Relation] {
+			seen[ref.Relation] = true
+			names = append(names, ref.Relation)
+		}
+	}
+	for _, join := range req.Joins {
+		if !seen[join.alias()] {
+			seen[join.alias()] = true
+			names = append(names, join.alias())
+		}
+	}
+	for _, lateral := range req.Lateral {
+		if !seen[lateral.Relation] {
+			seen[lateral.Relation] = true
+			names = append(names, lateral.Relation)
+		}
+	}
+	for _, name := range req.Select {
+		add(name)
+	}
+	for name := range req.Where {
+		add(name)
+	}
+	for _, orderBy := range req.OrderBy {
+		add(orderBy.Field)
+	}
+	for _, summary := range req.Summary {
+		add(summary.Field)
+	}
+	return names
+}
+
+// applyRelationJoins adds a LEFT JOIN for every relation in names. Related
+// tables are aliased with the relation name. Soft-deleted related rows are
+// left out of the joins unless includeDeleted is set.
+func applyRelationJoins(query squirrel.SelectBuilder, metadata ModelMetadata, names []string, includeDeleted bool) (squirrel.SelectBuilder, error) {
+	for _, name := range names {
+		rel := metadata.Relations[name]
+		local := metadata.TableName + "." + metadata.Fields[rel.LocalField].Name
+		foreign := name + "." + rel.target.Fields[rel.ForeignField].Name
+
+		dialect := metadata.dialect()
+		var extra string
+		if !includeDeleted {
+			if cond := notDeleted(rel.target, name); cond != "" {
+				extra = " AND " + cond
+			}
+		}
+
+		switch {
+		case rel.lateral != nil:
+			var err error
+			query, err = applyLateralJoin(query, metadata, name, rel, includeDeleted)
+			if err != nil {
+				return squirrel.SelectBuilder{}, err
+			}
+		case rel.joinType != "":
+			query = query.JoinClause(fmt.Sprintf("%s %s ON %s = %s%s", rel.joinType, tableAlias(dialect, rel.target.TableName, name), foreign, local, extra))
+		case rel.Kind == BelongsTo || rel.Kind == HasMany:
+			query = query.LeftJoin(fmt.Sprintf("%s ON %s = %s%s", tableAlias(dialect, rel.target.TableName, name), foreign, local, extra))
+		case rel.Kind == ManyToMany:
+			through := name + "_through"
+			query = query.
+				LeftJoin(fmt.Sprintf("%s ON %s.%s = %s", tableAlias(dialect, rel.Through.Table, through), through, rel.Through.LocalColumn, local)).
+				LeftJoin(fmt.Sprintf("%s ON %s = %s.%s%s", tableAlias(dialect, rel.target.TableName, name), foreign, through, rel.Through.ForeignColumn, extra))
+		}
+	}
+	return query, nil
+}
@@ -0,0 +1,193 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type RelEmployee struct {
+	ID           int64  `json:"id" db:"id"`
+	Name         string `json:"name" db:"name"`
+	DepartmentID int64  `json:"department_id" db:"department_id"`
+}
+
+func (RelEmployee) TableName() string {
+	return "employees"
+}
+
+type RelDepartment struct {
+	ID   int64  `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+}
+
+func (RelDepartment) TableName() string {
+	return "departments"
+}
+
+type RelProject struct {
+	ID    int64  `json:"id" db:"id"`
+	Title string `json:"title" db:"title"`
+}
+
+func (RelProject) TableName() string {
+	return "projects"
+}
+
+func registerRelationModels(t *testing.T, registry *Registry) {
+	t.Helper()
+	require.NoError(t, registry.Register(RelEmployee{}))
+	require.NoError(t, registry.Register(RelDepartment{}))
+	require.NoError(t, registry.Register(RelProject{}))
+
+	require.NoError(t, registry.RegisterRelation(RelEmployee{}, RelDepartment{}, "department", Relation{
+		Kind:         BelongsTo,
+		LocalField:   "department_id",
+		ForeignField: "id",
+	}))
+	require.NoError(t, registry.RegisterRelation(RelDepartment{}, RelEmployee{}, "employees", Relation{
+		Kind:         HasMany,
+		LocalField:   "id",
+		ForeignField: "department_id",
+	}))
+	require.NoError(t, registry.RegisterRelation(RelEmployee{}, RelProject{}, "projects", Relation{
+		Kind:         ManyToMany,
+		LocalField:   "id",
+		ForeignField: "id",
+		Through:      &ThroughTable{Table: "employee_projects", LocalColumn: "employee_id", ForeignColumn: "project_id"},
+	}))
+}
+
+func TestRegistry_RegisterRelation_Invalid(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+
+	tests := []struct {
+		name    string
+		relName string
+		rel     Relation
+	}{
+		{name: "unknown local field", relName: "dept", rel: Relation{Kind: BelongsTo, LocalField: "dept_id", ForeignField: "id"}},
+		{name: "unknown foreign field", relName: "dept", rel: Relation{Kind: BelongsTo, LocalField: "department_id", ForeignField: "code"}},
+		{name: "clashes with field", relName: "name", rel: Relation{Kind: BelongsTo, LocalField: "department_id", ForeignField: "id"}},
+		{name: "invalid name", relName: "a.b", rel: Relation{Kind: BelongsTo, LocalField: "department_id", ForeignField: "id"}},
+		{name: "many to many without through", relName: "dept", rel: Relation{Kind: ManyToMany, LocalField: "id", ForeignField: "id"}},
+		{name: "invalid kind", relName: "dept", rel: Relation{Kind: "has_one", LocalField: "department_id", ForeignField: "id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.RegisterRelation(RelEmployee{}, RelDepartment{}, tt.relName, tt.rel)
+			assert.Error(t, err)
+		})
+	}
+
+	err := registry.RegisterRelation(RelEmployee{}, BuilderTestModel{}, "other", Relation{Kind: BelongsTo, LocalField: "id", ForeignField: "id"})
+	assert.Error(t, err, "target model must be registered")
+}
+
+func TestBuildSelect_Relations(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+
+	employees, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+	departments, err := registry.GetModelMetadata(RelDepartment{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		metadata ModelMetadata
+		req      QueryRequest
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "no relation keeps columns unqualified",
+			metadata: employees,
+			req:      QueryRequest{Select: []string{"id", "name"}},
+			wantSQL:  `SELECT id, name FROM employees`,
+		},
+		{
+			name:     "belongs to in select, where and order by",
+			metadata: employees,
+			req: QueryRequest{
+				Select:  []string{"id", "name", "department.name"},
+				Where:   map[string]interface{}{"department.name": "eng"},
+				OrderBy: []OrderByClause{{Field: "name"}},
+			},
+			wantSQL: `SELECT employees.id, employees.name, department.name AS "department.name" FROM employees ` +
+				`LEFT JOIN departments AS department ON department.id = employees.department_id ` +
+				`WHERE department.name = $1 ORDER BY employees.name ASC`,
+			wantArgs: []interface{}{"eng"},
+		},
+		{
+			name:     "has many",
+			metadata: departments,
+			req:      QueryRequest{Select: []string{"name", "employees.name"}},
+			wantSQL: `SELECT departments.name, employees.name AS "employees.name" FROM departments ` +
+				`LEFT JOIN employees AS employees ON employees.department_id = departments.id`,
+		},
+		{
+			name:     "many to many",
+			metadata: employees,
+			req:      QueryRequest{Select: []string{"name", "projects.title"}},
+			wantSQL: `SELECT employees.name, projects.title AS "projects.title" FROM employees ` +
+				`LEFT JOIN employee_projects AS projects_through ON projects_through.employee_id = employees.id ` +
+				`LEFT JOIN projects AS projects ON projects.id = projects_through.project_id`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := buildSelect(tt.metadata, tt.req)
+			require.NoError(t, err)
+			sql, args, err := query.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, sql)
+			if tt.wantArgs != nil {
+				assert.Equal(t, tt.wantArgs, args)
+			}
+		})
+	}
+
+	_, err = buildSelect(employees, QueryRequest{Select: []string{"department.budget"}})
+	assert.Error(t, err)
+	_, err = buildSelect(employees, QueryRequest{Select: []string{"team.name"}})
+	assert.Error(t, err)
+
+	count, err := buildCount(employees, QueryRequest{
+		Select: []string{"name"},
+		Where:  map[string]interface{}{"department.name": "eng"},
+	})
+	require.NoError(t, err)
+	sql, _, err := count.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(*) FROM employees LEFT JOIN departments AS department ON department.id = employees.department_id WHERE department.name = $1`, sql)
+}
+
+func TestExecute_RelationFields(t *testing.T) {
+	require.NoError(t, Register(RelEmployee{}))
+	require.NoError(t, Register(RelDepartment{}))
+	require.NoError(t, RegisterRelation[RelEmployee, RelDepartment]("department", Relation{
+		Kind:         BelongsTo,
+		LocalField:   "department_id",
+		ForeignField: "id",
+	}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT employees.name, department.name AS "department.name" FROM employees LEFT JOIN departments`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "department.name"}).AddRow("alice", "eng"))
+
+	resp, err := Execute[RelEmployee](context.Background(), db, QueryRequest{
+		Select: []string{"name", "department.name"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"name": "alice", "department.name": "eng"}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
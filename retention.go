@@ -0,0 +1,169 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// purgeConfig holds the settings of PurgeOlderThan.
+type purgeConfig struct {
+	pause      time.Duration
+	maxBatches int
+	retries    int
+	hard       bool
+}
+
+// PurgeOption configures PurgeOlderThan.
+type PurgeOption func(*purgeConfig)
+
+// WithPurgePause sets the pause between two batches, giving replication and
+// vacuum a chance to keep up. Defaults to 100ms.
+func WithPurgePause(d time.Duration) PurgeOption {
+	return func(c *purgeConfig) {
+		c.pause = d
+	}
+}
+
+// WithMaxBatches stops the purge after n batches, even if more rows match.
+// Useful to bound the work done by a single retention job run.
+// Defaults to 0, meaning no limit.
+func WithMaxBatches(n int) PurgeOption {
+	return func(c *purgeConfig) {
+		c.maxBatches = n
+	}
+}
+
+// WithPurgeRetries sets how many times a failed batch is retried, with the
+// pause doubling after every failure. Defaults to 3.
+func WithPurgeRetries(n int) PurgeOption {
+	return func(c *purgeConfig) {
+		c.retries = n
+	}
+}
+
+// WithHardPurge deletes the rows of models registered with
+// RegisterSoftDelete for real instead of soft-deleting them.
+func WithHardPurge() PurgeOption {
+	return func(c *purgeConfig) {
+		c.hard = true
+	}
+}
+
+// PurgeOlderThan deletes rows of model T whose time field is older than age,
+// in batches of at most batchSize rows. It pauses between batches and stops
+// when a batch deletes fewer than batchSize rows, when the batch limit is
+// reached or when ctx is cancelled. It returns the number of deleted rows,
+// which is also valid when an error is returned.
+//
+// Like Delete, rows of models registered with RegisterSoftDelete are
+// soft-deleted, recording the actor of ctx when the model has a deleted-by
+// field, unless WithHardPurge is set; rows already soft-deleted are skipped.
+//
+// field is the JSON name of a time.Time (or *time.Time) field. Batches are
+// selected by ctid, so this helper is specific to Postgres and fails on
+// dialects without it.
+func PurgeOlderThan[T Model](ctx context.Context, db interface{}, field string, age time.Duration, batchSize int, opts ...PurgeOption) (int64, error) {
+	cfg := purgeConfig{
+		pause:   100 * time.Millisecond,
+		retries: 3,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be positive")
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("age must be positive")
+	}
+
+	var model T
+	metadata, err := registryFromContext(ctx).GetModelMetadata(model)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := checkWritable(metadata); err != nil {
+		return 0, err
+	}
+	f, ok := metadata.Fields[field]
+	if !ok {
+		return 0, &UnknownFieldError{Field: field, In: "purge"}
+	}
+	if t := f.Type; t != reflect.TypeOf(time.Time{}) && t != reflect.TypeOf(&time.Time{}) {
+		return 0, fmt.Errorf("purge field %s must be a time.Time, got %s", field, t)
+	}
+
+	// Batches are selected by the physical row id of Postgres
+	if dialect := metadata.dialect(); dialect != Postgres {
+		return 0, fmt.Errorf("purge selects batches by ctid, which the %s dialect lacks", dialect.Name())
+	}
+	args := []interface{}{time.Now().Add(-age)}
+	query := fmt.Sprintf("DELETE FROM %[1]s WHERE ctid IN (SELECT ctid FROM %[1]s WHERE %[2]s < $1 LIMIT %[3]d)",
+		metadata.TableName, f.Name, batchSize)
+	if sd := metadata.SoftDelete; sd != nil && !cfg.hard {
+		set := metadata.Fields[sd.DeletedAtField].Name + " = CURRENT_TIMESTAMP"
+		if sd.DeletedByField != "" {
+			by, _ := metadata.owner().actorFromContext(ctx)
+			set += ", " + metadata.Fields[sd.DeletedByField].Name + " = $2"
+			args = append(args, by)
+		}
+		query = fmt.Sprintf("UPDATE %[1]s SET %[4]s WHERE ctid IN (SELECT ctid FROM %[1]s WHERE %[2]s < $1 AND %[5]s LIMIT %[3]d)",
+			metadata.TableName, f.Name, batchSize, set, notDeleted(metadata, ""))
+	}
+
+	var total int64
+	for batch := 0; cfg.maxBatches <= 0 || batch < cfg.maxBatches; batch++ {
+		if batch > 0 {
+			if err := sleepContext(ctx, cfg.pause); err != nil {
+				return total, err
+			}
+		}
+
+		deleted, err := purgeBatch(ctx, db, query, args, cfg)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+		if deleted < int64(batchSize) {
+			break
+		}
+	}
+	return total, nil
+}
+
+// purgeBatch deletes one batch, retrying transient failures with exponential
+// backoff. Other errors are returned at once.
+func purgeBatch(ctx context.Context, db interface{}, query string, args []interface{}, cfg purgeConfig) (int64, error) {
+	backoff := cfg.pause
+	for attempt := 0; ; attempt++ {
+		deleted, err := execAffected(ctx, db, query, args...)
+		if err == nil {
+			return deleted, nil
+		}
+		if attempt >= cfg.retries || !isTransientError(err) || ctx.Err() != nil {
+			return 0, fmt.Errorf("failed to purge batch: %w", err)
+		}
+		if err := sleepContext(ctx, backoff); err != nil {
+			return 0, err
+		}
+		backoff *= 2
+	}
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
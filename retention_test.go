@@ -0,0 +1,145 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const purgeQuery = `DELETE FROM test_models WHERE ctid IN \(SELECT ctid FROM test_models WHERE created_at < \$1 LIMIT 2\)`
+
+func TestPurgeOlderThan(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(purgeQuery).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(purgeQuery).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(purgeQuery).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deleted, err := PurgeOlderThan[BuilderTestModel](context.Background(), db, "created_at", 24*time.Hour, 2,
+		WithPurgePause(time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeOlderThan_MaxBatches(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(purgeQuery).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	deleted, err := PurgeOlderThan[BuilderTestModel](context.Background(), db, "created_at", time.Hour, 2,
+		WithPurgePause(0), WithMaxBatches(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeOlderThan_RetriesFailedBatch(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(purgeQuery).WillReturnError(&pgconn.PgError{Code: "40P01", Message: "deadlock detected"})
+	mock.ExpectExec(purgeQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(purgeQuery).WillReturnError(&pgconn.PgError{Code: "08006", Message: "connection failure"})
+	mock.ExpectExec(purgeQuery).WillReturnError(&pgconn.PgError{Code: "08006", Message: "connection failure"})
+
+	deleted, err := PurgeOlderThan[BuilderTestModel](context.Background(), db, "created_at", time.Hour, 2,
+		WithPurgePause(time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	deleted, err = PurgeOlderThan[BuilderTestModel](context.Background(), db, "created_at", time.Hour, 2,
+		WithPurgePause(time.Millisecond), WithPurgeRetries(1))
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), deleted)
+
+	// Other errors aren't retried
+	mock.ExpectExec(purgeQuery).WillReturnError(errors.New(`relation "test_models" does not exist`))
+	deleted, err = PurgeOlderThan[BuilderTestModel](context.Background(), db, "created_at", time.Hour, 2,
+		WithPurgePause(time.Millisecond))
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type RetainedEvent struct {
+	ID        int64      `json:"id" db:"id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at" db:"deleted_at"`
+	DeletedBy *string    `json:"deleted_by" db:"deleted_by"`
+}
+
+func (RetainedEvent) TableName() string {
+	return "events"
+}
+
+func TestPurgeOlderThan_SoftDelete(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(RetainedEvent{}))
+	require.NoError(t, registry.RegisterSoftDelete(RetainedEvent{}, SoftDelete{DeletedAtField: "deleted_at", DeletedByField: "deleted_by"}))
+	registry.SetActorExtractor(func(context.Context) (interface{}, bool) { return "retention", true })
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE events SET deleted_at = CURRENT_TIMESTAMP, deleted_by = \$2 WHERE ctid IN \(SELECT ctid FROM events WHERE created_at < \$1 AND deleted_at IS NULL LIMIT 2\)`).
+		WithArgs(sqlmock.AnyArg(), "retention").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM events WHERE ctid IN \(SELECT ctid FROM events WHERE created_at < \$1 LIMIT 2\)`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deleted, err := PurgeOlderThan[RetainedEvent](ctx, db, "created_at", time.Hour, 2, WithPurgePause(0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	deleted, err = PurgeOlderThan[RetainedEvent](ctx, db, "created_at", time.Hour, 2, WithPurgePause(0), WithHardPurge())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeOlderThan_InvalidArguments(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = PurgeOlderThan[BuilderTestModel](ctx, db, "created_at", time.Hour, 0)
+	assert.Error(t, err)
+	_, err = PurgeOlderThan[BuilderTestModel](ctx, db, "created_at", 0, 10)
+	assert.Error(t, err)
+	_, err = PurgeOlderThan[BuilderTestModel](ctx, db, "updated_at", time.Hour, 10)
+	assert.Error(t, err)
+	_, err = PurgeOlderThan[BuilderTestModel](ctx, db, "name", time.Hour, 10)
+	assert.Error(t, err)
+
+	// Batches are selected by ctid, which only Postgres has
+	registry := NewRegistry()
+	registry.SetDialect(MySQL)
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	_, err = PurgeOlderThan[BuilderTestModel](WithRegistry(ctx, registry), db, "created_at", time.Hour, 10)
+	assert.EqualError(t, err, "purge selects batches by ctid, which the mysql dialect lacks")
+	// None of the invalid purges ran a query
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,28 +2,26 @@ package sqld
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
-
-	"github.com/georgysavva/scany/v2/pgxscan"
-	"github.com/georgysavva/scany/v2/sqlscan"
-	"github.com/jackc/pgx/v5"
 )
 
 type fieldInfo struct {
 	jsonKey string
 	goType  reflect.Type
 	fieldName string
+	rawJSON   bool // Tagged sqld:"rawjson"
 }
 
 // BuildMetadataMap uses reflection on the model struct to map db tags to fieldInfo.
-// It extracts the 'db' and 'json' tags from the struct fields and creates a map
-// where the key is the 'db' tag and the value is a fieldInfo struct containing the
-// 'json' tag and the Go type of the field. This map is used later in the ExecuteRaw
-// function to map database column names to JSON keys in the result.
+// It extracts the 'db' and 'json' tags from the struct fields, including those
+// promoted from embedded structs and excluding those tagged "-", and creates a map where the key is the 'db' tag
+// (or the SnakeCase field name without one) and the value is a fieldInfo struct
+// containing the 'json' tag and the Go type of the field. This map is used later
+// in the ExecuteRaw function to map database column names to JSON keys in the result.
 func BuildMetadataMap[T any]() (map[string]fieldInfo, error) {
 	t := reflect.TypeOf((*T)(nil)).Elem()
 	if t.Kind() != reflect.Struct {
@@ -31,16 +29,17 @@ func BuildMetadataMap[T any]() (map[string]fieldInfo, error) {
 	}
 
 	metaMap := make(map[string]fieldInfo)
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		dbTag := field.Tag.Get("db")
-		jsonTag := field.Tag.Get("json")
-		if dbTag != "" && jsonTag != "" {
-			metaMap[dbTag] = fieldInfo{
-				jsonKey: jsonTag,
-				goType:  field.Type,
-				fieldName: field.Name,
-			}
+	for _, field := range modelFields(t) {
+		// Only fields with both tags are mapped
+		dbTag := field.column
+		if dbTag == "" {
+			continue
+		}
+		metaMap[dbTag] = fieldInfo{
+			jsonKey:   field.jsonName,
+			goType:    field.Type,
+			fieldName: field.Name,
+			rawJSON:   slices.Contains(field.options, "rawjson"),
 		}
 	}
 	return metaMap, nil
@@ -51,9 +50,13 @@ func BuildMetadataMap[T any]() (map[string]fieldInfo, error) {
 // and false otherwise. It also handles the case where the expected type is an
 // empty interface, in which case any type is considered compatible.
 func isTypeCompatible(valType, expectedType reflect.Type) bool {
-	if valType == nil || expectedType == nil {
+	if expectedType == nil {
 		return false
 	}
+	if valType == nil {
+		// nil binds NULL to nullable parameters such as *string or sql.NullString
+		return isNullableType(expectedType)
+	}
 
 	// If the expected type is an empty interface, accept any type.
 	if expectedType.Kind() == reflect.Interface && expectedType.NumMethod() == 0 {
@@ -61,7 +64,16 @@ func isTypeCompatible(valType, expectedType reflect.Type) bool {
 		return true
 	}
 
-	return valType == expectedType
+	if valType == expectedType {
+		return true
+	}
+	// Nullable parameters also accept the value they hold, e.g. a string for
+	// a *string or sql.NullString parameter
+	if expectedType.Kind() == reflect.Ptr && valType == expectedType.Elem() {
+		return true
+	}
+	underlying, ok := nullValueType(expectedType)
+	return ok && valType == underlying
 }
 
 func typeNameOrNil(t reflect.Type) string {
@@ -99,6 +111,31 @@ func ReplaceNamedWithDollarPlaceholders(query string, queryParams []string) (str
 	return query, nil
 }
 
+// bindNamedPlaceholders replaces {{param_name}} with the bind parameters of
+// dialect and returns the arguments for them, given args holding the value
+// of each of queryParams. Numbered parameters such as $1 are reused when a
+// parameter appears several times; positional ones such as ? repeat it.
+func bindNamedPlaceholders(dialect Dialect, query string, queryParams []string, args []interface{}) (string, []interface{}) {
+	if dialect.BindVar(1) != dialect.BindVar(2) {
+		for i, p := range queryParams {
+			query = strings.ReplaceAll(query, fmt.Sprintf("{{%s}}", p), dialect.BindVar(i+1))
+		}
+		return query, args
+	}
+
+	index := make(map[string]int, len(queryParams))
+	for i, p := range queryParams {
+		index[p] = i
+	}
+	var bound []interface{}
+	query = namedParamRegex.ReplaceAllStringFunc(query, func(match string) string {
+		name := namedParamRegex.FindStringSubmatch(match)[1]
+		bound = append(bound, args[index[name]])
+		return dialect.BindVar(len(bound))
+	})
+	return query, bound
+}
+
 // ValidateMapParamsAgainstStructNamed ensures the params map matches the expected types from P.
 // It uses the isTypeCompatible function to check if the type of each parameter in the map
 // matches the expected type from P. This is primarily to prevent runtime errors due to type mismatches.
@@ -106,7 +143,19 @@ func ValidateMapParamsAgainstStructNamed[P any](
 	paramMap map[string]interface{},
 	queryParams []string,
 ) ([]interface{}, error) {
-	t := reflect.TypeOf((*P)(nil)).Elem()
+	typeByName, err := paramTypes(reflect.TypeOf((*P)(nil)).Elem(), false)
+	if err != nil {
+		return nil, err
+	}
+	return bindParams(typeByName, paramMap, queryParams)
+}
+
+// paramTypes returns the types of the parameters declared by the struct t,
+// keyed by name. Parameters are the fields with a db tag, which must also
+// have a json tag. With structParams, they are named like in
+// ParamsFromStruct instead, so that structs without db tags (such as
+// sqlc-generated Params structs) can be used with ExecuteRawParams.
+func paramTypes(t reflect.Type, structParams bool) (map[string]reflect.Type, error) {
 	if t.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("model must be a struct")
 	}
@@ -114,19 +163,31 @@ func ValidateMapParamsAgainstStructNamed[P any](
 	typeByName := make(map[string]reflect.Type)
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
+		if structParams {
+			if name, ok := paramName(field); ok {
+				typeByName[name] = field.Type
+			}
+			continue
+		}
+
 		dbTag := field.Tag.Get("db")
 		jsonTag := field.Tag.Get("json")
-		
+
 		// Validate that all fields with db tag must have json tag
 		if dbTag != "" && jsonTag == "" {
 			return nil, fmt.Errorf("field %s has db tag but missing json tag", field.Name)
 		}
-		
+
 		if dbTag != "" {
 			typeByName[dbTag] = field.Type
 		}
 	}
+	return typeByName, nil
+}
 
+// bindParams returns the values of queryParams in paramMap, in order,
+// checked against and converted to the types of typeByName.
+func bindParams(typeByName map[string]reflect.Type, paramMap map[string]interface{}, queryParams []string) ([]interface{}, error) {
 	args := make([]interface{}, 0, len(queryParams))
 	for _, p := range queryParams {
 		expectedType, found := typeByName[p]
@@ -141,10 +202,29 @@ func ValidateMapParamsAgainstStructNamed[P any](
 			continue
 		}
 
+		if convert := canonicalValue(expectedType); val != nil && convert != nil {
+			converted, err := convert(val)
+			if err != nil {
+				return nil, &TypeMismatchError{Field: p, Parameter: true, Value: val, Err: err}
+			}
+			args = append(args, converted)
+			continue
+		}
+
+		// Lists are bound as array literals, which database/sql drivers accept
+		if val != nil && isArrayType(expectedType) {
+			literal, err := arrayLiteral(expectedType, val)
+			if err != nil {
+				return nil, &TypeMismatchError{Field: p, Parameter: true, Value: val, Err: err}
+			}
+			args = append(args, literal)
+			continue
+		}
+
 		valType := reflect.TypeOf(val)
 		if !isTypeCompatible(valType, expectedType) {
-			return nil, fmt.Errorf("parameter %s type mismatch: got %s, want %s",
-				p, typeNameOrNil(valType), typeNameOrNil(expectedType))
+			return nil, &TypeMismatchError{Field: p, Parameter: true, Value: val,
+				Err: fmt.Errorf("type mismatch: got %s, want %s", typeNameOrNil(valType), typeNameOrNil(expectedType))}
 		}
 
 		args = append(args, val)
@@ -156,11 +236,63 @@ func ValidateMapParamsAgainstStructNamed[P any](
 // ExecuteRaw takes a query with {{param_name}} placeholders and executes it.
 // P is the type that defines parameter structure (with `db` tags)
 // R is the type that defines result structure (with `db` and `json` tags)
+// Under a context prepared with WithMemo, identical calls are executed only once.
+// Under a context prepared with WithResultCache, results are shared across requests.
 func ExecuteRaw[P, R any](
 	ctx context.Context,
 	db interface{},
 	query string,
 	params map[string]interface{},
+) ([]map[string]interface{}, error) {
+	return runRaw[P, R](ctx, db, query, params, false)
+}
+
+// runRaw implements ExecuteRaw and ExecuteRawParams, naming the parameters
+// of P as described by paramTypes.
+func runRaw[P, R any](
+	ctx context.Context,
+	db interface{},
+	query string,
+	params map[string]interface{},
+	structParams bool,
+) ([]map[string]interface{}, error) {
+	db, err := routeDB(ctx, db, params)
+	if err != nil {
+		return nil, err
+	}
+	m, c := memoFromContext(ctx), resultCacheFromContext(ctx)
+	if m == nil && c == nil {
+		return executeRaw[P, R](ctx, db, query, params, structParams)
+	}
+
+	kind := "raw"
+	if structParams {
+		kind += "+params"
+	}
+	types := []reflect.Type{reflect.TypeOf((*P)(nil)).Elem(), reflect.TypeOf((*R)(nil)).Elem()}
+	key, err := memoCallKey(registryFromContext(ctx), kind, types, db, struct {
+		Query  string
+		Params map[string]interface{}
+	}{query, params})
+	if err != nil {
+		return nil, err
+	}
+	value, err := cachedCall(ctx, m, c, key, func() (interface{}, error) {
+		return executeRaw[P, R](ctx, db, query, params, structParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyRows(value.([]map[string]interface{})), nil
+}
+
+// executeRaw implements runRaw.
+func executeRaw[P, R any](
+	ctx context.Context,
+	db interface{},
+	query string,
+	params map[string]interface{},
+	structParams bool,
 ) ([]map[string]interface{}, error) {
 	// 1. Extract named placeholders
 	queryParams, err := ExtractNamedPlaceholders(query)
@@ -169,17 +301,18 @@ func ExecuteRaw[P, R any](
 	}
 
 	// 2. Validate and convert map params to arguments in correct order
-	args, err := ValidateMapParamsAgainstStructNamed[P](params, queryParams)
+	typeByName, err := paramTypes(reflect.TypeOf((*P)(nil)).Elem(), structParams)
 	if err != nil {
 		return nil, fmt.Errorf("parameter validation failed: %w", err)
 	}
-
-	// 3. Replace named placeholders with $N placeholders
-	finalQuery, err := ReplaceNamedWithDollarPlaceholders(query, queryParams)
+	args, err := bindParams(typeByName, params, queryParams)
 	if err != nil {
-		return nil, fmt.Errorf("failed to replace named placeholders: %w", err)
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
 	}
 
+	// 3. Replace named placeholders with the bind parameters of the dialect
+	finalQuery, args := bindNamedPlaceholders(registryFromContext(ctx).Dialect(), query, queryParams, args)
+
 	// 4. Build metadata map for results (no instance needed)
 	metaMap, err := BuildMetadataMap[R]()
 	if err != nil {
@@ -187,24 +320,24 @@ func ExecuteRaw[P, R any](
 	}
 
 	// 5. Execute query and scan into slice of structs first to handle custom types
-	var structResults []R
-	switch db := db.(type) {
-	case *sql.DB:
-		if err := sqlscan.Select(ctx, db, &structResults, finalQuery, args...); err != nil {
-			return nil, fmt.Errorf("failed to execute query: %w", err)
-		}
-	case *pgx.Conn:
-		if err := pgxscan.Select(ctx, db, &structResults, finalQuery, args...); err != nil {
-			return nil, fmt.Errorf("failed to execute query: %w", err)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported database type: %T", db)
+	db, err = resolveDB(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	// Fields database/sql drivers can't scan are scanned as rawValue, see rawScanType
+	r := registryFromContext(ctx)
+	structResults := reflect.New(reflect.SliceOf(r.rawScanType(reflect.TypeOf((*R)(nil)).Elem())))
+	if err := selectAll(ctx, db, structResults.Interface(), finalQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 
-	// 6. Convert struct results to maps with only requested fields
-	results := make([]map[string]interface{}, len(structResults))
-	for i, row := range structResults {
-		val := reflect.ValueOf(row)
+	// 6. Convert struct results to maps with only requested fields, with the
+	// null defaults of R if it is registered
+	registered, _ := r.GetModelMetadata(*new(R))
+	rows := structResults.Elem()
+	results := make([]map[string]interface{}, rows.Len())
+	for i := range results {
+		val := rows.Index(i)
 		typ := val.Type()
 		resultMap := make(map[string]interface{})
 
@@ -213,7 +346,12 @@ func ExecuteRaw[P, R any](
 			if field, ok := typ.FieldByName(info.fieldName); ok {
 				fieldVal := val.FieldByName(field.Name)
 				if fieldVal.IsValid() {
-					resultMap[info.jsonKey] = fieldVal.Interface()
+					field := Field{Type: info.goType, RawJSON: info.rawJSON, NullDefault: registered.Fields[info.jsonKey].NullDefault}
+					value, err := r.outputValue(field, resultValue(fieldVal))
+					if err != nil {
+						return nil, fmt.Errorf("failed to read field %s: %w", info.jsonKey, err)
+					}
+					r.setResult(resultMap, info.jsonKey, field, value)
 				}
 			}
 		}
@@ -16,8 +16,8 @@ import (
 )
 
 type QueryParams struct {
-	ID     int64  `db:"id" db_param:"id"`
-	Status string `db:"status" db_param:"status"`
+	ID     int64  `db:"id" json:"id" db_param:"id"`
+	Status string `db:"status" json:"status" db_param:"status"`
 }
 
 type TestQueryResult struct {
@@ -67,7 +67,7 @@ func (c CustomID) Value() (driver.Value, error) {
 }
 
 type TestCustomParams struct {
-	ID CustomID `db:"id"`
+	ID CustomID `db:"id" json:"id"`
 }
 
 type TestCustomResult struct {
@@ -0,0 +1,163 @@
+package sqld
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// scanFieldValue converts val, read from the column of a field of type t,
+// into the value emitted in results. Types with a scanner registered with
+// RegisterScanner, and types implementing sql.Scanner or
+// encoding.TextUnmarshaler, decode the column value, so that e.g. an
+// EmployeeID needs no scanner of its own and malformed values are reported.
+// The decoded value is emitted as is when it marshals to JSON or text, and as
+// the value it binds as otherwise. UUIDs and decimals are emitted as
+// strings, see canonicalValue. Other values are returned unchanged.
+func (r *Registry) scanFieldValue(t reflect.Type, val interface{}) (interface{}, error) {
+	if val == nil || t.Kind() == reflect.Interface {
+		return val, nil
+	}
+	if convert := canonicalValue(t); convert != nil {
+		return convert(val) // Strings whatever the driver returns
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, isNull := nullValueField(t); isNull || reflect.TypeOf(val) == t ||
+		t == reflect.TypeOf(time.Time{}) || t.PkgPath() == pgtypePkgPath {
+		return val, nil // Driver values fit these types as they are
+	}
+
+	scanned := reflect.New(t)
+	if factory, ok := r.GetScanner(t); ok {
+		scanner := factory()
+		if err := scanner.Scan(val); err != nil {
+			return nil, err
+		}
+		if reflect.TypeOf(scanner) != scanned.Type() {
+			// Scanners of another type only check the value
+			if valuer, ok := scanner.(driver.Valuer); ok {
+				return valuer.Value()
+			}
+			return val, nil
+		}
+		scanned = reflect.ValueOf(scanner)
+	} else if scanner, ok := scanned.Interface().(sql.Scanner); ok {
+		if err := scanner.Scan(val); err != nil {
+			return nil, err
+		}
+	} else if unmarshaler, ok := scanned.Interface().(encoding.TextUnmarshaler); ok {
+		var text []byte
+		switch v := val.(type) {
+		case string:
+			text = []byte(v)
+		case []byte:
+			text = v
+		default:
+			return val, nil
+		}
+		if err := unmarshaler.UnmarshalText(text); err != nil {
+			return nil, err
+		}
+	} else {
+		return val, nil
+	}
+
+	if scanned.Type().Implements(jsonMarshalerType) || scanned.Type().Implements(textMarshalerType) {
+		if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+			return scanned.Elem().Interface(), nil
+		}
+		return scanned.Interface(), nil // Marshaled through its pointer methods
+	}
+	if valuer, ok := scanned.Interface().(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", t, err)
+		}
+		return value, nil
+	}
+	return scanned.Elem().Interface(), nil
+}
+
+// canonicalValue returns the function converting the values of fields of
+// type t, written in requests or read from the database, to the strings they
+// are bound as and emitted as: canonical UUIDs for UUID fields, and decimal
+// strings for decimal fields so that no precision is lost to floats. It
+// returns nil for other types.
+func canonicalValue(t reflect.Type) func(value interface{}) (interface{}, error) {
+	switch {
+	case isUUIDType(t):
+		return uuidValue
+	case isDecimalType(t):
+		return decimalValue
+	}
+	return nil
+}
+
+// conditionValue converts the value of a where condition, a value or a list
+// of values, with convert.
+func conditionValue(value interface{}, convert func(interface{}) (interface{}, error)) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	if value == nil || v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return convert(value)
+	}
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		converted, err := convert(v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		values[i] = converted
+	}
+	return values, nil
+}
+
+// outputValue converts val, the value of field scanned from the database, to
+// the value emitted in results: times and binary values as set with
+// SetTimeOutput and SetBinaryOutput, hstores, composite types and arrays as
+// maps and slices, see hstoreValue, compositeValue and arrayValue, and JSON
+// as nested values, see jsonValue. Non-null values are then converted with
+// the marshaler of the field, if any, see WithFieldMarshaler.
+func (r *Registry) outputValue(field Field, val interface{}) (interface{}, error) {
+	t := field.Type
+	var err error
+	if FieldType(t) == "datetime" {
+		val = r.timeValue(val)
+	}
+	if isBinaryType(t) {
+		if val, err = r.binaryValue(val); err != nil {
+			return nil, err
+		}
+	}
+	if isHstoreType(t) {
+		if val, err = hstoreValue(val); err != nil {
+			return nil, err
+		}
+	}
+	if val, err = r.compositeValue(t, val); err != nil {
+		return nil, err
+	}
+	if isArrayType(t) {
+		if val, err = arrayValue(t, val); err != nil {
+			return nil, err
+		}
+	}
+	if isJSONType(t) {
+		val = jsonValue(val, field.RawJSON)
+	}
+	if field.Marshal != nil && val != nil {
+		return field.Marshal(val)
+	}
+	return val, nil
+}
@@ -0,0 +1,116 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// BadgeID scans itself and binds as an int64.
+type BadgeID int64
+
+func (id *BadgeID) Scan(src interface{}) error {
+	n, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into BadgeID", src)
+	}
+	*id = BadgeID(n)
+	return nil
+}
+
+func (id BadgeID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+// Grade is read from text and written back as text.
+type Grade struct {
+	Level int
+}
+
+func (g *Grade) UnmarshalText(text []byte) error {
+	if !strings.HasPrefix(string(text), "L") {
+		return fmt.Errorf("invalid grade %q", text)
+	}
+	_, err := fmt.Sscanf(string(text), "L%d", &g.Level)
+	return err
+}
+
+func (g Grade) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("L%d", g.Level)), nil
+}
+
+type BadgeHolder struct {
+	ID    BadgeID   `json:"id" db:"id"`
+	Grade Grade     `json:"grade" db:"grade"`
+	Count CustomInt `json:"count" db:"count"`
+}
+
+func (BadgeHolder) TableName() string { return "badge_holders" }
+
+func TestExecute_ScanFieldValue(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BadgeHolder{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, grade, count FROM badge_holders`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "grade", "count"}).AddRow(int64(7), "L3", int64(2)))
+	resp, err := Execute[BadgeHolder](ctx, db, QueryRequest{Select: []string{"id", "grade", "count"}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(7), "grade": Grade{Level: 3}, "count": int64(2)}}, resp.Data)
+	encoded, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id": 7, "grade": "L3", "count": 2}]`, string(encoded))
+
+	mock.ExpectQuery(`SELECT grade FROM badge_holders`).
+		WillReturnRows(sqlmock.NewRows([]string{"grade"}).AddRow("senior"))
+	_, err = Execute[BadgeHolder](ctx, db, QueryRequest{Select: []string{"grade"}})
+	assert.ErrorContains(t, err, `failed to scan field grade: invalid grade "senior"`)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScanFieldValue_RegisteredScanner(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterScanner(reflect.TypeFor[CustomInt](), func() sql.Scanner { return &CustomScanner{} })
+	registry.RegisterScanner(reflect.TypeFor[Grade](), func() sql.Scanner { return &gradeScanner{} })
+
+	// Scanners of another type only check the value
+	val, err := registry.scanFieldValue(reflect.TypeFor[CustomInt](), int64(2))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), val)
+
+	val, err = registry.scanFieldValue(reflect.TypeFor[Grade](), int64(4))
+	require.NoError(t, err)
+	assert.Equal(t, "L4", val)
+}
+
+// gradeScanner reads grades stored as numbers.
+type gradeScanner struct {
+	grade Grade
+}
+
+func (s *gradeScanner) Scan(src interface{}) error {
+	n, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into Grade", src)
+	}
+	s.grade.Level = int(n)
+	return nil
+}
+
+func (s *gradeScanner) Value() (driver.Value, error) {
+	text, err := s.grade.MarshalText()
+	return string(text), err
+}
@@ -0,0 +1,220 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaIssue is a difference between a registered model and the database.
+type SchemaIssue struct {
+	Model   string `json:"model"`
+	Table   string `json:"table"`
+	Column  string `json:"column,omitempty"` // Empty for issues with the table
+	Problem string `json:"problem"`
+}
+
+// SchemaReport lists the differences found by ValidateSchema.
+type SchemaReport struct {
+	Issues []SchemaIssue `json:"issues"`
+}
+
+// Err returns an error describing every issue of the report, or nil when the
+// models match the database.
+func (r SchemaReport) Err() error {
+	if len(r.Issues) == 0 {
+		return nil
+	}
+	problems := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		problems[i] = issue.String()
+	}
+	return fmt.Errorf("schema validation found %d issues: %s", len(r.Issues), strings.Join(problems, "; "))
+}
+
+func (i SchemaIssue) String() string {
+	if i.Column == "" {
+		return fmt.Sprintf("%s (%s): %s", i.Model, i.Table, i.Problem)
+	}
+	return fmt.Sprintf("%s (%s.%s): %s", i.Model, i.Table, i.Column, i.Problem)
+}
+
+// columnsQueryDialect is implemented by dialects without an
+// information_schema.columns view.
+type columnsQueryDialect interface {
+	// ColumnsQuery returns the query listing the column_name, data_type and
+	// is_nullable of the columns of a table, bound to the schema and the table when qualified, and to
+	// the table of the current schema otherwise.
+	ColumnsQuery(qualified bool) string
+}
+
+// catalogColumn is a column reported by the database catalog.
+type catalogColumn struct {
+	ColumnName string `db:"column_name"`
+	DataType   string `db:"data_type"`
+	IsNullable string `db:"is_nullable"` // YES or Y when the column accepts NULL
+}
+
+// nullable reports whether the column accepts NULL.
+func (c catalogColumn) nullable() bool {
+	return strings.EqualFold(c.IsNullable, "YES") || strings.EqualFold(c.IsNullable, "Y")
+}
+
+// ValidateSchema cross-checks every model of the registry against the
+// catalog of db, e.g. at startup: tables and columns that don't exist, and
+// columns whose type can't hold the Go type of their field. Differences are
+// listed in the report, whose Err method turns them into an error; the
+// returned error is set when the catalog can't be read.
+//
+// The registry is the one of ctx, see WithRegistry.
+func ValidateSchema(ctx context.Context, db interface{}) (*SchemaReport, error) {
+	r := registryFromContext(ctx)
+	r.mu.RLock()
+	types := make([]reflect.Type, 0, len(r.models))
+	models := make(map[reflect.Type]ModelMetadata, len(r.models))
+	for t, metadata := range r.models {
+		types = append(types, t)
+		models[t] = metadata
+	}
+	r.mu.RUnlock()
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+
+	report := &SchemaReport{}
+	dialect := r.Dialect()
+	for _, t := range types {
+		metadata := models[t]
+		columns, err := catalogColumns(ctx, db, dialect, metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the columns of %s: %w", metadata.TableName, err)
+		}
+		report.Issues = append(report.Issues, compareSchema(t.Name(), metadata, columns)...)
+	}
+	return report, nil
+}
+
+// catalogColumns reads the columns of the table of metadata from the catalog.
+func catalogColumns(ctx context.Context, db interface{}, dialect Dialect, metadata ModelMetadata) ([]catalogColumn, error) {
+	parts := metadata.tableIdent
+	if parts == nil {
+		parts = catalogTableParts(dialect, metadata.TableName)
+	}
+	return queryCatalogColumns(ctx, db, dialect, parts)
+}
+
+// catalogTableParts splits the unquoted table name, optionally qualified with
+// a schema, into the names the catalog reports.
+func catalogTableParts(dialect Dialect, table string) []string {
+	parts := strings.Split(table, ".")
+	if folder, ok := dialect.(identFoldingDialect); ok {
+		for i, part := range parts {
+			parts[i] = folder.FoldIdent(part)
+		}
+	}
+	return parts
+}
+
+// queryCatalogColumns reads the columns of the table named by parts, the
+// table optionally preceded by its schema, in their catalog order.
+func queryCatalogColumns(ctx context.Context, db interface{}, dialect Dialect, parts []string) ([]catalogColumn, error) {
+	qualified := len(parts) > 1
+	var query string
+	if d, ok := dialect.(columnsQueryDialect); ok {
+		query = d.ColumnsQuery(qualified)
+	} else if qualified {
+		query = fmt.Sprintf("SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_schema = %s AND table_name = %s ORDER BY ordinal_position",
+			dialect.BindVar(1), dialect.BindVar(2))
+	} else {
+		query = fmt.Sprintf("SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_name = %s ORDER BY ordinal_position", dialect.BindVar(1))
+	}
+	args := make([]interface{}, len(parts))
+	for i, part := range parts {
+		args[i] = part
+	}
+
+	var columns []catalogColumn
+	if err := selectAll(ctx, db, &columns, query, args...); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// compareSchema returns the differences between metadata and the columns of
+// its table.
+func compareSchema(model string, metadata ModelMetadata, columns []catalogColumn) []SchemaIssue {
+	if len(columns) == 0 {
+		return []SchemaIssue{{Model: model, Table: metadata.TableName, Problem: "table does not exist"}}
+	}
+	dataTypes := make(map[string]string, len(columns))
+	for _, column := range columns {
+		dataTypes[strings.ToLower(column.ColumnName)] = column.DataType
+	}
+
+	var issues []SchemaIssue
+	for _, name := range sortedKeys(metadata.Fields) {
+		field := metadata.Fields[name]
+		dataType, ok := dataTypes[strings.ToLower(field.Name)]
+		if !ok {
+			issues = append(issues, SchemaIssue{Model: model, Table: metadata.TableName, Column: field.Name, Problem: "column does not exist"})
+			continue
+		}
+		if !columnTypeCompatible(field.Type, dataType) {
+			issues = append(issues, SchemaIssue{Model: model, Table: metadata.TableName, Column: field.Name,
+				Problem: fmt.Sprintf("column type %s can't hold field %s of type %s", dataType, name, field.Type)})
+		}
+	}
+	return issues
+}
+
+// columnTypeCompatible reports whether a column of the SQL type dataType can
+// be read into a field of type t. Only numbers, booleans and times are
+// checked: strings, byte slices and scanners accept most column types, and
+// unknown SQL types are accepted.
+func columnTypeCompatible(t reflect.Type, dataType string) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if underlying, ok := nullValueType(t); ok {
+		t = underlying
+	}
+
+	family := sqlTypeFamily(dataType)
+	if family == "" {
+		return true
+	}
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return family == "time"
+	case isIntegerKind(t.Kind()), t.Kind() == reflect.Float32, t.Kind() == reflect.Float64:
+		return family == "number"
+	case t.Kind() == reflect.Bool:
+		// MySQL and Oracle store booleans as small numbers
+		return family == "bool" || family == "number"
+	}
+	return true
+}
+
+// sqlTypeFamily classifies the SQL type dataType as "number", "bool", "time"
+// or "text", and returns "" for other types.
+func sqlTypeFamily(dataType string) string {
+	dataType = strings.ToLower(dataType)
+	switch {
+	case strings.Contains(dataType, "interval"), strings.Contains(dataType, "point"):
+		return ""
+	case strings.Contains(dataType, "bool"):
+		return "bool"
+	case strings.Contains(dataType, "int"), strings.Contains(dataType, "serial"),
+		strings.Contains(dataType, "numeric"), strings.Contains(dataType, "decimal"),
+		strings.Contains(dataType, "number"), strings.Contains(dataType, "double"),
+		strings.Contains(dataType, "real"), strings.Contains(dataType, "float"):
+		return "number"
+	case strings.Contains(dataType, "time"), strings.Contains(dataType, "date"):
+		return "time"
+	case strings.Contains(dataType, "char"), strings.Contains(dataType, "text"),
+		strings.Contains(dataType, "clob"), strings.Contains(dataType, "string"):
+		return "text"
+	}
+	return ""
+}
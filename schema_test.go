@@ -0,0 +1,98 @@
+package sqld
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// CheckedEmployee is validated against a mocked catalog.
+type CheckedEmployee struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	HireDate time.Time `json:"hire_date"`
+	Salary   float64   `json:"salary"`
+	Active   bool      `json:"active"`
+}
+
+func TestValidateSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(CheckedEmployee{}, WithSchema("hr")))
+	require.NoError(t, registry.Register(JobCategory{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	mock.ExpectQuery(`SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_schema = \$1 AND table_name = \$2`).
+		WithArgs("hr", "checked_employees").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type"}).
+			AddRow("id", "bigint").
+			AddRow("name", "character varying").
+			AddRow("hire_date", "text").
+			AddRow("active", "boolean"))
+	mock.ExpectQuery(`SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_name = \$1`).
+		WithArgs("job_categories").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type"}))
+
+	report, err := ValidateSchema(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []SchemaIssue{
+		{Model: "CheckedEmployee", Table: `"hr"."checked_employees"`, Column: "hire_date", Problem: "column type text can't hold field hire_date of type time.Time"},
+		{Model: "CheckedEmployee", Table: `"hr"."checked_employees"`, Column: "salary", Problem: "column does not exist"},
+		{Model: "JobCategory", Table: "job_categories", Problem: "table does not exist"},
+	}, report.Issues)
+	assert.ErrorContains(t, report.Err(), "schema validation found 3 issues: CheckedEmployee (\"hr\".\"checked_employees\".hire_date)")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateSchema_Oracle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	registry := NewRegistry()
+	registry.SetDialect(Oracle)
+	require.NoError(t, registry.Register(JobCategory{}))
+
+	mock.ExpectQuery(`FROM user_tab_columns WHERE table_name = :1`).
+		WithArgs("JOB_CATEGORIES").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type"}).
+			AddRow("ID", "NUMBER").
+			AddRow("NAME", "VARCHAR2"))
+
+	report, err := ValidateSchema(WithRegistry(context.Background(), registry), db)
+	require.NoError(t, err)
+	assert.NoError(t, report.Err())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestColumnTypeCompatible(t *testing.T) {
+	tests := []struct {
+		goType   interface{}
+		dataType string
+		want     bool
+	}{
+		{int64(0), "integer", true},
+		{int64(0), "NUMBER", true},
+		{int64(0), "character varying", false},
+		{float64(0), "numeric", true},
+		{true, "boolean", true},
+		{true, "tinyint", true},
+		{true, "text", false},
+		{time.Time{}, "timestamp with time zone", true},
+		{time.Time{}, "integer", false},
+		{"", "integer", true},
+		{int64(0), "USER-DEFINED", true},
+		{new(int64), "bigint", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, columnTypeCompatible(reflect.TypeOf(tt.goType), tt.dataType), "%T %s", tt.goType, tt.dataType)
+	}
+}
@@ -0,0 +1,97 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ShardRouter chooses the database of each call from a shard key, for
+// datasets partitioned across several databases, e.g. by tenant_id ranges.
+// A *ShardRouter is passed as the db of Execute, ExecuteRaw and the mutation
+// functions, which route on the value of Field in their Where clause (the
+// inserted values for inserts, the params for ExecuteRaw). Calls without the
+// field in their request, and other functions taking a db such as InTx,
+// ExecuteUnion or CopyFrom, route on the key returned by FromContext.
+type ShardRouter struct {
+	// Field is the JSON field name holding the shard key, e.g. "tenant_id".
+	// Optional - if empty, only FromContext is used.
+	Field string
+
+	// FromContext returns the shard key of calls whose request doesn't hold
+	// Field, e.g. the tenant of the authenticated user.
+	// Optional - if nil, such calls fail.
+	FromContext func(ctx context.Context) (interface{}, bool)
+
+	// Route returns the database of a shard key. Any db accepted by Execute
+	// may be returned, including a ManagedConn.
+	Route func(ctx context.Context, key interface{}) (interface{}, error)
+}
+
+// route returns the database of the call whose request holds values.
+func (r *ShardRouter) route(ctx context.Context, values map[string]interface{}) (interface{}, error) {
+	key, err := r.key(ctx, values)
+	if err != nil {
+		return nil, err
+	}
+	if r.Route == nil {
+		return nil, fmt.Errorf("shard router has no Route function")
+	}
+	db, err := r.Route(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route shard key %v: %w", key, err)
+	}
+	return db, nil
+}
+
+// key returns the shard key of the call whose request holds values.
+func (r *ShardRouter) key(ctx context.Context, values map[string]interface{}) (interface{}, error) {
+	if value, ok := values[r.Field]; ok && r.Field != "" {
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return nil, fmt.Errorf("shard key %s must be a single value", r.Field)
+		}
+		return value, nil
+	}
+	if r.FromContext != nil {
+		if key, ok := r.FromContext(ctx); ok {
+			return key, nil
+		}
+	}
+	if r.Field != "" {
+		return nil, fmt.Errorf("no shard key: %s is not set", r.Field)
+	}
+	return nil, fmt.Errorf("no shard key in context")
+}
+
+// routeDB returns the database of the call whose request holds values when
+// db is a *ShardRouter, and db otherwise.
+func routeDB(ctx context.Context, db interface{}, values map[string]interface{}) (interface{}, error) {
+	if r, ok := db.(*ShardRouter); ok {
+		return r.route(ctx, values)
+	}
+	return db, nil
+}
+
+// routeRows returns the database of rows, which must all have the same shard
+// key, when db is a *ShardRouter, and db otherwise.
+func routeRows(ctx context.Context, db interface{}, rows []map[string]interface{}) (interface{}, error) {
+	r, ok := db.(*ShardRouter)
+	if !ok || len(rows) == 0 {
+		return db, nil
+	}
+	first, err := r.key(ctx, rows[0])
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows[1:] {
+		key, err := r.key(ctx, row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		if !reflect.DeepEqual(key, first) {
+			return nil, fmt.Errorf("row %d has shard key %v, want %v as row 1", i+2, key, first)
+		}
+	}
+	return r.route(ctx, rows[0])
+}
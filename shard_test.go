@@ -0,0 +1,152 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type shardKey struct{}
+
+// newTestShardRouter routes owners before "m" to the first database and the
+// others to the second one.
+func newTestShardRouter(first, second *sql.DB) *ShardRouter {
+	return &ShardRouter{
+		Field: "owner",
+		FromContext: func(ctx context.Context) (interface{}, bool) {
+			key, ok := ctx.Value(shardKey{}).(string)
+			return key, ok
+		},
+		Route: func(ctx context.Context, key interface{}) (interface{}, error) {
+			owner, ok := key.(string)
+			if !ok || owner == "" {
+				return nil, fmt.Errorf("invalid owner")
+			}
+			if owner < "m" {
+				return first, nil
+			}
+			return second, nil
+		},
+	}
+}
+
+func TestShardRouter_Execute(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	first, firstMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer first.Close()
+	second, secondMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer second.Close()
+
+	firstMock.ExpectQuery(`SELECT id FROM mutation_accounts WHERE owner_name = \$1`).WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	secondMock.ExpectQuery(`SELECT id FROM mutation_accounts WHERE id = \$1`).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	router := newTestShardRouter(first, second)
+	ctx := context.Background()
+
+	// Routed on the where clause
+	_, err = Execute[MutationAccount](ctx, router, QueryRequest{
+		Select: []string{"id"},
+		Where:  map[string]interface{}{"owner": "alice"},
+	})
+	require.NoError(t, err)
+
+	// Routed on the context
+	_, err = Execute[MutationAccount](context.WithValue(ctx, shardKey{}, "zoe"), router, QueryRequest{
+		Select: []string{"id"},
+		Where:  map[string]interface{}{"id": 2},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, firstMock.ExpectationsWereMet())
+	require.NoError(t, secondMock.ExpectationsWereMet())
+}
+
+func TestShardRouter_Mutations(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	first, firstMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer first.Close()
+	second, secondMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer second.Close()
+
+	secondMock.ExpectQuery(`INSERT INTO mutation_accounts \(owner_name\) VALUES \(\$1\) RETURNING id`).WithArgs("zoe").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	firstMock.ExpectExec(`INSERT INTO mutation_accounts \(owner_name\) VALUES \(\$1\),\(\$2\)`).WithArgs("bob", "bob").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	secondMock.ExpectQuery(`UPDATE mutation_accounts SET balance = \$1 WHERE owner_name = \$2 RETURNING id`).WithArgs(5.0, "zoe").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	firstMock.ExpectQuery(`DELETE FROM mutation_accounts WHERE owner_name = \$1 RETURNING id`).WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	router := newTestShardRouter(first, second)
+	ctx := context.Background()
+
+	_, err = Insert[MutationAccount](ctx, router, InsertRequest{
+		Values:    map[string]interface{}{"owner": "zoe"},
+		Returning: []string{"id"},
+	})
+	require.NoError(t, err)
+	_, err = InsertMany[MutationAccount](ctx, router, InsertManyRequest{
+		Rows: []map[string]interface{}{{"owner": "bob"}, {"owner": "bob"}},
+	})
+	require.NoError(t, err)
+	_, err = Update[MutationAccount](ctx, router, UpdateRequest{
+		Set:       map[string]interface{}{"balance": 5.0},
+		Where:     map[string]interface{}{"owner": "zoe"},
+		Returning: []string{"id"},
+	})
+	require.NoError(t, err)
+	_, err = Delete[MutationAccount](ctx, router, DeleteRequest{
+		Where:     map[string]interface{}{"owner": "bob"},
+		Returning: []string{"id"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, firstMock.ExpectationsWereMet())
+	require.NoError(t, secondMock.ExpectationsWereMet())
+}
+
+func TestShardRouter_Errors(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	router := newTestShardRouter(nil, nil)
+	ctx := context.Background()
+
+	_, err := Execute[MutationAccount](ctx, router, QueryRequest{
+		Select: []string{"id"},
+		Where:  map[string]interface{}{"id": 1},
+	})
+	assert.EqualError(t, err, "no shard key: owner is not set")
+
+	_, err = Execute[MutationAccount](ctx, router, QueryRequest{
+		Select: []string{"id"},
+		Where:  map[string]interface{}{"owner": []string{"alice", "zoe"}},
+	})
+	assert.EqualError(t, err, "shard key owner must be a single value")
+
+	_, err = Execute[MutationAccount](ctx, router, QueryRequest{
+		Select: []string{"id"},
+		Where:  map[string]interface{}{"owner": ""},
+	})
+	assert.EqualError(t, err, "failed to route shard key : invalid owner")
+
+	_, err = InsertMany[MutationAccount](ctx, router, InsertManyRequest{
+		Rows: []map[string]interface{}{{"owner": "bob"}, {"owner": "zoe"}},
+	})
+	assert.EqualError(t, err, "row 2 has shard key zoe, want bob as row 1")
+
+	err = InTx(ctx, &ShardRouter{}, func(tx Runner) error { return nil })
+	assert.EqualError(t, err, "no shard key in context")
+}
@@ -0,0 +1,148 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SlowQuery describes a statement that took longer than the threshold set
+// with SetSlowQueryHook.
+type SlowQuery struct {
+	SQL         string        // Statement as sent, with the bind parameters of the dialect
+	Fingerprint string        // FingerprintSQL of the statement
+	ArgCount    int           // Number of values bound
+	ArgTypes    []string      // Go types of the values bound, which are left out
+	Duration    time.Duration // Time the statement took
+	Threshold   time.Duration // Threshold it exceeded
+	Plan        string        // Output of EXPLAIN for the statement, one line per row
+	PlanErr     error         // Why Plan is empty, if it is
+}
+
+// SlowQueryHook is called with the statements exceeding the threshold of
+// SetSlowQueryHook.
+type SlowQueryHook func(ctx context.Context, q SlowQuery)
+
+// SetSlowQueryHook calls hook with every statement run with the default
+// registry for longer than threshold, nil for none. The plan of the statement
+// is read with EXPLAIN, on the same database handle and with the same
+// values, before hook is called and before the call returns, so only
+// statements that succeeded are explained.
+func SetSlowQueryHook(threshold time.Duration, hook SlowQueryHook) {
+	defaultRegistry.SetSlowQueryHook(threshold, hook)
+}
+
+// SetSlowQueryHook calls hook with every statement run with the registry
+// for longer than threshold.
+func (r *Registry) SetSlowQueryHook(threshold time.Duration, hook SlowQueryHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.slowAfter = threshold
+	r.slowHook = hook
+}
+
+// checkSlowQuery calls the slow query hook of the registry of ctx when the
+// statement query, run on db with args since start, exceeded its threshold.
+func checkSlowQuery(ctx context.Context, db interface{}, query string, args []interface{}, start time.Time, err error) {
+	elapsed := time.Since(start)
+	r := registryFromContext(ctx)
+	r.mu.RLock()
+	threshold, hook := r.slowAfter, r.slowHook
+	r.mu.RUnlock()
+	if hook == nil || elapsed < threshold {
+		return
+	}
+
+	q := SlowQuery{
+		SQL:         query,
+		Fingerprint: FingerprintSQL(query),
+		ArgCount:    len(args),
+		Duration:    elapsed,
+		Threshold:   threshold,
+	}
+	for _, arg := range args {
+		q.ArgTypes = append(q.ArgTypes, fmt.Sprintf("%T", arg))
+	}
+	if err != nil {
+		q.PlanErr = fmt.Errorf("statement failed: %w", err)
+	} else {
+		q.Plan, q.PlanErr = explain(ctx, db, r.Dialect(), query, args)
+	}
+	hook(ctx, q)
+}
+
+// explain returns the plan of query with args, one line per row of EXPLAIN,
+// with the columns of each row separated by " | ".
+func explain(ctx context.Context, db interface{}, dialect Dialect, query string, args []interface{}) (string, error) {
+	explainer, ok := dialect.(explainDialect)
+	if !ok {
+		return "", fmt.Errorf("dialect %s can't explain statements", dialect.Name())
+	}
+	query = explainer.Explain(query)
+
+	var lines []string
+	switch db := db.(type) {
+	case Querier:
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return "", fmt.Errorf("failed to explain query: %w", err)
+		}
+		defer rows.Close()
+		columns, err := rows.Columns()
+		if err != nil {
+			return "", fmt.Errorf("failed to explain query: %w", err)
+		}
+		for rows.Next() {
+			values := make([]interface{}, len(columns))
+			for i := range values {
+				values[i] = new(interface{})
+			}
+			if err := rows.Scan(values...); err != nil {
+				return "", fmt.Errorf("failed to explain query: %w", err)
+			}
+			for i, value := range values {
+				values[i] = *value.(*interface{})
+			}
+			lines = append(lines, planLine(values))
+		}
+		if err := rows.Err(); err != nil {
+			return "", fmt.Errorf("failed to explain query: %w", err)
+		}
+	case PgxQuerier:
+		rows, err := db.Query(ctx, query, pgxArgs(ctx, args)...)
+		if err != nil {
+			return "", fmt.Errorf("failed to explain query: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			values, err := rows.Values()
+			if err != nil {
+				return "", fmt.Errorf("failed to explain query: %w", err)
+			}
+			lines = append(lines, planLine(values))
+		}
+		if err := rows.Err(); err != nil {
+			return "", fmt.Errorf("failed to explain query: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("can't explain statements on %T", db)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// planLine formats a row of EXPLAIN.
+func planLine(values []interface{}) string {
+	columns := make([]string, len(values))
+	for i, value := range values {
+		switch value := value.(type) {
+		case nil:
+			columns[i] = "NULL"
+		case []byte:
+			columns[i] = string(value)
+		default:
+			columns[i] = fmt.Sprint(value)
+		}
+	}
+	return strings.Join(columns, " | ")
+}
@@ -0,0 +1,66 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowQueryHook(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	var slow []SlowQuery
+	registry.SetSlowQueryHook(20*time.Millisecond, func(_ context.Context, q SlowQuery) {
+		slow = append(slow, q)
+	})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	req := QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"email": "a@example.com"}}
+
+	// Fast statements are left alone
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Empty(t, slow)
+
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1`).
+		WillDelayFor(30 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`EXPLAIN SELECT id FROM test_models WHERE email = \$1`).
+		WithArgs("a@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).
+			AddRow("Seq Scan on test_models  (cost=0.00..25.88 rows=6 width=4)").
+			AddRow("  Filter: (email = 'a@example.com'::text)"))
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	require.Len(t, slow, 1)
+	assert.Equal(t, "SELECT id FROM test_models WHERE email = $1", slow[0].SQL)
+	assert.Equal(t, 1, slow[0].ArgCount)
+	assert.Equal(t, []string{"string"}, slow[0].ArgTypes)
+	assert.GreaterOrEqual(t, slow[0].Duration, 30*time.Millisecond)
+	assert.Equal(t, 20*time.Millisecond, slow[0].Threshold)
+	assert.Equal(t, "Seq Scan on test_models  (cost=0.00..25.88 rows=6 width=4)\n  Filter: (email = 'a@example.com'::text)", slow[0].Plan)
+	assert.NoError(t, slow[0].PlanErr)
+
+	// Failed statements aren't explained
+	mock.ExpectQuery(`SELECT id FROM test_models WHERE email = \$1`).
+		WillDelayFor(30 * time.Millisecond).
+		WillReturnError(errors.New("canceling statement due to statement timeout"))
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	require.Error(t, err)
+	require.Len(t, slow, 2)
+	assert.Empty(t, slow[1].Plan)
+	assert.ErrorContains(t, slow[1].PlanErr, "statement timeout")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
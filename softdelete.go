@@ -0,0 +1,89 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// SoftDelete describes the columns marking the rows of a model as deleted.
+// Reads skip rows whose deleted-at column is set, and Delete sets it instead
+// of removing the rows.
+type SoftDelete struct {
+	DeletedAtField string // JSON name of the time.Time field set on deletion
+	DeletedByField string // JSON name of the field recording who deleted the row; optional
+}
+
+// RegisterSoftDelete enables soft deletion for model T in the default
+// registry.
+func RegisterSoftDelete[T Model](sd SoftDelete) error {
+	var model T
+	return defaultRegistry.RegisterSoftDelete(model, sd)
+}
+
+// RegisterSoftDelete enables soft deletion for a registered model.
+func (r *Registry) RegisterSoftDelete(model Model, sd SoftDelete) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(model)
+	metadata, ok := r.models[t]
+	if !ok {
+		return &ModelNotRegisteredError{Model: t.Name()}
+	}
+	field, ok := metadata.Fields[sd.DeletedAtField]
+	if !ok {
+		return fmt.Errorf("invalid deleted at field in soft delete: %s", sd.DeletedAtField)
+	}
+	if !isNullableType(field.Type) {
+		return fmt.Errorf("deleted at field %s must be nullable, got %s", sd.DeletedAtField, field.Type)
+	}
+	if sd.DeletedByField != "" {
+		if _, ok := metadata.Fields[sd.DeletedByField]; !ok {
+			return fmt.Errorf("invalid deleted by field in soft delete: %s", sd.DeletedByField)
+		}
+	}
+	r.softDeletes[t] = sd
+	return nil
+}
+
+// softDeleteFor returns the soft delete settings of model type t, if any.
+// The caller must hold r.mu.
+func (r *Registry) softDeleteFor(t reflect.Type) *SoftDelete {
+	sd, ok := r.softDeletes[t]
+	if !ok {
+		return nil
+	}
+	return &sd
+}
+
+// applySoftDelete excludes the soft-deleted rows of metadata from query
+// unless includeDeleted is set.
+func applySoftDelete(query squirrel.SelectBuilder, metadata ModelMetadata, includeDeleted, qualify bool) squirrel.SelectBuilder {
+	if includeDeleted {
+		return query
+	}
+	alias := ""
+	if qualify {
+		alias = metadata.TableName
+	}
+	if cond := notDeleted(metadata, alias); cond != "" {
+		query = query.Where(cond)
+	}
+	return query
+}
+
+// notDeleted returns the condition excluding the soft-deleted rows of
+// metadata, with the column qualified by alias when it is not empty. It
+// returns an empty string for models without soft deletion.
+func notDeleted(metadata ModelMetadata, alias string) string {
+	if metadata.SoftDelete == nil {
+		return ""
+	}
+	column := metadata.Fields[metadata.SoftDelete.DeletedAtField].Name
+	if alias != "" {
+		column = alias + "." + column
+	}
+	return column + " IS NULL"
+}
@@ -0,0 +1,146 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SoftHolding struct {
+	ID        int64      `json:"id" db:"id" sqld:"readonly"`
+	UserID    int64      `json:"user_id" db:"user_id"`
+	Amount    float64    `json:"amount" db:"amount"`
+	DeletedAt *time.Time `json:"deleted_at" db:"deleted_at"`
+	DeletedBy *string    `json:"deleted_by" db:"deleted_by"`
+}
+
+func (SoftHolding) TableName() string {
+	return "holdings"
+}
+
+type SoftUser struct {
+	ID        int64      `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	DeletedAt *time.Time `json:"deleted_at" db:"deleted_at"`
+}
+
+func (SoftUser) TableName() string {
+	return "users"
+}
+
+func registerSoftDeleteModels(t *testing.T, registry *Registry) {
+	t.Helper()
+	require.NoError(t, registry.Register(SoftHolding{}))
+	require.NoError(t, registry.Register(SoftUser{}))
+	require.NoError(t, registry.RegisterSoftDelete(SoftHolding{}, SoftDelete{DeletedAtField: "deleted_at", DeletedByField: "deleted_by"}))
+	require.NoError(t, registry.RegisterSoftDelete(SoftUser{}, SoftDelete{DeletedAtField: "deleted_at"}))
+	require.NoError(t, registry.RegisterRelation(SoftHolding{}, SoftUser{}, "owner", Relation{
+		Kind:         BelongsTo,
+		LocalField:   "user_id",
+		ForeignField: "id",
+	}))
+}
+
+func TestRegisterSoftDelete_Invalid(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(SoftHolding{}))
+
+	assert.Error(t, registry.RegisterSoftDelete(SoftHolding{}, SoftDelete{DeletedAtField: "removed_at"}))
+	assert.Error(t, registry.RegisterSoftDelete(SoftHolding{}, SoftDelete{DeletedAtField: "amount"}))
+	assert.Error(t, registry.RegisterSoftDelete(SoftHolding{}, SoftDelete{DeletedAtField: "deleted_at", DeletedByField: "remover"}))
+	assert.Error(t, registry.RegisterSoftDelete(SoftUser{}, SoftDelete{DeletedAtField: "deleted_at"}))
+}
+
+func TestBuildSelect_SoftDelete(t *testing.T) {
+	registry := NewRegistry()
+	registerSoftDeleteModels(t, registry)
+	holdings, err := registry.GetModelMetadata(SoftHolding{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		req     QueryRequest
+		wantSQL string
+	}{
+		{
+			name:    "deleted rows skipped",
+			req:     QueryRequest{Select: []string{"id", "amount"}, Where: map[string]interface{}{"user_id": 3}},
+			wantSQL: "SELECT id, amount FROM holdings WHERE user_id = $1 AND deleted_at IS NULL",
+		},
+		{
+			name:    "deleted rows included",
+			req:     QueryRequest{Select: []string{"id"}, IncludeDeleted: true},
+			wantSQL: "SELECT id FROM holdings",
+		},
+		{
+			name: "deleted related rows skipped",
+			req:  QueryRequest{Select: []string{"id", "owner.name"}},
+			wantSQL: `SELECT holdings.id, owner.name AS "owner.name" FROM holdings ` +
+				"LEFT JOIN users AS owner ON owner.id = holdings.user_id AND owner.deleted_at IS NULL " +
+				"WHERE holdings.deleted_at IS NULL",
+		},
+		{
+			name: "deleted related rows included",
+			req:  QueryRequest{Select: []string{"id", "owner.name"}, IncludeDeleted: true},
+			wantSQL: `SELECT holdings.id, owner.name AS "owner.name" FROM holdings ` +
+				"LEFT JOIN users AS owner ON owner.id = holdings.user_id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := buildSelect(holdings, tt.req)
+			require.NoError(t, err)
+			sql, _, err := query.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, sql)
+		})
+	}
+
+	count, err := buildCount(holdings, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	sql, _, err := count.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM holdings WHERE deleted_at IS NULL", sql)
+}
+
+func TestDelete_SoftDelete(t *testing.T) {
+	require.NoError(t, Register(SoftHolding{}))
+	require.NoError(t, RegisterSoftDelete[SoftHolding](SoftDelete{DeletedAtField: "deleted_at", DeletedByField: "deleted_by"}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"amount", "deleted_at", "deleted_by", "id", "user_id"}
+	mock.ExpectQuery(`UPDATE holdings SET deleted_at = CURRENT_TIMESTAMP, deleted_by = \$1 WHERE id = \$2 AND deleted_at IS NULL RETURNING amount, deleted_at, deleted_by, id, user_id`).
+		WithArgs("admin", 5).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1.0, time.Now(), "admin", 5, 2))
+
+	resp, err := Delete[SoftHolding](context.Background(), db, DeleteRequest{
+		Where: map[string]interface{}{"id": 5},
+		By:    "admin",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+
+	mock.ExpectQuery(`DELETE FROM holdings WHERE id = \$1 RETURNING`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	_, err = Delete[SoftHolding](context.Background(), db, DeleteRequest{
+		Where: map[string]interface{}{"id": 5},
+		Hard:  true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = Delete[SoftHolding](context.Background(), db, DeleteRequest{
+		Where: map[string]interface{}{"id": 5},
+		By:    42,
+	})
+	assert.ErrorContains(t, err, "invalid value for field deleted_by")
+}
@@ -0,0 +1,144 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+)
+
+// stitchBatchSize is the maximum number of keys per child query of
+// ExecuteStitched.
+const stitchBatchSize = 500
+
+// StitchRequest describes a query on a parent model whose rows are stitched
+// with the rows of a child model living in another database or service.
+type StitchRequest struct {
+	// Parent is the query on the parent model. It may be paginated.
+	Parent QueryRequest `json:"parent"`
+
+	// Child is the query on the child model. Its Where is combined with the
+	// key condition; it must not be paginated.
+	Child QueryRequest `json:"child"`
+
+	// ParentKey and ChildKey are the JSON names of the fields matched in
+	// memory. They are fetched even when not selected.
+	ParentKey string `json:"parent_key"`
+	ChildKey  string `json:"child_key"`
+
+	// As is the key under which child rows are stored in each parent row.
+	As string `json:"as"`
+
+	// Many stores an array of all matching child rows instead of the first
+	// one (or nil).
+	Many bool `json:"many,omitempty"`
+}
+
+// ExecuteStitched runs req.Parent against parentDB, then fetches the matching
+// rows of model C from childDB in batches of WHERE key IN (...) queries and
+// nests them in the parent rows under req.As. It is meant for data split
+// across databases, where a JOIN is not possible.
+func ExecuteStitched[P, C Model](ctx context.Context, parentDB, childDB interface{}, req StitchRequest) (QueryResponse[P], error) {
+	var parent P
+	var child C
+	parentMeta, err := registryFromContext(ctx).GetModelMetadata(parent)
+	if err != nil {
+		return QueryResponse[P]{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	childMeta, err := registryFromContext(ctx).GetModelMetadata(child)
+	if err != nil {
+		return QueryResponse[P]{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	if _, ok := parentMeta.Fields[req.ParentKey]; !ok {
+		return QueryResponse[P]{}, fmt.Errorf("invalid parent key: %s", req.ParentKey)
+	}
+	if _, ok := childMeta.Fields[req.ChildKey]; !ok {
+		return QueryResponse[P]{}, fmt.Errorf("invalid child key: %s", req.ChildKey)
+	}
+	if req.As == "" {
+		return QueryResponse[P]{}, fmt.Errorf("stitch target name cannot be empty")
+	}
+	if _, exists := parentMeta.Fields[req.As]; exists {
+		return QueryResponse[P]{}, fmt.Errorf("stitch target %s clashes with a field", req.As)
+	}
+	if req.Parent.Pivot != nil || req.Child.Pivot != nil {
+		return QueryResponse[P]{}, fmt.Errorf("stitched queries can't be pivoted")
+	}
+	if req.Child.Pagination != nil || req.Child.Limit != nil || req.Child.Offset != nil {
+		return QueryResponse[P]{}, fmt.Errorf("child query can't be paginated")
+	}
+	if _, exists := req.Child.Where[req.ChildKey]; exists {
+		return QueryResponse[P]{}, fmt.Errorf("child query can't filter on the child key")
+	}
+
+	req.Parent = withDefaultSelect(parentMeta, req.Parent)
+	req.Child = withDefaultSelect(childMeta, req.Child)
+	parentReq := req.Parent
+	parentReq.Select = appendMissing(req.Parent.Select, req.ParentKey)
+	resp, err := Execute[P](ctx, parentDB, parentReq)
+	if err != nil {
+		return QueryResponse[P]{}, err
+	}
+
+	var keys []interface{}
+	seen := make(map[string]bool)
+	for _, row := range resp.Data {
+		key := row[req.ParentKey]
+		if key == nil || seen[fmt.Sprint(key)] {
+			continue
+		}
+		seen[fmt.Sprint(key)] = true
+		keys = append(keys, key)
+	}
+
+	grouped := make(map[string][]QueryResult)
+	childReq := req.Child
+	childReq.Select = appendMissing(req.Child.Select, req.ChildKey)
+	for start := 0; start < len(keys); start += stitchBatchSize {
+		end := start + stitchBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		where := make(map[string]interface{}, len(req.Child.Where)+1)
+		for k, v := range req.Child.Where {
+			where[k] = v
+		}
+		where[req.ChildKey] = keys[start:end]
+		childReq.Where = where
+
+		children, err := Execute[C](ctx, childDB, childReq)
+		if err != nil {
+			return QueryResponse[P]{}, fmt.Errorf("failed to fetch %s: %w", req.As, err)
+		}
+		for _, row := range children.Data {
+			key := fmt.Sprint(row[req.ChildKey])
+			grouped[key] = append(grouped[key], row)
+		}
+	}
+
+	hideParentKey := len(parentReq.Select) > len(req.Parent.Select)
+	hideChildKey := len(childReq.Select) > len(req.Child.Select)
+	for _, rows := range grouped {
+		for _, row := range rows {
+			if hideChildKey {
+				delete(row, req.ChildKey)
+			}
+		}
+	}
+	for _, row := range resp.Data {
+		matches := grouped[fmt.Sprint(row[req.ParentKey])]
+		if req.Many {
+			if matches == nil {
+				matches = []QueryResult{}
+			}
+			row[req.As] = matches
+		} else if len(matches) > 0 {
+			row[req.As] = matches[0]
+		} else {
+			row[req.As] = nil
+		}
+		if hideParentKey {
+			delete(row, req.ParentKey)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,104 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteStitched(t *testing.T) {
+	require.NoError(t, Register(RelEmployee{}))
+	require.NoError(t, Register(RelDepartment{}))
+
+	hrDB, hrMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer hrDB.Close()
+	orgDB, orgMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer orgDB.Close()
+
+	t.Run("one child per parent", func(t *testing.T) {
+		hrMock.ExpectQuery(`SELECT name, department_id FROM employees`).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "department_id"}).
+				AddRow("alice", int64(1)).
+				AddRow("bob", int64(2)).
+				AddRow("carol", int64(1)))
+		orgMock.ExpectQuery(`SELECT name, id FROM departments WHERE id IN \(\$1,\$2\)`).
+			WithArgs(int64(1), int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "id"}).AddRow("eng", int64(1)))
+
+		resp, err := ExecuteStitched[RelEmployee, RelDepartment](context.Background(), hrDB, orgDB, StitchRequest{
+			Parent:    QueryRequest{Select: []string{"name"}},
+			Child:     QueryRequest{Select: []string{"name"}},
+			ParentKey: "department_id",
+			ChildKey:  "id",
+			As:        "department",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []QueryResult{
+			{"name": "alice", "department": QueryResult{"name": "eng"}},
+			{"name": "bob", "department": nil},
+			{"name": "carol", "department": QueryResult{"name": "eng"}},
+		}, resp.Data)
+	})
+
+	t.Run("many children per parent", func(t *testing.T) {
+		orgMock.ExpectQuery(`SELECT id, name FROM departments WHERE name = \$1`).
+			WithArgs("eng").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "eng").AddRow(int64(3), "eng"))
+		hrMock.ExpectQuery(`SELECT name, department_id FROM employees WHERE department_id IN \(\$1,\$2\)`).
+			WithArgs(int64(1), int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "department_id"}).
+				AddRow("alice", int64(1)).
+				AddRow("carol", int64(1)))
+
+		resp, err := ExecuteStitched[RelDepartment, RelEmployee](context.Background(), orgDB, hrDB, StitchRequest{
+			Parent:    QueryRequest{Select: []string{"id", "name"}, Where: map[string]interface{}{"name": "eng"}},
+			Child:     QueryRequest{Select: []string{"name", "department_id"}},
+			ParentKey: "id",
+			ChildKey:  "department_id",
+			As:        "employees",
+			Many:      true,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, []QueryResult{
+			{"name": "alice", "department_id": int64(1)},
+			{"name": "carol", "department_id": int64(1)},
+		}, resp.Data[0]["employees"])
+		assert.Equal(t, []QueryResult{}, resp.Data[1]["employees"])
+	})
+
+	require.NoError(t, hrMock.ExpectationsWereMet())
+	require.NoError(t, orgMock.ExpectationsWereMet())
+}
+
+func TestExecuteStitched_Invalid(t *testing.T) {
+	require.NoError(t, Register(RelEmployee{}))
+	require.NoError(t, Register(RelDepartment{}))
+
+	valid := StitchRequest{
+		Parent:    QueryRequest{Select: []string{"name"}},
+		Child:     QueryRequest{Select: []string{"name"}},
+		ParentKey: "department_id",
+		ChildKey:  "id",
+		As:        "department",
+	}
+	limit := 5
+	for name, mutate := range map[string]func(*StitchRequest){
+		"unknown parent key": func(r *StitchRequest) { r.ParentKey = "dept" },
+		"unknown child key":  func(r *StitchRequest) { r.ChildKey = "key" },
+		"empty target":       func(r *StitchRequest) { r.As = "" },
+		"target is a field":  func(r *StitchRequest) { r.As = "name" },
+		"paginated child":    func(r *StitchRequest) { r.Child.Limit = &limit },
+		"child key filtered": func(r *StitchRequest) { r.Child.Where = map[string]interface{}{"id": 1} },
+	} {
+		req := valid
+		mutate(&req)
+		_, err := ExecuteStitched[RelEmployee, RelDepartment](context.Background(), nil, nil, req)
+		assert.Error(t, err, name)
+	}
+}
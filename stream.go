@@ -0,0 +1,234 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/georgysavva/scany/v2/sqlscan"
+)
+
+// RowStream iterates over the rows of ExecuteStream one at a time, so that
+// exports of millions of rows don't hold them all in memory:
+//
+//	stream, err := sqld.ExecuteStream[Employee](ctx, db, req)
+//	if err != nil {
+//	    return err
+//	}
+//	defer stream.Close()
+//	for stream.Next() {
+//	    row := stream.Row()
+//	    ...
+//	}
+//	return stream.Err()
+//
+// A RowStream holds a connection until it is closed or fully read.
+type RowStream[T Model] struct {
+	ctx      context.Context // For the result transformers
+	metadata ModelMetadata
+	selected []string
+	indexes  map[string][]int // Fields of T by key, for Scan
+
+	next  func() bool
+	scan  func(dest *map[string]interface{}) error
+	close func() error
+	err   func() error
+
+	row       QueryResult
+	streamErr error
+	closed    bool
+	start     time.Time // For the metrics, recorded on Close
+	rows      int64
+}
+
+// ExecuteStream runs req like Execute and returns its rows as a RowStream
+// instead of a slice. Nested relations, includes, pivots and summaries need
+// the full result and are rejected, and so are the options running the call
+// in a transaction, with a timeout or with retries: the context and db of the
+// call govern the stream instead. Pagination only selects the page; no count
+// is run.
+func ExecuteStream[T Model](ctx context.Context, db interface{}, req QueryRequest, opts ...ExecuteOption) (*RowStream[T], error) {
+	switch {
+	case len(req.Nested) > 0:
+		return nil, fmt.Errorf("streams can't load nested relations")
+	case len(req.Include) > 0:
+		return nil, fmt.Errorf("streams can't include lookups")
+	case req.Pivot != nil:
+		return nil, fmt.Errorf("streams can't be pivoted")
+	case len(req.Summary) > 0:
+		return nil, fmt.Errorf("streams can't be summarized")
+	}
+	cfg := newExecuteConfig(opts)
+	if cfg.txOpts != nil || cfg.timeout > 0 || cfg.retries > 0 {
+		return nil, fmt.Errorf("streams can't run in a transaction, with a timeout or with retries")
+	}
+	if cfg.execMode != nil {
+		ctx = context.WithValue(ctx, execModeKey{}, *cfg.execMode)
+	}
+	if cfg.debug {
+		ctx = context.WithValue(ctx, debugKey{}, true)
+	}
+	if cfg.costLimits != nil {
+		ctx = context.WithValue(ctx, costLimitsKey{}, *cfg.costLimits)
+	}
+
+	var model T
+	r := registryFromContext(ctx)
+	db, err := routeDB(ctx, db, req.Where)
+	if err != nil {
+		return nil, err
+	}
+	metadata, req, err := r.prepareQuery(ctx, model, r.callerVariant(ctx, model, cfg.version), req)
+	if err != nil {
+		return nil, err
+	}
+	builder, err := buildSelect(metadata, withPagination(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sql: %w", err)
+	}
+	indexes, err := rowIndexes(reflect.TypeOf(model), reflect.TypeOf(model), metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &RowStream[T]{ctx: ctx, metadata: metadata, selected: req.Select, indexes: indexes}
+	if db, err = resolveDB(ctx, db); err != nil {
+		return nil, err
+	}
+	if err := checkCost(ctx, db, query, args); err != nil {
+		return nil, err
+	}
+	stream.start = time.Now()
+	switch db := db.(type) {
+	case Querier:
+		rows, err := db.QueryContext(ctx, query, args...)
+		logQuery(ctx, query, args, stream.start, dbError(err))
+		if err != nil {
+			recordCall(ctx, metadata, OpStream, stream.start, 0, err)
+			return nil, fmt.Errorf("failed to execute query: %w", dbError(err))
+		}
+		scanner := sqlscan.NewRowScanner(rows)
+		stream.next = rows.Next
+		stream.scan = func(dest *map[string]interface{}) error { return scanner.Scan(dest) }
+		stream.close = rows.Close
+		stream.err = rows.Err
+	case PgxQuerier:
+		rows, err := db.Query(ctx, query, pgxArgs(ctx, args)...)
+		logQuery(ctx, query, args, stream.start, dbError(err))
+		if err != nil {
+			recordCall(ctx, metadata, OpStream, stream.start, 0, err)
+			return nil, fmt.Errorf("failed to execute query: %w", dbError(err))
+		}
+		scanner := pgxscan.NewRowScanner(rows)
+		stream.next = rows.Next
+		stream.scan = func(dest *map[string]interface{}) error { return scanner.Scan(dest) }
+		stream.close = func() error {
+			rows.Close()
+			return rows.Err()
+		}
+		stream.err = rows.Err
+	default:
+		return nil, fmt.Errorf("unsupported database type: %T", db)
+	}
+	return stream, nil
+}
+
+// Next advances to the next row, returning false when there are no more rows
+// or reading one failed, see Err. The stream is closed after the last row.
+func (s *RowStream[T]) Next() bool {
+	if s.closed || s.streamErr != nil {
+		return false
+	}
+	if !s.next() {
+		if err := s.err(); err != nil {
+			s.streamErr = fmt.Errorf("failed to execute query: %w", dbError(err))
+		}
+		s.Close()
+		return false
+	}
+
+	var scanned map[string]interface{}
+	if err := s.scan(&scanned); err != nil {
+		s.streamErr = fmt.Errorf("failed to scan row: %w", err)
+		s.Close()
+		return false
+	}
+	rows, err := toQueryResults(s.metadata, s.selected, []map[string]interface{}{scanned})
+	if err == nil {
+		err = transformResults(s.ctx, s.metadata, rows)
+	}
+	if err != nil {
+		s.streamErr = err
+		s.Close()
+		return false
+	}
+	s.row = rows[0]
+	s.rows++
+	return true
+}
+
+// Columns returns the keys of the rows, the selected fields.
+func (s *RowStream[T]) Columns() []string {
+	return s.selected
+}
+
+// Row returns the current row, as Execute would return it.
+func (s *RowStream[T]) Row() QueryResult {
+	return s.row
+}
+
+// Scan populates dest with the current row, as ExecuteTyped would.
+func (s *RowStream[T]) Scan(dest *T) error {
+	if s.row == nil {
+		return fmt.Errorf("no current row")
+	}
+	return populate(reflect.ValueOf(dest).Elem(), s.indexes, s.row)
+}
+
+// Err returns the error that ended the iteration, if any.
+func (s *RowStream[T]) Err() error {
+	return s.streamErr
+}
+
+// Close releases the rows of the stream. It is safe to call several times.
+func (s *RowStream[T]) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.row = nil
+	err := s.close()
+	recordErr := s.streamErr
+	if recordErr == nil {
+		recordErr = err
+	}
+	recordCall(s.ctx, s.metadata, OpStream, s.start, s.rows, recordErr)
+	return err
+}
+
+// ExecuteForEach runs req like ExecuteStream and calls fn with each row,
+// populated as ExecuteTyped would, one at a time. It stops at the first error
+// of fn and returns it.
+func ExecuteForEach[T Model](ctx context.Context, db interface{}, req QueryRequest, fn func(row T) error, opts ...ExecuteOption) error {
+	stream, err := ExecuteStream[T](ctx, db, req, opts...)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	for stream.Next() {
+		var row T
+		if err := stream.Scan(&row); err != nil {
+			return fmt.Errorf("failed to populate row: %w", err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
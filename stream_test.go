@@ -0,0 +1,121 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteStream(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, name FROM test_models WHERE age = \$1 ORDER BY id ASC`).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "alice").AddRow(2, "bob")).
+		RowsWillBeClosed()
+	stream, err := ExecuteStream[BuilderTestModel](ctx, db, QueryRequest{
+		Select:  []string{"id", "name"},
+		Where:   map[string]interface{}{"age": 30},
+		OrderBy: []OrderByClause{{Field: "id"}},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.True(t, stream.Next())
+	assert.Equal(t, QueryResult{"id": int64(1), "name": "alice"}, stream.Row())
+	require.True(t, stream.Next())
+	var model BuilderTestModel
+	require.NoError(t, stream.Scan(&model))
+	assert.Equal(t, BuilderTestModel{ID: 2, Name: "bob"}, model)
+	assert.False(t, stream.Next())
+	assert.NoError(t, stream.Err())
+	assert.Error(t, stream.Scan(&model))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteStream_Errors(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, errors.New("connection reset"))).
+		RowsWillBeClosed()
+	stream, err := ExecuteStream[BuilderTestModel](ctx, db, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	assert.True(t, stream.Next())
+	assert.False(t, stream.Next())
+	assert.EqualError(t, stream.Err(), "failed to execute query: connection reset")
+	require.NoError(t, stream.Close())
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	tests := []struct {
+		name    string
+		req     QueryRequest
+		opts    []ExecuteOption
+		wantErr string
+	}{
+		{name: "summary", req: QueryRequest{Select: []string{"id"}, Summary: []SummaryField{{Field: "age", Func: SummarySum}}}, wantErr: "streams can't be summarized"},
+		{name: "timeout", req: QueryRequest{Select: []string{"id"}}, opts: []ExecuteOption{WithTimeout(time.Second)}, wantErr: "streams can't run in a transaction, with a timeout or with retries"},
+		{name: "invalid field", req: QueryRequest{Select: []string{"salary"}}, wantErr: "failed to validate query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExecuteStream[BuilderTestModel](ctx, db, tt.req, tt.opts...)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestExecuteForEach(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "alice").AddRow(2, "bob").AddRow(3, "carol")
+	}
+	req := QueryRequest{Select: []string{"id", "name"}}
+
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).WillReturnRows(rows()).RowsWillBeClosed()
+	var names []string
+	require.NoError(t, ExecuteForEach(ctx, db, req, func(row BuilderTestModel) error {
+		names = append(names, row.Name)
+		return nil
+	}))
+	assert.Equal(t, []string{"alice", "bob", "carol"}, names)
+
+	// The rows are closed when the callback stops the iteration
+	errStop := errors.New("stop")
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).WillReturnRows(rows()).RowsWillBeClosed()
+	var seen int
+	err = ExecuteForEach(ctx, db, req, func(row BuilderTestModel) error {
+		seen++
+		if row.ID == 2 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 2, seen)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,59 @@
+package sqld
+
+// maxSuggestions is the number of field names suggested for an unknown one.
+const maxSuggestions = 3
+
+// unknownField returns the error for field, named in the given part of a
+// request, which metadata doesn't have, suggesting the fields it may be a typo
+// of.
+func unknownField(metadata ModelMetadata, field, in string) *UnknownFieldError {
+	return &UnknownFieldError{Field: field, In: in, Suggestions: suggestFields(field, sortedKeys(metadata.Fields))}
+}
+
+// suggestFields returns the names closest to name, at most maxSuggestions of
+// them. Names further than a third of the length of name, in edits, are left
+// out; a typo such as "frist_name" is one edit from "first_name".
+func suggestFields(name string, names []string) []string {
+	best := len([]rune(name)) / 3
+	if best < 1 {
+		best = 1
+	}
+	var suggestions []string
+	for _, n := range names {
+		switch d := editDistance(name, n); {
+		case d < best:
+			best, suggestions = d, []string{n}
+		case d == best && len(suggestions) < maxSuggestions:
+			suggestions = append(suggestions, n)
+		}
+	}
+	return suggestions
+}
+
+// editDistance returns the number of insertions, deletions, substitutions
+// and transpositions of adjacent characters turning a into b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	// d[i][j] is the distance between the first i runes of s and j of t
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
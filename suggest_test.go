@@ -0,0 +1,25 @@
+package sqld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestFields(t *testing.T) {
+	names := []string{"email", "first_name", "id", "last_name", "name"}
+	assert.Equal(t, []string{"first_name"}, suggestFields("frist_name", names))
+	assert.Equal(t, []string{"email"}, suggestFields("emial", names))
+	assert.Equal(t, []string{"first_name"}, suggestFields("fist_name", names))
+	assert.Equal(t, []string{"id", "idx"}, suggestFields("ids", []string{"email", "id", "idx"}))
+	assert.Equal(t, []string{"last_name"}, suggestFields("lastname", names))
+	assert.Empty(t, suggestFields("salary", names))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("name", "name"))
+	assert.Equal(t, 1, editDistance("frist", "first"))
+	assert.Equal(t, 1, editDistance("nme", "name"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 4, editDistance("", "name"))
+}
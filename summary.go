@@ -0,0 +1,152 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Summary functions accepted in SummaryField.Func.
+const (
+	SummarySum   = "sum"
+	SummaryAvg   = "avg"
+	SummaryMin   = "min"
+	SummaryMax   = "max"
+	SummaryCount = "count"
+)
+
+// SummaryField requests one aggregate of a field over the full filtered set
+// of rows, such as the total of an amount column on a financial list screen.
+type SummaryField struct {
+	Field string `json:"field"` // JSON field name, may be a related field
+	Func  string `json:"func"`  // sum, avg, min, max or count (non-null values)
+}
+
+// validateSummary checks that the summary fields exist and that sum and avg
+// are only requested on numeric fields. It returns a ValidationErrors listing
+// every problem found.
+func validateSummary(metadata ModelMetadata, summary []SummaryField) error {
+	var errs ValidationErrors
+	seen := make(map[SummaryField]bool, len(summary))
+	for _, s := range summary {
+		ref, ok := metadata.lookupField(s.Field)
+		if !ok {
+			errs = errs.add(unknownField(metadata, s.Field, "summary"))
+			continue
+		}
+		if err := ref.Field.checkSelectable(s.Field); err != nil {
+			errs = errs.add(err)
+			continue
+		}
+		switch strings.ToLower(s.Func) {
+		case SummarySum, SummaryAvg:
+			if !isNumericType(ref.Field.Type) {
+				errs = errs.add(fmt.Errorf("summary %s requires a numeric field, %s is %s", s.Func, s.Field, ref.Field.Type))
+			}
+		case SummaryMin, SummaryMax, SummaryCount:
+		default:
+			errs = errs.add(&OperatorError{Operator: s.Func, In: "summary function"})
+		}
+		if seen[s] {
+			errs = errs.add(fmt.Errorf("duplicate summary: %s of %s", s.Func, s.Field))
+		}
+		seen[s] = true
+	}
+	return errs.err()
+}
+
+// isNumericType reports whether values of t can be summed.
+func isNumericType(t reflect.Type) bool {
+	if isDecimalType(t) {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullInt32{}),
+		reflect.TypeOf(sql.NullInt16{}), reflect.TypeOf(sql.NullFloat64{}):
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// summaryAlias is the column alias of one summary aggregate.
+func summaryAlias(s SummaryField) string {
+	return fmt.Sprintf("%s.%s", s.Field, strings.ToLower(s.Func))
+}
+
+// buildSummary builds the query computing the summary of req over the same
+// tables and conditions as buildSelect, ignoring pagination.
+func buildSummary(metadata ModelMetadata, req QueryRequest) (squirrel.SelectBuilder, error) {
+	return buildAggregate(metadata, req, func(source ModelMetadata, qualify bool) ([]string, error) {
+		columns := make([]string, len(req.Summary))
+		for i, s := range req.Summary {
+			ref, ok := source.lookupField(s.Field)
+			if !ok {
+				return nil, &UnknownFieldError{Field: s.Field, In: "summary"}
+			}
+			columns[i] = fmt.Sprintf(`%s(%s) AS %s`, strings.ToUpper(s.Func), ref.column(source, qualify), source.dialect().QuoteIdent(summaryAlias(s)))
+		}
+		return columns, nil
+	})
+}
+
+// executeSummary runs the summary query of req and returns the aggregates
+// keyed by field and function. Aggregates of decimal fields are decimal
+// strings and those of datetime fields are emitted as set with SetTimeOutput,
+// like their values.
+func executeSummary(ctx context.Context, db interface{}, metadata ModelMetadata, req QueryRequest) (map[string]map[string]interface{}, error) {
+	builder, err := buildSummary(metadata, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build summary query: %w", err)
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate summary sql: %w", err)
+	}
+
+	var rows []map[string]interface{}
+	if err := selectAll(ctx, db, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get summary: %w", err)
+	}
+	if len(rows) != 1 {
+		return nil, fmt.Errorf("failed to get summary: got %d rows", len(rows))
+	}
+
+	summary := make(map[string]map[string]interface{})
+	for _, s := range req.Summary {
+		if summary[s.Field] == nil {
+			summary[s.Field] = make(map[string]interface{})
+		}
+		value := rows[0][summaryAlias(s)]
+		// DuckDB sums integers as HUGEINT
+		if n, ok := value.(*big.Int); ok && n.IsInt64() {
+			value = n.Int64()
+		}
+		ref, _ := metadata.lookupField(s.Field)
+		switch {
+		case strings.EqualFold(s.Func, SummaryCount):
+		case isDecimalType(ref.Field.Type):
+			// Aggregates of decimals are decimal strings
+			if value, err = decimalValue(value); err != nil {
+				return nil, fmt.Errorf("failed to get summary %s of %s: %w", s.Func, s.Field, err)
+			}
+		case FieldType(ref.Field.Type) == "datetime":
+			value = metadata.owner().timeValue(value)
+		}
+		summary[s.Field][strings.ToLower(s.Func)] = value
+	}
+	return summary, nil
+}
@@ -0,0 +1,94 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSummary(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+	metadata, err := getModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+
+	assert.NoError(t, validateSummary(metadata, []SummaryField{
+		{Field: "age", Func: SummarySum},
+		{Field: "age", Func: SummaryAvg},
+		{Field: "name", Func: SummaryMax},
+		{Field: "created_at", Func: SummaryMin},
+		{Field: "email", Func: SummaryCount},
+	}))
+	assert.Error(t, validateSummary(metadata, []SummaryField{{Field: "salary", Func: SummarySum}}))
+	assert.Error(t, validateSummary(metadata, []SummaryField{{Field: "name", Func: SummarySum}}))
+	assert.Error(t, validateSummary(metadata, []SummaryField{{Field: "age", Func: "median"}}))
+	assert.Error(t, validateSummary(metadata, []SummaryField{
+		{Field: "age", Func: SummarySum},
+		{Field: "age", Func: SummarySum},
+	}))
+}
+
+func TestBuildSummary(t *testing.T) {
+	registry := NewRegistry()
+	registerRelationModels(t, registry)
+	metadata, err := registry.GetModelMetadata(RelEmployee{})
+	require.NoError(t, err)
+
+	limit := 10
+	req := QueryRequest{
+		Select:  []string{"name"},
+		Where:   map[string]interface{}{"name": "alice"},
+		OrderBy: []OrderByClause{{Field: "name"}},
+		Limit:   &limit,
+		Summary: []SummaryField{
+			{Field: "id", Func: SummaryCount},
+			{Field: "department.name", Func: SummaryMax},
+		},
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+
+	query, err := buildSummary(metadata, req)
+	require.NoError(t, err)
+	sql, args, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(employees.id) AS "id.count", MAX(department.name) AS "department.name.max" `+
+		`FROM employees LEFT JOIN departments AS department ON department.id = employees.department_id `+
+		`WHERE employees.name = $1`, sql)
+	assert.Equal(t, []interface{}{"alice"}, args)
+}
+
+func TestExecute_Summary(t *testing.T) {
+	require.NoError(t, Register(BuilderTestModel{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM test_models WHERE name = \$1`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+	mock.ExpectQuery(`SELECT SUM\(age\) AS "age.sum", AVG\(age\) AS "age.avg" FROM test_models WHERE name = \$1`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"age.sum", "age.avg"}).AddRow(int64(750), 30.0))
+	mock.ExpectQuery(`SELECT id, age FROM test_models WHERE name = \$1 LIMIT 10 OFFSET 0`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "age"}).AddRow(int64(1), int64(30)))
+
+	resp, err := Execute[BuilderTestModel](context.Background(), db, QueryRequest{
+		Select:     []string{"id", "age"},
+		Where:      map[string]interface{}{"name": "alice"},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 10},
+		Summary: []SummaryField{
+			{Field: "age", Func: SummarySum},
+			{Field: "age", Func: SummaryAvg},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"age": {"sum": int64(750), "avg": 30.0},
+	}, resp.Summary)
+	assert.Len(t, resp.Data, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,106 @@
+package sqld
+
+import (
+	"time"
+)
+
+// TimeFormat is how times are serialized in results, see TimeOutput.
+type TimeFormat int
+
+const (
+	// TimeFormatNative leaves times as time.Time values, which encoding/json
+	// writes as RFC 3339 strings with nanoseconds.
+	TimeFormatNative TimeFormat = iota
+	// TimeFormatRFC3339 emits times as RFC 3339 strings with the fraction
+	// of seconds they have, e.g. "2024-03-01T09:30:00Z".
+	TimeFormatRFC3339
+	// TimeFormatEpochMillis emits times as int64 milliseconds since the Unix
+	// epoch.
+	TimeFormatEpochMillis
+)
+
+// TimeOutput configures how the times of results are emitted, so that every
+// driver produces the same format: pgx, lib/pq and DuckDB scan times in
+// different zones, and MySQL and SQLite drivers may return them as text.
+type TimeOutput struct {
+	Location *time.Location // Zone times are converted to, e.g. time.UTC; nil keeps the zone of the driver
+	Format   TimeFormat
+}
+
+// timeTextLayouts are the layouts of times read as text, tried in order.
+var timeTextLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// SetTimeOutput sets how the default registry emits the times of results:
+// those of datetime fields in Execute, ExecuteRaw and summaries. Without it
+// times are emitted as the driver scans them.
+func SetTimeOutput(output TimeOutput) {
+	defaultRegistry.SetTimeOutput(output)
+}
+
+// SetTimeOutput sets how the registry emits the times of results.
+func (r *Registry) SetTimeOutput(output TimeOutput) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeOutput = &output
+}
+
+// timeValue returns val, the value of a datetime field, as configured with
+// SetTimeOutput. Times read as text are parsed first; text that isn't a time
+// and values that aren't times are returned unchanged.
+func (r *Registry) timeValue(val interface{}) interface{} {
+	r.mu.RLock()
+	output := r.timeOutput
+	r.mu.RUnlock()
+	if output == nil {
+		return val
+	}
+
+	var t time.Time
+	switch v := val.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		t = *v
+	case []byte:
+		return r.timeValue(string(v))
+	case string:
+		parsed, ok := parseTimeText(v)
+		if !ok {
+			return val
+		}
+		t = parsed
+	default:
+		return val
+	}
+
+	if output.Location != nil {
+		t = t.In(output.Location)
+	}
+	switch output.Format {
+	case TimeFormatRFC3339:
+		return t.Format(time.RFC3339Nano)
+	case TimeFormatEpochMillis:
+		return t.UnixMilli()
+	}
+	return t
+}
+
+// parseTimeText parses a time read as text. Times without a zone are taken
+// as UTC.
+func parseTimeText(s string) (time.Time, bool) {
+	for _, layout := range timeTextLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,60 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTimeOutput(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+	created := time.Date(2024, 3, 1, 15, 0, 0, 0, kolkata)
+
+	tests := []struct {
+		name   string
+		output *TimeOutput
+		value  interface{}
+		want   interface{}
+	}{
+		{name: "unset", value: created, want: created},
+		{name: "native in UTC", output: &TimeOutput{Location: time.UTC}, value: created, want: created.UTC()},
+		{name: "RFC 3339 in UTC", output: &TimeOutput{Location: time.UTC, Format: TimeFormatRFC3339}, value: created, want: "2024-03-01T09:30:00Z"},
+		{name: "RFC 3339 in zone", output: &TimeOutput{Format: TimeFormatRFC3339}, value: created, want: "2024-03-01T15:00:00+05:30"},
+		{name: "epoch millis", output: &TimeOutput{Format: TimeFormatEpochMillis}, value: created, want: created.UnixMilli()},
+		{name: "text", output: &TimeOutput{Location: time.UTC, Format: TimeFormatRFC3339}, value: []byte("2024-03-01 15:00:00.5+05:30"), want: "2024-03-01T09:30:00.5Z"},
+		{name: "text without zone", output: &TimeOutput{Format: TimeFormatEpochMillis}, value: "2024-03-01 09:30:00", want: created.UnixMilli()},
+		{name: "not a time", output: &TimeOutput{Format: TimeFormatRFC3339}, value: "soon", want: "soon"},
+		{name: "null", output: &TimeOutput{Format: TimeFormatRFC3339}, value: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			require.NoError(t, registry.Register(BuilderTestModel{}))
+			if tt.output != nil {
+				registry.SetTimeOutput(*tt.output)
+			}
+			ctx := WithRegistry(context.Background(), registry)
+
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT MAX\(created_at\) AS "created_at.max" FROM test_models`).
+				WillReturnRows(sqlmock.NewRows([]string{"created_at.max"}).AddRow(tt.value))
+			mock.ExpectQuery(`SELECT id, created_at FROM test_models`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, tt.value))
+			resp, err := Execute[BuilderTestModel](ctx, db, QueryRequest{
+				Select:  []string{"id", "created_at"},
+				Summary: []SummaryField{{Field: "created_at", Func: SummaryMax}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.Data[0]["created_at"])
+			assert.Equal(t, tt.want, resp.Summary["created_at"]["max"])
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ResultTransformer post-processes a row returned by a query on the model
+// described by metadata, e.g. to redact fields, add derived ones or convert
+// units. It changes row in place; an error fails the query.
+type ResultTransformer func(ctx context.Context, metadata ModelMetadata, row QueryResult) error
+
+// AddResultTransformer adds fn to the transformers run on the rows of every
+// model of the default registry.
+func AddResultTransformer(fn ResultTransformer) {
+	defaultRegistry.AddResultTransformer(fn)
+}
+
+// AddResultTransformer adds fn to the transformers run on the rows of every
+// model of r.
+func (r *Registry) AddResultTransformer(fn ResultTransformer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Transformers of every model are kept under the nil type
+	r.transforms[nil] = append(r.transforms[nil], fn)
+}
+
+// AddModelResultTransformer adds fn to the transformers run on the rows of
+// model T in the default registry.
+func AddModelResultTransformer[T Model](fn ResultTransformer) error {
+	var model T
+	return defaultRegistry.AddModelResultTransformer(model, fn)
+}
+
+// AddModelResultTransformer adds fn to the transformers run on the rows of a
+// registered model.
+func (r *Registry) AddModelResultTransformer(model Model, fn ResultTransformer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(model)
+	if _, ok := r.models[t]; !ok {
+		return &ModelNotRegisteredError{Model: t.Name()}
+	}
+	r.transforms[t] = append(r.transforms[t], fn)
+	return nil
+}
+
+// transformResults runs the transformers of metadata on rows: those of the
+// registry, then those of the model, each in the order they were added.
+func transformResults(ctx context.Context, metadata ModelMetadata, rows []QueryResult) error {
+	for _, row := range rows {
+		for _, fn := range metadata.Transformers {
+			if err := fn(ctx, metadata, row); err != nil {
+				return fmt.Errorf("failed to transform result: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultTransformers(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	var calls []string
+	registry.AddResultTransformer(func(ctx context.Context, metadata ModelMetadata, row QueryResult) error {
+		calls = append(calls, "global "+metadata.TableName)
+		delete(row, "name") // Redacted
+		return nil
+	})
+	require.NoError(t, registry.AddModelResultTransformer(BuilderTestModel{}, func(ctx context.Context, metadata ModelMetadata, row QueryResult) error {
+		calls = append(calls, "model")
+		row["label"] = "#" + row["id"].(string)
+		return nil
+	}))
+	assert.EqualError(t, registry.AddModelResultTransformer(TypedOrder{}, nil), "model TypedOrder not registered")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	req := QueryRequest{Select: []string{"id", "name"}}
+
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "a"))
+	resp, err := Execute[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": "1", "label": "#1"}}, resp.Data)
+	assert.Equal(t, []string{"global test_models", "model"}, calls)
+
+	// Streams and exporters see the transformed rows too
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("2", "b"))
+	stream, err := ExecuteStream[BuilderTestModel](ctx, db, req)
+	require.NoError(t, err)
+	require.True(t, stream.Next())
+	assert.Equal(t, QueryResult{"id": "2", "label": "#2"}, stream.Row())
+	require.NoError(t, stream.Close())
+
+	// An error fails the query
+	registry.AddResultTransformer(func(ctx context.Context, metadata ModelMetadata, row QueryResult) error {
+		return errors.New("denied")
+	})
+	mock.ExpectQuery(`SELECT id, name FROM test_models`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("3", "c"))
+	_, err = Execute[BuilderTestModel](ctx, db, req)
+	assert.EqualError(t, err, "failed to transform result: denied")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
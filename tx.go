@@ -0,0 +1,189 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Runner is the transaction handed to an InTx callback. It is a *sql.Tx when InTx was given
+// a *sql.DB and a pgx.Tx otherwise, and can be passed as the db argument of Execute,
+// ExecuteRaw, Insert, Update, Delete and the other functions taking a db.
+type Runner interface{}
+
+// TxOption configures InTx.
+type TxOption func(*txConfig)
+
+type txConfig struct {
+	retries   int
+	backoff   time.Duration
+	isolation sql.IsolationLevel
+	readOnly  bool
+}
+
+// WithTxRetries sets how many times a transaction failing with a serialization failure or a
+// deadlock is retried. The default is 3; 0 disables retries.
+func WithTxRetries(n int) TxOption {
+	return func(c *txConfig) { c.retries = n }
+}
+
+// WithTxBackoff sets the pause before the first retry. It doubles after every attempt.
+// The default is 10ms.
+func WithTxBackoff(d time.Duration) TxOption {
+	return func(c *txConfig) { c.backoff = d }
+}
+
+// WithTxIsolation sets the isolation level of the transaction. sql.LevelDefault, the default,
+// keeps the database default.
+func WithTxIsolation(level sql.IsolationLevel) TxOption {
+	return func(c *txConfig) { c.isolation = level }
+}
+
+// WithTxReadOnly makes the transaction read-only.
+func WithTxReadOnly() TxOption {
+	return func(c *txConfig) { c.readOnly = true }
+}
+
+// InTx runs fn in a transaction on db. The transaction is committed when fn returns nil and
+// rolled back when it returns an error or panics. When the transaction fails with a
+// serialization failure (SQLSTATE 40001) or a deadlock (40P01), it is rolled back and fn is
+// run again in a new transaction, so fn must not have side effects outside the database.
+//
+// db may be anything that can begin a database/sql or pgx transaction, such as *sql.DB,
+// *pgx.Conn, a pgx pool or a ManagedConn. A pgx.Tx runs fn in a savepoint.
+func InTx(ctx context.Context, db interface{}, fn func(tx Runner) error, opts ...TxOption) error {
+	cfg := txConfig{retries: 3, backoff: 10 * time.Millisecond}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if err := checkDialectWritable(registryFromContext(ctx).Dialect(), "transactions"); err != nil {
+		return err
+	}
+	db, err := resolveDB(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	backoff := cfg.backoff
+	for attempt := 0; ; attempt++ {
+		err := runTx(ctx, db, cfg, fn)
+		if err == nil {
+			return nil
+		}
+		if attempt >= cfg.retries || !isRetryableTxError(err) || ctx.Err() != nil {
+			return err
+		}
+		if err := sleepContext(ctx, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// sqlBeginner is a database/sql handle that can begin a transaction, such as *sql.DB.
+type sqlBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// pgxTxBeginner is a pgx handle that can begin a transaction with options, such as *pgx.Conn
+// or a pool.
+type pgxTxBeginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+// pgxBeginner is a pgx handle that can begin a transaction, such as pgx.Tx.
+type pgxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// runTx runs fn in a single transaction.
+func runTx(ctx context.Context, db interface{}, cfg txConfig, fn func(tx Runner) error) (err error) {
+	var (
+		tx       Runner
+		commit   func() error
+		rollback func() error
+	)
+	switch d := db.(type) {
+	case sqlBeginner:
+		t, err := d.BeginTx(ctx, &sql.TxOptions{Isolation: cfg.isolation, ReadOnly: cfg.readOnly})
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		tx, commit, rollback = t, t.Commit, t.Rollback
+	case pgxTxBeginner, pgxBeginner:
+		t, err := beginPgx(ctx, d, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		tx = t
+		commit = func() error { return t.Commit(ctx) }
+		rollback = func() error { return t.Rollback(context.WithoutCancel(ctx)) }
+	case *sql.Tx:
+		return fmt.Errorf("cannot nest a transaction in *sql.Tx")
+	default:
+		return fmt.Errorf("unsupported database type: %T", db)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		// database/sql rolls back by itself when ctx is done
+		if rbErr := rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	if err := commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", dbError(err))
+	}
+	return nil
+}
+
+// beginPgx begins a pgx transaction on db with the options in cfg. Savepoints can't change
+// them, so a pgx.Tx only accepts the defaults.
+func beginPgx(ctx context.Context, db interface{}, cfg txConfig) (pgx.Tx, error) {
+	if b, ok := db.(pgxTxBeginner); ok {
+		opts := pgx.TxOptions{}
+		switch cfg.isolation {
+		case sql.LevelDefault:
+		case sql.LevelReadUncommitted:
+			opts.IsoLevel = pgx.ReadUncommitted
+		case sql.LevelReadCommitted:
+			opts.IsoLevel = pgx.ReadCommitted
+		case sql.LevelRepeatableRead, sql.LevelSnapshot:
+			opts.IsoLevel = pgx.RepeatableRead
+		case sql.LevelSerializable, sql.LevelLinearizable:
+			opts.IsoLevel = pgx.Serializable
+		default:
+			return nil, fmt.Errorf("unsupported isolation level: %s", cfg.isolation)
+		}
+		if cfg.readOnly {
+			opts.AccessMode = pgx.ReadOnly
+		}
+		return b.BeginTx(ctx, opts)
+	}
+	if cfg.isolation != sql.LevelDefault || cfg.readOnly {
+		return nil, fmt.Errorf("transaction options are not supported by %T", db)
+	}
+	return db.(pgxBeginner).Begin(ctx)
+}
+
+// isRetryableTxError reports whether err is a serialization failure or a deadlock.
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
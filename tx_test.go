@@ -0,0 +1,134 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInTx_Commit(t *testing.T) {
+	require.NoError(t, Register(MutationAccount{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO mutation_accounts \(owner_name\) VALUES \(\$1\) RETURNING id`).WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	err = InTx(context.Background(), db, func(tx Runner) error {
+		_, err := Insert[MutationAccount](context.Background(), tx, InsertRequest{
+			Values:    map[string]interface{}{"owner": "alice"},
+			Returning: []string{"id"},
+		})
+		return err
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInTx_RollbackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	boom := errors.New("boom")
+	err = InTx(context.Background(), db, func(tx Runner) error { return boom })
+	assert.ErrorIs(t, err, boom)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInTx_RollbackOnPanic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = InTx(context.Background(), db, func(tx Runner) error { panic("boom") })
+	})
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInTx_RetriesSerializationFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	serialization := &pgconn.PgError{Code: "40001"}
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(serialization)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	calls := 0
+	err = InTx(context.Background(), db, func(tx Runner) error {
+		calls++
+		if calls == 1 {
+			return serialization
+		}
+		return nil
+	}, WithTxBackoff(time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInTx_RetriesExhausted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	for i := 0; i < 2; i++ {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+	}
+
+	calls := 0
+	err = InTx(context.Background(), db, func(tx Runner) error {
+		calls++
+		return deadlock
+	}, WithTxRetries(1), WithTxBackoff(0))
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 2, calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInTx_NoRetryOnOtherErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	calls := 0
+	err = InTx(context.Background(), db, func(tx Runner) error {
+		calls++
+		return &pgconn.PgError{Code: "23505"}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInTx_UnsupportedDB(t *testing.T) {
+	err := InTx(context.Background(), "not a db", func(tx Runner) error { return nil })
+	assert.EqualError(t, err, "unsupported database type: string")
+}
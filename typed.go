@@ -0,0 +1,187 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// TypedResponse is the response of ExecuteTyped and ExecuteInto: a
+// QueryResponse with the rows populated into struct values.
+type TypedResponse[T Model] struct {
+	Data       []T                               `json:"data"`
+	Pagination *PaginationResponse               `json:"pagination,omitempty"`
+	Warnings   []string                          `json:"warnings,omitempty"`
+	Truncated  bool                              `json:"truncated,omitempty"`
+	Summary    map[string]map[string]interface{} `json:"summary,omitempty"`
+	Metadata   *QueryMetadata                    `json:"metadata,omitempty"`
+	DryRun     *DryRun                           `json:"dry_run,omitempty"`
+}
+
+// ExecuteTyped runs req like Execute and returns its rows as values of T
+// instead of maps, for Go callers that want compile-time access to fields.
+// Selected fields are set as returned, converting the values emitted as
+// strings back to the type of the field, e.g. UUIDs and decimals; the
+// others, and related fields, are left zero. Pivoted requests can't be
+// typed.
+func ExecuteTyped[T Model](ctx context.Context, db interface{}, req QueryRequest, opts ...ExecuteOption) (TypedResponse[T], error) {
+	var data []T
+	return ExecuteInto[T](ctx, db, req, &data, opts...)
+}
+
+// ExecuteInto runs req for model T like ExecuteTyped and stores the rows in
+// *dest, reusing its capacity, so that hot paths can keep a slice across
+// calls. Rows are values of T or of another struct type R, whose fields are
+// set from the returned keys matching their json names. The Data of the
+// response is *dest.
+func ExecuteInto[T Model, R any](ctx context.Context, db interface{}, req QueryRequest, dest *[]R, opts ...ExecuteOption) (TypedResponse[R], error) {
+	if req.Pivot != nil {
+		return TypedResponse[R]{}, fmt.Errorf("pivoted results can't be typed")
+	}
+	var model T
+	r := registryFromContext(ctx)
+	metadata, err := r.variantMetadata(model, r.callerVariant(ctx, model, newExecuteConfig(opts).version))
+	if err != nil {
+		return TypedResponse[R]{}, err
+	}
+	indexes, err := rowIndexes(reflect.TypeFor[R](), reflect.TypeOf(model), metadata)
+	if err != nil {
+		return TypedResponse[R]{}, err
+	}
+	resp, err := Execute[T](ctx, db, req, opts...)
+	if err != nil {
+		return TypedResponse[R]{}, err
+	}
+
+	rows := (*dest)[:0]
+	var zero R
+	for i, row := range resp.Data {
+		rows = append(rows, zero)
+		if err := populate(reflect.ValueOf(&rows[i]).Elem(), indexes, row); err != nil {
+			return TypedResponse[R]{}, fmt.Errorf("failed to populate row %d: %w", i+1, err)
+		}
+	}
+	*dest = rows
+	return TypedResponse[R]{
+		Data:       rows,
+		Pagination: resp.Pagination,
+		Warnings:   resp.Warnings,
+		Truncated:  resp.Truncated,
+		Summary:    resp.Summary,
+		Metadata:   resp.Metadata,
+		DryRun:     resp.DryRun,
+	}, nil
+}
+
+// rowIndexes returns the indexes of the fields of row type t keyed by the
+// keys of results of the model of metadata, of type model: those of its
+// fields for the model itself, and the json names of the fields of other
+// struct types.
+func rowIndexes(t, model reflect.Type, metadata ModelMetadata) (map[string][]int, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("row type %s is not a struct", t)
+	}
+	indexes := make(map[string][]int)
+	if t == model {
+		for key, field := range metadata.Fields {
+			if field.JSONName != "" {
+				key = field.JSONName
+			}
+			if field.index != nil {
+				indexes[key] = field.index
+			}
+		}
+		return indexes, nil
+	}
+	for _, field := range modelFields(t) {
+		indexes[field.jsonName] = field.Index
+	}
+	return indexes, nil
+}
+
+// populate sets the fields of the struct v to the values of row, given the
+// indexes of the fields by key.
+func populate(v reflect.Value, indexes map[string][]int, row QueryResult) error {
+	for key, value := range row {
+		index, ok := indexes[key]
+		if !ok || value == nil {
+			continue
+		}
+		field, err := fieldByIndex(v, index)
+		if err != nil {
+			return err
+		}
+		if err := setFieldValue(field, value); err != nil {
+			return fmt.Errorf("field %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field of v with the given index, allocating
+// the embedded struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("unexported embedded struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// setFieldValue sets field to value, a non-null value as returned in results.
+// Values of another type are converted when both are numbers, and decoded
+// from their JSON encoding otherwise, which turns the strings of UUIDs,
+// decimals and times back into the type of the field. Decimal strings also
+// set number fields.
+func setFieldValue(field reflect.Value, value interface{}) error {
+	v := reflect.ValueOf(value)
+	t := field.Type()
+	switch {
+	case v.Type().AssignableTo(t):
+		field.Set(v)
+		return nil
+	case isNumericKind(v.Kind()) && isNumericKind(t.Kind()):
+		field.Set(v.Convert(t))
+		return nil
+	}
+
+	// Wrappers such as sql.NullString hold the value
+	target := field
+	if t.Kind() == reflect.Ptr {
+		field.Set(reflect.New(t.Elem()))
+		target = field.Elem()
+	}
+	if i, ok := nullValueField(target.Type()); ok {
+		if err := setFieldValue(target.Field(i), value); err != nil {
+			return err
+		}
+		target.FieldByName("Valid").SetBool(true)
+		return nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if v.Kind() == reflect.String && isNumericKind(target.Kind()) {
+		encoded = []byte(v.String()) // Decimals are strings
+	}
+	if err := json.Unmarshal(encoded, target.Addr().Interface()); err != nil {
+		return fmt.Errorf("can't set %s from %T: %w", t, value, err)
+	}
+	return nil
+}
+
+// isNumericKind reports whether values of kind k are numbers.
+func isNumericKind(k reflect.Kind) bool {
+	return isIntegerKind(k) || k == reflect.Float32 || k == reflect.Float64
+}
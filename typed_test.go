@@ -0,0 +1,104 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TypedOrder struct {
+	ID       uuid.UUID       `json:"id" db:"id"`
+	Quantity int32           `json:"quantity" db:"quantity"`
+	Total    decimal.Decimal `json:"total" db:"total"`
+	PlacedAt time.Time       `json:"placed_at" db:"placed_at"`
+	Note     sql.NullString  `json:"note" db:"note"`
+	Coupon   *string         `json:"coupon" db:"coupon"`
+}
+
+func (TypedOrder) TableName() string { return "orders" }
+
+func TestExecuteTyped(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(TypedOrder{}))
+	registry.SetTimeOutput(TimeOutput{Location: time.UTC, Format: TimeFormatRFC3339})
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	placed := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT id, quantity, total, placed_at, note, coupon FROM orders`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "total", "placed_at", "note", "coupon"}).
+			AddRow(testUUID, int64(3), "19.90", placed, "gift", nil).
+			AddRow(uuid.Nil.String(), int64(1), "5", placed, nil, "SAVE5"))
+	resp, err := ExecuteTyped[TypedOrder](ctx, db, QueryRequest{
+		Select: []string{"id", "quantity", "total", "placed_at", "note", "coupon"},
+	})
+	require.NoError(t, err)
+	coupon := "SAVE5"
+	assert.Equal(t, []TypedOrder{
+		{
+			ID:       uuid.MustParse(testUUID),
+			Quantity: 3,
+			Total:    decimal.RequireFromString("19.90"),
+			PlacedAt: placed,
+			Note:     sql.NullString{String: "gift", Valid: true},
+		},
+		{
+			ID:       uuid.Nil,
+			Quantity: 1,
+			Total:    decimal.RequireFromString("5"),
+			PlacedAt: placed,
+			Coupon:   &coupon,
+		},
+	}, resp.Data)
+
+	mock.ExpectQuery(`SELECT quantity FROM orders`).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(int64(2)))
+	resp, err = ExecuteTyped[TypedOrder](ctx, db, QueryRequest{Select: []string{"quantity"}})
+	require.NoError(t, err)
+	assert.Equal(t, []TypedOrder{{Quantity: 2}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = ExecuteTyped[TypedOrder](ctx, db, QueryRequest{Select: []string{"quantity"}, Pivot: &PivotRequest{}})
+	assert.EqualError(t, err, "pivoted results can't be typed")
+}
+
+// TypedOrderRow is a row type of TypedOrder results.
+type TypedOrderRow struct {
+	Qty   int     `json:"quantity"`
+	Total float64 `json:"total"`
+}
+
+func TestExecuteInto(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(TypedOrder{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	dest := make([]TypedOrderRow, 1, 4)
+	dest[0] = TypedOrderRow{Qty: 9}
+	mock.ExpectQuery(`SELECT quantity, total FROM orders`).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity", "total"}).AddRow(int64(3), "19.90").AddRow(int64(1), "5"))
+	resp, err := ExecuteInto[TypedOrder](ctx, db, QueryRequest{Select: []string{"quantity", "total"}}, &dest)
+	require.NoError(t, err)
+	assert.Equal(t, []TypedOrderRow{{Qty: 3, Total: 19.9}, {Qty: 1, Total: 5}}, dest)
+	assert.Equal(t, 4, cap(dest), "the slice is reused")
+	assert.Equal(t, dest, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	var values []int
+	_, err = ExecuteInto[TypedOrder](ctx, db, QueryRequest{Select: []string{"quantity"}}, &values)
+	assert.EqualError(t, err, "row type int is not a struct")
+}
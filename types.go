@@ -5,10 +5,13 @@ import (
 	"reflect"
 )
 
-// Model interface that represents a database table.
-// We have it so that we can ensure that any type
-// used with the query builder can map to a database table.
-type Model interface {
+// Model is a struct type that represents a database table. A model names its
+// table by implementing TableNamer; without it, the table is inferred from the
+// struct name, e.g. employees for Employee, unless WithTable sets it.
+type Model interface{}
+
+// TableNamer is implemented by models that name their table.
+type TableNamer interface {
 	TableName() string
 }
 
@@ -16,8 +19,47 @@ type Model interface {
 // We use it where we need the list of fields of a table and their types. For example,
 // validating fields names in queries, etc.
 type ModelMetadata struct {
-	TableName string
-	Fields    map[string]Field
+	TableName  string
+	Fields     map[string]Field
+	Relations  map[string]Relation // Relations declared with RegisterRelation, keyed by name
+	Hierarchy  *Hierarchy          // Tree structure declared with RegisterHierarchy, if any
+	ReadOnly   bool                // Backed by a view; see ReadOnlyModel
+	SoftDelete *SoftDelete         // Soft deletion declared with RegisterSoftDelete, if any
+	Audit      *Audit              // Audit columns declared with RegisterAudit, if any
+	Hooks      *Hooks              // Mutation hooks set with RegisterHooks, if any
+
+	// Transformers are run on the returned rows, those added with
+	// AddResultTransformer first, then AddModelResultTransformer.
+	Transformers []ResultTransformer
+
+	// DefaultSelect is the projection of requests without Select, set with
+	// WithDefaultSelect.
+	DefaultSelect []string
+
+	// ComplexityLimits cap the requests run against the model, set with
+	// WithComplexityLimits. When nil, those of the registry apply.
+	ComplexityLimits *ComplexityLimits
+
+	// MaxRows caps the rows returned for the model, set with WithMaxRows.
+	// When nil, the cap of the registry applies.
+	MaxRows *MaxRows
+
+	registry   *Registry // Registry the model is registered with
+	tableIdent []string  // Unquoted schema and table set with WithTable or WithSchema, if any
+}
+
+// owner returns the registry the model is registered with, or the default
+// registry for metadata built by hand.
+func (m ModelMetadata) owner() *Registry {
+	if m.registry == nil {
+		return defaultRegistry
+	}
+	return m.registry
+}
+
+// dialect returns the dialect of the registry the model is registered with.
+func (m ModelMetadata) dialect() Dialect {
+	return m.owner().Dialect()
 }
 
 // Field represents a queryable field with its metadata.
@@ -28,6 +70,23 @@ type Field struct {
 	Name     string       // Name of the field in the database
 	JSONName string       // Name of the field in the JSON request
 	Type     reflect.Type // Go type
+	Enum     string       // Name of the registered enum restricting Where values, from the enum tag
+	Required bool         // Must be given on insert, from the sqld:"required" tag
+	ReadOnly bool         // Set by the database, never written, from the sqld:"readonly" tag
+	RawJSON  bool         // JSON returned as stored rather than decoded, from the sqld:"rawjson" tag
+
+	// Constraints checked by Insert and Update, from WithConstraint or LoadConstraints
+	NotNull    bool // Never null
+	HasDefault bool // Set by the database when omitted on insert
+	MaxLength  int  // Maximum number of characters of string values, 0 for no limit
+
+	SelectOnly bool // Returned but never used in conditions, from WithSelectOnlyFields
+	FilterOnly bool // Used in conditions but never returned, from WithFilterOnlyFields
+
+	Marshal     FieldMarshaler // Converts returned values, from WithFieldMarshaler
+	NullDefault interface{}    // Returned for null values under the NullDefault policy, from WithNullDefault
+
+	index []int // Index of the struct field, for reflect.Value.FieldByIndex
 }
 
 // OrderByClause defines how to sort results
@@ -57,22 +116,59 @@ type PaginationResponse struct {
 // It provides type-safe query building with runtime validation against model metadata.
 type QueryRequest struct {
 	// Select specifies which fields to retrieve. Field names must match the JSON tags
-	// in your model struct. This field is required and cannot be empty.
+	// in your model struct. When empty, the default select list of the model set with
+	// WithDefaultSelect is used; without one, this field is required.
 	// Each field name is validated against the model's metadata.
 	Select []string `json:"select"`
 
+	// Joins adds explicit joins to other registered models for cases without a
+	// registered relation. Joined fields are referenced as "<model>.<field>".
+	// Optional - if not provided, only registered relations are joined.
+	Joins []JoinClause `json:"joins,omitempty"`
+
+	// Lateral joins the top rows of has-many relations to each row, e.g. the
+	// 3 highest-paid employees of each department. See LateralJoin.
+	// Optional - if not provided, relations are joined without limit.
+	Lateral []LateralJoin `json:"lateral,omitempty"`
+
+	// With declares CTEs (named queries on the same model) that From and
+	// later CTEs can read from, emitted as a WITH clause. See CTE.
+	// Optional - if not provided, no WITH clause is generated.
+	With []CTE `json:"with,omitempty"`
+
+	// From names the CTE of With the query reads from instead of the table.
+	// Only the fields selected by that CTE are available.
+	// Optional - if not provided, the model's table is queried.
+	From string `json:"from,omitempty"`
+
+	// Tree restricts the query to the ancestors or descendants of a node of a
+	// model with a hierarchy registered with RegisterHierarchy. Where, OrderBy
+	// and pagination apply to the nodes found.
+	// Optional - if not provided, the whole table is queried.
+	Tree *TreeRequest `json:"tree,omitempty"`
+
 	// Where specifies filter conditions as key-value pairs. Keys must match JSON field
 	// names from your model, and values are type-checked against model field types.
 	// Optional - if not provided, no filtering is applied.
 	// Each field name is validated against the model's metadata.
 	Where map[string]interface{} `json:"where"`
 
+	// IncludeDeleted includes soft-deleted rows, of the model and of joined
+	// relations, for models registered with RegisterSoftDelete.
+	// Optional - by default, soft-deleted rows are skipped.
+	IncludeDeleted bool `json:"include_deleted,omitempty"`
+
 	// OrderBy specifies sorting criteria. Each OrderByClause contains a field name
 	// (must match JSON field names) and sort direction.
 	// Optional - if not provided, no sorting is applied.
 	// Each field name is validated against the model's metadata.
 	OrderBy []OrderByClause `json:"order_by,omitempty"`
 
+	// LimitBy keeps the first rows of each group of rows, e.g. the latest
+	// events of each user. See LimitByClause.
+	// Optional - if not provided, all rows of each group are kept.
+	LimitBy *LimitByClause `json:"limit_by,omitempty"`
+
 	// Pagination enables page-based result limiting. If provided, it takes precedence
 	// over direct Limit/Offset values. Uses DefaultPageSize (10) if not specified,
 	// and caps at MaxPageSize (100).
@@ -90,15 +186,56 @@ type QueryRequest struct {
 	// Optional - nil means no offset.
 	// Must be non-negative if provided.
 	Offset *int `json:"offset,omitempty"`
+
+	// Lock locks the returned rows until the end of the transaction, with
+	// FOR UPDATE or FOR SHARE. See LockRequest.
+	// Optional - if not provided, rows are not locked.
+	Lock *LockRequest `json:"lock,omitempty"`
+
+	// Pivot reshapes the result rows into a matrix, turning the distinct values
+	// of one field into columns. See PivotRequest for details.
+	// Optional - if not provided, rows are returned as-is.
+	// The pivot is applied after pagination, so it only covers the current page.
+	Pivot *PivotRequest `json:"pivot,omitempty"`
+
+	// Summary computes aggregates (e.g. totals) of fields over all rows
+	// matching Where, not just the current page. Results are returned in
+	// QueryResponse.Summary. See SummaryField.
+	// Optional - if not provided, no summary is computed.
+	Summary []SummaryField `json:"summary,omitempty"`
+
+	// Include names lookups registered for the model with RegisterLookup.
+	// Each lookup adds a key with its name to every row.
+	// Optional - if not provided, no lookups are run.
+	Include []string `json:"include,omitempty"`
+
+	// Nested eager loads fields of registered relations, keyed by relation name.
+	// Each row gets a nested object (belongs-to) or array (has-many, many-to-many)
+	// under the relation name. In JSON requests nested selections can also be
+	// written inline in select: {"select": ["id", {"department": ["id", "name"]}]}.
+	// Optional - if not provided, no relations are loaded.
+	Nested map[string][]string `json:"nested,omitempty"`
 }
 
 // QueryResponse represents the outgoing JSON structure
 type QueryResponse[T Model] struct {
 	Data       []QueryResult       `json:"data"`
+	Columns    []string            `json:"columns,omitempty"` // Pivoted column keys, set only when Pivot is requested
 	Pagination *PaginationResponse `json:"pagination,omitempty"`
 	Error      string              `json:"error,omitempty"`
-	// TODO: Add these fields for enhanced responses
-	// Metadata QueryMetadata `json:"metadata,omitempty"`
+	Warnings   []string            `json:"warnings,omitempty"` // Failures of optional lookups
+	// Summary holds the aggregates requested in QueryRequest.Summary, keyed
+	// by field and then by function: {"salary": {"sum": 1200, "avg": 400}}
+	Summary map[string]map[string]interface{} `json:"summary,omitempty"`
+	// Metadata describes how the query ran, set only for calls made
+	// WithMetadata
+	Metadata *QueryMetadata `json:"metadata,omitempty"`
+	// DryRun holds the statements the request would run, set only for calls
+	// made WithDryRun, which leave Data empty
+	DryRun *DryRun `json:"dry_run,omitempty"`
+	// Truncated is set when rows beyond the MaxRows of the model were
+	// dropped
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // QueryResult represents a single row as map of field name to value
@@ -120,11 +257,3 @@ type NullTime struct {
 	sql.NullTime
 	Valid bool
 }
-
-// TODO: Add metadata type for enhanced responses
-// type QueryMetadata struct {
-//     TotalRows    int           `json:"total_rows"`
-//     ExecutionTime time.Duration `json:"execution_time"`
-//     Page         int           `json:"page"`
-//     TotalPages   int           `json:"total_pages"`
-// }
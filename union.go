@@ -0,0 +1,241 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// UnionPart is one query of a union, created with NewUnionPart.
+type UnionPart struct {
+	model Model
+	req   QueryRequest
+}
+
+// unionQuery is a union part resolved against a registry.
+type unionQuery struct {
+	metadata ModelMetadata
+	req      QueryRequest
+}
+
+// UnionRequest combines the results of several queries over the same
+// projection, e.g. an active and an archived table.
+type UnionRequest struct {
+	// Parts are the queries to combine. All parts must select fields with
+	// the same JSON names and Go types, in the same order.
+	Parts []UnionPart
+
+	// All keeps duplicate rows (UNION ALL) instead of removing them (UNION).
+	All bool
+
+	// OrderBy sorts the combined rows by fields of the projection.
+	OrderBy []OrderByClause
+
+	// Pagination, Limit and Offset apply to the combined rows, with the same
+	// semantics as in QueryRequest.
+	Pagination *PaginationRequest
+	Limit      *int
+	Offset     *int
+}
+
+// UnionResponse holds the combined rows of a union.
+type UnionResponse struct {
+	Data       []QueryResult       `json:"data"`
+	Pagination *PaginationResponse `json:"pagination,omitempty"`
+}
+
+// NewUnionPart declares a query on model T for use in a union. The part
+// must not order or paginate its rows; this is done on the union. The part is
+// resolved and validated by ExecuteUnion, against the registry of its
+// context.
+func NewUnionPart[T Model](req QueryRequest) (UnionPart, error) {
+	if len(req.OrderBy) > 0 || req.Pagination != nil || req.Limit != nil || req.Offset != nil {
+		return UnionPart{}, fmt.Errorf("invalid union part: ordering and pagination apply to the whole union")
+	}
+	if req.Pivot != nil || len(req.Include) > 0 || len(req.Nested) > 0 {
+		return UnionPart{}, fmt.Errorf("invalid union part: pivot, include and nested are not supported")
+	}
+	var model T
+	return UnionPart{model: model, req: req}, nil
+}
+
+// prepare resolves the part against r for the caller of ctx and validates
+// its request like Execute does.
+func (p UnionPart) prepare(ctx context.Context, r *Registry) (unionQuery, error) {
+	metadata, req, err := r.prepareQuery(ctx, p.model, r.callerVariant(ctx, p.model, ""), p.req)
+	if err != nil {
+		return unionQuery{}, err
+	}
+	return unionQuery{metadata: metadata, req: req}, nil
+}
+
+// ExecuteUnion runs the union of the parts of u and returns the combined
+// rows, keyed by the JSON names of the projection.
+func ExecuteUnion(ctx context.Context, db interface{}, u UnionRequest) (*UnionResponse, error) {
+	if len(u.Parts) < 2 {
+		return nil, fmt.Errorf("failed to build union: a union needs at least two parts")
+	}
+	r := registryFromContext(ctx)
+	parts := make([]unionQuery, len(u.Parts))
+	for i, part := range u.Parts {
+		var err error
+		parts[i], err = part.prepare(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid union part %d: %w", i+1, err)
+		}
+	}
+
+	union, args, err := buildUnion(parts, u.All)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build union: %w", err)
+	}
+
+	db, err = resolveDB(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, offset := u.Limit, u.Offset
+	var pagination *PaginationRequest
+	if u.Pagination != nil {
+		pagination = ValidatePagination(u.Pagination)
+		pageSize := pagination.PageSize
+		pageOffset := CalculateOffset(pagination.Page, pagination.PageSize)
+		limit, offset = &pageSize, &pageOffset
+	}
+	// Parts are resolved against the same registry
+	first := parts[0]
+	dialect := first.metadata.dialect()
+	query, err := unionTail(dialect, first.req.Select, u.OrderBy, union, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	query, err = dialect.Placeholder().ReplacePlaceholders(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sql: %w", err)
+	}
+
+	var paginationResp *PaginationResponse
+	if pagination != nil {
+		countQuery, err := dialect.Placeholder().ReplacePlaceholders("SELECT COUNT(*) FROM " + tableAlias(dialect, "("+union+")", "sqld_union"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate count sql: %w", err)
+		}
+
+		var totalItems int
+		if err := getOne(ctx, db, &totalItems, countQuery, args...); err != nil {
+			return nil, fmt.Errorf("failed to get total count: %w", err)
+		}
+		paginationResp = CalculatePagination(totalItems, pagination.PageSize, pagination.Page)
+	}
+
+	var results []map[string]interface{}
+	if err := selectAll(ctx, db, &results, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	// Rows are named after the columns of the first part
+	data, err := toQueryResults(first.metadata, first.req.Select, results)
+	if err != nil {
+		return nil, err
+	}
+	return &UnionResponse{Data: data, Pagination: paginationResp}, nil
+}
+
+// buildUnion checks that parts are compatible and combines them, keeping
+// duplicate rows when all is set. The returned SQL uses ? placeholders.
+func buildUnion(parts []unionQuery, all bool) (string, []interface{}, error) {
+	first := parts[0]
+	for i, part := range parts[1:] {
+		if len(part.req.Select) != len(first.req.Select) {
+			return "", nil, fmt.Errorf("part %d selects %d fields, want %d", i+2, len(part.req.Select), len(first.req.Select))
+		}
+		for j, name := range part.req.Select {
+			if name != first.req.Select[j] {
+				return "", nil, fmt.Errorf("part %d selects %s at position %d, want %s", i+2, name, j+1, first.req.Select[j])
+			}
+			got, _ := part.metadata.lookupField(name)
+			want, _ := first.metadata.lookupField(name)
+			if got.Field.Type != want.Field.Type {
+				return "", nil, fmt.Errorf("part %d: field %s is %s, want %s", i+2, name, got.Field.Type, want.Field.Type)
+			}
+		}
+	}
+
+	operator := " UNION "
+	if all {
+		operator = " UNION ALL "
+	}
+
+	queries := make([]string, len(parts))
+	var args []interface{}
+	for i, part := range parts {
+		if part.req.Lock != nil {
+			return "", nil, fmt.Errorf("part %d: lock is not supported in unions", i+1)
+		}
+		query, err := buildSelect(part.metadata, part.req)
+		if err != nil {
+			return "", nil, fmt.Errorf("part %d: %w", i+1, err)
+		}
+		sql, partArgs, err := query.PlaceholderFormat(squirrel.Question).ToSql()
+		if err != nil {
+			return "", nil, fmt.Errorf("part %d: %w", i+1, err)
+		}
+		queries[i] = "(" + sql + ")"
+		args = append(args, partArgs...)
+	}
+	return strings.Join(queries, operator), args, nil
+}
+
+// unionTail appends the ORDER BY, LIMIT and OFFSET clauses to union, which
+// selects the fields in selected. Fields are ordered by position, as column
+// names differ between models.
+func unionTail(dialect Dialect, selected []string, orderBys []OrderByClause, union string, limit, offset *int) (string, error) {
+	var b strings.Builder
+	b.WriteString(union)
+
+	for i, orderBy := range orderBys {
+		position := -1
+		for j, name := range selected {
+			if name == orderBy.Field {
+				position = j + 1
+				break
+			}
+		}
+		if position < 0 {
+			return "", &UnknownFieldError{Field: orderBy.Field, In: "order by clause"}
+		}
+		if i == 0 {
+			b.WriteString(" ORDER BY ")
+		} else {
+			b.WriteString(", ")
+		}
+		direction := "ASC"
+		if orderBy.Desc {
+			direction = "DESC"
+		}
+		fmt.Fprintf(&b, "%d %s", position, direction)
+	}
+
+	var l, o *uint64
+	if limit != nil {
+		if *limit < 0 {
+			return "", fmt.Errorf("limit must be non-negative")
+		}
+		n := uint64(*limit)
+		l = &n
+	}
+	if offset != nil {
+		if *offset < 0 {
+			return "", fmt.Errorf("offset must be non-negative")
+		}
+		n := uint64(*offset)
+		o = &n
+	}
+	if clause := dialect.LimitOffset(l, o); clause != "" {
+		b.WriteString(" " + clause)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,150 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type UnionActiveAccount struct {
+	ID    int64  `json:"id" db:"id"`
+	Owner string `json:"owner" db:"owner_name"`
+	State string `json:"state" db:"state"`
+}
+
+func (UnionActiveAccount) TableName() string {
+	return "accounts"
+}
+
+type UnionArchivedAccount struct {
+	ID    int64  `json:"id" db:"id"`
+	Owner string `json:"owner" db:"owner"`
+	Year  int    `json:"state" db:"archived_year"`
+}
+
+func (UnionArchivedAccount) TableName() string {
+	return "archived_accounts"
+}
+
+func registerUnionModels(t *testing.T) {
+	t.Helper()
+	require.NoError(t, Register(UnionActiveAccount{}))
+	require.NoError(t, Register(UnionArchivedAccount{}))
+}
+
+func TestExecuteUnion(t *testing.T) {
+	registerUnionModels(t)
+
+	active, err := NewUnionPart[UnionActiveAccount](QueryRequest{
+		Select: []string{"id", "owner"},
+		Where:  map[string]interface{}{"state": "open"},
+	})
+	require.NoError(t, err)
+	archived, err := NewUnionPart[UnionArchivedAccount](QueryRequest{
+		Select: []string{"id", "owner"},
+		Where:  map[string]interface{}{"owner": "bob"},
+	})
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	union := `\(SELECT id, owner_name FROM accounts WHERE state = \$1\) UNION ALL \(SELECT id, owner FROM archived_accounts WHERE owner = \$2\)`
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM \(`+union+`\) AS sqld_union`).
+		WithArgs("open", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(union+` ORDER BY 2 ASC, 1 DESC LIMIT 2 OFFSET 2`).
+		WithArgs("open", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_name"}).AddRow(int64(9), "bob"))
+
+	resp, err := ExecuteUnion(context.Background(), db, UnionRequest{
+		Parts:      []UnionPart{active, archived},
+		All:        true,
+		OrderBy:    []OrderByClause{{Field: "owner"}, {Field: "id", Desc: true}},
+		Pagination: &PaginationRequest{Page: 2, PageSize: 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, resp.Pagination.TotalItems)
+	assert.Equal(t, 2, resp.Pagination.TotalPages)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "bob", resp.Data[0]["owner"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteUnion_Incompatible(t *testing.T) {
+	registerUnionModels(t)
+
+	idOwner, err := NewUnionPart[UnionActiveAccount](QueryRequest{Select: []string{"id", "owner"}})
+	require.NoError(t, err)
+	ownerID, err := NewUnionPart[UnionArchivedAccount](QueryRequest{Select: []string{"owner", "id"}})
+	require.NoError(t, err)
+	onlyID, err := NewUnionPart[UnionArchivedAccount](QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	activeState, err := NewUnionPart[UnionActiveAccount](QueryRequest{Select: []string{"state"}})
+	require.NoError(t, err)
+	archivedState, err := NewUnionPart[UnionArchivedAccount](QueryRequest{Select: []string{"state"}})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for name, u := range map[string]UnionRequest{
+		"single part":       {Parts: []UnionPart{idOwner}},
+		"different order":   {Parts: []UnionPart{idOwner, ownerID}},
+		"different lengths": {Parts: []UnionPart{idOwner, onlyID}},
+		"different types":   {Parts: []UnionPart{activeState, archivedState}},
+		"unknown order by":  {Parts: []UnionPart{onlyID, onlyID}, OrderBy: []OrderByClause{{Field: "owner"}}},
+	} {
+		_, err := ExecuteUnion(ctx, nil, u)
+		assert.Error(t, err, name)
+	}
+
+	_, err = NewUnionPart[UnionActiveAccount](QueryRequest{Select: []string{"id"}, OrderBy: []OrderByClause{{Field: "id"}}})
+	assert.Error(t, err, "parts can't be ordered")
+}
+
+func TestExecuteUnion_Registry(t *testing.T) {
+	// The models are registered with the registry of the context only
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(UnionActiveAccount{}))
+	require.NoError(t, registry.Register(UnionArchivedAccount{}, WithComplexityLimits(ComplexityLimits{MaxPredicates: 1})))
+	ctx := WithRegistry(context.Background(), registry)
+
+	active, err := NewUnionPart[UnionActiveAccount](QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	archived, err := NewUnionPart[UnionArchivedAccount](QueryRequest{
+		Select: []string{"id"},
+		Where:  map[string]interface{}{"owner": "bob"},
+	})
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`\(SELECT id FROM accounts\) UNION \(SELECT id FROM archived_accounts WHERE owner = \$1\)`).
+		WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+
+	resp, err := ExecuteUnion(ctx, db, UnionRequest{Parts: []UnionPart{active, archived}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(9)}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// Parts are validated when the union runs, before any query
+	unknown, err := NewUnionPart[UnionActiveAccount](QueryRequest{Select: []string{"balance"}})
+	require.NoError(t, err)
+	_, err = ExecuteUnion(ctx, nil, UnionRequest{Parts: []UnionPart{unknown, active}})
+	assert.Error(t, err)
+
+	tooComplex, err := NewUnionPart[UnionArchivedAccount](QueryRequest{
+		Select: []string{"id"},
+		Where:  map[string]interface{}{"owner": "bob", "id": 3},
+	})
+	require.NoError(t, err)
+	_, err = ExecuteUnion(ctx, nil, UnionRequest{Parts: []UnionPart{active, tooComplex}})
+	var complexityErr *ComplexityError
+	assert.ErrorAs(t, err, &complexityErr)
+}
@@ -0,0 +1,55 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// isUUIDType reports whether fields of type t hold UUIDs: uuid.UUID and
+// pgtype.UUID, their pointers and nullable wrappers such as uuid.NullUUID.
+func isUUIDType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if underlying, ok := nullValueType(t); ok {
+		t = underlying
+	}
+	return t == reflect.TypeOf(uuid.UUID{}) || t == reflect.TypeOf(pgtype.UUID{})
+}
+
+// uuidValue returns the canonical string of value, a UUID given as a string
+// in any of the forms uuid.Parse accepts or as a UUID value. Nil stays nil.
+func uuidValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a UUID", v)
+		}
+		return id.String(), nil
+	case []byte:
+		id, err := uuid.ParseBytes(v)
+		if err != nil {
+			id, err = uuid.FromBytes(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a UUID", v)
+		}
+		return id.String(), nil
+	case uuid.UUID:
+		return v.String(), nil
+	case [16]byte:
+		return uuid.UUID(v).String(), nil
+	case pgtype.UUID:
+		if !v.Valid {
+			return nil, nil
+		}
+		return uuid.UUID(v.Bytes).String(), nil
+	}
+	return nil, fmt.Errorf("expected a UUID, got %T", value)
+}
@@ -0,0 +1,90 @@
+package sqld
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type UUIDDocument struct {
+	ID     uuid.UUID   `json:"id" db:"id"`
+	Owner  pgtype.UUID `json:"owner" db:"owner"`
+	Parent *uuid.UUID  `json:"parent" db:"parent"`
+}
+
+func (UUIDDocument) TableName() string { return "documents" }
+
+type UUIDDocumentParams struct {
+	ID uuid.UUID `db:"id" json:"id"`
+}
+
+const testUUID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+func TestExecute_UUID(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(UUIDDocument{}))
+	ctx := WithRegistry(context.Background(), registry)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	owner := uuid.MustParse(testUUID)
+	mock.ExpectQuery(`SELECT id, owner, parent FROM documents WHERE id = \$1`).
+		WithArgs(testUUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner", "parent"}).AddRow(testUUID, owner[:], nil))
+	resp, err := Execute[UUIDDocument](ctx, db, QueryRequest{
+		Select: []string{"id", "owner", "parent"},
+		Where:  map[string]interface{}{"id": "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": testUUID, "owner": testUUID, "parent": nil}}, resp.Data)
+
+	mock.ExpectQuery(`SELECT id FROM documents WHERE owner IN \(\$1,\$2\)`).
+		WithArgs(testUUID, "00000000-0000-0000-0000-000000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = Execute[UUIDDocument](ctx, db, QueryRequest{
+		Select: []string{"id"},
+		Where:  map[string]interface{}{"owner": []interface{}{testUUID, uuid.Nil.String()}},
+	})
+	require.NoError(t, err)
+
+	_, err = Execute[UUIDDocument](ctx, db, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"id": "42"}})
+	assert.ErrorContains(t, err, `invalid value for field id: "42" is not a UUID`)
+	_, err = Execute[UUIDDocument](ctx, db, QueryRequest{Select: []string{"id"}, Where: map[string]interface{}{"id": 42}})
+	assert.ErrorContains(t, err, "invalid value for field id: expected a UUID, got int")
+
+	_, err = Insert[UUIDDocument](ctx, db, InsertRequest{Values: map[string]interface{}{"id": "not-a-uuid"}})
+	assert.ErrorContains(t, err, `invalid value for field id: "not-a-uuid" is not a UUID`)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteRaw_UUIDParam(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id FROM documents WHERE id = \$1`).
+		WithArgs(testUUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testUUID))
+	results, err := ExecuteRaw[UUIDDocumentParams, UUIDDocument](context.Background(), db,
+		"SELECT id FROM documents WHERE id = {{id}}", map[string]interface{}{"id": testUUID})
+	require.NoError(t, err)
+	assert.Equal(t, uuid.MustParse(testUUID), results[0]["id"])
+
+	_, err = ExecuteRaw[UUIDDocumentParams, UUIDDocument](context.Background(), db,
+		"SELECT id FROM documents WHERE id = {{id}}", map[string]interface{}{"id": "x"})
+	assert.ErrorContains(t, err, `parameter id: "x" is not a UUID`)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFieldType_UUID(t *testing.T) {
+	assert.Equal(t, "string", FieldType(reflect.TypeOf(uuid.UUID{})))
+	assert.Equal(t, "string", FieldType(reflect.TypeOf(uuid.NullUUID{})))
+}
@@ -9,29 +9,80 @@ type Validator interface {
 type BasicValidator struct{}
 
 func (v BasicValidator) ValidateQuery(req QueryRequest, metadata ModelMetadata) error {
+	scopes, err := resolveCTEs(metadata, req.With)
+	if err != nil {
+		return err
+	}
+	source, err := sourceMetadata(metadata, scopes, req.From)
+	if err != nil {
+		return err
+	}
+	if req.Tree != nil && len(req.With) > 0 {
+		return fmt.Errorf("tree can't be combined with CTEs")
+	}
+	return validateRequest(req, source)
+}
+
+// validateRequest checks the fields and settings of req against the metadata
+// of what it reads from. It returns a ValidationErrors listing every problem
+// found.
+func validateRequest(req QueryRequest, metadata ModelMetadata) error {
+	var errs ValidationErrors
 	if len(req.Select) == 0 {
-		return fmt.Errorf("select fields cannot be empty")
+		errs = errs.add(fmt.Errorf("select fields cannot be empty"))
 	}
 	for _, field := range req.Select {
-		if _, ok := metadata.Fields[field]; !ok {
-			return fmt.Errorf("invalid field in select: %s", field)
+		ref, ok := metadata.lookupField(field)
+		if !ok {
+			errs = errs.add(unknownField(metadata, field, "select"))
+			continue
 		}
+		errs = errs.add(ref.Field.checkSelectable(field))
 	}
-	for whereField := range req.Where {
-		if _, ok := metadata.Fields[whereField]; !ok {
-			return fmt.Errorf("invalid field in where clause: %s", whereField)
+	for _, whereField := range sortedKeys(req.Where) {
+		ref, ok := metadata.lookupField(whereField)
+		if !ok {
+			errs = errs.add(unknownField(metadata, whereField, "where clause"))
+			continue
+		}
+		if err := ref.Field.checkFilterable(whereField); err != nil {
+			errs = errs.add(err)
+			continue
+		}
+		if convert := canonicalValue(ref.Field.Type); convert != nil {
+			value := req.Where[whereField]
+			if _, err := conditionValue(value, convert); err != nil {
+				errs = errs.add(&TypeMismatchError{Field: whereField, Value: value, Err: err})
+			}
 		}
 	}
 	for _, orderBy := range req.OrderBy {
-		if _, ok := metadata.Fields[orderBy.Field]; !ok {
-			return fmt.Errorf("invalid field in order by clause: %s", orderBy.Field)
+		ref, ok := metadata.lookupField(orderBy.Field)
+		if !ok {
+			errs = errs.add(unknownField(metadata, orderBy.Field, "order by clause"))
+			continue
 		}
+		errs = errs.add(ref.Field.checkFilterable(orderBy.Field))
 	}
 	if req.Limit != nil && *req.Limit < 0 {
-		return fmt.Errorf("limit must be non-negative")
+		errs = errs.add(fmt.Errorf("limit must be non-negative"))
 	}
 	if req.Offset != nil && *req.Offset < 0 {
-		return fmt.Errorf("offset must be non-negative")
+		errs = errs.add(fmt.Errorf("offset must be non-negative"))
+	}
+	errs = errs.add(validateNested(metadata, req.Nested))
+	errs = errs.add(validateSummary(metadata, req.Summary))
+	if req.Tree != nil {
+		errs = errs.add(req.Tree.validate(metadata))
+	}
+	if req.LimitBy != nil {
+		errs = errs.add(req.LimitBy.validate(metadata))
+	}
+	if req.Lock != nil {
+		errs = errs.add(req.Lock.validate(req))
+	}
+	if req.Pivot != nil {
+		errs = errs.add(req.Pivot.validate(metadata, req))
 	}
-	return nil
+	return errs.err()
 }
@@ -0,0 +1,28 @@
+package sqld
+
+import "fmt"
+
+// ReadOnlyModel is implemented by models backed by a view or a materialized
+// view. Such models are queried like tables, but write operations on them are
+// rejected before any SQL is sent. Views don't need a primary key; every
+// field is only used for selecting, filtering and ordering.
+type ReadOnlyModel interface {
+	Model
+	ReadOnly() bool
+}
+
+// isReadOnly reports whether model is registered as read-only.
+func isReadOnly(model Model) bool {
+	ro, ok := model.(ReadOnlyModel)
+	return ok && ro.ReadOnly()
+}
+
+// checkWritable returns an error when metadata describes a read-only model
+// or the dialect is read-only.
+// Write operations call it before building any statement.
+func checkWritable(metadata ModelMetadata) error {
+	if metadata.ReadOnly {
+		return fmt.Errorf("model %s is read-only", metadata.TableName)
+	}
+	return checkDialectWritable(metadata.dialect(), "mutations")
+}
@@ -0,0 +1,73 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SalesReport is a read-only model backed by a materialized view.
+type SalesReport struct {
+	Region    string    `json:"region" db:"region"`
+	Total     float64   `json:"total" db:"total"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (SalesReport) TableName() string {
+	return "sales_report_mv"
+}
+
+func (SalesReport) ReadOnly() bool {
+	return true
+}
+
+func TestRegister_ReadOnly(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(SalesReport{}))
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+
+	report, err := registry.GetModelMetadata(SalesReport{})
+	require.NoError(t, err)
+	assert.True(t, report.ReadOnly)
+	assert.Error(t, checkWritable(report))
+
+	table, err := registry.GetModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+	assert.False(t, table.ReadOnly)
+	assert.NoError(t, checkWritable(table))
+}
+
+func TestExecute_ReadOnly(t *testing.T) {
+	require.NoError(t, Register(SalesReport{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT region, total FROM sales_report_mv ORDER BY total DESC`).
+		WillReturnRows(sqlmock.NewRows([]string{"region", "total"}).AddRow("north", 12.5))
+
+	resp, err := Execute[SalesReport](context.Background(), db, QueryRequest{
+		Select:  []string{"region", "total"},
+		OrderBy: []OrderByClause{{Field: "total", Desc: true}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"region": "north", "total": 12.5}}, resp.Data)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeOlderThan_ReadOnly(t *testing.T) {
+	require.NoError(t, Register(SalesReport{}))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = PurgeOlderThan[SalesReport](context.Background(), db, "updated_at", time.Hour, 10)
+	assert.ErrorContains(t, err, "read-only")
+	require.NoError(t, mock.ExpectationsWereMet())
+}